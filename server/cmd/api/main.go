@@ -1,17 +1,22 @@
 package main
 
 import (
+	"context"
 	"log"
 	"fmt"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"todo-backend/internal/config"
+	"todo-backend/internal/events"
 	"todo-backend/internal/handlers"
+	"todo-backend/internal/jobs"
 	"todo-backend/internal/middleware"
 	"todo-backend/internal/models"
 	"todo-backend/internal/repository"
 	"todo-backend/internal/services"
 	"todo-backend/pkg/database"
+	"todo-backend/pkg/storage"
 )
 
 func main() {
@@ -21,6 +26,11 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// The admin and webhook routes are refused without a token
+	if cfg.Server.AdminToken == "" {
+		log.Println("Warning: ADMIN_TOKEN is not set, the /api/admin and /api/webhooks routes are disabled")
+	}
+
 	// Initialize database
 	dbConfig := database.Config{
 		Host:     cfg.Database.Host,
@@ -60,10 +70,105 @@ func main() {
 	// Initialize repositories
 	todoRepo := repository.NewTodoRepository(db.GetDB())
 	categoryRepo := repository.NewCategoryRepository(db.GetDB())
+	workflowRepo := repository.NewWorkflowRepository(db.GetDB())
+	commentRepo := repository.NewCommentRepository(db.GetDB())
+	attachmentRepo := repository.NewAttachmentRepository(db.GetDB())
+	trashRepo := repository.NewTrashRepository(db.GetDB())
+	auditRepo := repository.NewAuditRepository(db.GetDB())
+	webhookRepo := repository.NewWebhookRepository(db.GetDB())
+	syncRepo := repository.NewSyncRepository(db.GetDB())
+	idempotencyRepo := repository.NewIdempotencyRepository(db.GetDB())
+	calendarRepo := repository.NewCalendarRepository(db.GetDB())
+	caldavRepo := repository.NewCalDAVRepository(db.GetDB())
+	savedFilterRepo := repository.NewSavedFilterRepository(db.GetDB())
+
+	// Initialize attachment storage
+	attachmentStorage, err := storage.New(storage.Config{
+		Driver:         cfg.Storage.Driver,
+		LocalPath:      cfg.Storage.LocalPath,
+		S3Endpoint:     cfg.Storage.S3Endpoint,
+		S3Region:       cfg.Storage.S3Region,
+		S3Bucket:       cfg.Storage.S3Bucket,
+		S3AccessKey:    cfg.Storage.S3AccessKey,
+		S3SecretKey:    cfg.Storage.S3SecretKey,
+		S3UsePathStyle: cfg.Storage.S3UsePathStyle,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize attachment storage: %v", err)
+	}
 
 	// Initialize services
-	todoService := services.NewTodoService(todoRepo, categoryRepo)
+	workflowService := services.NewWorkflowService(workflowRepo, categoryRepo)
+	todoService := services.NewTodoService(todoRepo, categoryRepo, workflowService)
 	categoryService := services.NewCategoryService(categoryRepo)
+	commentService := services.NewCommentService(commentRepo)
+	attachmentService := services.NewAttachmentService(attachmentRepo, attachmentStorage, cfg.Storage.MaxUploadSize, cfg.Storage.AllowedMIMETypes)
+	trashService := services.NewTrashService(trashRepo, todoRepo, categoryRepo, workflowService, attachmentService, cfg.Jobs.TrashRetentionDays)
+	auditService := services.NewAuditService(auditRepo)
+	webhookService := services.NewWebhookService(webhookRepo, cfg.Webhooks.Timeout, cfg.Webhooks.MaxAttempts, cfg.Webhooks.RetryBase, cfg.Webhooks.RetryMax)
+	syncService := services.NewSyncService(syncRepo, todoRepo, todoService, categoryService)
+	calendarService := services.NewCalendarService(calendarRepo, categoryRepo)
+	caldavService := services.NewCalDAVService(caldavRepo, todoRepo, categoryRepo, syncRepo, todoService)
+	smartListService := services.NewSmartListService(savedFilterRepo, categoryRepo, todoService)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, cfg.Server.IdempotencyTTL)
+
+	// Seed the default workflow and assign statuses to existing todos
+	if err := workflowService.EnsureDefaultWorkflow(); err != nil {
+		log.Fatalf("Failed to initialize default workflow: %v", err)
+	}
+
+	// Give todos without a manual order position one before serving requests
+	if _, err := todoService.RebalancePositions(cfg.Jobs.PositionMaxLength); err != nil {
+		log.Fatalf("Failed to initialize todo positions: %v", err)
+	}
+
+	// Start background jobs, their changes are recorded in the audit log as made by the system
+	jobContext := func(name string) context.Context {
+		return repository.WithAuditInfo(context.Background(), repository.AuditInfo{Actor: models.AuditActorSystem, Route: name})
+	}
+	scheduler := jobs.NewScheduler()
+	scheduler.Add("rebalance-positions", cfg.Jobs.PositionRebalanceInterval, func() error {
+		updated, err := todoService.WithContext(jobContext("rebalance-positions")).RebalancePositions(cfg.Jobs.PositionMaxLength)
+		if updated > 0 {
+			log.Printf("Rebalanced positions of %d todos", updated)
+		}
+		return err
+	})
+	if cfg.Jobs.TrashRetentionDays > 0 {
+		scheduler.Add("purge-trash", cfg.Jobs.TrashPurgeInterval, func() error {
+			purged, err := trashService.WithContext(jobContext("purge-trash")).PurgeExpired()
+			if purged > 0 {
+				log.Printf("Purged %d items deleted more than %d days ago", purged, cfg.Jobs.TrashRetentionDays)
+			}
+			return err
+		})
+	}
+	if cfg.Jobs.ArchiveAfterDays > 0 {
+		archiveDelay := time.Duration(cfg.Jobs.ArchiveAfterDays) * 24 * time.Hour
+		scheduler.Add("archive-completed", cfg.Jobs.ArchiveInterval, func() error {
+			archived, err := todoService.WithContext(jobContext("archive-completed")).AutoArchive(archiveDelay)
+			if archived > 0 {
+				log.Printf("Archived %d todos completed more than %d days ago", archived, cfg.Jobs.ArchiveAfterDays)
+			}
+			return err
+		})
+	}
+	scheduler.Add("purge-idempotency-keys", cfg.Jobs.IdempotencyPurgeInterval, func() error {
+		_, err := idempotencyService.PurgeExpired()
+		return err
+	})
+	scheduler.Add("deliver-webhooks", cfg.Webhooks.DeliveryInterval, func() error {
+		_, err := webhookService.DeliverDue()
+		return err
+	})
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	// Relay change events from all API instances to the event streams of this one
+	eventBus := events.NewBus(cfg.Server.EventsReplay)
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+	go events.Relay(relayCtx, db, eventBus)
 
 	// Initialize Gin router
 	if cfg.IsProduction() {
@@ -74,13 +179,38 @@ func main() {
 
 	// Add middleware
 	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.RequestID())
 	router.Use(middleware.StructuredLogger())
 	router.Use(middleware.CORS())
 	router.Use(middleware.Security())
 	router.Use(middleware.RateLimitHeaders())
+	if cfg.Server.RequireIfMatch {
+		router.Use(middleware.RequireIfMatch("/api/todos/:id", "/api/categories/:id"))
+	}
+
+	// Replay responses to retried create and bulk requests that carry an Idempotency-Key
+	router.Use(middleware.Idempotency(idempotencyService, cfg.Storage.MaxUploadSize+1<<20,
+		"/api/todos",
+		"/api/todos/bulk",
+		"/api/todos/quick",
+		"/api/todos/archive-completed",
+		"/api/todos/:id/comments",
+		"/api/todos/:id/attachments",
+		"/api/categories",
+		"/api/workflow/statuses",
+		"/api/workflow/transitions",
+		"/api/sync",
+		"/api/import",
+		"/api/todos.txt",
+		"/api/calendar/feeds",
+		"/api/smart-lists",
+	))
+
+	// Identify who made each request in the audit log
+	router.Use(middleware.Audit())
 
 	// Setup routes
-	handlers.SetupRoutes(router, todoService, categoryService)
+	handlers.SetupRoutes(router, todoService, categoryService, workflowService, commentService, attachmentService, trashService, auditService, webhookService, syncService, calendarService, caldavService, smartListService, eventBus, cfg.Server.AdminToken, cfg.Server.CalDAVPassword, cfg.Storage.MaxUploadSize)
 
 	// Handle 404
 	router.NoRoute(middleware.NotFoundHandler())
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,12 +14,20 @@ import (
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
+	Storage  StorageConfig
+	Jobs     JobsConfig
+	Webhooks WebhooksConfig
 }
 
 // ServerConfig holds server-specific configuration
 type ServerConfig struct {
-	Port string
-	Env  string
+	Port           string
+	Env            string
+	RequireIfMatch bool          // reject updates and deletes of todos and categories without If-Match
+	AdminToken     string        // bearer token for /api/admin and /api/webhooks routes, empty disables them
+	EventsReplay   int           // number of change events kept for clients resuming /api/events
+	IdempotencyTTL time.Duration // how long responses to requests with an Idempotency-Key are replayed
+	CalDAVPassword string        // Basic auth password for /caldav, empty leaves it open
 }
 
 // DatabaseConfig holds database-specific configuration
@@ -31,6 +41,41 @@ type DatabaseConfig struct {
 	TimeZone string
 }
 
+// StorageConfig holds attachment storage configuration
+type StorageConfig struct {
+	Driver           string // "local" or "s3"
+	LocalPath        string
+	MaxUploadSize    int64 // in bytes
+	AllowedMIMETypes []string
+
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UsePathStyle bool
+}
+
+// JobsConfig holds background job configuration
+type JobsConfig struct {
+	PositionRebalanceInterval time.Duration
+	PositionMaxLength         int
+	TrashPurgeInterval        time.Duration
+	TrashRetentionDays        int // 0 keeps deleted items until deleted permanently
+	ArchiveInterval           time.Duration
+	ArchiveAfterDays          int // 0 disables automatic archiving
+	IdempotencyPurgeInterval  time.Duration
+}
+
+// WebhooksConfig holds webhook delivery configuration
+type WebhooksConfig struct {
+	DeliveryInterval time.Duration // how often the delivery queue is polled, 0 stops sending
+	Timeout          time.Duration
+	MaxAttempts      int
+	RetryBase        time.Duration // delay after the first failed attempt, doubled after each further one
+	RetryMax         time.Duration
+}
+
 // Load loads configuration from environment variables
 // It attempts to load from .env file first, then falls back to system env vars
 func Load() (*Config, error) {
@@ -39,8 +84,13 @@ func Load() (*Config, error) {
 
 	config := &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
-			Env:  getEnv("APP_ENV", "development"),
+			Port:           getEnv("PORT", "8080"),
+			Env:            getEnv("APP_ENV", "development"),
+			RequireIfMatch: getEnv("REQUIRE_IF_MATCH", "false") == "true",
+			AdminToken:     getEnv("ADMIN_TOKEN", ""),
+			EventsReplay:   int(getEnvInt64("EVENTS_REPLAY_SIZE", 500)),
+			IdempotencyTTL: getEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+			CalDAVPassword: getEnv("CALDAV_PASSWORD", ""),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -51,6 +101,34 @@ func Load() (*Config, error) {
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
 			TimeZone: getEnv("DB_TIMEZONE", "UTC"),
 		},
+		Storage: StorageConfig{
+			Driver:           getEnv("STORAGE_DRIVER", "local"),
+			LocalPath:        getEnv("STORAGE_LOCAL_PATH", "./data/attachments"),
+			MaxUploadSize:    getEnvInt64("MAX_UPLOAD_SIZE_MB", 10) * 1024 * 1024,
+			AllowedMIMETypes: getEnvList("ALLOWED_UPLOAD_TYPES", "image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain"),
+			S3Endpoint:       getEnv("S3_ENDPOINT", ""),
+			S3Region:         getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:         getEnv("S3_BUCKET", ""),
+			S3AccessKey:      getEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey:      getEnv("S3_SECRET_KEY", ""),
+			S3UsePathStyle:   getEnv("S3_USE_PATH_STYLE", "true") == "true",
+		},
+		Jobs: JobsConfig{
+			PositionRebalanceInterval: getEnvDuration("POSITION_REBALANCE_INTERVAL", time.Hour),
+			PositionMaxLength:         int(getEnvInt64("POSITION_MAX_LENGTH", 24)),
+			TrashPurgeInterval:        getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
+			TrashRetentionDays:        int(getEnvInt64("TRASH_RETENTION_DAYS", 30)),
+			ArchiveInterval:           getEnvDuration("ARCHIVE_INTERVAL", time.Hour),
+			ArchiveAfterDays:          int(getEnvInt64("ARCHIVE_COMPLETED_AFTER_DAYS", 0)),
+			IdempotencyPurgeInterval:  getEnvDuration("IDEMPOTENCY_PURGE_INTERVAL", time.Hour),
+		},
+		Webhooks: WebhooksConfig{
+			DeliveryInterval: getEnvDuration("WEBHOOK_DELIVERY_INTERVAL", 5*time.Second),
+			Timeout:          getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:      int(getEnvInt64("WEBHOOK_MAX_ATTEMPTS", 8)),
+			RetryBase:        getEnvDuration("WEBHOOK_RETRY_BASE", 30*time.Second),
+			RetryMax:         getEnvDuration("WEBHOOK_RETRY_MAX", 6*time.Hour),
+		},
 	}
 
 	// Validate required configuration
@@ -82,6 +160,49 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("DB_PORT must be a valid number: %w", err)
 	}
 
+	// Validate attachment storage
+	switch c.Storage.Driver {
+	case "local":
+	case "s3":
+		if c.Storage.S3Endpoint == "" || c.Storage.S3Bucket == "" {
+			return fmt.Errorf("S3_ENDPOINT and S3_BUCKET are required when STORAGE_DRIVER is s3")
+		}
+	default:
+		return fmt.Errorf("STORAGE_DRIVER must be either local or s3")
+	}
+	if c.Storage.MaxUploadSize <= 0 {
+		return fmt.Errorf("MAX_UPLOAD_SIZE_MB must be a positive number")
+	}
+
+	if c.Server.EventsReplay < 0 {
+		return fmt.Errorf("EVENTS_REPLAY_SIZE must not be negative")
+	}
+	if c.Server.IdempotencyTTL <= 0 {
+		return fmt.Errorf("IDEMPOTENCY_KEY_TTL must be a positive duration")
+	}
+
+	// Validate background jobs
+	if c.Jobs.PositionMaxLength < 8 {
+		return fmt.Errorf("POSITION_MAX_LENGTH must be at least 8")
+	}
+	if c.Jobs.TrashRetentionDays < 0 {
+		return fmt.Errorf("TRASH_RETENTION_DAYS must not be negative")
+	}
+	if c.Jobs.ArchiveAfterDays < 0 {
+		return fmt.Errorf("ARCHIVE_COMPLETED_AFTER_DAYS must not be negative")
+	}
+
+	// Validate webhook delivery
+	if c.Webhooks.Timeout <= 0 {
+		return fmt.Errorf("WEBHOOK_TIMEOUT must be a positive duration")
+	}
+	if c.Webhooks.MaxAttempts < 1 {
+		return fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.Webhooks.RetryBase <= 0 || c.Webhooks.RetryMax < c.Webhooks.RetryBase {
+		return fmt.Errorf("WEBHOOK_RETRY_BASE must be positive and not exceed WEBHOOK_RETRY_MAX")
+	}
+
 	return nil
 }
 
@@ -101,4 +222,37 @@ func getEnv(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+// getEnvInt64 gets an integer environment variable with a fallback default value
+// Invalid numbers fall back to the default value
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDuration gets a duration environment variable such as "30m" with a fallback default value
+// Invalid durations fall back to the default value, "0" disables the related job
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList gets a comma-separated environment variable as a trimmed list
+func getEnvList(key, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,185 @@
+package events
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// subscriberBuffer is the number of events a subscriber may fall behind before it has to resynchronize
+const subscriberBuffer = 64
+
+// Event is a change event received from the database, kept as the JSON payload it arrived as
+type Event struct {
+	ID         string
+	Type       string
+	EntityType string
+	CategoryID *uint
+	Payload    []byte
+}
+
+// Decode parses a change event payload
+func Decode(payload []byte) (Event, error) {
+	var header struct {
+		ID         string `json:"id"`
+		Type       string `json:"type"`
+		EntityType string `json:"entity_type"`
+		CategoryID *uint  `json:"category_id"`
+	}
+	if err := json.Unmarshal(payload, &header); err != nil {
+		return Event{}, err
+	}
+	return Event{
+		ID:         header.ID,
+		Type:       header.Type,
+		EntityType: header.EntityType,
+		CategoryID: header.CategoryID,
+		Payload:    payload,
+	}, nil
+}
+
+// Filter selects the events a subscriber receives
+// Types holds entity types such as "todo" or event types such as "category.deleted";
+// empty fields select everything
+type Filter struct {
+	Types       []string
+	CategoryIDs []uint
+}
+
+// Match reports whether an event passes the filter
+func (f Filter) Match(event Event) bool {
+	if len(f.Types) > 0 {
+		matched := false
+		for _, t := range f.Types {
+			if t == event.Type || t == event.EntityType {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(f.CategoryIDs) > 0 {
+		if event.CategoryID == nil {
+			return false
+		}
+		for _, id := range f.CategoryIDs {
+			if id == *event.CategoryID {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Subscription receives the events of a bus that pass its filter
+type Subscription struct {
+	filter Filter
+
+	// Events delivers matching events in commit order
+	Events chan Event
+	// Resync is signalled when events were missed, the subscriber should reload its data
+	Resync chan struct{}
+}
+
+// Bus fans change events out to the subscribers of this API instance
+// It keeps the last events in a replay buffer so reconnecting clients can resume where they left off
+type Bus struct {
+	mu          sync.Mutex
+	replay      []Event
+	replaySize  int
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBus creates a bus that keeps the last replaySize events for resuming clients
+func NewBus(replaySize int) *Bus {
+	return &Bus{
+		replaySize:  replaySize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish sends an event to all matching subscribers and adds it to the replay buffer
+// A subscriber that cannot keep up misses the event and is asked to resynchronize
+func (b *Bus) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.replaySize > 0 {
+		if len(b.replay) >= b.replaySize {
+			b.replay = append(b.replay[:0], b.replay[len(b.replay)-b.replaySize+1:]...)
+		}
+		b.replay = append(b.replay, event)
+	}
+
+	for subscription := range b.subscribers {
+		if !subscription.filter.Match(event) {
+			continue
+		}
+		select {
+		case subscription.Events <- event:
+		default:
+			signal(subscription.Resync)
+		}
+	}
+}
+
+// Reset empties the replay buffer and asks all subscribers to resynchronize
+// It is called when events may have been lost, e.g. after the database connection dropped
+func (b *Bus) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.replay = nil
+	for subscription := range b.subscribers {
+		signal(subscription.Resync)
+	}
+}
+
+// Subscribe registers a subscriber and returns the buffered events after lastEventID that pass the filter
+// resumed is false when lastEventID is set but no longer buffered, the subscriber then has to resynchronize
+func (b *Bus) Subscribe(filter Filter, lastEventID string) (subscription *Subscription, replay []Event, resumed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subscription = &Subscription{
+		filter: filter,
+		Events: make(chan Event, subscriberBuffer),
+		Resync: make(chan struct{}, 1),
+	}
+	b.subscribers[subscription] = struct{}{}
+
+	if lastEventID == "" {
+		return subscription, nil, true
+	}
+	for i := len(b.replay) - 1; i >= 0; i-- {
+		if b.replay[i].ID != lastEventID {
+			continue
+		}
+		for _, event := range b.replay[i+1:] {
+			if filter.Match(event) {
+				replay = append(replay, event)
+			}
+		}
+		return subscription, replay, true
+	}
+	return subscription, nil, false
+}
+
+// Unsubscribe removes a subscriber
+func (b *Bus) Unsubscribe(subscription *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribers, subscription)
+}
+
+// signal sends to a channel without blocking, a pending signal is enough
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,45 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/pkg/database"
+)
+
+// Relay delivers the change events every API instance publishes through Postgres NOTIFY to the bus
+// It reconnects after a failure and resets the bus, since notifications sent meanwhile are lost
+func Relay(ctx context.Context, db *database.Database, bus *Bus) {
+	delay := time.Second
+	for {
+		connected := time.Now()
+		err := db.Listen(ctx, models.ChangeEventsChannel, func(payload string) {
+			event, err := Decode([]byte(payload))
+			if err != nil {
+				log.Printf("Ignoring invalid change event: %v", err)
+				return
+			}
+			bus.Publish(event)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		bus.Reset()
+		if time.Since(connected) > time.Minute {
+			delay = time.Second
+		}
+		log.Printf("Change event listener stopped: %v, reconnecting in %v", err, delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay < 30*time.Second {
+			delay *= 2
+		}
+	}
+}
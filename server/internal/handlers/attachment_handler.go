@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/internal/services"
+	"todo-backend/pkg/utils"
+)
+
+// multipartOverhead is the allowance for multipart headers and boundaries on top of the file size
+const multipartOverhead = 1 << 20
+
+// AttachmentHandler handles HTTP requests for todo attachments
+type AttachmentHandler struct {
+	attachmentService services.AttachmentService
+	maxUploadSize     int64
+}
+
+// NewAttachmentHandler creates a new attachment handler
+func NewAttachmentHandler(attachmentService services.AttachmentService, maxUploadSize int64) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		maxUploadSize:     maxUploadSize,
+	}
+}
+
+// service returns the attachment service scoped to the request, so its changes are audited with it
+func (h *AttachmentHandler) service(c *gin.Context) services.AttachmentService {
+	return h.attachmentService.WithContext(c.Request.Context())
+}
+
+// UploadAttachment handles POST /api/todos/:id/attachments
+// Expects a multipart/form-data body with the file in the "file" field
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	// Extract todo ID from URL parameter
+	todoID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Stream the multipart body instead of buffering the whole form
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			utils.ValidationErrorResponse(c, errors.New("file field is required"))
+			return
+		}
+		if err != nil {
+			h.uploadErrorResponse(c, err)
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		// Store the file using service
+		attachment, err := h.service(c).UploadAttachment(uint(todoID), part.FileName(), part)
+		part.Close()
+		if err != nil {
+			h.uploadErrorResponse(c, err)
+			return
+		}
+
+		utils.SuccessResponse(c, http.StatusCreated, "Attachment uploaded successfully", attachment)
+		return
+	}
+}
+
+// ListAttachments handles GET /api/todos/:id/attachments
+func (h *AttachmentHandler) ListAttachments(c *gin.Context) {
+	// Extract todo ID from URL parameter
+	todoID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Get attachments using service
+	attachments, err := h.service(c).ListAttachments(uint(todoID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Todo")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Attachments retrieved successfully", attachments)
+}
+
+// DownloadAttachment handles GET /api/todos/:id/attachments/:attachment_id
+// Supports Range and conditional requests through http.ServeContent
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	// Extract IDs from URL parameters
+	todoID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	attachmentID, err := strconv.ParseUint(c.Param("attachment_id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Open attachment content using service
+	attachment, object, err := h.service(c).OpenAttachment(uint(todoID), uint(attachmentID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Attachment")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+	defer object.Close()
+
+	disposition := "attachment"
+	if c.Query("inline") == "true" {
+		disposition = "inline"
+	}
+
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}))
+	c.Header("ETag", `"`+attachment.Checksum+`"`)
+	c.Header("Cache-Control", "private, max-age=0, must-revalidate")
+
+	http.ServeContent(c.Writer, c.Request, attachment.FileName, attachment.CreatedAt, object)
+}
+
+// DeleteAttachment handles DELETE /api/todos/:id/attachments/:attachment_id
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	// Extract IDs from URL parameters
+	todoID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	attachmentID, err := strconv.ParseUint(c.Param("attachment_id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Delete the attachment using service
+	if err := h.service(c).DeleteAttachment(uint(todoID), uint(attachmentID)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Attachment")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Attachment deleted successfully", nil)
+}
+
+// uploadErrorResponse maps upload errors to HTTP responses
+func (h *AttachmentHandler) uploadErrorResponse(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr) || strings.Contains(err.Error(), "exceeds maximum size"):
+		utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "File exceeds maximum size of "+strconv.FormatInt(h.maxUploadSize, 10)+" bytes")
+	case strings.Contains(err.Error(), "not allowed"):
+		utils.ErrorResponse(c, http.StatusUnsupportedMediaType, err.Error())
+	case strings.Contains(err.Error(), "todo not found"):
+		utils.NotFoundErrorResponse(c, "Todo")
+	case strings.Contains(err.Error(), "invalid") ||
+		strings.Contains(err.Error(), "required") ||
+		strings.Contains(err.Error(), "empty") ||
+		strings.Contains(err.Error(), "multipart"):
+		utils.ValidationErrorResponse(c, err)
+	default:
+		utils.InternalServerErrorResponse(c, err)
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/internal/repository"
+	"todo-backend/internal/services"
+	"todo-backend/pkg/utils"
+)
+
+// AuditHandler handles HTTP requests for the audit log
+type AuditHandler struct {
+	auditService services.AuditService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListEvents handles GET /api/admin/audit
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	var filters repository.AuditFilters
+	var pagination repository.PaginationParams
+
+	// Bind query parameters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Get audit events using service
+	events, paginationResult, err := h.auditService.ListEvents(filters, pagination)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Audit events retrieved successfully", events, paginationResult)
+}
+
+// ExportEvents handles GET /api/admin/audit/export
+// Streams the matching audit events as JSON Lines, oldest first
+func (h *AuditHandler) ExportEvents(c *gin.Context) {
+	var filters repository.AuditFilters
+
+	// Bind query parameters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit-events.jsonl"`)
+
+	// Stream audit events using service
+	if err := h.auditService.ExportEvents(filters, c.Writer); err != nil {
+		// Once events have been written the status can no longer change
+		if c.Writer.Written() {
+			log.Printf("Failed to export audit events: %v", err)
+			return
+		}
+		c.Header("Content-Type", "")
+		c.Header("Content-Disposition", "")
+		if strings.Contains(err.Error(), "invalid") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	// An empty export still answers 200
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,672 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/internal/services"
+	"todo-backend/pkg/utils"
+)
+
+// CalDAV paths; the principal is also the root of the server
+const (
+	caldavRoot      = "/caldav/"
+	caldavCalendars = "/caldav/calendars/"
+)
+
+// XML namespaces of WebDAV and CalDAV properties
+const (
+	nsDAV            = "DAV:"
+	nsCalDAV         = "urn:ietf:params:xml:ns:caldav"
+	nsCalendarServer = "http://calendarserver.org/ns/"
+	nsAppleICal      = "http://apple.com/ns/ical/"
+)
+
+// caldavMethods are the methods answered on CalDAV paths
+var caldavMethods = []string{"OPTIONS", "PROPFIND", "PROPPATCH", "REPORT", "GET", "HEAD", "PUT", "DELETE"}
+
+// maxCalDAVObjectSize is the largest calendar object accepted by PUT, in bytes
+const maxCalDAVObjectSize = 1 << 20
+
+// CalDAVHandler serves categories as CalDAV calendars of VTODO resources
+type CalDAVHandler struct {
+	caldavService services.CalDAVService
+}
+
+// NewCalDAVHandler creates a new CalDAV handler
+func NewCalDAVHandler(caldavService services.CalDAVService) *CalDAVHandler {
+	return &CalDAVHandler{
+		caldavService: caldavService,
+	}
+}
+
+// service returns the CalDAV service scoped to the request, so its changes are audited with it
+func (h *CalDAVHandler) service(c *gin.Context) services.CalDAVService {
+	return h.caldavService.WithContext(c.Request.Context())
+}
+
+// davResource is a resource addressed by a CalDAV path
+// The principal has no calendar, the calendar home has calendar "", calendars have no object
+type davResource struct {
+	home     bool
+	calendar string
+	object   string
+}
+
+// parseCalDAVPath splits a path below /caldav into the resource it addresses
+func parseCalDAVPath(path string) (davResource, bool) {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return davResource{}, true
+	}
+
+	parts := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	if parts[0] != "calendars" || len(parts) > 3 {
+		return davResource{}, false
+	}
+	resource := davResource{home: true}
+	if len(parts) > 1 {
+		resource.calendar = parts[1]
+	}
+	if len(parts) > 2 {
+		resource.object = parts[2]
+	}
+	return resource, true
+}
+
+// Serve handles all methods on /caldav/*path
+func (h *CalDAVHandler) Serve(c *gin.Context) {
+	resource, ok := parseCalDAVPath(c.Param("path"))
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Header("DAV", "1, 3, calendar-access")
+	switch c.Request.Method {
+	case "OPTIONS":
+		c.Header("Allow", strings.Join(caldavMethods, ", "))
+		c.Status(http.StatusOK)
+	case "PROPFIND":
+		h.propfind(c, resource)
+	case "PROPPATCH":
+		// Calendars mirror categories, whose names and colors are changed through the API
+		c.Status(http.StatusForbidden)
+	case "REPORT":
+		h.report(c, resource)
+	case "GET", "HEAD":
+		h.get(c, resource)
+	case "PUT":
+		h.put(c, resource)
+	case "DELETE":
+		h.delete(c, resource)
+	default:
+		c.Header("Allow", strings.Join(caldavMethods, ", "))
+		c.Status(http.StatusMethodNotAllowed)
+	}
+}
+
+// WellKnown handles /.well-known/caldav, pointing clients to the CalDAV root
+func (h *CalDAVHandler) WellKnown(c *gin.Context) {
+	c.Redirect(http.StatusMovedPermanently, caldavRoot)
+}
+
+// davPropfind is the body of a PROPFIND request
+type davPropfind struct {
+	Prop    *davPropNames `xml:"DAV: prop"`
+	AllProp *struct{}     `xml:"DAV: allprop"`
+}
+
+// davPropNames lists the properties a request asks for
+type davPropNames struct {
+	Names []struct {
+		XMLName xml.Name
+	} `xml:",any"`
+}
+
+// names returns the requested property names
+func (p *davPropNames) names() []xml.Name {
+	if p == nil {
+		return nil
+	}
+	names := make([]xml.Name, len(p.Names))
+	for i, name := range p.Names {
+		names[i] = name.XMLName
+	}
+	return names
+}
+
+// propfind answers PROPFIND with the properties of the resource and, for Depth 1, its members
+// A missing body asks for all properties, as does allprop
+func (h *CalDAVHandler) propfind(c *gin.Context, resource davResource) {
+	var body davPropfind
+	if err := decodeDAVBody(c, &body); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	var names []xml.Name
+	if body.AllProp == nil {
+		names = body.Prop.names()
+	}
+	depth := c.GetHeader("Depth")
+
+	var responses []davResponse
+	switch {
+	case !resource.home:
+		responses = append(responses, propResponse(caldavRoot, principalProps(), names))
+		if depth != "0" {
+			responses = append(responses, propResponse(caldavCalendars, homeProps(), names))
+		}
+
+	case resource.calendar == "":
+		responses = append(responses, propResponse(caldavCalendars, homeProps(), names))
+		if depth != "0" {
+			calendars, err := h.service(c).Calendars()
+			if err != nil {
+				utils.InternalServerErrorResponse(c, err)
+				return
+			}
+			ctag, err := h.service(c).CTag()
+			if err != nil {
+				utils.InternalServerErrorResponse(c, err)
+				return
+			}
+			for i := range calendars {
+				responses = append(responses, propResponse(calendarHref(calendars[i].Name), calendarProps(&calendars[i], ctag), names))
+			}
+		}
+
+	case resource.object == "":
+		calendar, err := h.service(c).Calendar(resource.calendar)
+		if err != nil {
+			h.errorResponse(c, err)
+			return
+		}
+		ctag, err := h.service(c).CTag()
+		if err != nil {
+			utils.InternalServerErrorResponse(c, err)
+			return
+		}
+		responses = append(responses, propResponse(calendarHref(calendar.Name), calendarProps(calendar, ctag), names))
+		if depth != "0" {
+			objects, err := h.service(c).Objects(resource.calendar)
+			if err != nil {
+				h.errorResponse(c, err)
+				return
+			}
+			for i := range objects {
+				responses = append(responses, propResponse(objectHref(resource.calendar, objects[i].Name), objectProps(&objects[i], names), names))
+			}
+		}
+
+	default:
+		object, err := h.service(c).Object(resource.calendar, resource.object)
+		if err != nil {
+			h.errorResponse(c, err)
+			return
+		}
+		responses = append(responses, propResponse(objectHref(resource.calendar, object.Name), objectProps(object, names), names))
+	}
+
+	writeMultistatus(c, responses)
+}
+
+// davReport is the body of a calendar-query or calendar-multiget REPORT
+type davReport struct {
+	XMLName xml.Name
+	Prop    *davPropNames  `xml:"DAV: prop"`
+	Hrefs   []string       `xml:"DAV: href"`
+	Filter  *davCompFilter `xml:"urn:ietf:params:xml:ns:caldav filter>comp-filter"`
+}
+
+// davCompFilter is a comp-filter of a calendar-query
+type davCompFilter struct {
+	Name         string          `xml:"name,attr"`
+	IsNotDefined *struct{}       `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	Comps        []davCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	Props        []davPropFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+}
+
+// davPropFilter is a prop-filter of a calendar-query
+type davPropFilter struct {
+	Name         string    `xml:"name,attr"`
+	IsNotDefined *struct{} `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TextMatch    *struct {
+		Value  string `xml:",chardata"`
+		Negate string `xml:"negate-condition,attr"`
+	} `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+// report answers calendar-multiget and calendar-query REPORTs on a calendar
+func (h *CalDAVHandler) report(c *gin.Context, resource davResource) {
+	if !resource.home || resource.calendar == "" || resource.object != "" {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	var body davReport
+	if err := decodeDAVBody(c, &body); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	names := body.Prop.names()
+
+	var responses []davResponse
+	switch body.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		prefix := calendarHref(resource.calendar)
+		for _, href := range body.Hrefs {
+			path, err := url.PathUnescape(strings.TrimSpace(href))
+			if err != nil || !strings.HasPrefix(path, prefix) {
+				responses = append(responses, davResponse{Href: href, Status: davStatus(http.StatusNotFound)})
+				continue
+			}
+			object, err := h.service(c).Object(resource.calendar, strings.TrimPrefix(path, prefix))
+			if err != nil {
+				if !strings.Contains(err.Error(), "not found") {
+					utils.InternalServerErrorResponse(c, err)
+					return
+				}
+				responses = append(responses, davResponse{Href: href, Status: davStatus(http.StatusNotFound)})
+				continue
+			}
+			responses = append(responses, propResponse(objectHref(resource.calendar, object.Name), objectProps(object, names), names))
+		}
+
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		objects, err := h.service(c).Objects(resource.calendar)
+		if err != nil {
+			h.errorResponse(c, err)
+			return
+		}
+		for i := range objects {
+			if !matchesFilter(body.Filter, &objects[i]) {
+				continue
+			}
+			responses = append(responses, propResponse(objectHref(resource.calendar, objects[i].Name), objectProps(&objects[i], names), names))
+		}
+
+	default:
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	writeMultistatus(c, responses)
+}
+
+// matchesFilter reports whether a todo matches a calendar-query filter
+// Only VTODO components exist; COMPLETED and STATUS conditions are checked and others match every
+// todo, which the query allows as clients filter the results again
+func matchesFilter(filter *davCompFilter, object *services.CalDAVObject) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.Name != "VCALENDAR" || filter.IsNotDefined != nil {
+		return false
+	}
+	for _, comp := range filter.Comps {
+		if comp.Name != "VTODO" || comp.IsNotDefined != nil {
+			return false
+		}
+		for _, prop := range comp.Props {
+			if !matchesPropFilter(prop, object.Todo.Completed) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchesPropFilter checks a prop-filter on COMPLETED or STATUS against the completed flag of a todo
+func matchesPropFilter(prop davPropFilter, completed bool) bool {
+	switch strings.ToUpper(prop.Name) {
+	case "COMPLETED":
+		if prop.IsNotDefined != nil {
+			return !completed
+		}
+		if prop.TextMatch == nil {
+			return completed
+		}
+	case "STATUS":
+		if prop.IsNotDefined != nil {
+			return false
+		}
+		if prop.TextMatch == nil {
+			return true
+		}
+		status := "NEEDS-ACTION"
+		if completed {
+			status = "COMPLETED"
+		}
+		matched := strings.Contains(status, strings.ToUpper(strings.TrimSpace(prop.TextMatch.Value)))
+		return matched != (prop.TextMatch.Negate == "yes")
+	}
+	return true
+}
+
+// get returns a calendar object
+func (h *CalDAVHandler) get(c *gin.Context, resource davResource) {
+	if resource.object == "" {
+		c.Header("Allow", "OPTIONS, PROPFIND, REPORT")
+		c.Status(http.StatusMethodNotAllowed)
+		return
+	}
+
+	object, err := h.service(c).Object(resource.calendar, resource.object)
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+	if utils.NotModified(c, object.Todo.Version) {
+		return
+	}
+
+	utils.SetETag(c, object.Todo.Version)
+	c.Header("Last-Modified", object.Todo.UpdatedAt.UTC().Format(http.TimeFormat))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", object.Data)
+}
+
+// put creates or replaces a todo from the VTODO in the request body
+// If-None-Match: * only creates, If-Match only replaces the todo with that ETag.
+// The stored todo differs from the request, so no ETag is returned and clients fetch it again
+func (h *CalDAVHandler) put(c *gin.Context, resource davResource) {
+	if resource.object == "" {
+		c.Status(http.StatusMethodNotAllowed)
+		return
+	}
+
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	createOnly := strings.TrimSpace(c.GetHeader("If-None-Match")) == "*"
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCalDAVObjectSize+1))
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	if len(data) > maxCalDAVObjectSize {
+		utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Calendar object exceeds maximum size of "+strconv.Itoa(maxCalDAVObjectSize)+" bytes")
+		return
+	}
+
+	created, err := h.service(c).PutObject(resource.calendar, resource.object, data, version, createOnly)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "calendar not found"):
+			// The parent collection must exist
+			c.Status(http.StatusConflict)
+		case strings.Contains(err.Error(), "calendar object not found"),
+			strings.Contains(err.Error(), "already exists"),
+			strings.Contains(err.Error(), "modified by another request"):
+			c.Status(http.StatusPreconditionFailed)
+		case strings.Contains(err.Error(), "open blockers"),
+			strings.Contains(err.Error(), "not allowed"):
+			utils.ConflictErrorResponse(c, err.Error())
+		case strings.Contains(err.Error(), "invalid"),
+			strings.Contains(err.Error(), "required"),
+			strings.Contains(err.Error(), "exceed"),
+			strings.Contains(err.Error(), "past"),
+			strings.Contains(err.Error(), "does not exist"):
+			utils.ValidationErrorResponse(c, err)
+		default:
+			utils.InternalServerErrorResponse(c, err)
+		}
+		return
+	}
+
+	if created {
+		c.Status(http.StatusCreated)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// delete deletes a todo; If-Match only deletes the todo with that ETag
+func (h *CalDAVHandler) delete(c *gin.Context, resource davResource) {
+	if resource.object == "" {
+		// Calendars mirror categories, which are deleted through the API
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	if err := h.service(c).DeleteObject(resource.calendar, resource.object, version); err != nil {
+		if strings.Contains(err.Error(), "modified by another request") {
+			c.Status(http.StatusPreconditionFailed)
+			return
+		}
+		h.errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// errorResponse answers 404 for missing calendars and objects and 500 otherwise
+func (h *CalDAVHandler) errorResponse(c *gin.Context, err error) {
+	if strings.Contains(err.Error(), "not found") {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	utils.InternalServerErrorResponse(c, err)
+}
+
+// davProperty is a property value, as the XML of the whole property element
+type davProperty struct {
+	name  xml.Name
+	value string
+}
+
+// davResponse is a response element of a multistatus
+type davResponse struct {
+	Href      string        `xml:"D:href"`
+	Status    string        `xml:"D:status,omitempty"`
+	Propstats []davPropstat `xml:"D:propstat"`
+}
+
+// davPropstat groups properties with the same status
+type davPropstat struct {
+	Prop   davInnerXML `xml:"D:prop"`
+	Status string      `xml:"D:status"`
+}
+
+// davInnerXML holds XML written as is
+type davInnerXML struct {
+	XML string `xml:",innerxml"`
+}
+
+// davMultistatus is a multistatus response with the namespace prefixes used by property values
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"D:multistatus"`
+	DAV       string        `xml:"xmlns:D,attr"`
+	CalDAV    string        `xml:"xmlns:C,attr"`
+	CS        string        `xml:"xmlns:CS,attr"`
+	AppleICal string        `xml:"xmlns:A,attr"`
+	Responses []davResponse `xml:"D:response"`
+}
+
+// writeMultistatus sends a 207 Multi-Status response
+func writeMultistatus(c *gin.Context, responses []davResponse) {
+	body, err := xml.Marshal(davMultistatus{
+		DAV:       nsDAV,
+		CalDAV:    nsCalDAV,
+		CS:        nsCalendarServer,
+		AppleICal: nsAppleICal,
+		Responses: responses,
+	})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+	c.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
+
+// propResponse answers a request for the given properties of a resource, or for all of them when none are named
+// Requested properties the resource doesn't have are listed as not found
+func propResponse(href string, props []davProperty, names []xml.Name) davResponse {
+	var found, missing strings.Builder
+	if len(names) == 0 {
+		for _, prop := range props {
+			found.WriteString(prop.value)
+		}
+	} else {
+		for _, name := range names {
+			value, ok := "", false
+			for _, prop := range props {
+				if prop.name == name {
+					value, ok = prop.value, true
+					break
+				}
+			}
+			if ok {
+				found.WriteString(value)
+			} else {
+				missing.WriteString(emptyElement(name))
+			}
+		}
+	}
+
+	response := davResponse{Href: href}
+	if found.Len() > 0 {
+		response.Propstats = append(response.Propstats, davPropstat{Prop: davInnerXML{found.String()}, Status: davStatus(http.StatusOK)})
+	}
+	if missing.Len() > 0 {
+		response.Propstats = append(response.Propstats, davPropstat{Prop: davInnerXML{missing.String()}, Status: davStatus(http.StatusNotFound)})
+	}
+	return response
+}
+
+// principalProps returns the properties of the principal
+func principalProps() []davProperty {
+	return []davProperty{
+		davProp(nsDAV, "resourcetype", "D:resourcetype", "<D:collection/><D:principal/>"),
+		davProp(nsDAV, "displayname", "D:displayname", escapeXML("Todos")),
+		davProp(nsDAV, "current-user-principal", "D:current-user-principal", hrefXML(caldavRoot)),
+		davProp(nsDAV, "principal-URL", "D:principal-URL", hrefXML(caldavRoot)),
+		davProp(nsCalDAV, "calendar-home-set", "C:calendar-home-set", hrefXML(caldavCalendars)),
+		davProp(nsDAV, "current-user-privilege-set", "D:current-user-privilege-set", "<D:privilege><D:read/></D:privilege>"),
+	}
+}
+
+// homeProps returns the properties of the calendar home
+func homeProps() []davProperty {
+	return []davProperty{
+		davProp(nsDAV, "resourcetype", "D:resourcetype", "<D:collection/>"),
+		davProp(nsDAV, "displayname", "D:displayname", escapeXML("Calendars")),
+		davProp(nsDAV, "current-user-principal", "D:current-user-principal", hrefXML(caldavRoot)),
+		davProp(nsDAV, "owner", "D:owner", hrefXML(caldavRoot)),
+		davProp(nsDAV, "current-user-privilege-set", "D:current-user-privilege-set", "<D:privilege><D:read/></D:privilege>"),
+	}
+}
+
+// calendarProps returns the properties of a calendar
+func calendarProps(calendar *services.CalDAVCalendar, ctag string) []davProperty {
+	props := []davProperty{
+		davProp(nsDAV, "resourcetype", "D:resourcetype", "<D:collection/><C:calendar/>"),
+		davProp(nsDAV, "displayname", "D:displayname", escapeXML(calendar.DisplayName)),
+		davProp(nsDAV, "current-user-principal", "D:current-user-principal", hrefXML(caldavRoot)),
+		davProp(nsDAV, "owner", "D:owner", hrefXML(caldavRoot)),
+		davProp(nsDAV, "current-user-privilege-set", "D:current-user-privilege-set",
+			"<D:privilege><D:read/></D:privilege><D:privilege><D:write/></D:privilege><D:privilege><D:write-content/></D:privilege>"+
+				"<D:privilege><D:bind/></D:privilege><D:privilege><D:unbind/></D:privilege>"),
+		davProp(nsDAV, "supported-report-set", "D:supported-report-set",
+			"<D:supported-report><D:report><C:calendar-query/></D:report></D:supported-report>"+
+				"<D:supported-report><D:report><C:calendar-multiget/></D:report></D:supported-report>"),
+		davProp(nsDAV, "getetag", "D:getetag", escapeXML(ctag)),
+		davProp(nsCalendarServer, "getctag", "CS:getctag", escapeXML(ctag)),
+		davProp(nsCalDAV, "supported-calendar-component-set", "C:supported-calendar-component-set", `<C:comp name="VTODO"/>`),
+		davProp(nsCalDAV, "supported-calendar-data", "C:supported-calendar-data", `<C:calendar-data content-type="text/calendar" version="2.0"/>`),
+		davProp(nsCalDAV, "max-resource-size", "C:max-resource-size", strconv.Itoa(maxCalDAVObjectSize)),
+	}
+	if calendar.Color != "" {
+		props = append(props, davProp(nsAppleICal, "calendar-color", "A:calendar-color", escapeXML(calendar.Color)))
+	}
+	return props
+}
+
+// objectProps returns the properties of a calendar object
+// The calendar data is only included when it is asked for by name
+func objectProps(object *services.CalDAVObject, names []xml.Name) []davProperty {
+	props := []davProperty{
+		davProp(nsDAV, "resourcetype", "D:resourcetype", ""),
+		davProp(nsDAV, "getetag", "D:getetag", escapeXML(utils.ETag(object.Todo.Version))),
+		davProp(nsDAV, "getcontenttype", "D:getcontenttype", "text/calendar; charset=utf-8; component=VTODO"),
+		davProp(nsDAV, "getcontentlength", "D:getcontentlength", strconv.Itoa(len(object.Data))),
+		davProp(nsDAV, "getlastmodified", "D:getlastmodified", object.Todo.UpdatedAt.UTC().Format(http.TimeFormat)),
+	}
+	for _, name := range names {
+		if name == (xml.Name{Space: nsCalDAV, Local: "calendar-data"}) {
+			props = append(props, davProp(nsCalDAV, "calendar-data", "C:calendar-data", escapeXML(string(object.Data))))
+		}
+	}
+	return props
+}
+
+// davProp builds a property from its name, its prefixed element name and its inner XML
+func davProp(space, local, element, inner string) davProperty {
+	return davProperty{
+		name:  xml.Name{Space: space, Local: local},
+		value: "<" + element + ">" + inner + "</" + element + ">",
+	}
+}
+
+// emptyElement writes an empty element for a property name in its own namespace
+func emptyElement(name xml.Name) string {
+	return `<X:` + name.Local + ` xmlns:X="` + escapeXML(name.Space) + `"/>`
+}
+
+// hrefXML writes an href element
+func hrefXML(href string) string {
+	return "<D:href>" + escapeXML(href) + "</D:href>"
+}
+
+// escapeXML escapes text for use in XML
+func escapeXML(text string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// davStatus formats a status line of a multistatus response
+func davStatus(code int) string {
+	return "HTTP/1.1 " + strconv.Itoa(code) + " " + http.StatusText(code)
+}
+
+// calendarHref returns the path of a calendar
+func calendarHref(name string) string {
+	return caldavCalendars + url.PathEscape(name) + "/"
+}
+
+// objectHref returns the path of a calendar object
+func objectHref(calendar, name string) string {
+	return calendarHref(calendar) + url.PathEscape(name)
+}
+
+// decodeDAVBody decodes an XML request body, leaving v empty when there is none
+func decodeDAVBody(c *gin.Context, v interface{}) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCalDAVObjectSize))
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(body)) == "" {
+		return nil
+	}
+	if err := xml.Unmarshal(body, v); err != nil {
+		return errors.New("invalid XML body: " + err.Error())
+	}
+	return nil
+}
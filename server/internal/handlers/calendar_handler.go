@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/internal/models"
+	"todo-backend/internal/services"
+	"todo-backend/pkg/utils"
+)
+
+// CalendarHandler handles HTTP requests for calendar feeds
+type CalendarHandler struct {
+	calendarService services.CalendarService
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(calendarService services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// CreateFeed handles POST /api/calendar/feeds
+// The response is the only one that contains the token and the feed URL
+func (h *CalendarHandler) CreateFeed(c *gin.Context) {
+	var feed models.CalendarFeed
+
+	// Bind JSON to feed struct with validation
+	if err := c.ShouldBindJSON(&feed); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Create the feed using service
+	if err := h.calendarService.CreateFeed(&feed); err != nil {
+		if strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "required") ||
+			strings.Contains(err.Error(), "does not exist") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+	feed.URL = "/api/calendar/" + feed.Token + ".ics"
+
+	utils.SuccessResponse(c, http.StatusCreated, "Calendar feed created successfully", feed)
+}
+
+// ListFeeds handles GET /api/calendar/feeds
+func (h *CalendarHandler) ListFeeds(c *gin.Context) {
+	// Get feeds using service
+	feeds, err := h.calendarService.ListFeeds()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Calendar feeds retrieved successfully", feeds)
+}
+
+// GetFeed handles GET /api/calendar/feeds/:id
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Get the feed using service
+	feed, err := h.calendarService.GetFeed(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Calendar feed")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Calendar feed retrieved successfully", feed)
+}
+
+// UpdateFeed handles PUT /api/calendar/feeds/:id
+// The token and URL of the feed stay the same
+func (h *CalendarHandler) UpdateFeed(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	var feed models.CalendarFeed
+
+	// Bind JSON to feed struct with validation
+	if err := c.ShouldBindJSON(&feed); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Set the ID from URL parameter
+	feed.ID = uint(id)
+
+	// Update the feed using service
+	if err := h.calendarService.UpdateFeed(&feed); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Calendar feed")
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "required") ||
+			strings.Contains(err.Error(), "does not exist") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Calendar feed updated successfully", feed)
+}
+
+// DeleteFeed handles DELETE /api/calendar/feeds/:id
+func (h *CalendarHandler) DeleteFeed(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Delete the feed using service
+	if err := h.calendarService.DeleteFeed(uint(id)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Calendar feed")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Calendar feed deleted successfully", nil)
+}
+
+// Feed handles GET /api/calendar/:token
+// Returns the iCalendar file of a feed; the token is the only credential, with or without an .ics suffix
+func (h *CalendarHandler) Feed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	// Render the feed using service, buffered so errors still get a proper status
+	var body bytes.Buffer
+	if err := h.calendarService.WriteFeed(token, &body); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Calendar feed")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="todos.ics"`)
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", body.Bytes())
+}
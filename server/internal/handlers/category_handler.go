@@ -24,6 +24,11 @@ func NewCategoryHandler(categoryService services.CategoryService) *CategoryHandl
 	}
 }
 
+// service returns the category service scoped to the request, so its changes are audited with it
+func (h *CategoryHandler) service(c *gin.Context) services.CategoryService {
+	return h.categoryService.WithContext(c.Request.Context())
+}
+
 // CreateCategory handles POST /api/categories
 func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 	var category models.Category
@@ -35,7 +40,7 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 	}
 
 	// Create the category using service
-	if err := h.categoryService.CreateCategory(&category); err != nil {
+	if err := h.service(c).CreateCategory(&category); err != nil {
 		if strings.Contains(err.Error(), "already exists") {
 			utils.ConflictErrorResponse(c, err.Error())
 			return
@@ -44,6 +49,7 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		return
 	}
 
+	utils.SetETag(c, category.Version)
 	utils.SuccessResponse(c, http.StatusCreated, "Category created successfully", category)
 }
 
@@ -58,7 +64,7 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 	}
 
 	// Get category using service
-	category, err := h.categoryService.GetCategoryByID(uint(id))
+	category, err := h.service(c).GetCategoryByID(uint(id))
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundErrorResponse(c, "Category")
@@ -68,6 +74,12 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 		return
 	}
 
+	// Answer 304 when the client already has this version
+	if utils.NotModified(c, category.Version) {
+		return
+	}
+
+	utils.SetETag(c, category.Version)
 	utils.SuccessResponse(c, http.StatusOK, "Category retrieved successfully", category)
 }
 
@@ -81,6 +93,13 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
+	// The category is only updated if it still has the version from If-Match
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
 	var category models.Category
 
 	// Bind JSON to category struct with validation
@@ -89,11 +108,16 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	// Set the ID from URL parameter
+	// Set the ID from URL parameter and the expected version from If-Match
 	category.ID = uint(id)
+	category.Version = version
 
 	// Update the category using service
-	if err := h.categoryService.UpdateCategory(&category); err != nil {
+	if err := h.service(c).UpdateCategory(&category); err != nil {
+		if strings.Contains(err.Error(), "modified by another request") {
+			h.versionConflictResponse(c, uint(id), err)
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundErrorResponse(c, "Category")
 			return
@@ -106,6 +130,49 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
+	utils.SetETag(c, category.Version)
+	utils.SuccessResponse(c, http.StatusOK, "Category updated successfully", category)
+}
+
+// PatchCategory handles PATCH /api/categories/:id
+// Accepts application/merge-patch+json (or application/json) and application/json-patch+json
+func (h *CategoryHandler) PatchCategory(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// The category is only patched if it still has the version from If-Match
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	patch, mediaType, ok := readPatch(c)
+	if !ok {
+		return
+	}
+
+	// Patch the category using service
+	category, err := h.service(c).PatchCategory(uint(id), version, patch, mediaType)
+	if err != nil {
+		if strings.Contains(err.Error(), "modified by another request") {
+			h.versionConflictResponse(c, uint(id), err)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Category")
+			return
+		}
+		patchErrorResponse(c, err)
+		return
+	}
+
+	utils.SetETag(c, category.Version)
 	utils.SuccessResponse(c, http.StatusOK, "Category updated successfully", category)
 }
 
@@ -119,8 +186,19 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 		return
 	}
 
+	// The category is only deleted if it still has the version from If-Match
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
 	// Delete the category using service
-	if err := h.categoryService.DeleteCategory(uint(id)); err != nil {
+	if err := h.service(c).DeleteCategory(uint(id), version); err != nil {
+		if strings.Contains(err.Error(), "modified by another request") {
+			h.versionConflictResponse(c, uint(id), err)
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundErrorResponse(c, "Category")
 			return
@@ -154,7 +232,7 @@ func (h *CategoryHandler) ListCategories(c *gin.Context) {
 	}
 
 	// Get categories using service
-	categories, paginationResult, err := h.categoryService.ListCategories(filters, pagination)
+	categories, paginationResult, err := h.service(c).ListCategories(filters, pagination)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, err)
 		return
@@ -166,11 +244,27 @@ func (h *CategoryHandler) ListCategories(c *gin.Context) {
 // GetAllCategories handles GET /api/categories/all
 func (h *CategoryHandler) GetAllCategories(c *gin.Context) {
 	// Get all categories using service (for dropdowns)
-	categories, err := h.categoryService.GetAllCategories()
+	categories, err := h.service(c).GetAllCategories()
 	if err != nil {
 		utils.InternalServerErrorResponse(c, err)
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "All categories retrieved successfully", categories)
-}
\ No newline at end of file
+}
+
+// versionConflictResponse answers 412 Precondition Failed with the current version of a category
+func (h *CategoryHandler) versionConflictResponse(c *gin.Context, id uint, err error) {
+	current, getErr := h.service(c).GetCategoryByID(id)
+	if getErr != nil {
+		if strings.Contains(getErr.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Category")
+			return
+		}
+		utils.InternalServerErrorResponse(c, getErr)
+		return
+	}
+
+	utils.SetETag(c, current.Version)
+	utils.PreconditionFailedResponse(c, err.Error(), current)
+}
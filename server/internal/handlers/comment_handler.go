@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/internal/services"
+	"todo-backend/pkg/utils"
+)
+
+// CommentHandler handles HTTP requests for todo comments
+type CommentHandler struct {
+	commentService services.CommentService
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler(commentService services.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+	}
+}
+
+// service returns the comment service scoped to the request, so its changes are audited with it
+func (h *CommentHandler) service(c *gin.Context) services.CommentService {
+	return h.commentService.WithContext(c.Request.Context())
+}
+
+// CreateComment handles POST /api/todos/:id/comments
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	// Extract todo ID from URL parameter
+	todoID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	var comment models.Comment
+
+	// Bind JSON to comment struct with validation
+	if err := c.ShouldBindJSON(&comment); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Create the comment using service
+	if err := h.service(c).CreateComment(uint(todoID), &comment); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Todo")
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "required") ||
+			strings.Contains(err.Error(), "exceed") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Comment created successfully", comment)
+}
+
+// GetComment handles GET /api/todos/:id/comments/:comment_id
+func (h *CommentHandler) GetComment(c *gin.Context) {
+	// Extract IDs from URL parameters
+	todoID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	commentID, err := strconv.ParseUint(c.Param("comment_id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Get comment using service
+	comment, err := h.service(c).GetComment(uint(todoID), uint(commentID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Comment")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Comment retrieved successfully", comment)
+}
+
+// UpdateComment handles PUT /api/todos/:id/comments/:comment_id
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	// Extract IDs from URL parameters
+	todoID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	commentID, err := strconv.ParseUint(c.Param("comment_id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	var comment models.Comment
+
+	// Bind JSON to comment struct with validation
+	if err := c.ShouldBindJSON(&comment); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Set the ID from URL parameter
+	comment.ID = uint(commentID)
+
+	// Update the comment using service
+	if err := h.service(c).UpdateComment(uint(todoID), &comment); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Comment")
+			return
+		}
+		if strings.Contains(err.Error(), "only the comment author") {
+			utils.ForbiddenErrorResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "required") ||
+			strings.Contains(err.Error(), "exceed") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Comment updated successfully", comment)
+}
+
+// DeleteComment handles DELETE /api/todos/:id/comments/:comment_id
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	// Extract IDs from URL parameters
+	todoID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	commentID, err := strconv.ParseUint(c.Param("comment_id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Delete the comment using service
+	if err := h.service(c).DeleteComment(uint(todoID), uint(commentID)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Comment")
+			return
+		}
+		if strings.Contains(err.Error(), "only the comment author") {
+			utils.ForbiddenErrorResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Comment deleted successfully", nil)
+}
+
+// ListComments handles GET /api/todos/:id/comments
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	// Extract todo ID from URL parameter
+	todoID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Bind pagination query parameters
+	var pagination repository.PaginationParams
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Get comments using service
+	comments, paginationResult, err := h.service(c).ListComments(uint(todoID), pagination)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Todo")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Comments retrieved successfully", comments, paginationResult)
+}
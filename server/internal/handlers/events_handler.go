@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"todo-backend/internal/events"
+	"todo-backend/internal/middleware"
+	"todo-backend/internal/models"
+	"todo-backend/pkg/utils"
+)
+
+const (
+	// eventsKeepAlive is how often an idle stream sends a keep-alive so proxies keep it open
+	eventsKeepAlive = 25 * time.Second
+	// eventsRetry is the reconnect delay suggested to EventSource clients, in milliseconds
+	eventsRetry = 3000
+	// websocketWriteTimeout limits how long a write to a WebSocket client may block
+	websocketWriteTimeout = 10 * time.Second
+)
+
+// resetMessage tells a client that it missed events and has to reload its data
+var resetMessage = []byte(`{"type":"reset"}`)
+
+// upgrader upgrades event stream requests to WebSocket connections
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin:     webSocketOriginAllowed,
+}
+
+// EventsHandler streams change events of todos and categories to clients
+type EventsHandler struct {
+	bus *events.Bus
+}
+
+// NewEventsHandler creates a new events handler
+func NewEventsHandler(bus *events.Bus) *EventsHandler {
+	return &EventsHandler{
+		bus: bus,
+	}
+}
+
+// Stream handles GET /api/events
+// Sends change events as Server-Sent Events, resuming after the Last-Event-ID header when it is still buffered
+func (h *EventsHandler) Stream(c *gin.Context) {
+	filter, err := parseEventFilter(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	subscription, replay, resumed := h.bus.Subscribe(filter, lastEventID(c))
+	defer h.bus.Unsubscribe(subscription)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprintf(w, "retry: %d\n\n", eventsRetry)
+	if !resumed {
+		writeSSEReset(w)
+	}
+	for _, event := range replay {
+		writeSSEEvent(w, event)
+	}
+	w.Flush()
+
+	ticker := time.NewTicker(eventsKeepAlive)
+	defer ticker.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-subscription.Events:
+			err = writeSSEEvent(w, event)
+		case <-subscription.Resync:
+			err = writeSSEReset(w)
+		case <-ticker.C:
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		if err != nil {
+			return
+		}
+		w.Flush()
+	}
+}
+
+// WebSocket handles GET /api/events/ws
+// Sends every change event as a JSON text message; clients resume with the last_event_id query parameter
+func (h *EventsHandler) WebSocket(c *gin.Context) {
+	filter, err := parseEventFilter(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// The upgrader answers failed handshakes itself
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	subscription, replay, resumed := h.bus.Subscribe(filter, lastEventID(c))
+	defer h.bus.Unsubscribe(subscription)
+
+	// Messages from the client are not used, reading detects when it goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(512)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(payload []byte) error {
+		conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout))
+		return conn.WriteMessage(websocket.TextMessage, payload)
+	}
+
+	if !resumed {
+		if err := send(resetMessage); err != nil {
+			return
+		}
+	}
+	for _, event := range replay {
+		if err := send(event.Payload); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(eventsKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		select {
+		case <-closed:
+			return
+		case event := <-subscription.Events:
+			err = send(event.Payload)
+		case <-subscription.Resync:
+			err = send(resetMessage)
+		case <-ticker.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(websocketWriteTimeout))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeSSEEvent writes a change event in the Server-Sent Events format
+func writeSSEEvent(w gin.ResponseWriter, event events.Event) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Payload)
+	return err
+}
+
+// writeSSEReset writes a reset event, it has no ID so the client keeps its position
+func writeSSEReset(w gin.ResponseWriter) error {
+	_, err := fmt.Fprintf(w, "event: reset\ndata: %s\n\n", resetMessage)
+	return err
+}
+
+// lastEventID returns the ID of the last event a reconnecting client received
+func lastEventID(c *gin.Context) string {
+	if id := c.GetHeader("Last-Event-ID"); id != "" {
+		return id
+	}
+	return c.Query("last_event_id")
+}
+
+// parseEventFilter reads the types and category_id query parameters
+func parseEventFilter(c *gin.Context) (events.Filter, error) {
+	var filter events.Filter
+
+	known := map[string]bool{models.EntityTodo: true, models.EntityCategory: true}
+	for _, eventType := range models.EventTypes {
+		known[eventType] = true
+	}
+	for _, t := range splitList(c.Query("types")) {
+		if !known[t] {
+			return events.Filter{}, errors.New("invalid event type: " + t)
+		}
+		filter.Types = append(filter.Types, t)
+	}
+
+	for _, value := range splitList(c.Query("category_id")) {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || id == 0 {
+			return events.Filter{}, errors.New("invalid category_id: " + value)
+		}
+		filter.CategoryIDs = append(filter.CategoryIDs, uint(id))
+	}
+	return filter, nil
+}
+
+// splitList splits a comma-separated query parameter into trimmed values
+func splitList(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+// webSocketOriginAllowed accepts WebSocket upgrades from the API's own origin and the CORS origins
+func webSocketOriginAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return middleware.OriginAllowed(origin)
+}
@@ -2,16 +2,29 @@ package handlers
 
 import (
 	"net/http"
+	"todo-backend/internal/events"
+	"todo-backend/internal/middleware"
 	"todo-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRoutes configures all API routes
-func SetupRoutes(r *gin.Engine, todoService services.TodoService, categoryService services.CategoryService) {
+func SetupRoutes(r *gin.Engine, todoService services.TodoService, categoryService services.CategoryService, workflowService services.WorkflowService, commentService services.CommentService, attachmentService services.AttachmentService, trashService services.TrashService, auditService services.AuditService, webhookService services.WebhookService, syncService services.SyncService, calendarService services.CalendarService, caldavService services.CalDAVService, smartListService services.SmartListService, eventBus *events.Bus, adminToken, caldavPassword string, maxUploadSize int64) {
 	// Create handlers
 	todoHandler := NewTodoHandler(todoService)
 	categoryHandler := NewCategoryHandler(categoryService)
+	workflowHandler := NewWorkflowHandler(workflowService)
+	commentHandler := NewCommentHandler(commentService)
+	attachmentHandler := NewAttachmentHandler(attachmentService, maxUploadSize)
+	trashHandler := NewTrashHandler(trashService)
+	auditHandler := NewAuditHandler(auditService)
+	webhookHandler := NewWebhookHandler(webhookService)
+	eventsHandler := NewEventsHandler(eventBus)
+	syncHandler := NewSyncHandler(syncService)
+	calendarHandler := NewCalendarHandler(calendarService)
+	caldavHandler := NewCalDAVHandler(caldavService)
+	smartListHandler := NewSmartListHandler(smartListService)
 
 	// API version group
 	api := r.Group("/api")
@@ -24,15 +37,49 @@ func SetupRoutes(r *gin.Engine, todoService services.TodoService, categoryServic
 			})
 		})
 
+		// Change event streams
+		api.GET("/events", eventsHandler.Stream)       // GET /api/events
+		api.GET("/events/ws", eventsHandler.WebSocket) // GET /api/events/ws
+
 		// Todo routes
 		todos := api.Group("/todos")
 		{
-			todos.POST("", todoHandler.CreateTodo)                       // POST /api/todos
-			todos.GET("", todoHandler.ListTodos)                         // GET /api/todos
-			todos.GET("/:id", todoHandler.GetTodo)                       // GET /api/todos/:id
-			todos.PUT("/:id", todoHandler.UpdateTodo)                    // PUT /api/todos/:id
-			todos.DELETE("/:id", todoHandler.DeleteTodo)                 // DELETE /api/todos/:id
-			todos.PATCH("/:id/complete", todoHandler.ToggleTodoComplete) // PATCH /api/todos/:id/complete
+			todos.POST("", todoHandler.CreateTodo)                              // POST /api/todos
+			todos.GET("", todoHandler.ListTodos)                                // GET /api/todos
+			todos.GET("/export", todoHandler.ExportTodos)                       // GET /api/todos/export
+			todos.GET("/:id", todoHandler.GetTodo)                              // GET /api/todos/:id
+			todos.PUT("/:id", todoHandler.UpdateTodo)                           // PUT /api/todos/:id
+			todos.PATCH("/:id", todoHandler.PatchTodo)                          // PATCH /api/todos/:id
+			todos.DELETE("/:id", todoHandler.DeleteTodo)                        // DELETE /api/todos/:id
+			todos.PATCH("/:id/complete", todoHandler.ToggleTodoComplete)        // PATCH /api/todos/:id/complete
+			todos.PATCH("/:id/status", todoHandler.ChangeTodoStatus)            // PATCH /api/todos/:id/status
+			todos.POST("/:id/move", todoHandler.MoveTodo)                       // POST /api/todos/:id/move
+			todos.POST("/:id/archive", todoHandler.ArchiveTodo)                 // POST /api/todos/:id/archive
+			todos.POST("/:id/unarchive", todoHandler.UnarchiveTodo)             // POST /api/todos/:id/unarchive
+			todos.POST("/archive-completed", todoHandler.ArchiveCompletedTodos) // POST /api/todos/archive-completed
+			todos.POST("/bulk", todoHandler.BulkUpdateTodos)                    // POST /api/todos/bulk
+			todos.POST("/quick", todoHandler.QuickAddTodo)                      // POST /api/todos/quick
+
+			// History routes
+			todos.GET("/:id/history", todoHandler.GetTodoHistory)      // GET /api/todos/:id/history
+			todos.POST("/:id/revert/:version", todoHandler.RevertTodo) // POST /api/todos/:id/revert/:version
+
+			// Dependency routes
+			todos.POST("/:id/blockers", todoHandler.AddBlocker)                  // POST /api/todos/:id/blockers
+			todos.DELETE("/:id/blockers/:blocker_id", todoHandler.RemoveBlocker) // DELETE /api/todos/:id/blockers/:blocker_id
+
+			// Comment routes
+			todos.GET("/:id/comments", commentHandler.ListComments)                 // GET /api/todos/:id/comments
+			todos.POST("/:id/comments", commentHandler.CreateComment)               // POST /api/todos/:id/comments
+			todos.GET("/:id/comments/:comment_id", commentHandler.GetComment)       // GET /api/todos/:id/comments/:comment_id
+			todos.PUT("/:id/comments/:comment_id", commentHandler.UpdateComment)    // PUT /api/todos/:id/comments/:comment_id
+			todos.DELETE("/:id/comments/:comment_id", commentHandler.DeleteComment) // DELETE /api/todos/:id/comments/:comment_id
+
+			// Attachment routes
+			todos.GET("/:id/attachments", attachmentHandler.ListAttachments)                    // GET /api/todos/:id/attachments
+			todos.POST("/:id/attachments", attachmentHandler.UploadAttachment)                  // POST /api/todos/:id/attachments
+			todos.GET("/:id/attachments/:attachment_id", attachmentHandler.DownloadAttachment)  // GET /api/todos/:id/attachments/:attachment_id
+			todos.DELETE("/:id/attachments/:attachment_id", attachmentHandler.DeleteAttachment) // DELETE /api/todos/:id/attachments/:attachment_id
 		}
 
 		// Category routes
@@ -43,7 +90,91 @@ func SetupRoutes(r *gin.Engine, todoService services.TodoService, categoryServic
 			categories.GET("/all", categoryHandler.GetAllCategories)  // GET /api/categories/all
 			categories.GET("/:id", categoryHandler.GetCategory)       // GET /api/categories/:id
 			categories.PUT("/:id", categoryHandler.UpdateCategory)    // PUT /api/categories/:id
+			categories.PATCH("/:id", categoryHandler.PatchCategory)   // PATCH /api/categories/:id
 			categories.DELETE("/:id", categoryHandler.DeleteCategory) // DELETE /api/categories/:id
 		}
+
+		// Workflow routes
+		workflow := api.Group("/workflow")
+		{
+			workflow.GET("", workflowHandler.GetWorkflow)                         // GET /api/workflow
+			workflow.POST("/statuses", workflowHandler.CreateStatus)              // POST /api/workflow/statuses
+			workflow.PUT("/statuses/:id", workflowHandler.UpdateStatus)           // PUT /api/workflow/statuses/:id
+			workflow.DELETE("/statuses/:id", workflowHandler.DeleteStatus)        // DELETE /api/workflow/statuses/:id
+			workflow.POST("/transitions", workflowHandler.CreateTransition)       // POST /api/workflow/transitions
+			workflow.DELETE("/transitions/:id", workflowHandler.DeleteTransition) // DELETE /api/workflow/transitions/:id
+		}
+
+		// Full account export and import
+		api.GET("/export", todoHandler.ExportAccount) // GET /api/export
+		api.POST("/import", todoHandler.ImportTodos)  // POST /api/import
+
+		// todo.txt routes
+		api.GET("/todos.txt", todoHandler.ExportTodoTxt)  // GET /api/todos.txt
+		api.POST("/todos.txt", todoHandler.ImportTodoTxt) // POST /api/todos.txt
+
+		// Sync routes
+		api.GET("/sync", syncHandler.GetChanges)    // GET /api/sync
+		api.POST("/sync", syncHandler.ApplyChanges) // POST /api/sync
+
+		// Calendar routes, feeds are read by calendar apps with their token as the only credential
+		calendar := api.Group("/calendar")
+		{
+			calendar.POST("/feeds", calendarHandler.CreateFeed)       // POST /api/calendar/feeds
+			calendar.GET("/feeds", calendarHandler.ListFeeds)         // GET /api/calendar/feeds
+			calendar.GET("/feeds/:id", calendarHandler.GetFeed)       // GET /api/calendar/feeds/:id
+			calendar.PUT("/feeds/:id", calendarHandler.UpdateFeed)    // PUT /api/calendar/feeds/:id
+			calendar.DELETE("/feeds/:id", calendarHandler.DeleteFeed) // DELETE /api/calendar/feeds/:id
+			calendar.GET("/:token", calendarHandler.Feed)             // GET /api/calendar/:token.ics
+		}
+
+		// Smart list routes, built-in lists are addressed by name and saved filters by ID
+		smartLists := api.Group("/smart-lists")
+		{
+			smartLists.GET("", smartListHandler.ListSmartLists)           // GET /api/smart-lists
+			smartLists.POST("", smartListHandler.CreateSmartList)         // POST /api/smart-lists
+			smartLists.GET("/:id", smartListHandler.GetSmartList)         // GET /api/smart-lists/:id
+			smartLists.PUT("/:id", smartListHandler.UpdateSmartList)      // PUT /api/smart-lists/:id
+			smartLists.DELETE("/:id", smartListHandler.DeleteSmartList)   // DELETE /api/smart-lists/:id
+			smartLists.GET("/:id/todos", smartListHandler.SmartListTodos) // GET /api/smart-lists/:id/todos
+		}
+
+		// Trash routes
+		trash := api.Group("/trash")
+		{
+			trash.GET("/todos", trashHandler.ListTodos)                         // GET /api/trash/todos
+			trash.POST("/todos/:id/restore", trashHandler.RestoreTodo)          // POST /api/trash/todos/:id/restore
+			trash.DELETE("/todos/:id", trashHandler.DeleteTodo)                 // DELETE /api/trash/todos/:id
+			trash.GET("/categories", trashHandler.ListCategories)               // GET /api/trash/categories
+			trash.POST("/categories/:id/restore", trashHandler.RestoreCategory) // POST /api/trash/categories/:id/restore
+			trash.DELETE("/categories/:id", trashHandler.DeleteCategory)        // DELETE /api/trash/categories/:id
+		}
+
+		// Webhook routes, subscriptions hold signing secrets so they share the admin token
+		webhooks := api.Group("/webhooks", middleware.AdminToken(adminToken))
+		{
+			webhooks.POST("", webhookHandler.CreateSubscription)                              // POST /api/webhooks
+			webhooks.GET("", webhookHandler.ListSubscriptions)                                // GET /api/webhooks
+			webhooks.GET("/:id", webhookHandler.GetSubscription)                              // GET /api/webhooks/:id
+			webhooks.PUT("/:id", webhookHandler.UpdateSubscription)                           // PUT /api/webhooks/:id
+			webhooks.DELETE("/:id", webhookHandler.DeleteSubscription)                        // DELETE /api/webhooks/:id
+			webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)                    // GET /api/webhooks/:id/deliveries
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver) // POST /api/webhooks/:id/deliveries/:delivery_id/redeliver
+		}
+
+		// Admin routes
+		admin := api.Group("/admin", middleware.AdminToken(adminToken))
+		{
+			admin.GET("/audit", auditHandler.ListEvents)          // GET /api/admin/audit
+			admin.GET("/audit/export", auditHandler.ExportEvents) // GET /api/admin/audit/export
+		}
+	}
+
+	// CalDAV routes, calendar apps edit todos through them with the categories as calendars
+	r.GET("/.well-known/caldav", caldavHandler.WellKnown)                // GET /.well-known/caldav
+	r.Handle("PROPFIND", "/.well-known/caldav", caldavHandler.WellKnown) // PROPFIND /.well-known/caldav
+	caldav := r.Group("/caldav", middleware.BasicPassword(caldavPassword, "Todos"))
+	for _, method := range caldavMethods {
+		caldav.Handle(method, "/*path", caldavHandler.Serve) // /caldav/*path
 	}
 }
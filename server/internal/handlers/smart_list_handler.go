@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/internal/services"
+	"todo-backend/pkg/utils"
+)
+
+// SmartListHandler handles HTTP requests for smart lists and saved filters
+type SmartListHandler struct {
+	smartListService services.SmartListService
+}
+
+// NewSmartListHandler creates a new smart list handler
+func NewSmartListHandler(smartListService services.SmartListService) *SmartListHandler {
+	return &SmartListHandler{
+		smartListService: smartListService,
+	}
+}
+
+// ListSmartLists handles GET /api/smart-lists
+func (h *SmartListHandler) ListSmartLists(c *gin.Context) {
+	// Get smart lists using service
+	lists, err := h.smartListService.ListSmartLists()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Smart lists retrieved successfully", lists)
+}
+
+// GetSmartList handles GET /api/smart-lists/:id
+func (h *SmartListHandler) GetSmartList(c *gin.Context) {
+	// Get the smart list using service
+	list, err := h.smartListService.GetSmartList(c.Param("id"))
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Smart list retrieved successfully", list)
+}
+
+// CreateSmartList handles POST /api/smart-lists
+func (h *SmartListHandler) CreateSmartList(c *gin.Context) {
+	var filter models.SavedFilter
+
+	// Bind JSON to saved filter struct with validation
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Create the saved filter using service
+	list, err := h.smartListService.CreateSavedFilter(&filter)
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Smart list created successfully", list)
+}
+
+// UpdateSmartList handles PUT /api/smart-lists/:id
+func (h *SmartListHandler) UpdateSmartList(c *gin.Context) {
+	var filter models.SavedFilter
+
+	// Bind JSON to saved filter struct with validation
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Update the saved filter using service
+	list, err := h.smartListService.UpdateSavedFilter(c.Param("id"), &filter)
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Smart list updated successfully", list)
+}
+
+// DeleteSmartList handles DELETE /api/smart-lists/:id
+func (h *SmartListHandler) DeleteSmartList(c *gin.Context) {
+	// Delete the saved filter using service
+	if err := h.smartListService.DeleteSavedFilter(c.Param("id")); err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Smart list deleted successfully", nil)
+}
+
+// SmartListTodos handles GET /api/smart-lists/:id/todos
+// Takes the page and limit query parameters, and sort_by and sort_order for lists without a sort order
+func (h *SmartListHandler) SmartListTodos(c *gin.Context) {
+	var pagination repository.PaginationParams
+
+	// Bind query parameters
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Get the todos of the smart list using service
+	list, todos, paginationResult, err := h.smartListService.SmartListTodos(c.Param("id"), pagination)
+	if err != nil {
+		h.errorResponse(c, err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Todos of smart list "+list.Name+" retrieved successfully", todos, paginationResult)
+}
+
+// errorResponse maps smart list service errors to responses
+func (h *SmartListHandler) errorResponse(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		utils.NotFoundErrorResponse(c, "Smart list")
+	case strings.Contains(err.Error(), "built-in"):
+		utils.ForbiddenErrorResponse(c, err.Error())
+	case strings.Contains(err.Error(), "already exists"):
+		utils.ConflictErrorResponse(c, err.Error())
+	case strings.Contains(err.Error(), "invalid") ||
+		strings.Contains(err.Error(), "required") ||
+		strings.Contains(err.Error(), "exceed") ||
+		strings.Contains(err.Error(), "does not exist"):
+		utils.ValidationErrorResponse(c, err)
+	default:
+		utils.InternalServerErrorResponse(c, err)
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/internal/services"
+	"todo-backend/pkg/utils"
+)
+
+// Page sizes of a sync pull
+const (
+	defaultSyncLimit = 500
+	maxSyncLimit     = 1000
+)
+
+// SyncHandler handles HTTP requests for offline synchronization
+type SyncHandler struct {
+	syncService services.SyncService
+}
+
+// NewSyncHandler creates a new sync handler
+func NewSyncHandler(syncService services.SyncService) *SyncHandler {
+	return &SyncHandler{
+		syncService: syncService,
+	}
+}
+
+// service returns the sync service scoped to the request, so its changes are audited with it
+func (h *SyncHandler) service(c *gin.Context) services.SyncService {
+	return h.syncService.WithContext(c.Request.Context())
+}
+
+// GetChanges handles GET /api/sync
+// Returns the records changed after the since token and the token to pull from next
+func (h *SyncHandler) GetChanges(c *gin.Context) {
+	limit := defaultSyncLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxSyncLimit {
+			utils.ValidationErrorResponse(c, errors.New("invalid limit: must be between 1 and 1000"))
+			return
+		}
+		limit = parsed
+	}
+
+	// Get changes using service
+	changes, err := h.service(c).Changes(c.Query("since"), limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Changes retrieved successfully", changes)
+}
+
+// ApplyChanges handles POST /api/sync
+// Every mutation gets its own result, conflicts do not fail the request
+func (h *SyncHandler) ApplyChanges(c *gin.Context) {
+	var batch services.SyncBatch
+
+	// Bind JSON to sync batch struct with validation
+	if err := c.ShouldBindJSON(&batch); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Apply mutations using service
+	results, err := h.service(c).Apply(batch)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Changes applied", results)
+}
@@ -1,14 +1,21 @@
 package handlers
 
 import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"todo-backend/internal/models"
 	"todo-backend/internal/repository"
 	"todo-backend/internal/services"
+	"todo-backend/pkg/jsonpatch"
 	"todo-backend/pkg/utils"
 )
 
@@ -24,6 +31,11 @@ func NewTodoHandler(todoService services.TodoService) *TodoHandler {
 	}
 }
 
+// service returns the todo service scoped to the request, so its changes are audited with it
+func (h *TodoHandler) service(c *gin.Context) services.TodoService {
+	return h.todoService.WithContext(c.Request.Context())
+}
+
 // CreateTodo handles POST /api/todos
 func (h *TodoHandler) CreateTodo(c *gin.Context) {
 	var todo models.Todo
@@ -35,7 +47,7 @@ func (h *TodoHandler) CreateTodo(c *gin.Context) {
 	}
 
 	// Create the todo using service
-	if err := h.todoService.CreateTodo(&todo); err != nil {
+	if err := h.service(c).CreateTodo(&todo); err != nil {
 		if strings.Contains(err.Error(), "not found") || 
 		   strings.Contains(err.Error(), "does not exist") {
 			utils.ValidationErrorResponse(c, err)
@@ -50,6 +62,7 @@ func (h *TodoHandler) CreateTodo(c *gin.Context) {
 		return
 	}
 
+	utils.SetETag(c, todo.Version)
 	utils.SuccessResponse(c, http.StatusCreated, "Todo created successfully", todo)
 }
 
@@ -64,7 +77,7 @@ func (h *TodoHandler) GetTodo(c *gin.Context) {
 	}
 
 	// Get todo using service
-	todo, err := h.todoService.GetTodoByID(uint(id))
+	todo, err := h.service(c).GetTodoByID(uint(id))
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundErrorResponse(c, "Todo")
@@ -74,6 +87,12 @@ func (h *TodoHandler) GetTodo(c *gin.Context) {
 		return
 	}
 
+	// Answer 304 when the client already has this version
+	if utils.NotModified(c, todo.Version) {
+		return
+	}
+
+	utils.SetETag(c, todo.Version)
 	utils.SuccessResponse(c, http.StatusOK, "Todo retrieved successfully", todo)
 }
 
@@ -87,6 +106,13 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 		return
 	}
 
+	// The todo is only updated if it still has the version from If-Match
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
 	var todo models.Todo
 
 	// Bind JSON to todo struct with validation
@@ -95,15 +121,25 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 		return
 	}
 
-	// Set the ID from URL parameter
+	// Set the ID from URL parameter and the expected version from If-Match
 	todo.ID = uint(id)
+	todo.Version = version
 
 	// Update the todo using service
-	if err := h.todoService.UpdateTodo(&todo); err != nil {
+	if err := h.service(c).UpdateTodo(&todo); err != nil {
+		if strings.Contains(err.Error(), "modified by another request") {
+			h.versionConflictResponse(c, uint(id), err)
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundErrorResponse(c, "Todo")
 			return
 		}
+		if strings.Contains(err.Error(), "open blockers") ||
+			strings.Contains(err.Error(), "not allowed") {
+			utils.ConflictErrorResponse(c, err.Error())
+			return
+		}
 		if strings.Contains(err.Error(), "past") ||
 		   strings.Contains(err.Error(), "invalid") ||
 		   strings.Contains(err.Error(), "does not exist") {
@@ -114,6 +150,54 @@ func (h *TodoHandler) UpdateTodo(c *gin.Context) {
 		return
 	}
 
+	utils.SetETag(c, todo.Version)
+	utils.SuccessResponse(c, http.StatusOK, "Todo updated successfully", todo)
+}
+
+// PatchTodo handles PATCH /api/todos/:id
+// Accepts application/merge-patch+json (or application/json) and application/json-patch+json
+func (h *TodoHandler) PatchTodo(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// The todo is only patched if it still has the version from If-Match
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	patch, mediaType, ok := readPatch(c)
+	if !ok {
+		return
+	}
+
+	// Patch the todo using service
+	todo, err := h.service(c).PatchTodo(uint(id), version, patch, mediaType)
+	if err != nil {
+		if strings.Contains(err.Error(), "modified by another request") {
+			h.versionConflictResponse(c, uint(id), err)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Todo")
+			return
+		}
+		if strings.Contains(err.Error(), "open blockers") ||
+			strings.Contains(err.Error(), "not allowed") {
+			utils.ConflictErrorResponse(c, err.Error())
+			return
+		}
+		patchErrorResponse(c, err)
+		return
+	}
+
+	utils.SetETag(c, todo.Version)
 	utils.SuccessResponse(c, http.StatusOK, "Todo updated successfully", todo)
 }
 
@@ -127,8 +211,19 @@ func (h *TodoHandler) DeleteTodo(c *gin.Context) {
 		return
 	}
 
+	// The todo is only deleted if it still has the version from If-Match
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
 	// Delete the todo using service
-	if err := h.todoService.DeleteTodo(uint(id)); err != nil {
+	if err := h.service(c).DeleteTodo(uint(id), version); err != nil {
+		if strings.Contains(err.Error(), "modified by another request") {
+			h.versionConflictResponse(c, uint(id), err)
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundErrorResponse(c, "Todo")
 			return
@@ -158,7 +253,7 @@ func (h *TodoHandler) ListTodos(c *gin.Context) {
 	}
 
 	// Get todos using service
-	todos, paginationResult, err := h.todoService.ListTodos(filters, pagination)
+	todos, paginationResult, err := h.service(c).ListTodos(filters, pagination)
 	if err != nil {
 		if strings.Contains(err.Error(), "invalid") {
 			utils.ValidationErrorResponse(c, err)
@@ -181,8 +276,461 @@ func (h *TodoHandler) ToggleTodoComplete(c *gin.Context) {
 		return
 	}
 
+	// The todo is only changed if it still has the version from If-Match
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Completing a blocked todo requires ?force=true
+	force := c.Query("force") == "true"
+
 	// Toggle todo completion using service
-	if err := h.todoService.ToggleTodoComplete(uint(id)); err != nil {
+	if err := h.service(c).ToggleTodoComplete(uint(id), version, force); err != nil {
+		if strings.Contains(err.Error(), "modified by another request") {
+			h.versionConflictResponse(c, uint(id), err)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Todo")
+			return
+		}
+		if strings.Contains(err.Error(), "open blockers") {
+			utils.ConflictErrorResponse(c, "Todo has open blockers, complete them first or retry with force=true")
+			return
+		}
+		if strings.Contains(err.Error(), "not allowed") {
+			utils.ConflictErrorResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	// Return the todo with its new completion status
+	todo, err := h.service(c).GetTodoByID(uint(id))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SetETag(c, todo.Version)
+	utils.SuccessResponse(c, http.StatusOK, "Todo completion status toggled successfully", todo)
+}
+
+// changeStatusRequest is the request body for moving a todo to another status
+type changeStatusRequest struct {
+	StatusID uint `json:"status_id" binding:"required,min=1"`
+}
+
+// ChangeTodoStatus handles PATCH /api/todos/:id/status
+// Pass ?force=true to move a todo with open blockers into a done status
+func (h *TodoHandler) ChangeTodoStatus(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// The todo is only changed if it still has the version from If-Match
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	var req changeStatusRequest
+
+	// Bind JSON to request struct with validation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Change the status using service
+	if err := h.service(c).ChangeTodoStatus(uint(id), req.StatusID, version, c.Query("force") == "true"); err != nil {
+		if strings.Contains(err.Error(), "modified by another request") {
+			h.versionConflictResponse(c, uint(id), err)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Todo")
+			return
+		}
+		if strings.Contains(err.Error(), "open blockers") {
+			utils.ConflictErrorResponse(c, "Todo has open blockers, complete them first or retry with force=true")
+			return
+		}
+		if strings.Contains(err.Error(), "not allowed") {
+			utils.ConflictErrorResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	// Return the todo in its new status
+	todo, err := h.service(c).GetTodoByID(uint(id))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SetETag(c, todo.Version)
+	utils.SuccessResponse(c, http.StatusOK, "Todo status updated successfully", todo)
+}
+
+// moveTodoRequest is the request body for moving a todo in manual order
+type moveTodoRequest struct {
+	BeforeID   *uint `json:"before_id"`
+	AfterID    *uint `json:"after_id"`
+	CategoryID *uint `json:"category_id"`
+}
+
+// MoveTodo handles POST /api/todos/:id/move
+func (h *TodoHandler) MoveTodo(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// The todo is only changed if it still has the version from If-Match
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	var req moveTodoRequest
+
+	// Bind JSON to request struct with validation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Move the todo using service
+	if err := h.service(c).MoveTodo(uint(id), version, req.BeforeID, req.AfterID, req.CategoryID); err != nil {
+		if strings.Contains(err.Error(), "modified by another request") {
+			h.versionConflictResponse(c, uint(id), err)
+			return
+		}
+		if strings.Contains(err.Error(), "todo not found") {
+			utils.NotFoundErrorResponse(c, "Todo")
+			return
+		}
+		if strings.Contains(err.Error(), "not allowed") {
+			utils.ConflictErrorResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "required") ||
+			strings.Contains(err.Error(), "does not exist") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	// Return the todo at its new place
+	todo, err := h.service(c).GetTodoByID(uint(id))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SetETag(c, todo.Version)
+	utils.SuccessResponse(c, http.StatusOK, "Todo moved successfully", todo)
+}
+
+// BulkUpdateTodos handles POST /api/todos/bulk
+func (h *TodoHandler) BulkUpdateTodos(c *gin.Context) {
+	var op services.BulkOperation
+
+	// Bind JSON to operation struct with validation
+	if err := c.ShouldBindJSON(&op); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Apply the operation using service
+	result, err := h.service(c).BulkUpdateTodos(op)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "required") ||
+			strings.Contains(err.Error(), "exceed") ||
+			strings.Contains(err.Error(), "does not exist") ||
+			strings.Contains(err.Error(), "in the past") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	message := "Bulk operation completed"
+	if result.DryRun {
+		message = "Bulk operation dry run completed"
+	}
+	utils.SuccessResponse(c, http.StatusOK, message, result)
+}
+
+// QuickAddTodo handles POST /api/todos/quick
+func (h *TodoHandler) QuickAddTodo(c *gin.Context) {
+	var req services.QuickAddRequest
+
+	// Bind JSON to request struct with validation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Parse the text and create the todo using service
+	result, err := h.service(c).QuickAddTodo(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "required") ||
+			strings.Contains(err.Error(), "exceed") ||
+			strings.Contains(err.Error(), "does not exist") ||
+			strings.Contains(err.Error(), "past") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	if result.DryRun {
+		utils.SuccessResponse(c, http.StatusOK, "Todo parsed successfully", result)
+		return
+	}
+	utils.SetETag(c, result.Todo.Version)
+	utils.SuccessResponse(c, http.StatusCreated, "Todo created successfully", result)
+}
+
+// ArchiveTodo handles POST /api/todos/:id/archive
+func (h *TodoHandler) ArchiveTodo(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// The todo is only changed if it still has the version from If-Match
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Archive the todo using service
+	if err := h.service(c).ArchiveTodo(uint(id), version); err != nil {
+		if strings.Contains(err.Error(), "modified by another request") {
+			h.versionConflictResponse(c, uint(id), err)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Todo")
+			return
+		}
+		if strings.Contains(err.Error(), "not completed") {
+			utils.ConflictErrorResponse(c, "Only completed todos can be archived")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	// Return the archived todo
+	todo, err := h.service(c).GetTodoByID(uint(id))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SetETag(c, todo.Version)
+	utils.SuccessResponse(c, http.StatusOK, "Todo archived successfully", todo)
+}
+
+// UnarchiveTodo handles POST /api/todos/:id/unarchive
+func (h *TodoHandler) UnarchiveTodo(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// The todo is only changed if it still has the version from If-Match
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Unarchive the todo using service
+	if err := h.service(c).UnarchiveTodo(uint(id), version); err != nil {
+		if strings.Contains(err.Error(), "modified by another request") {
+			h.versionConflictResponse(c, uint(id), err)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Todo")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	// Return the unarchived todo
+	todo, err := h.service(c).GetTodoByID(uint(id))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SetETag(c, todo.Version)
+	utils.SuccessResponse(c, http.StatusOK, "Todo unarchived successfully", todo)
+}
+
+// archiveCompletedRequest is the request body for archiving completed todos in bulk
+type archiveCompletedRequest struct {
+	CategoryID *uint `json:"category_id"`
+}
+
+// ArchiveCompletedTodos handles POST /api/todos/archive-completed
+// Archives the completed todos of category_id, or of all categories when it is omitted
+func (h *TodoHandler) ArchiveCompletedTodos(c *gin.Context) {
+	var req archiveCompletedRequest
+
+	// The body is optional
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Archive the todos using service
+	archived, err := h.service(c).ArchiveCompletedTodos(req.CategoryID)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Completed todos archived successfully", gin.H{
+		"archived": archived,
+	})
+}
+
+// addBlockerRequest is the request body for adding a blocker to a todo
+type addBlockerRequest struct {
+	BlockerID uint `json:"blocker_id" binding:"required,min=1"`
+}
+
+// AddBlocker handles POST /api/todos/:id/blockers
+func (h *TodoHandler) AddBlocker(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	var req addBlockerRequest
+
+	// Bind JSON to request struct with validation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Add the blocker using service
+	if err := h.service(c).AddBlocker(uint(id), req.BlockerID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Todo")
+			return
+		}
+		if strings.Contains(err.Error(), "already exists") {
+			utils.ConflictErrorResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "does not exist") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	// Return the todo with its updated blockers
+	todo, err := h.service(c).GetTodoByID(uint(id))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Blocker added successfully", todo)
+}
+
+// RemoveBlocker handles DELETE /api/todos/:id/blockers/:blocker_id
+func (h *TodoHandler) RemoveBlocker(c *gin.Context) {
+	// Extract IDs from URL parameters
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	blockerID, err := strconv.ParseUint(c.Param("blocker_id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Remove the blocker using service
+	if err := h.service(c).RemoveBlocker(uint(id), uint(blockerID)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Dependency")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Blocker removed successfully", nil)
+}
+
+// GetTodoHistory handles GET /api/todos/:id/history
+func (h *TodoHandler) GetTodoHistory(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Get the change history using service
+	revisions, err := h.service(c).GetTodoHistory(uint(id))
+	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.NotFoundErrorResponse(c, "Todo")
 			return
@@ -191,5 +739,324 @@ func (h *TodoHandler) ToggleTodoComplete(c *gin.Context) {
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Todo completion status toggled successfully", nil)
-}
\ No newline at end of file
+	utils.SuccessResponse(c, http.StatusOK, "Todo history retrieved successfully", revisions)
+}
+
+// RevertTodo handles POST /api/todos/:id/revert/:version
+func (h *TodoHandler) RevertTodo(c *gin.Context) {
+	// Extract ID and version from URL parameters
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	version, err := strconv.ParseUint(c.Param("version"), 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// The todo is only reverted if it still has the version from If-Match
+	expectedVersion, err := utils.IfMatchVersion(c)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Revert the todo using service
+	todo, err := h.service(c).RevertTodo(uint(id), uint(version), expectedVersion)
+	if err != nil {
+		if strings.Contains(err.Error(), "modified by another request") {
+			h.versionConflictResponse(c, uint(id), err)
+			return
+		}
+		if strings.Contains(err.Error(), "revision not found") {
+			utils.NotFoundErrorResponse(c, "Revision")
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Todo")
+			return
+		}
+		if strings.Contains(err.Error(), "open blockers") ||
+			strings.Contains(err.Error(), "not allowed") {
+			utils.ConflictErrorResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "past") ||
+			strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "does not exist") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SetETag(c, todo.Version)
+	utils.SuccessResponse(c, http.StatusOK, "Todo reverted successfully", todo)
+}
+
+// versionConflictResponse answers 412 Precondition Failed with the current version of a todo
+func (h *TodoHandler) versionConflictResponse(c *gin.Context, id uint, err error) {
+	current, getErr := h.service(c).GetTodoByID(id)
+	if getErr != nil {
+		if strings.Contains(getErr.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Todo")
+			return
+		}
+		utils.InternalServerErrorResponse(c, getErr)
+		return
+	}
+
+	utils.SetETag(c, current.Version)
+	utils.PreconditionFailedResponse(c, err.Error(), current)
+}
+
+// maxPatchSize is the largest patch document accepted by PATCH endpoints
+const maxPatchSize = 1 << 20
+
+// readPatch reads a patch document and its media type from the request body
+// On failure an error response has already been sent
+func readPatch(c *gin.Context) ([]byte, string, bool) {
+	mediaType := ""
+	if contentType := c.GetHeader("Content-Type"); contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			utils.ValidationErrorResponse(c, err)
+			return nil, "", false
+		}
+		mediaType = parsed
+	}
+	if mediaType != jsonpatch.MediaTypeMergePatch && mediaType != jsonpatch.MediaTypeJSONPatch && mediaType != "application/json" {
+		c.Header("Accept-Patch", jsonpatch.MediaTypeMergePatch+", "+jsonpatch.MediaTypeJSONPatch)
+		utils.ErrorResponse(c, http.StatusUnsupportedMediaType, "Unsupported patch media type, use "+jsonpatch.MediaTypeMergePatch+" or "+jsonpatch.MediaTypeJSONPatch)
+		return nil, "", false
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPatchSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Patch exceeds maximum size of "+strconv.Itoa(maxPatchSize)+" bytes")
+			return nil, "", false
+		}
+		utils.ValidationErrorResponse(c, err)
+		return nil, "", false
+	}
+	return patch, mediaType, true
+}
+
+// patchErrorResponse maps errors shared by all PATCH endpoints to responses
+func patchErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, jsonpatch.ErrTestFailed) || strings.Contains(err.Error(), "already exists") {
+		utils.ConflictErrorResponse(c, err.Error())
+		return
+	}
+	if strings.Contains(err.Error(), "past") ||
+		strings.Contains(err.Error(), "invalid") ||
+		strings.Contains(err.Error(), "required") ||
+		strings.Contains(err.Error(), "exceed") ||
+		strings.Contains(err.Error(), "does not exist") {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	utils.InternalServerErrorResponse(c, err)
+}
+
+// exportContentTypes maps todo export formats to their content type
+var exportContentTypes = map[string]string{
+	services.ExportFormatCSV:      "text/csv; charset=utf-8",
+	services.ExportFormatJSONL:    "application/x-ndjson",
+	services.ExportFormatMarkdown: "text/markdown; charset=utf-8",
+	services.ExportFormatTodoTxt:  "text/plain; charset=utf-8",
+}
+
+// ExportTodos handles GET /api/todos/export
+// Streams the todos matching the ListTodos filters as csv (default), jsonl, md or txt
+func (h *TodoHandler) ExportTodos(c *gin.Context) {
+	var filters repository.TodoFilters
+	var pagination repository.PaginationParams
+
+	// Bind query parameters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	format := c.DefaultQuery("format", services.ExportFormatCSV)
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		utils.ValidationErrorResponse(c, errors.New("invalid export format: must be csv, jsonl, md or txt"))
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="todos.`+format+`"`)
+
+	// Stream todos using service
+	if err := h.service(c).ExportTodos(filters, pagination, format, c.Writer); err != nil {
+		// Once todos have been written the status can no longer change
+		if c.Writer.Written() {
+			log.Printf("Failed to export todos: %v", err)
+			return
+		}
+		c.Header("Content-Type", "")
+		c.Header("Content-Disposition", "")
+		if strings.Contains(err.Error(), "invalid") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// ExportAccount handles GET /api/export
+// Streams a zip archive with all todos and categories
+func (h *TodoHandler) ExportAccount(c *gin.Context) {
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="todo-export-`+time.Now().UTC().Format("20060102")+`.zip"`)
+
+	// Stream the archive using service
+	if err := h.service(c).ExportAccount(c.Writer); err != nil {
+		if c.Writer.Written() {
+			log.Printf("Failed to export account: %v", err)
+			return
+		}
+		c.Header("Content-Type", "")
+		c.Header("Content-Disposition", "")
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// maxImportSize is the largest import file accepted, in bytes
+const maxImportSize = 10 << 20
+
+// ImportTodos handles POST /api/import
+// The file is sent as the request body or as the "file" field of a multipart form
+func (h *TodoHandler) ImportTodos(c *gin.Context) {
+	h.importTodos(c, c.Query("format"))
+}
+
+// ExportTodoTxt handles GET /api/todos.txt
+// Returns the todos matching the ListTodos filters as a todo.txt file
+func (h *TodoHandler) ExportTodoTxt(c *gin.Context) {
+	var filters repository.TodoFilters
+	var pagination repository.PaginationParams
+
+	// Bind query parameters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Type", exportContentTypes[services.ExportFormatTodoTxt])
+
+	// Stream todos using service
+	if err := h.service(c).ExportTodos(filters, pagination, services.ExportFormatTodoTxt, c.Writer); err != nil {
+		if c.Writer.Written() {
+			log.Printf("Failed to export todo.txt: %v", err)
+			return
+		}
+		c.Header("Content-Type", "")
+		if strings.Contains(err.Error(), "invalid") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// ImportTodoTxt handles POST /api/todos.txt
+// Imports the todo.txt file sent as the request body or as the "file" field of a multipart form
+func (h *TodoHandler) ImportTodoTxt(c *gin.Context) {
+	h.importTodos(c, services.ImportFormatTodoTxt)
+}
+
+// importTodos reads an import file from the request and imports it in the given format
+// An empty format is detected from the file content
+func (h *TodoHandler) importTodos(c *gin.Context, format string) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize+multipartOverhead)
+
+	// Read the file from the form or the body
+	var data []byte
+	var err error
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		var header *multipart.FileHeader
+		if header, err = c.FormFile("file"); err == nil {
+			var file multipart.File
+			if file, err = header.Open(); err == nil {
+				data, err = io.ReadAll(io.LimitReader(file, maxImportSize+1))
+				file.Close()
+			}
+		}
+	} else {
+		data, err = io.ReadAll(c.Request.Body)
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) || len(data) > maxImportSize {
+		utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Import file exceeds maximum size of "+strconv.Itoa(maxImportSize)+" bytes")
+		return
+	}
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	req := services.ImportRequest{
+		Format:  format,
+		Data:    data,
+		Mapping: c.QueryMap("mapping"),
+		DryRun:  c.Query("dry_run") == "true",
+	}
+
+	// Import todos using service
+	report, err := h.service(c).ImportTodos(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			utils.ConflictErrorResponse(c, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "exceed") ||
+			strings.Contains(err.Error(), "no todos") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	// Nothing is imported when any row is invalid, the report tells which rows to fix
+	if report.Invalid > 0 {
+		c.JSON(http.StatusUnprocessableEntity, utils.APIResponse{
+			Success: false,
+			Message: "Import failed: " + strconv.Itoa(report.Invalid) + " rows are invalid",
+			Data:    report,
+		})
+		return
+	}
+
+	if report.DryRun {
+		utils.SuccessResponse(c, http.StatusOK, "Import dry run completed", report)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusCreated, "Todos imported successfully", report)
+}
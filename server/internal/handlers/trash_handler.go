@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/internal/repository"
+	"todo-backend/internal/services"
+	"todo-backend/pkg/utils"
+)
+
+// TrashHandler handles HTTP requests for soft-deleted todos and categories
+type TrashHandler struct {
+	trashService services.TrashService
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(trashService services.TrashService) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+// service returns the trash service scoped to the request, so its changes are audited with it
+func (h *TrashHandler) service(c *gin.Context) services.TrashService {
+	return h.trashService.WithContext(c.Request.Context())
+}
+
+// ListTodos handles GET /api/trash/todos
+func (h *TrashHandler) ListTodos(c *gin.Context) {
+	var pagination repository.PaginationParams
+
+	// Bind query parameters
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Get deleted todos using service
+	todos, paginationResult, err := h.service(c).ListTodos(pagination)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Deleted todos retrieved successfully", todos, paginationResult)
+}
+
+// ListCategories handles GET /api/trash/categories
+func (h *TrashHandler) ListCategories(c *gin.Context) {
+	var pagination repository.PaginationParams
+
+	// Bind query parameters
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Get deleted categories using service
+	categories, paginationResult, err := h.service(c).ListCategories(pagination)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Deleted categories retrieved successfully", categories, paginationResult)
+}
+
+// RestoreTodo handles POST /api/trash/todos/:id/restore
+func (h *TrashHandler) RestoreTodo(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Restore the todo using service
+	todo, err := h.service(c).RestoreTodo(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Deleted todo")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Todo restored successfully", todo)
+}
+
+// RestoreCategory handles POST /api/trash/categories/:id/restore
+func (h *TrashHandler) RestoreCategory(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Restore the category using service
+	category, err := h.service(c).RestoreCategory(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Deleted category")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category restored successfully", category)
+}
+
+// DeleteTodo handles DELETE /api/trash/todos/:id
+func (h *TrashHandler) DeleteTodo(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Permanently delete the todo using service
+	if err := h.service(c).DeleteTodo(uint(id)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Deleted todo")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Todo permanently deleted", nil)
+}
+
+// DeleteCategory handles DELETE /api/trash/categories/:id
+func (h *TrashHandler) DeleteCategory(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Permanently delete the category using service
+	if err := h.service(c).DeleteCategory(uint(id)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Deleted category")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Category permanently deleted", nil)
+}
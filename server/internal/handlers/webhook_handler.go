@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/internal/services"
+	"todo-backend/pkg/utils"
+)
+
+// WebhookHandler handles HTTP requests for webhook subscriptions and their deliveries
+type WebhookHandler struct {
+	webhookService services.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateSubscription handles POST /api/webhooks
+// The response is the only one that contains the signing secret
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var subscription models.WebhookSubscription
+
+	// Bind JSON to subscription struct with validation
+	if err := c.ShouldBindJSON(&subscription); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Create the subscription using service
+	if err := h.webhookService.CreateSubscription(&subscription); err != nil {
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "required") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Webhook subscription created successfully", subscription)
+}
+
+// ListSubscriptions handles GET /api/webhooks
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	// Get subscriptions using service
+	subscriptions, err := h.webhookService.ListSubscriptions()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook subscriptions retrieved successfully", subscriptions)
+}
+
+// GetSubscription handles GET /api/webhooks/:id
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Get the subscription using service
+	subscription, err := h.webhookService.GetSubscription(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Webhook subscription")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook subscription retrieved successfully", subscription)
+}
+
+// UpdateSubscription handles PUT /api/webhooks/:id
+// Omitting the secret keeps the current one, sending a new secret rotates it
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	var subscription models.WebhookSubscription
+
+	// Bind JSON to subscription struct with validation
+	if err := c.ShouldBindJSON(&subscription); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Set the ID from URL parameter
+	subscription.ID = uint(id)
+
+	// Update the subscription using service
+	if err := h.webhookService.UpdateSubscription(&subscription); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Webhook subscription")
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "required") {
+			utils.ValidationErrorResponse(c, err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook subscription updated successfully", subscription)
+}
+
+// DeleteSubscription handles DELETE /api/webhooks/:id
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Delete the subscription using service
+	if err := h.webhookService.DeleteSubscription(uint(id)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Webhook subscription")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook subscription deleted successfully", nil)
+}
+
+// ListDeliveries handles GET /api/webhooks/:id/deliveries
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	var pagination repository.PaginationParams
+
+	// Bind query parameters
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Get the delivery log using service
+	deliveries, paginationResult, err := h.webhookService.ListDeliveries(uint(id), pagination)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Webhook subscription")
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.PaginatedSuccessResponse(c, "Webhook deliveries retrieved successfully", deliveries, paginationResult)
+}
+
+// Redeliver handles POST /api/webhooks/:id/deliveries/:delivery_id/redeliver
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	// Extract IDs from URL parameters
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+	deliveryIDParam := c.Param("delivery_id")
+	deliveryID, err := strconv.ParseUint(deliveryIDParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Queue the redelivery using service
+	delivery, err := h.webhookService.Redeliver(uint(id), uint(deliveryID))
+	if err != nil {
+		if strings.Contains(err.Error(), "subscription not found") {
+			utils.NotFoundErrorResponse(c, "Webhook subscription")
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			utils.NotFoundErrorResponse(c, "Webhook delivery")
+			return
+		}
+		if strings.Contains(err.Error(), "disabled") {
+			utils.ConflictErrorResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Webhook delivery queued successfully", delivery)
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/internal/models"
+	"todo-backend/internal/services"
+	"todo-backend/pkg/utils"
+)
+
+// WorkflowHandler handles HTTP requests for workflow statuses and transitions
+type WorkflowHandler struct {
+	workflowService services.WorkflowService
+}
+
+// NewWorkflowHandler creates a new workflow handler
+func NewWorkflowHandler(workflowService services.WorkflowService) *WorkflowHandler {
+	return &WorkflowHandler{
+		workflowService: workflowService,
+	}
+}
+
+// service returns the workflow service scoped to the request, so its changes are audited with it
+func (h *WorkflowHandler) service(c *gin.Context) services.WorkflowService {
+	return h.workflowService.WithContext(c.Request.Context())
+}
+
+// workflowResponse is the effective workflow of a category
+type workflowResponse struct {
+	Statuses    []models.WorkflowStatus   `json:"statuses"`
+	Transitions []models.StatusTransition `json:"transitions"`
+}
+
+// GetWorkflow handles GET /api/workflow
+// Returns the effective workflow of ?category_id=, or the default workflow
+func (h *WorkflowHandler) GetWorkflow(c *gin.Context) {
+	categoryID, err := optionalUintQuery(c, "category_id")
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Get workflow using service
+	statuses, err := h.service(c).GetWorkflow(categoryID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+	transitions, err := h.service(c).GetTransitions(categoryID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Workflow retrieved successfully", workflowResponse{
+		Statuses:    statuses,
+		Transitions: transitions,
+	})
+}
+
+// CreateStatus handles POST /api/workflow/statuses
+func (h *WorkflowHandler) CreateStatus(c *gin.Context) {
+	var status models.WorkflowStatus
+
+	// Bind JSON to status struct with validation
+	if err := c.ShouldBindJSON(&status); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Create the status using service
+	if err := h.service(c).CreateStatus(&status); err != nil {
+		h.statusErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Status created successfully", status)
+}
+
+// UpdateStatus handles PUT /api/workflow/statuses/:id
+func (h *WorkflowHandler) UpdateStatus(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	var status models.WorkflowStatus
+
+	// Bind JSON to status struct with validation
+	if err := c.ShouldBindJSON(&status); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Set the ID from URL parameter
+	status.ID = uint(id)
+
+	// Update the status using service
+	if err := h.service(c).UpdateStatus(&status); err != nil {
+		h.statusErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Status updated successfully", status)
+}
+
+// DeleteStatus handles DELETE /api/workflow/statuses/:id
+func (h *WorkflowHandler) DeleteStatus(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Delete the status using service
+	if err := h.service(c).DeleteStatus(uint(id)); err != nil {
+		h.statusErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Status deleted successfully", nil)
+}
+
+// CreateTransition handles POST /api/workflow/transitions
+func (h *WorkflowHandler) CreateTransition(c *gin.Context) {
+	var transition models.StatusTransition
+
+	// Bind JSON to transition struct with validation
+	if err := c.ShouldBindJSON(&transition); err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Create the transition using service
+	if err := h.service(c).CreateTransition(&transition); err != nil {
+		h.statusErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Transition created successfully", transition)
+}
+
+// DeleteTransition handles DELETE /api/workflow/transitions/:id
+func (h *WorkflowHandler) DeleteTransition(c *gin.Context) {
+	// Extract ID from URL parameter
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.ValidationErrorResponse(c, err)
+		return
+	}
+
+	// Delete the transition using service
+	if err := h.service(c).DeleteTransition(uint(id)); err != nil {
+		h.statusErrorResponse(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Transition deleted successfully", nil)
+}
+
+// statusErrorResponse maps workflow errors to HTTP responses
+func (h *WorkflowHandler) statusErrorResponse(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "status not found"):
+		utils.NotFoundErrorResponse(c, "Status")
+	case strings.Contains(err.Error(), "transition not found"):
+		utils.NotFoundErrorResponse(c, "Transition")
+	case strings.Contains(err.Error(), "already exists") ||
+		strings.Contains(err.Error(), "associated todos"):
+		utils.ConflictErrorResponse(c, err.Error())
+	case strings.Contains(err.Error(), "invalid") ||
+		strings.Contains(err.Error(), "required") ||
+		strings.Contains(err.Error(), "exceed") ||
+		strings.Contains(err.Error(), "does not exist"):
+		utils.ValidationErrorResponse(c, err)
+	default:
+		utils.InternalServerErrorResponse(c, err)
+	}
+}
+
+// optionalUintQuery parses an optional unsigned integer query parameter
+func optionalUintQuery(c *gin.Context, name string) (*uint, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	id := uint(parsed)
+	return &id, nil
+}
@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a named task that runs periodically in the background
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() error
+}
+
+// Scheduler runs background jobs on fixed intervals
+// Each job runs in its own goroutine and never overlaps with itself
+type Scheduler struct {
+	jobs []Job
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewScheduler creates a new job scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		stop: make(chan struct{}),
+	}
+}
+
+// Add registers a job, jobs with a non-positive interval are disabled
+func (s *Scheduler) Add(name string, interval time.Duration, run func() error) {
+	if interval <= 0 {
+		log.Printf("Job %s is disabled", name)
+		return
+	}
+	s.jobs = append(s.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+// Start starts all registered jobs
+func (s *Scheduler) Start() {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(job)
+	}
+}
+
+// Stop signals all jobs to stop and waits for running jobs to finish
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// loop runs a job on every tick until the scheduler is stopped
+func (s *Scheduler) loop(job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.run(job)
+		}
+	}
+}
+
+// run executes a job once, logging errors and recovering from panics
+func (s *Scheduler) run(job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Job %s panicked: %v", job.Name, r)
+		}
+	}()
+
+	start := time.Now()
+	if err := job.Run(); err != nil {
+		log.Printf("Job %s failed after %v: %v", job.Name, time.Since(start), err)
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/pkg/utils"
+)
+
+// AdminToken requires an "Authorization: Bearer <token>" header matching the given token
+// An empty token disables the routes, so the audit log and webhook secrets are never served unprotected
+func AdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			utils.ErrorResponse(c, http.StatusServiceUnavailable, "admin routes are disabled, set ADMIN_TOKEN to enable them")
+			c.Abort()
+			return
+		}
+
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", "Bearer")
+			utils.ErrorResponse(c, http.StatusUnauthorized, "a valid admin token is required")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// BasicPassword requires HTTP Basic authentication with the given password, for clients such as
+// calendar apps that cannot send bearer tokens; any user name is accepted
+// An empty password leaves the routes open, which is only meant for development
+func BasicPassword(password, realm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if password == "" {
+			c.Next()
+			return
+		}
+
+		_, provided, ok := c.Request.BasicAuth()
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(password)) != 1 {
+			c.Header("WWW-Authenticate", `Basic realm="`+realm+`", charset="UTF-8"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"todo-backend/internal/repository"
+)
+
+// ActorHeader is the header that identifies who made a request
+const ActorHeader = "X-Actor"
+
+// Audit attaches who made a request to its context
+// Services scoped to the request context record it in the audit log with every change they commit
+func Audit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := repository.WithAuditInfo(c.Request.Context(), repository.AuditInfo{
+			Actor:     c.GetHeader(ActorHeader),
+			IP:        c.ClientIP(),
+			RequestID: c.GetString(RequestIDKey),
+			Method:    c.Request.Method,
+			Route:     c.FullPath(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
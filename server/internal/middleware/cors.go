@@ -7,15 +7,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// developmentOrigins are the origins of the React development server
+var developmentOrigins = []string{
+	"http://localhost:3000", // React development server
+	"http://localhost:3001", // Alternative React port
+	"http://127.0.0.1:3000",
+	"http://127.0.0.1:3001",
+}
+
 // CORS returns a CORS middleware with appropriate configuration
 func CORS() gin.HandlerFunc {
 	return cors.New(cors.Config{
-		AllowOrigins: []string{
-			"http://localhost:3000",  // React development server
-			"http://localhost:3001",  // Alternative React port
-			"http://127.0.0.1:3000",
-			"http://127.0.0.1:3001",
-		},
+		AllowOrigins: developmentOrigins,
 		AllowMethods: []string{
 			"GET",
 			"POST",
@@ -30,10 +33,18 @@ func CORS() gin.HandlerFunc {
 			"Accept",
 			"Authorization",
 			"X-Requested-With",
+			"If-Match",
+			"If-None-Match",
+			"X-Actor",
+			"X-Request-ID",
+			"Idempotency-Key",
 		},
 		ExposeHeaders: []string{
 			"Content-Length",
 			"Content-Type",
+			"ETag",
+			"X-Request-ID",
+			"Idempotent-Replayed",
 		},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -58,12 +69,31 @@ func ProductionCORS(allowedOrigins []string) gin.HandlerFunc {
 			"Accept",
 			"Authorization",
 			"X-Requested-With",
+			"If-Match",
+			"If-None-Match",
+			"X-Actor",
+			"X-Request-ID",
+			"Idempotency-Key",
 		},
 		ExposeHeaders: []string{
 			"Content-Length",
 			"Content-Type",
+			"ETag",
+			"X-Request-ID",
+			"Idempotent-Replayed",
 		},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	})
-}
\ No newline at end of file
+}
+
+// OriginAllowed reports whether the CORS middleware accepts requests from an origin
+// It is used for WebSocket upgrades, which are not covered by CORS
+func OriginAllowed(origin string) bool {
+	for _, allowed := range developmentOrigins {
+		if origin == allowed {
+			return true
+		}
+	}
+	return false
+}
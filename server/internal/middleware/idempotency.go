@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/internal/models"
+	"todo-backend/internal/services"
+	"todo-backend/pkg/utils"
+)
+
+// Idempotency headers
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response that was replayed for a retried request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// replayedHeaders are the response headers stored and replayed with a response
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// idempotencyWriter keeps a copy of the response body, so it can be replayed
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes POST requests to the given routes safe to retry when they carry an Idempotency-Key header
+// The first request with a key is handled and its response stored; retries with the same key and body get
+// the stored response, a different body is refused with 422 and a retry during the first request with 409.
+// Responses with a server error are not stored, so the request can be retried. Bodies are read
+// into memory to compute their fingerprint, requests with a key and a body over maxBodySize are refused
+func Idempotency(idempotencyService services.IdempotencyService, maxBodySize int64, routes ...string) gin.HandlerFunc {
+	guarded := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		guarded[route] = true
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || c.Request.Method != http.MethodPost || !guarded[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		// The fingerprint covers the target and the body, which is restored for the handler
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodySize+1))
+		if err != nil {
+			utils.ValidationErrorResponse(c, err)
+			c.Abort()
+			return
+		}
+		if int64(len(body)) > maxBodySize {
+			utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "Request body is too large for an idempotent request")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		stored, err := idempotencyService.Begin(key, fingerprint)
+		if err != nil {
+			switch {
+			case strings.Contains(err.Error(), "invalid"):
+				utils.ValidationErrorResponse(c, err)
+			case strings.Contains(err.Error(), "different request"):
+				utils.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+			case strings.Contains(err.Error(), "in progress"):
+				c.Header("Retry-After", "1")
+				utils.ErrorResponse(c, http.StatusConflict, err.Error())
+			default:
+				utils.InternalServerErrorResponse(c, err)
+			}
+			c.Abort()
+			return
+		}
+
+		// Replay the response to the first request
+		if stored != nil {
+			for name, value := range stored.Headers {
+				c.Header(name, value)
+			}
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(stored.Status, stored.Headers["Content-Type"], stored.Body)
+			c.Abort()
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			if err := idempotencyService.Release(key); err != nil {
+				log.Printf("Failed to release idempotency key %q: %v", key, err)
+			}
+			return
+		}
+
+		headers := models.Headers{}
+		for _, name := range replayedHeaders {
+			if value := writer.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		if err := idempotencyService.Complete(key, status, headers, writer.body.Bytes()); err != nil {
+			log.Printf("Failed to store response for idempotency key %q: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/internal/services"
+)
+
+// fakeIdempotencyRepo keeps idempotency keys in memory, following the contract of IdempotencyRepository
+type fakeIdempotencyRepo struct {
+	repository.IdempotencyRepository
+	keys map[string]*models.IdempotencyKey
+}
+
+func (r *fakeIdempotencyRepo) Acquire(key *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	now := time.Now()
+	existing, ok := r.keys[key.Key]
+	takeOver := ok && (existing.ExpiresAt.Before(now) ||
+		(!existing.Completed() && existing.LockedUntil.Before(now) && existing.Fingerprint == key.Fingerprint))
+	if ok && !takeOver {
+		copied := *existing
+		return &copied, nil
+	}
+	copied := *key
+	r.keys[key.Key] = &copied
+	return nil, nil
+}
+
+func (r *fakeIdempotencyRepo) Complete(key string, status int, headers models.Headers, body []byte) error {
+	stored, ok := r.keys[key]
+	if !ok || stored.Completed() {
+		return nil
+	}
+	now := time.Now()
+	stored.Status, stored.Headers, stored.Body = status, headers, body
+	stored.LockedUntil, stored.CompletedAt = nil, &now
+	return nil
+}
+
+func (r *fakeIdempotencyRepo) Release(key string) error {
+	if stored, ok := r.keys[key]; ok && !stored.Completed() {
+		delete(r.keys, key)
+	}
+	return nil
+}
+
+// expireLocks ends the locks of all keys, as if the lock timeout had passed
+func (r *fakeIdempotencyRepo) expireLocks() {
+	past := time.Now().Add(-time.Second)
+	for _, key := range r.keys {
+		if key.LockedUntil != nil {
+			key.LockedUntil = &past
+		}
+	}
+}
+
+// idempotencyStep is one request of an idempotency scenario
+type idempotencyStep struct {
+	before       func(repo *fakeIdempotencyRepo)
+	body         string
+	wantStatus   int
+	wantReplayed bool
+	wantCalls    int // handler calls after the step
+	wantFirst    bool
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name  string
+		steps []idempotencyStep
+	}{
+		{
+			name: "retry replays the stored response",
+			steps: []idempotencyStep{
+				{body: `{"title":"a"}`, wantStatus: http.StatusCreated, wantCalls: 1},
+				{body: `{"title":"a"}`, wantStatus: http.StatusCreated, wantReplayed: true, wantCalls: 1, wantFirst: true},
+			},
+		},
+		{
+			name: "different body is refused",
+			steps: []idempotencyStep{
+				{body: `{"title":"a"}`, wantStatus: http.StatusCreated, wantCalls: 1},
+				{body: `{"title":"b"}`, wantStatus: http.StatusUnprocessableEntity, wantCalls: 1},
+			},
+		},
+		{
+			name: "server error is not stored",
+			steps: []idempotencyStep{
+				{body: `{"title":"error"}`, wantStatus: http.StatusInternalServerError, wantCalls: 1},
+				{body: `{"title":"error"}`, wantStatus: http.StatusInternalServerError, wantCalls: 2},
+			},
+		},
+		{
+			name: "retry while the key is locked",
+			steps: []idempotencyStep{
+				{body: `{"title":"crash"}`, wantCalls: 1},
+				{body: `{"title":"crash"}`, wantStatus: http.StatusConflict, wantCalls: 1},
+			},
+		},
+		{
+			name: "retry after the lock timeout is handled",
+			steps: []idempotencyStep{
+				{body: `{"title":"crash"}`, wantCalls: 1},
+				{before: (*fakeIdempotencyRepo).expireLocks, body: `{"title":"crash"}`, wantStatus: http.StatusCreated, wantCalls: 2},
+				{body: `{"title":"crash"}`, wantStatus: http.StatusCreated, wantReplayed: true, wantCalls: 2},
+			},
+		},
+		{
+			name: "expired lock is not taken over by a different body",
+			steps: []idempotencyStep{
+				{body: `{"title":"crash"}`, wantCalls: 1},
+				{before: (*fakeIdempotencyRepo).expireLocks, body: `{"title":"b"}`, wantStatus: http.StatusUnprocessableEntity, wantCalls: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeIdempotencyRepo{keys: map[string]*models.IdempotencyKey{}}
+			calls := 0
+
+			// The handler fails with "error" and crashes on the first "crash", leaving the key locked
+			router := gin.New()
+			router.Use(Idempotency(services.NewIdempotencyService(repo, time.Hour), 1024, "/api/todos"))
+			router.POST("/api/todos", func(c *gin.Context) {
+				calls++
+				body, _ := io.ReadAll(c.Request.Body)
+				switch {
+				case strings.Contains(string(body), "error"):
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed"})
+				case strings.Contains(string(body), "crash") && calls == 1:
+					panic("request abandoned")
+				default:
+					c.Header("ETag", fmt.Sprintf(`"%d"`, calls))
+					c.JSON(http.StatusCreated, gin.H{"call": calls, "body": string(body)})
+				}
+			})
+
+			var first *httptest.ResponseRecorder
+			for i, step := range tt.steps {
+				if step.before != nil {
+					step.before(repo)
+				}
+				recorder := serveIdempotent(router, step.body)
+
+				if step.wantStatus == 0 {
+					if recorder != nil {
+						t.Fatalf("step %d: status = %d; want the handler to crash", i, recorder.Code)
+					}
+				} else {
+					if recorder == nil {
+						t.Fatalf("step %d: handler crashed; want status %d", i, step.wantStatus)
+					}
+					if recorder.Code != step.wantStatus {
+						t.Fatalf("step %d: status = %d; want %d: %s", i, recorder.Code, step.wantStatus, recorder.Body)
+					}
+					if replayed := recorder.Header().Get(IdempotentReplayedHeader) == "true"; replayed != step.wantReplayed {
+						t.Errorf("step %d: replayed = %v; want %v", i, replayed, step.wantReplayed)
+					}
+					if step.wantStatus == http.StatusConflict && recorder.Header().Get("Retry-After") == "" {
+						t.Errorf("step %d: conflict without Retry-After", i)
+					}
+				}
+				if calls != step.wantCalls {
+					t.Errorf("step %d: handler calls = %d; want %d", i, calls, step.wantCalls)
+				}
+
+				if step.wantFirst {
+					if recorder.Body.String() != first.Body.String() {
+						t.Errorf("step %d: body = %s; want the first response %s", i, recorder.Body, first.Body)
+					}
+					if got, want := recorder.Header().Get("ETag"), first.Header().Get("ETag"); got != want {
+						t.Errorf("step %d: ETag = %q; want %q", i, got, want)
+					}
+				}
+				if first == nil {
+					first = recorder
+				}
+			}
+		})
+	}
+}
+
+// serveIdempotent sends a POST with an idempotency key, returning nil when the handler crashes
+func serveIdempotent(router *gin.Engine, body string) (recorder *httptest.ResponseRecorder) {
+	defer func() {
+		if recover() != nil {
+			recorder = nil
+		}
+	}()
+
+	request := httptest.NewRequest(http.MethodPost, "/api/todos", strings.NewReader(body))
+	request.Header.Set(IdempotencyKeyHeader, "key-1")
+	request.Header.Set("Content-Type", "application/json")
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
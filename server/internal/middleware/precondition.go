@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"todo-backend/pkg/utils"
+)
+
+// RequireIfMatch rejects PUT, PATCH and DELETE requests to the given routes that have no If-Match header
+// Clients must send the ETag they read, so concurrent edits cannot silently overwrite each other
+func RequireIfMatch(routes ...string) gin.HandlerFunc {
+	guarded := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		guarded[route] = true
+	}
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPut, http.MethodPatch, http.MethodDelete:
+			if guarded[c.FullPath()] && c.GetHeader("If-Match") == "" {
+				utils.ErrorResponse(c, http.StatusPreconditionRequired, "If-Match header is required, send the ETag of the resource being changed")
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
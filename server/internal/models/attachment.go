@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// Attachment represents a file attached to a todo
+// Blobs are content-addressed by checksum, so identical uploads share one stored blob
+type Attachment struct {
+	ID          uint      `json:"id" gorm:"primarykey"`
+	TodoID      uint      `json:"todo_id" gorm:"not null;index"`
+	FileName    string    `json:"file_name" gorm:"not null;size:255"`
+	ContentType string    `json:"content_type" gorm:"not null;size:100"`
+	Size        int64     `json:"size" gorm:"not null"`
+	Checksum    string    `json:"checksum" gorm:"not null;size:64;index"`
+	StorageKey  string    `json:"-" gorm:"not null;size:255;index"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relationship: Attachment belongs to a todo
+	Todo *Todo `json:"-" gorm:"foreignKey:TodoID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for Attachment model
+func (Attachment) TableName() string {
+	return "attachments"
+}
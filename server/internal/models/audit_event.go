@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actors of changes whose caller is not identified
+const (
+	// AuditActorAnonymous is the actor of requests that do not identify their caller
+	AuditActorAnonymous = "anonymous"
+	// AuditActorSystem is the actor of changes made by background jobs
+	AuditActorSystem = "system"
+)
+
+// Audit actions of changes that publish no change event
+// Changes that do publish one are recorded with the event type as action, e.g. "todo.updated"
+const (
+	AuditActionTodoPurged        = "todo.purged"
+	AuditActionCategoryPurged    = "category.purged"
+	AuditActionCommentCreated    = "comment.created"
+	AuditActionCommentUpdated    = "comment.updated"
+	AuditActionCommentDeleted    = "comment.deleted"
+	AuditActionAttachmentCreated = "attachment.created"
+	AuditActionAttachmentDeleted = "attachment.deleted"
+	AuditActionBlockerAdded      = "blocker.added"
+	AuditActionBlockerRemoved    = "blocker.removed"
+)
+
+// AuditEvent records one committed change of a todo or category, in the transaction of the change
+// Audit events are append-only, the repository has no way to change or remove them
+type AuditEvent struct {
+	ID         uint            `json:"id" gorm:"primarykey"`
+	Actor      string          `json:"actor" gorm:"not null;size:100;index"`
+	IP         string          `json:"ip" gorm:"size:45"`
+	RequestID  string          `json:"request_id" gorm:"size:64;index"`
+	Method     string          `json:"method" gorm:"not null;size:10"`
+	Route      string          `json:"route" gorm:"not null;size:255"`
+	EntityType string          `json:"entity_type" gorm:"not null;size:20;index:idx_audit_events_entity"`
+	EntityID   *uint           `json:"entity_id,omitempty" gorm:"index:idx_audit_events_entity"`
+	Action     string          `json:"action" gorm:"not null;size:50;index"`
+	Before     json.RawMessage `json:"before,omitempty" gorm:"type:jsonb"`
+	After      json.RawMessage `json:"after,omitempty" gorm:"type:jsonb"`
+	CreatedAt  time.Time       `json:"created_at" gorm:"index"`
+}
+
+// TableName returns the table name for AuditEvent model
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
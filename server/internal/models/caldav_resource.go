@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// CalDAVResource keeps the resource name and UID that a CalDAV client chose for a todo it created
+// Todos without one are served as todo-<id>.ics with a UID derived from their ID
+type CalDAVResource struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	TodoID    uint      `json:"todo_id" gorm:"not null;uniqueIndex"`
+	Name      string    `json:"name" gorm:"not null;size:255;uniqueIndex"`
+	UID       string    `json:"uid" gorm:"column:uid;not null;size:255"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationship: Resource belongs to a todo and goes away with it
+	Todo *Todo `json:"-" gorm:"foreignKey:TodoID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for CalDAVResource model
+func (CalDAVResource) TableName() string {
+	return "caldav_resources"
+}
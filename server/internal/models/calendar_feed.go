@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// CalendarFeed is a read-only iCalendar feed of todos with a due date, reached through a secret token
+// Only a hash of the token is stored, the token itself is returned once when the feed is created
+type CalendarFeed struct {
+	ID            uint      `json:"id" gorm:"primarykey"`
+	Name          string    `json:"name" gorm:"not null;size:100" binding:"required,min=1,max=100"`
+	Token         string    `json:"token,omitempty" gorm:"-"`
+	URL           string    `json:"url,omitempty" gorm:"-"`
+	TokenHash     string    `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	CategoryIDs   []uint    `json:"category_ids" gorm:"type:jsonb;not null;serializer:json"`
+	TimeZone      string    `json:"time_zone" gorm:"size:64"`
+	IncludeEvents bool      `json:"include_events" gorm:"not null;default:false"`
+	HideCompleted bool      `json:"hide_completed" gorm:"not null;default:false"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for CalendarFeed model
+func (CalendarFeed) TableName() string {
+	return "calendar_feeds"
+}
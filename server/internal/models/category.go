@@ -12,6 +12,7 @@ type Category struct {
 	ID        uint           `json:"id" gorm:"primarykey"`
 	Name      string         `json:"name" gorm:"uniqueIndex;not null;size:100" binding:"required,min=1,max=100"`
 	Color     string         `json:"color" gorm:"not null;size:7" binding:"required,hexcolor"`
+	Version   uint           `json:"version" gorm:"not null;default:1"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
@@ -26,10 +27,11 @@ func (Category) TableName() string {
 }
 
 // BeforeCreate hook runs before creating a category
-// Sets default color if not provided
+// Sets default color if not provided and starts the version at 1
 func (c *Category) BeforeCreate(tx *gorm.DB) error {
 	if c.Color == "" {
 		c.Color = "#3B82F6" // Default blue color
 	}
+	c.Version = 1
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// ChangeEventsChannel is the Postgres NOTIFY channel change events are published on
+const ChangeEventsChannel = "change_events"
+
+// Change event entity types
+const (
+	EntityTodo     = "todo"
+	EntityCategory = "category"
+)
+
+// Change event types
+const (
+	EventTodoCreated      = "todo.created"
+	EventTodoUpdated      = "todo.updated"
+	EventTodoCompleted    = "todo.completed"
+	EventTodoReopened     = "todo.reopened"
+	EventTodoArchived     = "todo.archived"
+	EventTodoUnarchived   = "todo.unarchived"
+	EventTodoDeleted      = "todo.deleted"
+	EventTodoRestored     = "todo.restored"
+	EventTodoMoved        = "todo.moved"
+	EventCategoryCreated  = "category.created"
+	EventCategoryUpdated  = "category.updated"
+	EventCategoryDeleted  = "category.deleted"
+	EventCategoryRestored = "category.restored"
+
+	// EventAll subscribes to every event type
+	EventAll = "*"
+)
+
+// EventTypes lists all change event types
+var EventTypes = []string{
+	EventTodoCreated, EventTodoUpdated, EventTodoCompleted, EventTodoReopened,
+	EventTodoArchived, EventTodoUnarchived, EventTodoDeleted, EventTodoRestored, EventTodoMoved,
+	EventCategoryCreated, EventCategoryUpdated, EventCategoryDeleted, EventCategoryRestored,
+}
+
+// todoRevisionEvents maps todo revision actions to the change event they publish
+var todoRevisionEvents = map[string]string{
+	RevisionActionCreate:    EventTodoCreated,
+	RevisionActionUpdate:    EventTodoUpdated,
+	RevisionActionComplete:  EventTodoCompleted,
+	RevisionActionReopen:    EventTodoReopened,
+	RevisionActionArchive:   EventTodoArchived,
+	RevisionActionUnarchive: EventTodoUnarchived,
+	RevisionActionDelete:    EventTodoDeleted,
+	RevisionActionRestore:   EventTodoRestored,
+}
+
+// TodoEventType returns the change event type of a todo revision action
+func TodoEventType(action string) string {
+	return todoRevisionEvents[action]
+}
+
+// ChangeEvent describes a committed change of a todo or category
+// It is sent to webhook subscribers and pushed to clients of the event stream
+type ChangeEvent struct {
+	ID         string        `json:"id"`
+	Type       string        `json:"type"`
+	EntityType string        `json:"entity_type"`
+	EntityID   uint          `json:"entity_id"`
+	CategoryID *uint         `json:"category_id,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+	Data       interface{}   `json:"data,omitempty"`
+	Changes    []FieldChange `json:"changes,omitempty"`
+
+	// Truncated is set when data and changes were left out because the event was too large
+	Truncated bool `json:"truncated,omitempty"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment represents a discussion comment attached to a todo
+// The body is stored as markdown and rendered by the client
+type Comment struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	TodoID    uint           `json:"todo_id" gorm:"not null;index"`
+	Author    string         `json:"author" gorm:"not null;size:100" binding:"required,min=1,max=100"`
+	Body      string         `json:"body" gorm:"type:text;not null" binding:"required,min=1"`
+	EditedAt  *time.Time     `json:"edited_at,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationship: Comment belongs to a todo
+	Todo *Todo `json:"-" gorm:"foreignKey:TodoID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// Relationship: One comment can have many mentions and revisions
+	Mentions  []CommentMention  `json:"mentions,omitempty" gorm:"foreignKey:CommentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Revisions []CommentRevision `json:"revisions,omitempty" gorm:"foreignKey:CommentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for Comment model
+func (Comment) TableName() string {
+	return "comments"
+}
+
+// CommentMention records a user mentioned with @username in a comment
+type CommentMention struct {
+	ID        uint      `json:"-" gorm:"primarykey"`
+	CommentID uint      `json:"-" gorm:"not null;uniqueIndex:idx_comment_mentions_comment_username"`
+	Username  string    `json:"username" gorm:"not null;size:100;index;uniqueIndex:idx_comment_mentions_comment_username"`
+	CreatedAt time.Time `json:"-"`
+}
+
+// TableName returns the table name for CommentMention model
+func (CommentMention) TableName() string {
+	return "comment_mentions"
+}
+
+// CommentRevision stores a previous body of an edited comment
+// Revisions form the edit history of a comment, oldest first
+type CommentRevision struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CommentID uint      `json:"-" gorm:"not null;index"`
+	Body      string    `json:"body" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName returns the table name for CommentRevision model
+func (CommentRevision) TableName() string {
+	return "comment_revisions"
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// IdempotencyKey stores the response to a request sent with an Idempotency-Key header
+// A key without a response belongs to a request that is still being handled and acts as its lock
+type IdempotencyKey struct {
+	ID          uint       `json:"id" gorm:"primarykey"`
+	Key         string     `json:"key" gorm:"not null;size:255;uniqueIndex"`
+	Fingerprint string     `json:"fingerprint" gorm:"not null;size:64"`
+	Status      int        `json:"status"`
+	Headers     Headers    `json:"headers" gorm:"type:jsonb;serializer:json"`
+	Body        []byte     `json:"-"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null;index"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Headers holds response headers that are replayed with a stored response
+type Headers map[string]string
+
+// TableName returns the table name for IdempotencyKey model
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// Completed reports whether the response to the request has been stored
+func (k *IdempotencyKey) Completed() bool {
+	return k.CompletedAt != nil
+}
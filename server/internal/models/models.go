@@ -8,6 +8,23 @@ package models
 func AllModels() []interface{} {
 	return []interface{}{
 		&Category{},
+		&WorkflowStatus{},
+		&StatusTransition{},
+		&Tag{},
 		&Todo{},
+		&Comment{},
+		&CommentMention{},
+		&CommentRevision{},
+		&Attachment{},
+		&TodoDependency{},
+		&TodoRevision{},
+		&AuditEvent{},
+		&WebhookSubscription{},
+		&WebhookDelivery{},
+		&SyncChange{},
+		&IdempotencyKey{},
+		&CalendarFeed{},
+		&CalDAVResource{},
+		&SavedFilter{},
 	}
 }
\ No newline at end of file
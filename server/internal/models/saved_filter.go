@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SavedFilter is a named combination of todo filters and a sort order, read as a smart list
+// Filters holds the query parameters of GET /api/todos as a JSON object; relative dates in its
+// filter query, such as due:<7d, are resolved each time the list is read
+type SavedFilter struct {
+	ID        uint            `json:"id" gorm:"primarykey"`
+	Name      string          `json:"name" gorm:"not null;size:100" binding:"required,min=1,max=100"`
+	Filters   json.RawMessage `json:"filters" gorm:"type:jsonb;not null"`
+	SortBy    string          `json:"sort_by" gorm:"size:20"`
+	SortOrder string          `json:"sort_order" gorm:"size:4" binding:"omitempty,oneof=asc desc"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// TableName returns the table name for SavedFilter model
+func (SavedFilter) TableName() string {
+	return "saved_filters"
+}
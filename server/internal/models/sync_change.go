@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// SyncChange records that a todo or category changed, its ID is the change sequence used by sync tokens
+// Changes are written in commit order, so a client that has seen a sequence number has seen every change before it
+type SyncChange struct {
+	ID         uint64    `json:"id" gorm:"primarykey"`
+	EntityType string    `json:"entity_type" gorm:"not null;size:20"`
+	EntityID   uint      `json:"entity_id" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName returns the table name for SyncChange model
+func (SyncChange) TableName() string {
+	return "sync_changes"
+}
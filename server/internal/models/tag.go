@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag is a free-form label that can be attached to many todos
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	Name      string    `json:"name" gorm:"uniqueIndex;not null;size:50"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName returns the table name for Tag model
+func (Tag) TableName() string {
+	return "tags"
+}
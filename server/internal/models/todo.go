@@ -35,12 +35,29 @@ type Todo struct {
 	Priority    Priority       `json:"priority" gorm:"type:varchar(10);default:'medium'" binding:"omitempty,oneof=low medium high"`
 	DueDate     *time.Time     `json:"due_date,omitempty" gorm:"index"`
 	CategoryID  *uint          `json:"category_id,omitempty" gorm:"index"`
+	StatusID    *uint          `json:"status_id,omitempty" gorm:"index"`
+	Position    string         `json:"position" gorm:"size:255;not null;default:''"`
+	Recurrence  string         `json:"recurrence" gorm:"size:255;not null;default:''"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+	ArchivedAt  *time.Time     `json:"archived_at,omitempty" gorm:"index"`
+	Version     uint           `json:"version" gorm:"not null;default:1"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// Computed fields, filled when todos are loaded and not stored
+	CommentCount int64  `json:"comment_count" gorm:"-"`
+	Blocked      bool   `json:"blocked" gorm:"-"`
+	BlockedBy    []uint `json:"blocked_by" gorm:"-"`
+
 	// Relationship: Todo belongs to a category
 	Category *Category `json:"category,omitempty" gorm:"foreignKey:CategoryID;references:ID"`
+
+	// Relationship: Todo has many tags, managed through bulk operations
+	Tags []Tag `json:"tags" gorm:"many2many:todo_tags;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// Relationship: Todo is in a workflow status, Completed mirrors its done flag
+	Status *WorkflowStatus `json:"status,omitempty" gorm:"foreignKey:StatusID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
 }
 
 // TableName returns the table name for Todo model
@@ -49,11 +66,27 @@ func (Todo) TableName() string {
 }
 
 // BeforeCreate hook runs before creating a todo
-// Sets default priority if not provided
+// Sets default priority if not provided and starts the version at 1
 func (t *Todo) BeforeCreate(tx *gorm.DB) error {
 	if t.Priority == "" {
 		t.Priority = PriorityMedium
 	}
+	t.Version = 1
+	return nil
+}
+
+// BeforeSave hook runs before creating or saving a todo
+// Keeps the completion time in sync with the completed flag; reopened todos leave the archive
+func (t *Todo) BeforeSave(tx *gorm.DB) error {
+	if t.Completed {
+		if t.CompletedAt == nil {
+			now := time.Now().UTC()
+			t.CompletedAt = &now
+		}
+	} else {
+		t.CompletedAt = nil
+		t.ArchivedAt = nil
+	}
 	return nil
 }
 
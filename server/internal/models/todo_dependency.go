@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// TodoDependency represents a "blocked by" edge between two todos
+// TodoID cannot be completed while the todo referenced by BlockedByID is open
+type TodoDependency struct {
+	ID          uint      `json:"id" gorm:"primarykey"`
+	TodoID      uint      `json:"todo_id" gorm:"not null;uniqueIndex:idx_todo_dependencies_edge"`
+	BlockedByID uint      `json:"blocked_by_id" gorm:"not null;index;uniqueIndex:idx_todo_dependencies_edge"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Relationships: both ends of the edge are todos
+	Todo      *Todo `json:"-" gorm:"foreignKey:TodoID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	BlockedBy *Todo `json:"-" gorm:"foreignKey:BlockedByID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for TodoDependency model
+func (TodoDependency) TableName() string {
+	return "todo_dependencies"
+}
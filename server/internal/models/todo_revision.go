@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Revision actions
+const (
+	RevisionActionCreate    = "create"
+	RevisionActionUpdate    = "update"
+	RevisionActionComplete  = "complete"
+	RevisionActionReopen    = "reopen"
+	RevisionActionArchive   = "archive"
+	RevisionActionUnarchive = "unarchive"
+	RevisionActionDelete    = "delete"
+	RevisionActionRestore   = "restore"
+)
+
+// TodoRevision records the state of a todo after a change
+// Revisions form the change history of a todo, one per version
+type TodoRevision struct {
+	ID        uint          `json:"id" gorm:"primarykey"`
+	TodoID    uint          `json:"todo_id" gorm:"not null;uniqueIndex:idx_todo_revisions_todo_version"`
+	Version   uint          `json:"version" gorm:"not null;uniqueIndex:idx_todo_revisions_todo_version"`
+	Action    string        `json:"action" gorm:"not null;size:20"`
+	Snapshot  TodoSnapshot  `json:"snapshot" gorm:"type:jsonb;not null;serializer:json"`
+	Changes   []FieldChange `json:"changes" gorm:"type:jsonb;not null;serializer:json"`
+	CreatedAt time.Time     `json:"created_at"`
+
+	// Relationship: Revision belongs to a todo
+	Todo *Todo `json:"-" gorm:"foreignKey:TodoID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for TodoRevision model
+func (TodoRevision) TableName() string {
+	return "todo_revisions"
+}
+
+// TodoSnapshot holds the user-editable state of a todo
+// The manual order position is not part of the history
+type TodoSnapshot struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Completed   bool       `json:"completed"`
+	Priority    Priority   `json:"priority"`
+	DueDate     *time.Time `json:"due_date"`
+	Recurrence  string     `json:"recurrence"`
+	CategoryID  *uint      `json:"category_id"`
+	StatusID    *uint      `json:"status_id"`
+	Tags        []string   `json:"tags"`
+	Archived    bool       `json:"archived"`
+	Deleted     bool       `json:"deleted"`
+}
+
+// FieldChange describes how one field of a todo changed between two revisions
+type FieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+// NewTodoSnapshot captures the current state of a todo, its tags must be loaded
+func NewTodoSnapshot(todo *Todo) TodoSnapshot {
+	tags := make([]string, 0, len(todo.Tags))
+	for _, tag := range todo.Tags {
+		tags = append(tags, tag.Name)
+	}
+	sort.Strings(tags)
+
+	return TodoSnapshot{
+		Title:       todo.Title,
+		Description: todo.Description,
+		Completed:   todo.Completed,
+		Priority:    todo.Priority,
+		DueDate:     todo.DueDate,
+		Recurrence:  todo.Recurrence,
+		CategoryID:  todo.CategoryID,
+		StatusID:    todo.StatusID,
+		Tags:        tags,
+		Archived:    todo.ArchivedAt != nil,
+		Deleted:     todo.DeletedAt.Valid,
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// TrashedTodo is a soft-deleted todo as listed in the trash
+type TrashedTodo struct {
+	Todo
+	DeletedAt time.Time `json:"deleted_at"`
+	// PurgeAt is when the retention job removes the todo permanently, nil when purging is disabled
+	PurgeAt *time.Time `json:"purge_at,omitempty"`
+}
+
+// TrashedCategory is a soft-deleted category as listed in the trash
+type TrashedCategory struct {
+	Category
+	DeletedAt time.Time `json:"deleted_at"`
+	// PurgeAt is when the retention job removes the category permanently, nil when purging is disabled
+	PurgeAt *time.Time `json:"purge_at,omitempty"`
+}
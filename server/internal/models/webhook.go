@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookSubscription sends the selected events to a URL
+// Requests are signed with the secret, which is only returned when the subscription is created
+type WebhookSubscription struct {
+	ID          uint      `json:"id" gorm:"primarykey"`
+	URL         string    `json:"url" gorm:"not null;size:2048" binding:"required,url,max=2048"`
+	Secret      string    `json:"secret,omitempty" gorm:"not null;size:100" binding:"omitempty,min=16,max=100"`
+	Events      []string  `json:"events" gorm:"type:jsonb;not null;serializer:json" binding:"required,min=1"`
+	Description string    `json:"description" gorm:"size:255" binding:"max=255"`
+	Disabled    bool      `json:"disabled" gorm:"not null;default:false"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relationship: One subscription has many deliveries
+	Deliveries []WebhookDelivery `json:"-" gorm:"foreignKey:SubscriptionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for WebhookSubscription model
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Subscribes reports whether the subscription selects an event type
+func (s *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, event := range s.Events {
+		if event == EventAll || event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for a subscription, together with the result of its last attempt
+// Failed attempts are retried with exponential backoff until the delivery succeeds or runs out of attempts
+type WebhookDelivery struct {
+	ID             uint            `json:"id" gorm:"primarykey"`
+	SubscriptionID uint            `json:"subscription_id" gorm:"not null;index"`
+	EventID        string          `json:"event_id" gorm:"not null;size:32;index"`
+	EventType      string          `json:"event_type" gorm:"not null;size:50"`
+	Payload        json.RawMessage `json:"payload" gorm:"type:jsonb;not null"`
+	Status         string          `json:"status" gorm:"not null;size:20;index:idx_webhook_deliveries_due"`
+	Attempts       int             `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" gorm:"not null;index:idx_webhook_deliveries_due"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty" gorm:"type:text"`
+	Error          string          `json:"error,omitempty" gorm:"type:text"`
+	RedeliveryOf   *uint           `json:"redelivery_of,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+
+	// Relationship: Delivery belongs to a subscription
+	Subscription *WebhookSubscription `json:"-" gorm:"foreignKey:SubscriptionID"`
+}
+
+// TableName returns the table name for WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+// WorkflowStatus represents a step of a kanban workflow such as "In progress"
+// Statuses without a category form the default workflow used by all categories
+// that do not define their own
+type WorkflowStatus struct {
+	ID         uint      `json:"id" gorm:"primarykey"`
+	CategoryID *uint     `json:"category_id,omitempty" gorm:"index;uniqueIndex:idx_workflow_statuses_category_key"`
+	Key        string    `json:"key" gorm:"not null;size:50;uniqueIndex:idx_workflow_statuses_category_key" binding:"required,min=1,max=50"`
+	Name       string    `json:"name" gorm:"not null;size:100" binding:"required,min=1,max=100"`
+	Position   int       `json:"position" gorm:"not null;default:0" binding:"min=0"`
+	IsDone     bool      `json:"is_done" gorm:"not null;default:false"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Relationship: Status optionally belongs to a category
+	Category *Category `json:"-" gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for WorkflowStatus model
+func (WorkflowStatus) TableName() string {
+	return "workflow_statuses"
+}
+
+// StatusTransition represents an allowed move between two workflow statuses
+// When a status has no outgoing transitions, todos may move to any status
+type StatusTransition struct {
+	ID           uint      `json:"id" gorm:"primarykey"`
+	FromStatusID uint      `json:"from_status_id" gorm:"not null;uniqueIndex:idx_status_transitions_edge" binding:"required,min=1"`
+	ToStatusID   uint      `json:"to_status_id" gorm:"not null;index;uniqueIndex:idx_status_transitions_edge" binding:"required,min=1"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Relationships: both ends of the transition are statuses
+	FromStatus *WorkflowStatus `json:"-" gorm:"foreignKey:FromStatusID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ToStatus   *WorkflowStatus `json:"-" gorm:"foreignKey:ToStatusID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for StatusTransition model
+func (StatusTransition) TableName() string {
+	return "status_transitions"
+}
+
+// DefaultWorkflowStatuses returns the statuses seeded into the default workflow
+func DefaultWorkflowStatuses() []WorkflowStatus {
+	return []WorkflowStatus{
+		{Key: "todo", Name: "To do", Position: 0},
+		{Key: "in_progress", Name: "In progress", Position: 1},
+		{Key: "in_review", Name: "In review", Position: 2},
+		{Key: "done", Name: "Done", Position: 3, IsDone: true},
+	}
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"todo-backend/internal/models"
+)
+
+// attachmentRepository implements AttachmentRepository interface
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAttachmentRepository creates a new attachment repository
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the repository running its queries with ctx
+func (r *attachmentRepository) WithContext(ctx context.Context) AttachmentRepository {
+	return &attachmentRepository{db: r.db.WithContext(ctx)}
+}
+
+// blobLockClass namespaces the advisory locks taken on stored blobs, the storage key is the second half
+const blobLockClass = 7_402_612
+
+// Create creates a new attachment record
+// storeBlob is called first with the blob's lock held, so a blob cannot be released while it is referenced again
+func (r *attachmentRepository) Create(attachment *models.Attachment, storeBlob func() error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Validate todo exists
+		var todo models.Todo
+		if err := tx.First(&todo, attachment.TodoID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("todo not found")
+			}
+			return err
+		}
+
+		if err := lockBlob(tx, attachment.StorageKey); err != nil {
+			return err
+		}
+		if err := storeBlob(); err != nil {
+			return err
+		}
+		if err := tx.Create(attachment).Error; err != nil {
+			return err
+		}
+		return recordAuditChange(tx, models.EntityTodo, attachment.TodoID, models.AuditActionAttachmentCreated, nil, attachment)
+	})
+}
+
+// GetByID retrieves an attachment of a todo by its ID
+func (r *attachmentRepository) GetByID(todoID, id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.db.Where("todo_id = ?", todoID).First(&attachment, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("attachment not found")
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// ListByTodo retrieves all attachments of a todo
+func (r *attachmentRepository) ListByTodo(todoID uint) ([]models.Attachment, error) {
+	// Validate todo exists
+	var todo models.Todo
+	if err := r.db.First(&todo, todoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, err
+	}
+
+	var attachments []models.Attachment
+	err := r.db.Where("todo_id = ?", todoID).Order("created_at ASC").Find(&attachments).Error
+	return attachments, err
+}
+
+// Delete removes an attachment record and returns its storage key
+func (r *attachmentRepository) Delete(todoID, id uint) (string, error) {
+	attachment, err := r.GetByID(todoID, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(attachment).Error; err != nil {
+			return err
+		}
+		return recordAuditChange(tx, models.EntityTodo, todoID, models.AuditActionAttachmentDeleted, attachment, nil)
+	})
+	if err != nil {
+		return "", err
+	}
+	return attachment.StorageKey, nil
+}
+
+// ReleaseBlob calls deleteBlob when no attachment record references a stored blob anymore
+// The count and the deletion happen under the blob's lock, so an upload of the same content waits for them
+func (r *attachmentRepository) ReleaseBlob(storageKey string, deleteBlob func() error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockBlob(tx, storageKey); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.Attachment{}).Where("storage_key = ?", storageKey).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		return deleteBlob()
+	})
+}
+
+// lockBlob takes the transaction-level advisory lock of a stored blob
+func lockBlob(tx *gorm.DB, storageKey string) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))", blobLockClass, storageKey).Error
+}
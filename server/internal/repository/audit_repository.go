@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"gorm.io/gorm"
+	"todo-backend/internal/models"
+)
+
+// auditBatchSize is the number of audit events loaded at a time when exporting
+const auditBatchSize = 500
+
+// AuditInfo describes who made the changes of a request or background job
+type AuditInfo struct {
+	Actor     string
+	IP        string
+	RequestID string
+	Method    string
+	Route     string
+}
+
+// auditInfoKey is the context key of the audit info
+type auditInfoKey struct{}
+
+// WithAuditInfo returns a context carrying the audit info of a request or job
+// Repositories given the context through WithContext record it with every change
+func WithAuditInfo(ctx context.Context, info AuditInfo) context.Context {
+	return context.WithValue(ctx, auditInfoKey{}, info)
+}
+
+// AuditInfoFrom returns the audit info of a context as given, and whether it has one
+func AuditInfoFrom(ctx context.Context) (AuditInfo, bool) {
+	if ctx == nil {
+		return AuditInfo{}, false
+	}
+	info, ok := ctx.Value(auditInfoKey{}).(AuditInfo)
+	return info, ok
+}
+
+// auditInfoFrom returns the audit info of a context, changes made without one are made by the system
+func auditInfoFrom(ctx context.Context) AuditInfo {
+	if ctx != nil {
+		if info, ok := ctx.Value(auditInfoKey{}).(AuditInfo); ok {
+			if info.Actor == "" {
+				info.Actor = models.AuditActorAnonymous
+			}
+			return info
+		}
+	}
+	return AuditInfo{Actor: models.AuditActorSystem}
+}
+
+// recordAudit appends audit events in the transaction of the change they describe,
+// so an event is stored if and only if the change is committed
+func recordAudit(tx *gorm.DB, events ...models.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	info := auditInfoFrom(tx.Statement.Context)
+	for i := range events {
+		events[i].ID = 0
+		events[i].Actor = info.Actor
+		events[i].IP = info.IP
+		events[i].RequestID = info.RequestID
+		events[i].Method = info.Method
+		events[i].Route = info.Route
+	}
+	return tx.Create(&events).Error
+}
+
+// recordAuditChange records a single change in the audit log, see auditEvent
+func recordAuditChange(tx *gorm.DB, entityType string, entityID uint, action string, before, after interface{}) error {
+	event, err := auditEvent(entityType, entityID, action, before, after)
+	if err != nil {
+		return err
+	}
+	return recordAudit(tx, event)
+}
+
+// auditEvent builds the audit event of a change, before or after is nil when the entity did not exist
+func auditEvent(entityType string, entityID uint, action string, before, after interface{}) (models.AuditEvent, error) {
+	event := models.AuditEvent{
+		EntityType: entityType,
+		EntityID:   &entityID,
+		Action:     action,
+	}
+	var err error
+	if event.Before, err = auditState(before); err != nil {
+		return event, err
+	}
+	if event.After, err = auditState(after); err != nil {
+		return event, err
+	}
+	return event, nil
+}
+
+// auditState returns the JSON state of an entity, or nil for a nil value or nil pointer
+func auditState(value interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(value)
+	if err != nil || string(data) == "null" {
+		return nil, err
+	}
+	return data, nil
+}
+
+// auditRepository implements AuditRepository interface
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+// List retrieves audit events with pagination and filtering, newest first
+func (r *auditRepository) List(filters AuditFilters, pagination PaginationParams) ([]models.AuditEvent, PaginationResult, error) {
+	var events []models.AuditEvent
+	var total int64
+
+	query := r.filter(r.db.Model(&models.AuditEvent{}), filters)
+
+	// Count total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, PaginationResult{}, err
+	}
+
+	// Apply pagination
+	offset := pagination.GetOffset()
+	limit := pagination.GetLimit()
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&events).Error; err != nil {
+		return nil, PaginationResult{}, err
+	}
+
+	// Calculate pagination result
+	paginationResult := PaginationResult{
+		CurrentPage: pagination.Page,
+		PerPage:     limit,
+		Total:       total,
+	}
+	paginationResult.CalculateTotalPages()
+
+	return events, paginationResult, nil
+}
+
+// Each calls fn for every audit event matching the filters, oldest first, loading events in batches
+func (r *auditRepository) Each(filters AuditFilters, fn func(event *models.AuditEvent) error) error {
+	var batch []models.AuditEvent
+	query := r.filter(r.db.Model(&models.AuditEvent{}), filters).Order("id ASC")
+	return query.FindInBatches(&batch, auditBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// filter applies audit filters to a query
+func (r *auditRepository) filter(query *gorm.DB, filters AuditFilters) *gorm.DB {
+	if filters.Actor != "" {
+		query = query.Where("actor = ?", filters.Actor)
+	}
+	if filters.EntityType != "" {
+		query = query.Where("entity_type = ?", filters.EntityType)
+	}
+	if filters.EntityID != nil {
+		query = query.Where("entity_id = ?", *filters.EntityID)
+	}
+	if filters.Action != "" {
+		query = query.Where("action = ?", filters.Action)
+	}
+	if filters.RequestID != "" {
+		query = query.Where("request_id = ?", filters.RequestID)
+	}
+	if filters.From != nil {
+		query = query.Where("created_at >= ?", *filters.From)
+	}
+	if filters.To != nil {
+		query = query.Where("created_at < ?", *filters.To)
+	}
+	return query
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"todo-backend/internal/models"
+)
+
+// caldavRepository implements CalDAVRepository interface
+type caldavRepository struct {
+	db *gorm.DB
+}
+
+// NewCalDAVRepository creates a new CalDAV repository
+func NewCalDAVRepository(db *gorm.DB) CalDAVRepository {
+	return &caldavRepository{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the repository running its queries with ctx
+func (r *caldavRepository) WithContext(ctx context.Context) CalDAVRepository {
+	return &caldavRepository{db: r.db.WithContext(ctx)}
+}
+
+// Todos retrieves the todos of a category that are not archived, in manual order
+// A nil category selects the todos without a category
+func (r *caldavRepository) Todos(categoryID *uint) ([]models.Todo, error) {
+	query := r.db.Where("archived_at IS NULL")
+	if categoryID != nil {
+		query = query.Where("category_id = ?", *categoryID)
+	} else {
+		query = query.Where("category_id IS NULL")
+	}
+
+	var todos []models.Todo
+	err := query.Order("position ASC, id ASC").Find(&todos).Error
+	return todos, err
+}
+
+// Resources retrieves the client-chosen resources of the given todos, keyed by todo ID
+func (r *caldavRepository) Resources(todoIDs []uint) (map[uint]models.CalDAVResource, error) {
+	resources := make(map[uint]models.CalDAVResource, len(todoIDs))
+	if len(todoIDs) == 0 {
+		return resources, nil
+	}
+
+	var rows []models.CalDAVResource
+	if err := r.db.Where("todo_id IN ?", todoIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		resources[row.TodoID] = row
+	}
+	return resources, nil
+}
+
+// ResourceByName retrieves a client-chosen resource by its name
+func (r *caldavRepository) ResourceByName(name string) (*models.CalDAVResource, error) {
+	var resource models.CalDAVResource
+	err := r.db.Where("name = ?", name).First(&resource).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("calendar resource not found")
+		}
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// CreateTodo creates a todo and stores its client-chosen resource in a single transaction,
+// so a failed resource never leaves a todo behind that a retried request would create again
+// The resource takes the name over from a deleted todo that had it
+func (r *caldavRepository) CreateTodo(todo *models.Todo, resource *models.CalDAVResource) error {
+	return writeTransaction(r.db, func(tx *gorm.DB) error {
+		if err := createTodo(tx, todo); err != nil {
+			return err
+		}
+		resource.TodoID = todo.ID
+		return saveResource(tx, resource)
+	})
+}
+
+// saveResource stores the resource of a todo, taking the name over from a deleted todo that had it
+func saveResource(tx *gorm.DB, resource *models.CalDAVResource) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"todo_id", "uid"}),
+	}).Create(resource).Error
+}
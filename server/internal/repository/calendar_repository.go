@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"todo-backend/internal/models"
+)
+
+// calendarRepository implements CalendarRepository interface
+type calendarRepository struct {
+	db *gorm.DB
+}
+
+// NewCalendarRepository creates a new calendar repository
+func NewCalendarRepository(db *gorm.DB) CalendarRepository {
+	return &calendarRepository{
+		db: db,
+	}
+}
+
+// CreateFeed creates a new calendar feed
+func (r *calendarRepository) CreateFeed(feed *models.CalendarFeed) error {
+	return r.db.Create(feed).Error
+}
+
+// GetFeed retrieves a calendar feed by its ID
+func (r *calendarRepository) GetFeed(id uint) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.db.First(&feed, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("calendar feed not found")
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// GetFeedByTokenHash retrieves a calendar feed by the hash of its token
+func (r *calendarRepository) GetFeedByTokenHash(tokenHash string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.db.Where("token_hash = ?", tokenHash).First(&feed).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("calendar feed not found")
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// ListFeeds retrieves all calendar feeds ordered by ID
+func (r *calendarRepository) ListFeeds() ([]models.CalendarFeed, error) {
+	var feeds []models.CalendarFeed
+	err := r.db.Order("id ASC").Find(&feeds).Error
+	return feeds, err
+}
+
+// UpdateFeed updates the settings of an existing calendar feed, keeping its token
+func (r *calendarRepository) UpdateFeed(feed *models.CalendarFeed) error {
+	result := r.db.Model(feed).Select("name", "category_ids", "time_zone", "include_events", "hide_completed").Updates(feed)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("calendar feed not found")
+	}
+	return nil
+}
+
+// DeleteFeed deletes a calendar feed, its URL stops working
+func (r *calendarRepository) DeleteFeed(id uint) error {
+	result := r.db.Delete(&models.CalendarFeed{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("calendar feed not found")
+	}
+	return nil
+}
+
+// FeedTodos retrieves the todos of a feed: those with a due date that are not archived, ordered by due date
+// An empty list of categories selects todos of every category and without one
+func (r *calendarRepository) FeedTodos(categoryIDs []uint, hideCompleted bool) ([]models.Todo, error) {
+	query := r.db.Preload("Category").
+		Where("due_date IS NOT NULL").
+		Where("archived_at IS NULL")
+	if len(categoryIDs) > 0 {
+		query = query.Where("category_id IN ?", categoryIDs)
+	}
+	if hideCompleted {
+		query = query.Where("completed = ?", false)
+	}
+
+	var todos []models.Todo
+	err := query.Order("due_date ASC, id ASC").Find(&todos).Error
+	return todos, err
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"strings"
 
@@ -20,9 +21,20 @@ func NewCategoryRepository(db *gorm.DB) CategoryRepository {
 	}
 }
 
+// WithContext returns a copy of the repository running its queries with ctx
+func (r *categoryRepository) WithContext(ctx context.Context) CategoryRepository {
+	return &categoryRepository{db: r.db.WithContext(ctx)}
+}
+
 // Create creates a new category
 func (r *categoryRepository) Create(category *models.Category) error {
-	if err := r.db.Create(category).Error; err != nil {
+	err := writeTransaction(r.db, func(tx *gorm.DB) error {
+		if err := tx.Create(category).Error; err != nil {
+			return err
+		}
+		return publishCategoryChange(tx, models.EventCategoryCreated, nil, category)
+	})
+	if err != nil {
 		// Handle unique constraint violation
 		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "UNIQUE constraint") {
 			return errors.New("category name already exists")
@@ -56,8 +68,66 @@ func (r *categoryRepository) Update(category *models.Category) error {
 		return err
 	}
 
-	// Update the category
-	if err := r.db.Save(category).Error; err != nil {
+	// Update the category only if nobody changed it since the expected version was read
+	expected := category.Version
+	if expected == 0 {
+		expected = existingCategory.Version
+	}
+	category.Version = expected + 1
+
+	err := writeTransaction(r.db, func(tx *gorm.DB) error {
+		result := tx.Select("*").Where("version = ?", expected).Save(category)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("category was modified by another request")
+		}
+		return publishCategoryChange(tx, models.EventCategoryUpdated, &existingCategory, category)
+	})
+	if err != nil {
+		category.Version = expected
+		// Handle unique constraint violation
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "UNIQUE constraint") {
+			return errors.New("category name already exists")
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateFields updates only the given columns of a category if it still has the expected version
+func (r *categoryRepository) UpdateFields(id uint, version uint, columns map[string]interface{}) error {
+	// Check if category exists
+	var category models.Category
+	if err := r.db.First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("category not found")
+		}
+		return err
+	}
+	columns["version"] = nextVersion
+
+	err := writeTransaction(r.db, func(tx *gorm.DB) error {
+		query := tx.Model(&models.Category{}).Where("id = ?", id)
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+		result := query.Updates(columns)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("category was modified by another request")
+		}
+
+		before := category
+		if err := tx.First(&category, id).Error; err != nil {
+			return err
+		}
+		return publishCategoryChange(tx, models.EventCategoryUpdated, &before, &category)
+	})
+	if err != nil {
 		// Handle unique constraint violation
 		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "UNIQUE constraint") {
 			return errors.New("category name already exists")
@@ -68,7 +138,7 @@ func (r *categoryRepository) Update(category *models.Category) error {
 }
 
 // Delete soft deletes a category by ID
-func (r *categoryRepository) Delete(id uint) error {
+func (r *categoryRepository) Delete(id uint, version uint) error {
 	// Check if category exists
 	var category models.Category
 	if err := r.db.First(&category, id).Error; err != nil {
@@ -85,8 +155,21 @@ func (r *categoryRepository) Delete(id uint) error {
 		return errors.New("cannot delete category with associated todos")
 	}
 
-	// Soft delete the category
-	return r.db.Delete(&category).Error
+	// Soft delete the category, only if it still has the expected version
+	return writeTransaction(r.db, func(tx *gorm.DB) error {
+		query := tx
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+		result := query.Delete(&category)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("category was modified by another request")
+		}
+		return publishCategoryChange(tx, models.EventCategoryDeleted, &category, &category)
+	})
 }
 
 // List retrieves categories with pagination and filtering
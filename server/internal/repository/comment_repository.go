@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"todo-backend/internal/models"
+)
+
+// commentRepository implements CommentRepository interface
+type commentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository creates a new comment repository
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the repository running its queries with ctx
+func (r *commentRepository) WithContext(ctx context.Context) CommentRepository {
+	return &commentRepository{db: r.db.WithContext(ctx)}
+}
+
+// Create creates a new comment together with its mentions
+func (r *commentRepository) Create(comment *models.Comment) error {
+	// Validate todo exists
+	var todo models.Todo
+	if err := r.db.First(&todo, comment.TodoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("todo not found")
+		}
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		return recordAuditChange(tx, models.EntityTodo, comment.TodoID, models.AuditActionCommentCreated, nil, comment)
+	})
+}
+
+// GetByID retrieves a comment of a todo by its ID
+func (r *commentRepository) GetByID(todoID, id uint) (*models.Comment, error) {
+	var comment models.Comment
+	err := r.db.
+		Preload("Mentions").
+		Preload("Revisions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Where("todo_id = ?", todoID).
+		First(&comment, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("comment not found")
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// Update stores the new body and mentions, keeping the previous body as a revision
+func (r *commentRepository) Update(comment *models.Comment, previousBody string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var before models.Comment
+		if err := tx.Preload("Mentions").First(&before, comment.ID).Error; err != nil {
+			return err
+		}
+
+		// Keep the previous body in the edit history
+		revision := models.CommentRevision{
+			CommentID: comment.ID,
+			Body:      previousBody,
+		}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+
+		// Update the comment body
+		now := time.Now().UTC()
+		comment.EditedAt = &now
+		if err := tx.Model(comment).Updates(map[string]interface{}{
+			"body":      comment.Body,
+			"edited_at": comment.EditedAt,
+		}).Error; err != nil {
+			return err
+		}
+
+		// Replace the recorded mentions
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.CommentMention{}).Error; err != nil {
+			return err
+		}
+		for i := range comment.Mentions {
+			comment.Mentions[i].ID = 0
+			comment.Mentions[i].CommentID = comment.ID
+		}
+		if len(comment.Mentions) > 0 {
+			if err := tx.Create(&comment.Mentions).Error; err != nil {
+				return err
+			}
+		}
+
+		return recordAuditChange(tx, models.EntityTodo, comment.TodoID, models.AuditActionCommentUpdated, &before, comment)
+	})
+}
+
+// Delete soft deletes a comment by ID
+func (r *commentRepository) Delete(todoID, id uint) error {
+	// Check if comment exists
+	var comment models.Comment
+	if err := r.db.Where("todo_id = ?", todoID).First(&comment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("comment not found")
+		}
+		return err
+	}
+
+	// Soft delete the comment
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&comment).Error; err != nil {
+			return err
+		}
+		return recordAuditChange(tx, models.EntityTodo, todoID, models.AuditActionCommentDeleted, &comment, nil)
+	})
+}
+
+// ListByTodo retrieves comments of a todo with pagination
+func (r *commentRepository) ListByTodo(todoID uint, pagination PaginationParams) ([]models.Comment, PaginationResult, error) {
+	var comments []models.Comment
+	var total int64
+
+	// Validate todo exists
+	var todo models.Todo
+	if err := r.db.First(&todo, todoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, PaginationResult{}, errors.New("todo not found")
+		}
+		return nil, PaginationResult{}, err
+	}
+
+	// Build the base query with mentions preload
+	query := r.db.Model(&models.Comment{}).Preload("Mentions").Where("todo_id = ?", todoID)
+
+	// Count total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, PaginationResult{}, err
+	}
+
+	// Apply sorting (discussions read oldest first by default)
+	sortBy := "created_at"
+	if pagination.SortBy != "" {
+		// Validate sort field
+		validSortFields := map[string]bool{
+			"created_at": true,
+			"updated_at": true,
+		}
+		if validSortFields[pagination.SortBy] {
+			sortBy = pagination.SortBy
+		}
+	}
+	sortOrder := "asc"
+	if pagination.SortOrder != "" {
+		sortOrder = pagination.SortOrder
+	}
+	query = query.Order(sortBy + " " + sortOrder)
+
+	// Apply pagination
+	offset := pagination.GetOffset()
+	limit := pagination.GetLimit()
+	if err := query.Offset(offset).Limit(limit).Find(&comments).Error; err != nil {
+		return nil, PaginationResult{}, err
+	}
+
+	// Calculate pagination result
+	paginationResult := PaginationResult{
+		CurrentPage: pagination.Page,
+		PerPage:     limit,
+		Total:       total,
+	}
+	paginationResult.CalculateTotalPages()
+
+	return comments, paginationResult, nil
+}
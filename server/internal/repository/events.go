@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"todo-backend/internal/models"
+)
+
+// maxNotifyPayload keeps NOTIFY payloads below the 8000 byte limit of Postgres
+const maxNotifyPayload = 7900
+
+// publishEvents appends the changed entities to the sync sequence, queues webhook deliveries for the events
+// and notifies the event stream of every API instance
+// It runs inside the transaction of the change: changes are recorded, deliveries stored and
+// notifications sent if and only if the change is committed, in commit order
+func publishEvents(tx *gorm.DB, events ...models.ChangeEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	for _, event := range events {
+		if err := recordSyncChanges(tx, event.EntityType, event.EntityID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	payloads := make([][]byte, len(events))
+	for i := range events {
+		events[i].ID = newEventID()
+		events[i].CreatedAt = now
+		payload, err := json.Marshal(events[i])
+		if err != nil {
+			return err
+		}
+		payloads[i] = payload
+	}
+
+	if err := enqueueWebhookDeliveries(tx, events, payloads); err != nil {
+		return err
+	}
+
+	for i, payload := range payloads {
+		// Large events are announced without their data, clients reload the entity instead
+		if len(payload) > maxNotifyPayload {
+			stub := events[i]
+			stub.Data = nil
+			stub.Changes = nil
+			stub.Truncated = true
+			var err error
+			if payload, err = json.Marshal(stub); err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("SELECT pg_notify(?, ?)", models.ChangeEventsChannel, string(payload)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// todoEvent builds the change event of a todo
+func todoEvent(eventType string, todo *models.Todo, changes []models.FieldChange) models.ChangeEvent {
+	return models.ChangeEvent{
+		Type:       eventType,
+		EntityType: models.EntityTodo,
+		EntityID:   todo.ID,
+		CategoryID: todo.CategoryID,
+		Data:       todo,
+		Changes:    changes,
+	}
+}
+
+// categoryEvent builds the change event of a category
+func categoryEvent(eventType string, category *models.Category) models.ChangeEvent {
+	id := category.ID
+	return models.ChangeEvent{
+		Type:       eventType,
+		EntityType: models.EntityCategory,
+		EntityID:   category.ID,
+		CategoryID: &id,
+		Data:       category,
+	}
+}
+
+// publishCategoryChange publishes the change event of a category and records it in the audit log
+// before is nil for created and restored categories, deleted categories have no after state in the audit log
+func publishCategoryChange(tx *gorm.DB, eventType string, before, category *models.Category) error {
+	var after interface{} = category
+	if eventType == models.EventCategoryDeleted {
+		after = nil
+	}
+	if err := recordAuditChange(tx, models.EntityCategory, category.ID, eventType, before, after); err != nil {
+		return err
+	}
+	return publishEvents(tx, categoryEvent(eventType, category))
+}
+
+// newEventID generates a random change event ID
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeRows is the result of a query run against a fakeDB
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+// fakeStatement is a statement received by a fakeDB, BEGIN, COMMIT and ROLLBACK included
+type fakeStatement struct {
+	query string
+	args  []any
+}
+
+// fakeDB records the statements it receives and answers queries with rows from its handler
+// It stands in for Postgres, which the repository tests can't rely on
+type fakeDB struct {
+	mu         sync.Mutex
+	statements []fakeStatement
+	query      func(query string, args []any) fakeRows
+}
+
+var (
+	fakeDriverOnce sync.Once
+	fakeDBs        sync.Map
+)
+
+// newFakeDB returns a gorm connection to a new fakeDB answering queries with query,
+// which may be nil to return no rows
+func newFakeDB(t *testing.T, query func(query string, args []any) fakeRows) (*gorm.DB, *fakeDB) {
+	t.Helper()
+	fakeDriverOnce.Do(func() { sql.Register("repositorytest", fakeDriver{}) })
+
+	fake := &fakeDB{query: query}
+	fakeDBs.Store(t.Name(), fake)
+	t.Cleanup(func() { fakeDBs.Delete(t.Name()) })
+
+	conn, err := sql.Open("repositorytest", t.Name())
+	if err != nil {
+		t.Fatalf("open fake database: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+	return db, fake
+}
+
+// Statements returns the statements received so far
+func (f *fakeDB) Statements() []fakeStatement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]fakeStatement(nil), f.statements...)
+}
+
+// Queries returns the SQL of the statements received so far
+func (f *fakeDB) Queries() []string {
+	statements := f.Statements()
+	queries := make([]string, len(statements))
+	for i, statement := range statements {
+		queries[i] = statement.query
+	}
+	return queries
+}
+
+// Find returns the statements whose SQL contains part
+func (f *fakeDB) Find(part string) []fakeStatement {
+	var found []fakeStatement
+	for _, statement := range f.Statements() {
+		if strings.Contains(statement.query, part) {
+			found = append(found, statement)
+		}
+	}
+	return found
+}
+
+func (f *fakeDB) record(query string, args []driver.NamedValue) []any {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	f.mu.Lock()
+	f.statements = append(f.statements, fakeStatement{query: query, args: values})
+	f.mu.Unlock()
+	return values
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fake, ok := fakeDBs.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("no fake database %q", name)
+	}
+	return &fakeConn{db: fake.(*fakeDB)}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("fake database does not prepare statements")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.db.record("BEGIN", nil)
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.db.record("COMMIT", nil)
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.db.record("ROLLBACK", nil)
+	return nil
+}
+
+func (c *fakeConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values := c.db.record(query, args)
+	var rows fakeRows
+	if c.db.query != nil {
+		rows = c.db.query(query, values)
+	}
+	return &fakeResult{rows: rows}, nil
+}
+
+type fakeResult struct {
+	rows fakeRows
+	next int
+}
+
+func (r *fakeResult) Columns() []string { return r.rows.columns }
+
+func (r *fakeResult) Close() error { return nil }
+
+func (r *fakeResult) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows.values) {
+		return io.EOF
+	}
+	copy(dest, r.rows.values[r.next])
+	r.next++
+	return nil
+}
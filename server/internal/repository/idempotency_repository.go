@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"todo-backend/internal/models"
+)
+
+// idempotencyRepository implements IdempotencyRepository interface
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency repository
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{
+		db: db,
+	}
+}
+
+// Acquire stores a new idempotency key and locks it for the request, returning nil
+// When the key is taken, the stored key is returned instead. An expired key, or a key whose
+// request was abandoned while holding the lock and that is retried with the same fingerprint, is taken over
+func (r *idempotencyRepository) Acquire(key *models.IdempotencyKey) (*models.IdempotencyKey, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	result = r.db.Model(&models.IdempotencyKey{}).
+		Where("key = ?", key.Key).
+		Where("expires_at < ? OR (completed_at IS NULL AND locked_until < ? AND fingerprint = ?)", now, now, key.Fingerprint).
+		Select("fingerprint", "status", "headers", "body", "locked_until", "completed_at", "expires_at", "created_at").
+		Updates(&models.IdempotencyKey{
+			Fingerprint: key.Fingerprint,
+			LockedUntil: key.LockedUntil,
+			ExpiresAt:   key.ExpiresAt,
+			CreatedAt:   now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var existing models.IdempotencyKey
+	if err := r.db.Where("key = ?", key.Key).First(&existing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The key expired and was purged in the meantime
+			return r.Acquire(key)
+		}
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// Complete stores the response of the request holding an idempotency key and releases its lock
+func (r *idempotencyRepository) Complete(key string, status int, headers models.Headers, body []byte) error {
+	now := time.Now()
+	return r.db.Model(&models.IdempotencyKey{}).
+		Where("key = ? AND completed_at IS NULL", key).
+		Select("status", "headers", "body", "locked_until", "completed_at").
+		Updates(&models.IdempotencyKey{
+			Status:      status,
+			Headers:     headers,
+			Body:        body,
+			CompletedAt: &now,
+		}).Error
+}
+
+// Release removes an idempotency key whose request did not complete, so it can be retried
+func (r *idempotencyRepository) Release(key string) error {
+	return r.db.Where("key = ? AND completed_at IS NULL", key).Delete(&models.IdempotencyKey{}).Error
+}
+
+// DeleteExpired removes idempotency keys whose retention has ended
+// Returns the number of keys removed
+func (r *idempotencyRepository) DeleteExpired() (int64, error) {
+	result := r.db.Where("expires_at < ?", time.Now()).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
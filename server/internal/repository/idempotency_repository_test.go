@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"todo-backend/internal/models"
+)
+
+func TestIdempotencyAcquireTakesOverAbandonedKey(t *testing.T) {
+	// The insert conflicts with a stored key and returns no row
+	db, fake := newFakeDB(t, nil)
+	lockedUntil := time.Now().Add(5 * time.Minute)
+	existing, err := NewIdempotencyRepository(db).Acquire(&models.IdempotencyKey{
+		Key:         "key-1",
+		Fingerprint: "abc",
+		LockedUntil: &lockedUntil,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if existing != nil {
+		t.Fatalf("Acquire = %+v; want the key taken over", existing)
+	}
+
+	// Only an expired key, or an unfinished one whose lock timed out and that has the same fingerprint, is taken over
+	updates := fake.Find(`UPDATE "idempotency_keys"`)
+	if len(updates) != 1 {
+		t.Fatalf("updates = %d; want 1 in %q", len(updates), fake.Queries())
+	}
+	for _, condition := range []string{"expires_at < $", "completed_at IS NULL AND locked_until < $", "fingerprint = $"} {
+		if !strings.Contains(updates[0].query, condition) {
+			t.Errorf("update %q does not check %s", updates[0].query, condition)
+		}
+	}
+	if !containsArg(updates[0].args, "abc") {
+		t.Errorf("update args %v do not contain the fingerprint", updates[0].args)
+	}
+}
+
+// containsArg reports whether a statement was given an argument
+func containsArg(args []any, want any) bool {
+	for _, arg := range args {
+		if arg == want {
+			return true
+		}
+	}
+	return false
+}
@@ -1,11 +1,18 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"todo-backend/internal/models"
 )
 
 // TodoRepository defines the interface for todo data operations
 type TodoRepository interface {
+	// WithContext returns a copy of the repository running its queries with ctx,
+	// changes are recorded in the audit log with the audit info of ctx
+	WithContext(ctx context.Context) TodoRepository
+	
 	// Create creates a new todo
 	Create(todo *models.Todo) error
 	
@@ -13,20 +20,80 @@ type TodoRepository interface {
 	GetByID(id uint) (*models.Todo, error)
 	
 	// Update updates an existing todo
+	// A non-zero todo.Version must match the stored version; the new version is stored in todo.Version
 	Update(todo *models.Todo) error
 	
-	// Delete soft deletes a todo by ID
-	Delete(id uint) error
+	// Delete soft deletes a todo by ID, a non-zero version must match the stored version
+	Delete(id uint, version uint) error
 	
 	// List retrieves todos with pagination and filtering
 	List(filters TodoFilters, pagination PaginationParams) ([]models.Todo, PaginationResult, error)
+
+	// EachExportRow calls fn for every todo matching the filters, sorted like List, reading rows from a cursor
+	EachExportRow(filters TodoFilters, pagination PaginationParams, fn func(row *TodoExportRow) error) error
+
+	// Import creates categories and todos with their tags in a single transaction
+	Import(categories []*models.Category, items []ImportItem) error
 	
-	// ToggleComplete toggles the completion status of a todo
-	ToggleComplete(id uint) error
+	// SetStatus moves a todo to a workflow status and stores the derived completion flag,
+	// a non-zero version must match the stored version
+	SetStatus(id uint, version uint, statusID uint, completed bool) error
+
+	// UpdateFields updates only the given columns of a todo, a non-zero version must match the stored version
+	UpdateFields(id uint, version uint, columns map[string]interface{}) error
+
+	// MovePosition places a todo between two others in manual order, next to the closest todo when one of them is nil,
+	// and publishes a todo.moved event. The given columns are updated along with the position,
+	// a non-zero version must match the stored version
+	MovePosition(id uint, version uint, afterID, beforeID *uint, columns map[string]interface{}) error
+
+	// NeedsRebalance reports whether any todo has no position, a position longer than maxLength or a duplicate position
+	NeedsRebalance(maxLength int) (bool, error)
+
+	// RebalancePositions rewrites all positions with short, evenly spaced keys, keeping the order
+	RebalancePositions() (int, error)
+
+	// SetArchived archives or unarchives a todo, a non-zero version must match the stored version
+	SetArchived(id uint, version uint, archived bool) error
+
+	// ArchiveCompleted archives completed todos, optionally limited to a category and to todos completed before a time
+	ArchiveCompleted(categoryID *uint, completedBefore *time.Time) (int64, error)
+
+	// GetByIDs retrieves the todos with the given IDs, missing todos are skipped
+	GetByIDs(ids []uint) ([]models.Todo, error)
+
+	// ListIDs retrieves the IDs of up to limit todos matching the filters
+	ListIDs(filters TodoFilters, limit int) ([]uint, error)
+
+	// BulkApply applies the given changes in a single transaction and returns a result per item
+	// A failing item is rolled back on its own and does not affect the other items
+	BulkApply(items []BulkItem) ([]BulkItemResult, error)
+
+	// FindOrCreateTag retrieves a tag by name, creating it when it does not exist yet
+	FindOrCreateTag(name string) (*models.Tag, error)
+
+	// AddDependency records that a todo is blocked by another todo
+	AddDependency(todoID, blockerID uint) error
+
+	// RemoveDependency removes a blocker from a todo
+	RemoveDependency(todoID, blockerID uint) error
+
+	// GetBlockerIDs returns the blocker IDs of each of the given todos, including blockers in the trash
+	GetBlockerIDs(todoIDs []uint) (map[uint][]uint, error)
+
+	// ListRevisions retrieves the change history of a todo, newest first
+	ListRevisions(todoID uint) ([]models.TodoRevision, error)
+
+	// GetRevision retrieves the revision of a todo at a version
+	GetRevision(todoID, version uint) (*models.TodoRevision, error)
 }
 
 // CategoryRepository defines the interface for category data operations
 type CategoryRepository interface {
+	// WithContext returns a copy of the repository running its queries with ctx,
+	// changes are recorded in the audit log with the audit info of ctx
+	WithContext(ctx context.Context) CategoryRepository
+	
 	// Create creates a new category
 	Create(category *models.Category) error
 	
@@ -34,14 +101,274 @@ type CategoryRepository interface {
 	GetByID(id uint) (*models.Category, error)
 	
 	// Update updates an existing category
+	// A non-zero category.Version must match the stored version; the new version is stored in category.Version
 	Update(category *models.Category) error
+
+	// UpdateFields updates only the given columns of a category, a non-zero version must match the stored version
+	UpdateFields(id uint, version uint, columns map[string]interface{}) error
 	
-	// Delete soft deletes a category by ID
-	Delete(id uint) error
+	// Delete soft deletes a category by ID, a non-zero version must match the stored version
+	Delete(id uint, version uint) error
 	
 	// List retrieves categories with pagination and filtering
 	List(filters CategoryFilters, pagination PaginationParams) ([]models.Category, PaginationResult, error)
 	
 	// GetAll retrieves all categories without pagination (for dropdowns)
 	GetAll() ([]models.Category, error)
-}
\ No newline at end of file
+}
+
+// CommentRepository defines the interface for comment data operations
+type CommentRepository interface {
+	// WithContext returns a copy of the repository running its queries with ctx,
+	// changes are recorded in the audit log with the audit info of ctx
+	WithContext(ctx context.Context) CommentRepository
+	
+	// Create creates a new comment together with its mentions
+	Create(comment *models.Comment) error
+
+	// GetByID retrieves a comment of a todo by its ID
+	GetByID(todoID, id uint) (*models.Comment, error)
+
+	// Update stores the new body and mentions, keeping the previous body as a revision
+	Update(comment *models.Comment, previousBody string) error
+
+	// Delete soft deletes a comment by ID
+	Delete(todoID, id uint) error
+
+	// ListByTodo retrieves comments of a todo with pagination
+	ListByTodo(todoID uint, pagination PaginationParams) ([]models.Comment, PaginationResult, error)
+}
+
+// AttachmentRepository defines the interface for attachment data operations
+type AttachmentRepository interface {
+	// WithContext returns a copy of the repository running its queries with ctx,
+	// changes are recorded in the audit log with the audit info of ctx
+	WithContext(ctx context.Context) AttachmentRepository
+	
+	// Create creates a new attachment record, storing its blob first with the blob locked
+	Create(attachment *models.Attachment, storeBlob func() error) error
+
+	// GetByID retrieves an attachment of a todo by its ID
+	GetByID(todoID, id uint) (*models.Attachment, error)
+
+	// ListByTodo retrieves all attachments of a todo
+	ListByTodo(todoID uint) ([]models.Attachment, error)
+
+	// Delete removes an attachment record and returns its storage key
+	Delete(todoID, id uint) (string, error)
+
+	// ReleaseBlob deletes a stored blob through deleteBlob once no attachment record references it
+	ReleaseBlob(storageKey string, deleteBlob func() error) error
+}
+
+// WorkflowRepository defines the interface for workflow status data operations
+type WorkflowRepository interface {
+	// WithContext returns a copy of the repository running its queries with ctx,
+	// changes are recorded in the audit log with the audit info of ctx
+	WithContext(ctx context.Context) WorkflowRepository
+	
+	// ListStatuses retrieves the statuses of a category workflow, or of the default workflow when categoryID is nil
+	ListStatuses(categoryID *uint) ([]models.WorkflowStatus, error)
+
+	// GetStatusByID retrieves a workflow status by its ID
+	GetStatusByID(id uint) (*models.WorkflowStatus, error)
+
+	// CreateStatuses creates several workflow statuses in one transaction
+	CreateStatuses(statuses []models.WorkflowStatus) error
+
+	// UpdateStatus updates an existing workflow status
+	UpdateStatus(status *models.WorkflowStatus) error
+
+	// DeleteStatus deletes a workflow status that is not used by any todo
+	DeleteStatus(id uint) error
+
+	// ListTransitions retrieves the transitions leaving any of the given statuses
+	ListTransitions(fromStatusIDs []uint) ([]models.StatusTransition, error)
+
+	// CreateTransition creates an allowed transition between two statuses
+	CreateTransition(transition *models.StatusTransition) error
+
+	// DeleteTransition deletes a transition by ID
+	DeleteTransition(id uint) error
+
+	// BackfillTodoStatuses assigns a status to todos that do not have one yet
+	BackfillTodoStatuses(openStatusID, doneStatusID uint) error
+}
+
+// TrashRepository defines the interface for soft-deleted todo and category operations
+type TrashRepository interface {
+	// WithContext returns a copy of the repository running its queries with ctx,
+	// changes are recorded in the audit log with the audit info of ctx
+	WithContext(ctx context.Context) TrashRepository
+	
+	// ListTodos retrieves soft-deleted todos with pagination, most recently deleted first
+	ListTodos(pagination PaginationParams) ([]models.Todo, PaginationResult, error)
+
+	// ListCategories retrieves soft-deleted categories with pagination, most recently deleted first
+	ListCategories(pagination PaginationParams) ([]models.Category, PaginationResult, error)
+
+	// GetTodo retrieves a soft-deleted todo by its ID
+	GetTodo(id uint) (*models.Todo, error)
+
+	// GetCategory retrieves a soft-deleted category by its ID
+	GetCategory(id uint) (*models.Category, error)
+
+	// RestoreTodo restores a soft-deleted todo together with its deleted category
+	RestoreTodo(id uint) error
+
+	// RestoreCategory restores a soft-deleted category
+	RestoreCategory(id uint) error
+
+	// PurgeTodo permanently deletes a soft-deleted todo with its attachment records
+	// and returns the storage keys of the attachments, whose blobs are released by the caller
+	PurgeTodo(id uint) ([]string, error)
+
+	// PurgeCategory permanently deletes a soft-deleted category
+	PurgeCategory(id uint) error
+
+	// ListExpiredTodoIDs returns the IDs of todos deleted before the given time
+	ListExpiredTodoIDs(deletedBefore time.Time) ([]uint, error)
+
+	// PurgeExpiredCategories permanently deletes categories deleted before the given time
+	PurgeExpiredCategories(deletedBefore time.Time) (int64, error)
+}
+
+// AuditRepository defines the interface for reading the append-only audit log
+// Events are appended by the other repositories in the transaction of each change
+type AuditRepository interface {
+	// List retrieves audit events with pagination and filtering, newest first
+	List(filters AuditFilters, pagination PaginationParams) ([]models.AuditEvent, PaginationResult, error)
+
+	// Each calls fn for every audit event matching the filters, oldest first, loading events in batches
+	Each(filters AuditFilters, fn func(event *models.AuditEvent) error) error
+}
+
+// WebhookRepository defines the interface for webhook subscriptions and their delivery queue
+type WebhookRepository interface {
+	// CreateSubscription creates a new webhook subscription
+	CreateSubscription(subscription *models.WebhookSubscription) error
+
+	// GetSubscription retrieves a webhook subscription by its ID
+	GetSubscription(id uint) (*models.WebhookSubscription, error)
+
+	// ListSubscriptions retrieves all webhook subscriptions
+	ListSubscriptions() ([]models.WebhookSubscription, error)
+
+	// UpdateSubscription updates an existing webhook subscription
+	UpdateSubscription(subscription *models.WebhookSubscription) error
+
+	// DeleteSubscription deletes a webhook subscription together with its deliveries
+	DeleteSubscription(id uint) error
+
+	// ListDeliveries retrieves the deliveries of a subscription with pagination, newest first
+	ListDeliveries(subscriptionID uint, pagination PaginationParams) ([]models.WebhookDelivery, PaginationResult, error)
+
+	// GetDelivery retrieves a delivery of a subscription by its ID
+	GetDelivery(subscriptionID, id uint) (*models.WebhookDelivery, error)
+
+	// CreateDelivery queues a delivery
+	CreateDelivery(delivery *models.WebhookDelivery) error
+
+	// ClaimDueDeliveries reserves up to limit due deliveries for the lease duration, with their subscriptions loaded
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+
+	// SaveDeliveryResult stores the outcome of a delivery attempt
+	SaveDeliveryResult(delivery *models.WebhookDelivery) error
+}
+
+// SyncRepository defines the interface for reading the change sequence of todos and categories
+type SyncRepository interface {
+	// WithContext returns a copy of the repository running its queries with ctx,
+	// changes are recorded in the audit log with the audit info of ctx
+	WithContext(ctx context.Context) SyncRepository
+	
+	// Snapshot returns all todos and categories that are not deleted, with the token of the latest change
+	Snapshot() (*SyncChangeSet, error)
+
+	// Changes returns the current state of up to limit todos and categories changed after the since token
+	Changes(since uint64, limit int) (*SyncChangeSet, error)
+
+	// LatestToken returns the sequence number of the latest change
+	LatestToken() (uint64, error)
+}
+
+// IdempotencyRepository defines the interface for storing responses to requests with an idempotency key
+type IdempotencyRepository interface {
+	// Acquire stores a new idempotency key and locks it for the request, returning nil,
+	// or returns the stored key when it is already taken
+	Acquire(key *models.IdempotencyKey) (*models.IdempotencyKey, error)
+
+	// Complete stores the response of the request holding an idempotency key and releases its lock
+	Complete(key string, status int, headers models.Headers, body []byte) error
+
+	// Release removes an idempotency key whose request did not complete
+	Release(key string) error
+
+	// DeleteExpired removes idempotency keys whose retention has ended
+	DeleteExpired() (int64, error)
+}
+
+// CalendarRepository defines the interface for calendar feeds and the todos they publish
+type CalendarRepository interface {
+	// CreateFeed creates a new calendar feed
+	CreateFeed(feed *models.CalendarFeed) error
+
+	// GetFeed retrieves a calendar feed by its ID
+	GetFeed(id uint) (*models.CalendarFeed, error)
+
+	// GetFeedByTokenHash retrieves a calendar feed by the hash of its token
+	GetFeedByTokenHash(tokenHash string) (*models.CalendarFeed, error)
+
+	// ListFeeds retrieves all calendar feeds
+	ListFeeds() ([]models.CalendarFeed, error)
+
+	// UpdateFeed updates the settings of an existing calendar feed, keeping its token
+	UpdateFeed(feed *models.CalendarFeed) error
+
+	// DeleteFeed deletes a calendar feed
+	DeleteFeed(id uint) error
+
+	// FeedTodos retrieves the todos with a due date that are not archived, optionally limited to some categories
+	FeedTodos(categoryIDs []uint, hideCompleted bool) ([]models.Todo, error)
+}
+
+// CalDAVRepository defines the interface for the todos and resource names served over CalDAV
+type CalDAVRepository interface {
+	// WithContext returns a copy of the repository running its queries with ctx,
+	// changes are recorded in the audit log with the audit info of ctx
+	WithContext(ctx context.Context) CalDAVRepository
+	
+	// Todos retrieves the todos of a category that are not archived, a nil category selects todos without one
+	Todos(categoryID *uint) ([]models.Todo, error)
+
+	// Resources retrieves the client-chosen resources of the given todos, keyed by todo ID
+	Resources(todoIDs []uint) (map[uint]models.CalDAVResource, error)
+
+	// ResourceByName retrieves a client-chosen resource by its name
+	ResourceByName(name string) (*models.CalDAVResource, error)
+
+	// CreateTodo creates a todo like TodoRepository.Create and stores its client-chosen resource in the same transaction,
+	// taking the name over from a deleted todo that had it
+	CreateTodo(todo *models.Todo, resource *models.CalDAVResource) error
+}
+
+// SavedFilterRepository defines the interface for saved filter data operations
+type SavedFilterRepository interface {
+	// Create creates a new saved filter
+	Create(filter *models.SavedFilter) error
+
+	// GetByID retrieves a saved filter by its ID
+	GetByID(id uint) (*models.SavedFilter, error)
+
+	// GetByName retrieves a saved filter by its name, ignoring case
+	GetByName(name string) (*models.SavedFilter, error)
+
+	// List retrieves all saved filters ordered by name
+	List() ([]models.SavedFilter, error)
+
+	// Update updates the name, filters and sort order of an existing saved filter
+	Update(filter *models.SavedFilter) error
+
+	// Delete deletes a saved filter
+	Delete(id uint) error
+}
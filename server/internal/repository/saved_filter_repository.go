@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"todo-backend/internal/models"
+)
+
+// savedFilterRepository implements SavedFilterRepository interface
+type savedFilterRepository struct {
+	db *gorm.DB
+}
+
+// NewSavedFilterRepository creates a new saved filter repository
+func NewSavedFilterRepository(db *gorm.DB) SavedFilterRepository {
+	return &savedFilterRepository{
+		db: db,
+	}
+}
+
+// Create creates a new saved filter
+func (r *savedFilterRepository) Create(filter *models.SavedFilter) error {
+	return r.db.Create(filter).Error
+}
+
+// GetByID retrieves a saved filter by its ID
+func (r *savedFilterRepository) GetByID(id uint) (*models.SavedFilter, error) {
+	var filter models.SavedFilter
+	err := r.db.First(&filter, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("saved filter not found")
+		}
+		return nil, err
+	}
+	return &filter, nil
+}
+
+// GetByName retrieves a saved filter by its name, ignoring case
+func (r *savedFilterRepository) GetByName(name string) (*models.SavedFilter, error) {
+	var filter models.SavedFilter
+	err := r.db.Where("LOWER(name) = LOWER(?)", name).First(&filter).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("saved filter not found")
+		}
+		return nil, err
+	}
+	return &filter, nil
+}
+
+// List retrieves all saved filters ordered by name
+func (r *savedFilterRepository) List() ([]models.SavedFilter, error) {
+	var filters []models.SavedFilter
+	err := r.db.Order("LOWER(name) ASC").Order("id ASC").Find(&filters).Error
+	return filters, err
+}
+
+// Update updates the name, filters and sort order of an existing saved filter
+func (r *savedFilterRepository) Update(filter *models.SavedFilter) error {
+	result := r.db.Model(filter).Select("name", "filters", "sort_by", "sort_order").Updates(filter)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("saved filter not found")
+	}
+	return nil
+}
+
+// Delete deletes a saved filter
+func (r *savedFilterRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.SavedFilter{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("saved filter not found")
+	}
+	return nil
+}
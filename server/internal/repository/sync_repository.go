@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+	"todo-backend/internal/models"
+)
+
+// syncLockKey is the transaction-level advisory lock that serializes writers of the change sequence
+// Every transaction that records changes takes it first, see writeTransaction, so all writes of
+// todos and categories in the app run one at a time
+const syncLockKey = 7_402_611
+
+// syncRepository implements SyncRepository interface
+type syncRepository struct {
+	db *gorm.DB
+}
+
+// NewSyncRepository creates a new sync repository
+func NewSyncRepository(db *gorm.DB) SyncRepository {
+	return &syncRepository{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the repository running its queries with ctx
+func (r *syncRepository) WithContext(ctx context.Context) SyncRepository {
+	return &syncRepository{db: r.db.WithContext(ctx)}
+}
+
+// Snapshot returns all todos and categories that are not deleted, with the token of the latest change
+func (r *syncRepository) Snapshot() (*SyncChangeSet, error) {
+	set := &SyncChangeSet{
+		Todos:              []models.Todo{},
+		Categories:         []models.Category{},
+		DeletedTodoIDs:     []uint{},
+		DeletedCategoryIDs: []uint{},
+	}
+
+	// Read the token and the data from the same snapshot of the database
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := latestSyncToken(tx, &set.Token); err != nil {
+			return err
+		}
+		if err := tx.Preload("Tags").Order("id ASC").Find(&set.Todos).Error; err != nil {
+			return err
+		}
+		return tx.Order("id ASC").Find(&set.Categories).Error
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// Changes returns the current state of up to limit todos and categories changed after the since token
+func (r *syncRepository) Changes(since uint64, limit int) (*SyncChangeSet, error) {
+	set := &SyncChangeSet{
+		Token:              since,
+		Todos:              []models.Todo{},
+		Categories:         []models.Category{},
+		DeletedTodoIDs:     []uint{},
+		DeletedCategoryIDs: []uint{},
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Every changed item once, in the order of its latest change
+		var changes []struct {
+			EntityType string
+			EntityID   uint
+			Seq        uint64
+		}
+		err := tx.Model(&models.SyncChange{}).
+			Select("entity_type, entity_id, MAX(id) AS seq").
+			Where("id > ?", since).
+			Group("entity_type, entity_id").
+			Order("seq ASC").
+			Limit(limit + 1).
+			Scan(&changes).Error
+		if err != nil {
+			return err
+		}
+		if len(changes) > limit {
+			changes = changes[:limit]
+			set.HasMore = true
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		set.Token = changes[len(changes)-1].Seq
+
+		var todoIDs, categoryIDs []uint
+		for _, change := range changes {
+			switch change.EntityType {
+			case models.EntityTodo:
+				todoIDs = append(todoIDs, change.EntityID)
+			case models.EntityCategory:
+				categoryIDs = append(categoryIDs, change.EntityID)
+			}
+		}
+
+		// Deleted items are loaded as well to report them as tombstones, purged items are missing
+		var todos []models.Todo
+		if len(todoIDs) > 0 {
+			if err := tx.Unscoped().Preload("Tags").Where("id IN ?", todoIDs).Order("id ASC").Find(&todos).Error; err != nil {
+				return err
+			}
+		}
+		found := make(map[uint]bool, len(todos))
+		for _, todo := range todos {
+			found[todo.ID] = true
+			if todo.DeletedAt.Valid {
+				set.DeletedTodoIDs = append(set.DeletedTodoIDs, todo.ID)
+			} else {
+				set.Todos = append(set.Todos, todo)
+			}
+		}
+		for _, id := range todoIDs {
+			if !found[id] {
+				set.DeletedTodoIDs = append(set.DeletedTodoIDs, id)
+			}
+		}
+
+		var categories []models.Category
+		if len(categoryIDs) > 0 {
+			if err := tx.Unscoped().Where("id IN ?", categoryIDs).Order("id ASC").Find(&categories).Error; err != nil {
+				return err
+			}
+		}
+		found = make(map[uint]bool, len(categories))
+		for _, category := range categories {
+			found[category.ID] = true
+			if category.DeletedAt.Valid {
+				set.DeletedCategoryIDs = append(set.DeletedCategoryIDs, category.ID)
+			} else {
+				set.Categories = append(set.Categories, category)
+			}
+		}
+		for _, id := range categoryIDs {
+			if !found[id] {
+				set.DeletedCategoryIDs = append(set.DeletedCategoryIDs, id)
+			}
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// LatestToken returns the sequence number of the latest change
+func (r *syncRepository) LatestToken() (uint64, error) {
+	var token uint64
+	err := latestSyncToken(r.db, &token)
+	return token, err
+}
+
+// latestSyncToken reads the sequence number of the latest change, zero when nothing changed yet
+func latestSyncToken(tx *gorm.DB, token *uint64) error {
+	return tx.Model(&models.SyncChange{}).Select("COALESCE(MAX(id), 0)").Scan(token).Error
+}
+
+// writeTransaction runs fn in a transaction that takes the sync lock before anything else
+// Writers hold the lock until they commit, so sequence numbers become visible in increasing order
+// and a reader never skips a change that commits later with a lower number. Taking it before any
+// row lock keeps the lock order the same in every writer: a writer holding a row lock while
+// waiting for the sync lock would deadlock with the holder of the sync lock waiting for that row
+func writeTransaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := lockSyncSequence(tx); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// lockSyncSequence takes the sync lock until the transaction ends, taking it again is a no-op
+func lockSyncSequence(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", syncLockKey).Error
+}
+
+// recordSyncChanges appends the given items to the change sequence
+// It must run in a writeTransaction; the lock is taken again so a missed one can't reorder the sequence
+func recordSyncChanges(tx *gorm.DB, entityType string, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := lockSyncSequence(tx); err != nil {
+		return err
+	}
+
+	changes := make([]models.SyncChange, len(ids))
+	for i, id := range ids {
+		changes[i] = models.SyncChange{EntityType: entityType, EntityID: id}
+	}
+	return tx.Create(&changes).Error
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"todo-backend/internal/models"
+)
+
+// todoExportColumns selects a todo with the names of its category, status and tags
+const todoExportColumns = `todos.id, todos.title, todos.description, todos.completed, todos.priority,
+	todos.due_date, todos.recurrence, todos.category_id, todos.position, todos.completed_at, todos.archived_at,
+	todos.created_at, todos.updated_at,
+	(SELECT c.name FROM categories c WHERE c.id = todos.category_id) AS category_name,
+	(SELECT c.color FROM categories c WHERE c.id = todos.category_id) AS category_color,
+	(SELECT s.key FROM workflow_statuses s WHERE s.id = todos.status_id) AS status,
+	(SELECT COALESCE(json_agg(t.name ORDER BY t.name), '[]')::text
+		FROM todo_tags tt JOIN tags t ON t.id = tt.tag_id WHERE tt.todo_id = todos.id) AS tags_json`
+
+// EachExportRow calls fn for every todo matching the filters in the order of the sort parameters
+// Rows are read from a database cursor one at a time, so exports of any size use constant memory
+func (r *todoRepository) EachExportRow(filters TodoFilters, pagination PaginationParams, fn func(row *TodoExportRow) error) error {
+	query := r.db.Model(&models.Todo{}).Select(todoExportColumns)
+	query = r.applyFilters(query, filters)
+	query = r.applySort(query, pagination).Order("todos.id ASC")
+
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row TodoExportRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		row.Tags = []string{}
+		if err := json.Unmarshal([]byte(row.TagsJSON), &row.Tags); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+
+	"gorm.io/gorm"
+	"todo-backend/internal/models"
+)
+
+// ListRevisions retrieves the change history of a todo, newest first
+// The history of a deleted todo is kept until the todo is purged from the trash
+func (r *todoRepository) ListRevisions(todoID uint) ([]models.TodoRevision, error) {
+	var revisions []models.TodoRevision
+	err := r.db.Where("todo_id = ?", todoID).Order("version DESC").Find(&revisions).Error
+	return revisions, err
+}
+
+// GetRevision retrieves the revision of a todo at a version
+func (r *todoRepository) GetRevision(todoID, version uint) (*models.TodoRevision, error) {
+	var revision models.TodoRevision
+	err := r.db.Where("todo_id = ? AND version = ?", todoID, version).First(&revision).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("revision not found")
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// recordRevisions stores a revision with the current state of each given todo, publishes the matching change events
+// and records them in the audit log
+// Changes are computed against the previous revision; plain updates that change the
+// completed flag are recorded as complete or reopen
+func recordRevisions(tx *gorm.DB, action string, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var todos []models.Todo
+	if err := tx.Unscoped().Preload("Tags").Where("id IN ?", ids).Find(&todos).Error; err != nil {
+		return err
+	}
+
+	// Load the latest revision of each todo
+	var previous []models.TodoRevision
+	latestIDs := tx.Model(&models.TodoRevision{}).Select("MAX(id)").Where("todo_id IN ?", ids).Group("todo_id")
+	if err := tx.Where("id IN (?)", latestIDs).Find(&previous).Error; err != nil {
+		return err
+	}
+	latest := make(map[uint]*models.TodoRevision, len(previous))
+	for i := range previous {
+		latest[previous[i].TodoID] = &previous[i]
+	}
+
+	revisions := make([]models.TodoRevision, 0, len(todos))
+	for i := range todos {
+		snapshot := models.NewTodoSnapshot(&todos[i])
+		revision := models.TodoRevision{
+			TodoID:   todos[i].ID,
+			Version:  todos[i].Version,
+			Action:   action,
+			Snapshot: snapshot,
+			Changes:  []models.FieldChange{},
+		}
+
+		// Todos changed before history was recorded have nothing to compare with
+		if last, ok := latest[todos[i].ID]; ok {
+			changes, err := diffSnapshots(&last.Snapshot, &snapshot)
+			if err != nil {
+				return err
+			}
+			revision.Changes = changes
+		} else if action == models.RevisionActionCreate {
+			changes, err := diffSnapshots(&models.TodoSnapshot{Tags: []string{}}, &snapshot)
+			if err != nil {
+				return err
+			}
+			revision.Changes = changes
+		}
+
+		if action == models.RevisionActionUpdate {
+			for _, change := range revision.Changes {
+				if change.Field == "completed" && snapshot.Completed {
+					revision.Action = models.RevisionActionComplete
+				} else if change.Field == "completed" {
+					revision.Action = models.RevisionActionReopen
+				}
+			}
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if len(revisions) == 0 {
+		return nil
+	}
+	if err := tx.Create(&revisions).Error; err != nil {
+		return err
+	}
+
+	events := make([]models.ChangeEvent, len(revisions))
+	audits := make([]models.AuditEvent, len(revisions))
+	for i := range revisions {
+		eventType := models.TodoEventType(revisions[i].Action)
+		events[i] = todoEvent(eventType, &todos[i], revisions[i].Changes)
+
+		// The audit log holds the snapshots before and after the change
+		var before *models.TodoSnapshot
+		if last, ok := latest[todos[i].ID]; ok {
+			before = &last.Snapshot
+		}
+		audit, err := auditEvent(models.EntityTodo, todos[i].ID, eventType, before, &revisions[i].Snapshot)
+		if err != nil {
+			return err
+		}
+		audits[i] = audit
+	}
+	if err := recordAudit(tx, audits...); err != nil {
+		return err
+	}
+	return publishEvents(tx, events...)
+}
+
+// diffSnapshots returns the fields that differ between two snapshots, sorted by field name
+func diffSnapshots(before, after *models.TodoSnapshot) ([]models.FieldChange, error) {
+	beforeFields, err := snapshotFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := snapshotFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []models.FieldChange{}
+	for field, value := range afterFields {
+		if reflect.DeepEqual(beforeFields[field], value) {
+			continue
+		}
+		from, _ := json.Marshal(beforeFields[field])
+		to, _ := json.Marshal(value)
+		changes = append(changes, models.FieldChange{Field: field, From: from, To: to})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
+
+// snapshotFields converts a snapshot to its JSON fields
+func snapshotFields(snapshot *models.TodoSnapshot) (map[string]interface{}, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"todo-backend/internal/models"
+	"todo-backend/pkg/rank"
+)
+
+// Import creates categories and todos with their tags in a single transaction
+// Nothing is stored when any of them fails. Imported todos follow the existing ones in manual order
+func (r *todoRepository) Import(categories []*models.Category, items []ImportItem) error {
+	err := writeTransaction(r.db, func(tx *gorm.DB) error {
+		if err := lockPositions(tx); err != nil {
+			return err
+		}
+		last, err := lastPosition(tx)
+		if err != nil {
+			return err
+		}
+		for i, position := range rank.Spread(len(items)) {
+			items[i].Todo.Position = last + position
+		}
+
+		categoryIDs := make(map[string]uint, len(categories))
+		for _, category := range categories {
+			if err := tx.Create(category).Error; err != nil {
+				return err
+			}
+			if err := publishCategoryChange(tx, models.EventCategoryCreated, nil, category); err != nil {
+				return err
+			}
+			categoryIDs[strings.ToLower(category.Name)] = category.ID
+		}
+
+		tagIDs := make(map[string]uint)
+		ids := make([]uint, 0, len(items))
+		for _, item := range items {
+			if item.CategoryName != "" {
+				id, ok := categoryIDs[strings.ToLower(item.CategoryName)]
+				if !ok {
+					return errors.New("category " + item.CategoryName + " not found")
+				}
+				item.Todo.CategoryID = &id
+			}
+			if err := tx.Omit(clause.Associations).Create(item.Todo).Error; err != nil {
+				return err
+			}
+			ids = append(ids, item.Todo.ID)
+
+			for _, name := range item.Tags {
+				tagID, ok := tagIDs[name]
+				if !ok {
+					tag := models.Tag{Name: name}
+					if err := tx.Where(models.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+						return err
+					}
+					tagID = tag.ID
+					tagIDs[name] = tagID
+				}
+				err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+					Table("todo_tags").
+					Create(map[string]interface{}{"todo_id": item.Todo.ID, "tag_id": tagID}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Revisions are recorded once the tags are attached, so the first revision includes them
+		return recordRevisions(tx, models.RevisionActionCreate, ids...)
+	})
+	if err != nil {
+		// Handle unique constraint violation
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "UNIQUE constraint") {
+			return errors.New("category name already exists")
+		}
+		return err
+	}
+	return nil
+}
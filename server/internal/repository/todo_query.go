@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"todo-backend/pkg/todoquery"
+)
+
+// likeEscaper escapes the wildcards of a LIKE pattern, so searched text matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// openBlockersCondition matches todos that still have open blockers
+const openBlockersCondition = `EXISTS (
+	SELECT 1 FROM todo_dependencies d
+	JOIN todos b ON b.id = d.blocked_by_id AND b.deleted_at IS NULL
+	WHERE d.todo_id = todos.id AND b.completed = false
+)`
+
+// applyQuery restricts a query to the todos matching a filter query
+// Parse errors are added to the query, so they are returned when it runs
+func applyQuery(query *gorm.DB, q string, now time.Time) *gorm.DB {
+	node, err := todoquery.Parse(q, now)
+	if err != nil {
+		_ = query.AddError(err)
+		return query
+	}
+	if node == nil {
+		return query
+	}
+	sql, args := compileQuery(node, now)
+	return query.Where(sql, args...)
+}
+
+// compileQuery compiles a query node into an SQL condition on the todos table
+// Values are always passed as arguments, only the structure of the condition comes from the query
+func compileQuery(node todoquery.Node, now time.Time) (string, []interface{}) {
+	switch n := node.(type) {
+	case *todoquery.And:
+		return compileQueryList(n.Nodes, " AND ", now)
+	case *todoquery.Or:
+		return compileQueryList(n.Nodes, " OR ", now)
+	case *todoquery.Not:
+		sql, args := compileQuery(n.Node, now)
+		return "NOT (" + sql + ")", args
+	case *todoquery.Term:
+		return compileQueryTerm(n, now)
+	}
+	// The parser only produces the nodes above
+	return "FALSE", nil
+}
+
+func compileQueryList(nodes []todoquery.Node, separator string, now time.Time) (string, []interface{}) {
+	parts := make([]string, len(nodes))
+	var args []interface{}
+	for i, node := range nodes {
+		sql, nodeArgs := compileQuery(node, now)
+		parts[i] = sql
+		args = append(args, nodeArgs...)
+	}
+	return "(" + strings.Join(parts, separator) + ")", args
+}
+
+func compileQueryTerm(term *todoquery.Term, now time.Time) (string, []interface{}) {
+	switch term.Field {
+	case todoquery.FieldText:
+		return "LOWER(todos.title) LIKE ?", []interface{}{"%" + likeEscaper.Replace(term.Values[0]) + "%"}
+
+	case todoquery.FieldIs:
+		var parts []string
+		var args []interface{}
+		for _, flag := range term.Values {
+			switch flag {
+			case todoquery.FlagCompleted:
+				parts = append(parts, "todos.completed = true")
+			case todoquery.FlagOpen:
+				parts = append(parts, "todos.completed = false")
+			case todoquery.FlagArchived:
+				parts = append(parts, "todos.archived_at IS NOT NULL")
+			case todoquery.FlagBlocked:
+				parts = append(parts, openBlockersCondition)
+			case todoquery.FlagOverdue:
+				parts = append(parts, "(todos.completed = false AND todos.due_date < ?)")
+				args = append(args, now)
+			}
+		}
+		return anyOf(parts), args
+
+	case todoquery.FieldPriority:
+		return "todos.priority IN ?", []interface{}{term.Values}
+
+	case todoquery.FieldStatus:
+		return "todos.status_id IN (SELECT id FROM workflow_statuses WHERE key IN ?)", []interface{}{term.Values}
+
+	case todoquery.FieldCategory:
+		var parts []string
+		var args []interface{}
+		var names []string
+		for _, value := range term.Values {
+			if value == todoquery.ValueNone {
+				parts = append(parts, "todos.category_id IS NULL")
+			} else {
+				names = append(names, value)
+			}
+		}
+		if len(names) > 0 {
+			parts = append(parts, "todos.category_id IN (SELECT id FROM categories WHERE deleted_at IS NULL AND LOWER(name) IN ?)")
+			args = append(args, names)
+		}
+		return anyOf(parts), args
+
+	case todoquery.FieldTag:
+		var parts []string
+		var args []interface{}
+		var names []string
+		for _, value := range term.Values {
+			if value == todoquery.ValueNone {
+				parts = append(parts, "NOT EXISTS (SELECT 1 FROM todo_tags tt WHERE tt.todo_id = todos.id)")
+			} else {
+				names = append(names, value)
+			}
+		}
+		if len(names) > 0 {
+			parts = append(parts, "EXISTS (SELECT 1 FROM todo_tags tt JOIN tags t ON t.id = tt.tag_id WHERE tt.todo_id = todos.id AND LOWER(t.name) IN ?)")
+			args = append(args, names)
+		}
+		return anyOf(parts), args
+	}
+
+	// Date fields
+	column := map[string]string{
+		todoquery.FieldDue:     "todos.due_date",
+		todoquery.FieldCreated: "todos.created_at",
+		todoquery.FieldUpdated: "todos.updated_at",
+		todoquery.FieldDone:    "todos.completed_at",
+	}[term.Field]
+	if !term.IsDate() {
+		if term.Values[0] == todoquery.ValueAny {
+			return column + " IS NOT NULL", nil
+		}
+		return column + " IS NULL", nil
+	}
+	switch term.Op {
+	case todoquery.OpLess:
+		return column + " < ?", []interface{}{term.From}
+	case todoquery.OpLE:
+		return column + " < ?", []interface{}{term.To}
+	case todoquery.OpMore:
+		return column + " >= ?", []interface{}{term.To}
+	case todoquery.OpGE:
+		return column + " >= ?", []interface{}{term.From}
+	}
+	return "(" + column + " >= ? AND " + column + " < ?)", []interface{}{term.From, term.To}
+}
+
+// anyOf joins conditions with OR
+func anyOf(parts []string) string {
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return "(" + strings.Join(parts, " OR ") + ")"
+}
+
+// queryMentionsArchived reports whether a filter query has a condition on the archived flag,
+// in which case archived todos are not hidden by default
+func queryMentionsArchived(q string) bool {
+	if q == "" {
+		return false
+	}
+	node, err := todoquery.Parse(q, time.Now())
+	return err == nil && todoquery.Mentions(node, todoquery.FieldIs, todoquery.FlagArchived)
+}
+
+// ParseFilterTime resolves the value of a date filter such as due_before to a time range [from, to)
+// RFC 3339 timestamps stand for themselves; dates, today, tomorrow, yesterday and offsets such as 7d
+// stand for a whole day in UTC, resolved against now
+func ParseFilterTime(value string, now time.Time) (time.Time, time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, t.Add(time.Nanosecond), nil
+	}
+	day, ok := todoquery.ParseDay(value, now)
+	if !ok {
+		return time.Time{}, time.Time{}, errors.New("must be an RFC 3339 time, a date such as 2026-01-01, today, tomorrow, yesterday or an offset such as 7d")
+	}
+	return day, day.AddDate(0, 0, 1), nil
+}
+
+// applyTimeFilter restricts a query with a condition on the start of a date filter's range,
+// or on its end for upper bounds so the whole day is included
+func applyTimeFilter(query *gorm.DB, condition, value string, now time.Time, upper bool) *gorm.DB {
+	from, to, err := ParseFilterTime(value, now)
+	if err != nil {
+		_ = query.AddError(errors.New("invalid date filter " + value + ": " + err.Error()))
+		return query
+	}
+	if upper {
+		return query.Where(condition, to)
+	}
+	return query.Where(condition, from)
+}
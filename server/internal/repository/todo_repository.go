@@ -1,13 +1,29 @@
 package repository
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"todo-backend/internal/models"
+	"todo-backend/pkg/rank"
 )
 
+// positionOrder orders todos by manual position, compared byte-wise regardless of the database collation
+const positionOrder = `position COLLATE "C"`
+
+// positionLockKey is the transaction-level advisory lock that serializes writers of manual order positions,
+// so keys are always generated from committed neighbors and two todos never get the same key
+const positionLockKey = 7_402_613
+
+// rebalanceBatchSize is the number of positions rewritten by a single statement
+const rebalanceBatchSize = 1000
+
 // todoRepository implements TodoRepository interface
 type todoRepository struct {
 	db *gorm.DB
@@ -20,12 +36,24 @@ func NewTodoRepository(db *gorm.DB) TodoRepository {
 	}
 }
 
+// WithContext returns a copy of the repository running its queries with ctx
+func (r *todoRepository) WithContext(ctx context.Context) TodoRepository {
+	return &todoRepository{db: r.db.WithContext(ctx)}
+}
+
 // Create creates a new todo
 func (r *todoRepository) Create(todo *models.Todo) error {
+	return writeTransaction(r.db, func(tx *gorm.DB) error {
+		return createTodo(tx, todo)
+	})
+}
+
+// createTodo creates a todo at the end of the manual order within a writeTransaction
+func createTodo(tx *gorm.DB, todo *models.Todo) error {
 	// Validate category exists if provided
 	if todo.CategoryID != nil {
 		var category models.Category
-		if err := r.db.First(&category, *todo.CategoryID).Error; err != nil {
+		if err := tx.First(&category, *todo.CategoryID).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return errors.New("category not found")
 			}
@@ -33,20 +61,41 @@ func (r *todoRepository) Create(todo *models.Todo) error {
 		}
 	}
 
-	return r.db.Create(todo).Error
+	// New todos are added to the end of the manual order
+	if err := lockPositions(tx); err != nil {
+		return err
+	}
+	last, err := lastPosition(tx)
+	if err != nil {
+		return err
+	}
+	if todo.Position, err = rank.After(last); err != nil {
+		return err
+	}
+
+	if err := tx.Create(todo).Error; err != nil {
+		return err
+	}
+	return recordRevisions(tx, models.RevisionActionCreate, todo.ID)
 }
 
 // GetByID retrieves a todo by its ID
 func (r *todoRepository) GetByID(id uint) (*models.Todo, error) {
 	var todo models.Todo
-	err := r.db.Preload("Category").First(&todo, id).Error
+	err := r.db.Preload("Category").Preload("Status").Preload("Tags").First(&todo, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("todo not found")
 		}
 		return nil, err
 	}
-	return &todo, nil
+
+	// Attach computed fields
+	todos := []models.Todo{todo}
+	if err := r.attachComputedFields(todos); err != nil {
+		return nil, err
+	}
+	return &todos[0], nil
 }
 
 // Update updates an existing todo
@@ -71,12 +120,32 @@ func (r *todoRepository) Update(todo *models.Todo) error {
 		}
 	}
 
-	// Update the todo
-	return r.db.Save(todo).Error
+	// Update the todo only if nobody changed it since the expected version was read
+	expected := todo.Version
+	if expected == 0 {
+		expected = existingTodo.Version
+	}
+	todo.Version = expected + 1
+
+	err := writeTransaction(r.db, func(tx *gorm.DB) error {
+		// The position is only written by moves, so an edit never undoes a concurrent move
+		result := tx.Select("*").Omit("position").Where("version = ?", expected).Save(todo)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("todo was modified by another request")
+		}
+		return recordRevisions(tx, models.RevisionActionUpdate, todo.ID)
+	})
+	if err != nil {
+		todo.Version = expected
+	}
+	return err
 }
 
 // Delete soft deletes a todo by ID
-func (r *todoRepository) Delete(id uint) error {
+func (r *todoRepository) Delete(id uint, version uint) error {
 	// Check if todo exists
 	var todo models.Todo
 	if err := r.db.First(&todo, id).Error; err != nil {
@@ -86,8 +155,21 @@ func (r *todoRepository) Delete(id uint) error {
 		return err
 	}
 
-	// Soft delete the todo
-	return r.db.Delete(&todo).Error
+	// Soft delete the todo, only if it still has the expected version
+	return writeTransaction(r.db, func(tx *gorm.DB) error {
+		query := tx.Model(&todo)
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+		result := query.Updates(softDeleteColumns())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("todo was modified by another request")
+		}
+		return recordRevisions(tx, models.RevisionActionDelete, id)
+	})
 }
 
 // List retrieves todos with pagination and filtering
@@ -96,35 +178,174 @@ func (r *todoRepository) List(filters TodoFilters, pagination PaginationParams)
 	var total int64
 
 	// Build the base query with category preload
-	query := r.db.Model(&models.Todo{}).Preload("Category")
+	query := r.db.Model(&models.Todo{}).Preload("Category").Preload("Status").Preload("Tags")
+	query = r.applyFilters(query, filters)
 
-	// Apply search filter (search in title using full-text search)
-	if filters.Search != "" {
-		searchTerm := "%" + strings.ToLower(filters.Search) + "%"
-		query = query.Where("LOWER(title) LIKE ?", searchTerm)
+	// Count total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, PaginationResult{}, err
 	}
 
-	// Apply completion filter
-	if filters.Completed != nil {
-		query = query.Where("completed = ?", *filters.Completed)
+	// Apply sorting
+	query = r.applySort(query, pagination)
+
+	// Apply pagination
+	offset := pagination.GetOffset()
+	limit := pagination.GetLimit()
+	if err := query.Offset(offset).Limit(limit).Find(&todos).Error; err != nil {
+		return nil, PaginationResult{}, err
 	}
 
-	// Apply category filter
-	if filters.CategoryID != nil {
-		query = query.Where("category_id = ?", *filters.CategoryID)
+	// Attach computed fields
+	if err := r.attachComputedFields(todos); err != nil {
+		return nil, PaginationResult{}, err
 	}
 
-	// Apply priority filter
-	if filters.Priority != "" {
-		query = query.Where("priority = ?", filters.Priority)
+	// Calculate pagination result
+	paginationResult := PaginationResult{
+		CurrentPage: pagination.Page,
+		PerPage:     limit,
+		Total:       total,
 	}
+	paginationResult.CalculateTotalPages()
 
-	// Count total records
-	if err := query.Count(&total).Error; err != nil {
-		return nil, PaginationResult{}, err
+	return todos, paginationResult, nil
+}
+
+// GetByIDs retrieves the todos with the given IDs, missing todos are skipped
+func (r *todoRepository) GetByIDs(ids []uint) ([]models.Todo, error) {
+	var todos []models.Todo
+	if len(ids) == 0 {
+		return todos, nil
 	}
 
-	// Apply sorting
+	err := r.db.Preload("Category").Preload("Status").Preload("Tags").
+		Where("id IN ?", ids).
+		Order("id ASC").
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach computed fields
+	if err := r.attachComputedFields(todos); err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+// ListIDs retrieves the IDs of up to limit todos matching the filters
+func (r *todoRepository) ListIDs(filters TodoFilters, limit int) ([]uint, error) {
+	var ids []uint
+	query := r.applyFilters(r.db.Model(&models.Todo{}), filters)
+	err := query.Order("id ASC").Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// BulkApply applies the given changes in a single transaction and returns a result per item
+// Each item runs in its own savepoint, so a failing item is rolled back on its own
+func (r *todoRepository) BulkApply(items []BulkItem) ([]BulkItemResult, error) {
+	results := make([]BulkItemResult, len(items))
+
+	err := writeTransaction(r.db, func(tx *gorm.DB) error {
+		for i, item := range items {
+			results[i] = BulkItemResult{ID: item.ID, Success: true}
+
+			// Nested transactions are savepoints
+			err := tx.Transaction(func(tx *gorm.DB) error {
+				return r.applyBulkItem(tx, item)
+			})
+			if err != nil {
+				results[i].Success = false
+				results[i].Error = err.Error()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// applyBulkItem applies the change of one bulk item
+func (r *todoRepository) applyBulkItem(tx *gorm.DB, item BulkItem) error {
+	// Lock the todo, it may have been deleted since it was selected
+	var todo models.Todo
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&todo, item.ID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("todo not found")
+		}
+		return err
+	}
+
+	if item.Delete {
+		if err := tx.Model(&todo).Updates(softDeleteColumns()).Error; err != nil {
+			return err
+		}
+		return recordRevisions(tx, models.RevisionActionDelete, item.ID)
+	}
+
+	// Blockers added since the todo was selected are seen here, adding one locks the todo too
+	if item.RejectBlocked {
+		var blocked int64
+		err := tx.Model(&models.Todo{}).Where("todos.id = ? AND "+openBlockersCondition, item.ID).Count(&blocked).Error
+		if err != nil {
+			return err
+		}
+		if blocked > 0 {
+			return errors.New("todo has open blockers")
+		}
+	}
+
+	columns := map[string]interface{}{}
+	if item.StatusID != nil {
+		columns = completionColumns(item.Completed)
+		columns["status_id"] = *item.StatusID
+	}
+	if item.Priority != "" {
+		columns["priority"] = item.Priority
+	}
+	if item.SetCategory {
+		columns["category_id"] = item.CategoryID
+	}
+	if item.SetDueDate {
+		columns["due_date"] = item.DueDate
+	}
+	tagAdded := false
+	if item.AddTagID != 0 {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Table("todo_tags").
+			Create(map[string]interface{}{"todo_id": item.ID, "tag_id": item.AddTagID})
+		if result.Error != nil {
+			return result.Error
+		}
+		tagAdded = result.RowsAffected > 0
+	}
+
+	if len(columns) == 0 && !tagAdded {
+		return nil
+	}
+	columns["version"] = nextVersion
+	if err := tx.Model(&todo).Updates(columns).Error; err != nil {
+		return err
+	}
+	return recordRevisions(tx, models.RevisionActionUpdate, item.ID)
+}
+
+// FindOrCreateTag retrieves a tag by name, creating it when it does not exist yet
+func (r *todoRepository) FindOrCreateTag(name string) (*models.Tag, error) {
+	tag := models.Tag{Name: name}
+	err := r.db.Where(models.Tag{Name: name}).FirstOrCreate(&tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// applySort orders a todo query by the sort field of the pagination parameters, creation date by default
+func (r *todoRepository) applySort(query *gorm.DB, pagination PaginationParams) *gorm.DB {
 	sortBy := "created_at"
 	if pagination.SortBy != "" {
 		// Validate sort field
@@ -135,6 +356,7 @@ func (r *todoRepository) List(filters TodoFilters, pagination PaginationParams)
 			"due_date":   true,
 			"created_at": true,
 			"updated_at": true,
+			"position":   true,
 		}
 		if validSortFields[pagination.SortBy] {
 			sortBy = pagination.SortBy
@@ -148,30 +370,116 @@ func (r *todoRepository) List(filters TodoFilters, pagination PaginationParams)
 			orderClause += " DESC"
 		}
 		query = query.Order(orderClause)
+	} else if sortBy == "position" {
+		// Manual order reads top to bottom unless a sort order is given
+		sortOrder := "asc"
+		if pagination.SortOrder != "" {
+			sortOrder = pagination.SortOrder
+		}
+		query = query.Order(positionOrder + " " + sortOrder).Order("id " + sortOrder)
 	} else {
 		query = query.Order(sortBy + " " + pagination.GetSortOrder())
 	}
+	return query
+}
 
-	// Apply pagination
-	offset := pagination.GetOffset()
-	limit := pagination.GetLimit()
-	if err := query.Offset(offset).Limit(limit).Find(&todos).Error; err != nil {
-		return nil, PaginationResult{}, err
+// applyFilters applies the todo filters to a query
+func (r *todoRepository) applyFilters(query *gorm.DB, filters TodoFilters) *gorm.DB {
+	// Apply search filter (search in title using full-text search)
+	if filters.Search != "" {
+		searchTerm := "%" + strings.ToLower(filters.Search) + "%"
+		query = query.Where("LOWER(title) LIKE ?", searchTerm)
 	}
 
-	// Calculate pagination result
-	paginationResult := PaginationResult{
-		CurrentPage: pagination.Page,
-		PerPage:     limit,
-		Total:       total,
+	// Apply completion filter
+	if filters.Completed != nil {
+		query = query.Where("completed = ?", *filters.Completed)
 	}
-	paginationResult.CalculateTotalPages()
 
-	return todos, paginationResult, nil
+	// Apply category filter, matching any of the categories or todos without one
+	if len(filters.CategoryID) > 0 {
+		var conditions []string
+		var args []interface{}
+		var ids []uint64
+		for _, value := range filters.CategoryID {
+			if value == FilterValueNone {
+				conditions = append(conditions, "category_id IS NULL")
+			} else if id, err := strconv.ParseUint(value, 10, 32); err == nil {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > 0 {
+			conditions = append(conditions, "category_id IN ?")
+			args = append(args, ids)
+		}
+		if len(conditions) == 0 {
+			conditions = append(conditions, "FALSE")
+		}
+		query = query.Where(anyOf(conditions), args...)
+	}
+
+	// Apply priority filter
+	if len(filters.Priority) > 0 {
+		query = query.Where("priority IN ?", []string(filters.Priority))
+	}
+
+	// Apply date filters, relative dates are resolved now
+	now := time.Now()
+	if filters.DueAfter != "" {
+		query = applyTimeFilter(query, "due_date >= ?", filters.DueAfter, now, false)
+	}
+	if filters.DueBefore != "" {
+		query = applyTimeFilter(query, "due_date < ?", filters.DueBefore, now, true)
+	}
+	if filters.CreatedAfter != "" {
+		query = applyTimeFilter(query, "created_at >= ?", filters.CreatedAfter, now, false)
+	}
+	if filters.UpdatedAfter != "" {
+		query = applyTimeFilter(query, "updated_at >= ?", filters.UpdatedAfter, now, false)
+	}
+	if filters.HasDueDate != nil {
+		if *filters.HasDueDate {
+			query = query.Where("due_date IS NOT NULL")
+		} else {
+			query = query.Where("due_date IS NULL")
+		}
+	}
+
+	// Overdue todos are open and were due before now
+	if filters.Overdue != nil {
+		if *filters.Overdue {
+			query = query.Where("completed = false AND due_date < ?", now)
+		} else {
+			query = query.Where("NOT (completed = false AND due_date IS NOT NULL AND due_date < ?)", now)
+		}
+	}
+
+	// Archived todos are hidden unless requested, or unless the filter query asks for them
+	if !filters.IncludeArchived && !queryMentionsArchived(filters.Query) {
+		query = query.Where("archived_at IS NULL")
+	}
+
+	// Apply workflow status filter (by status key, across all workflows)
+	if filters.Status != "" {
+		query = query.Where("status_id IN (?)", r.db.Model(&models.WorkflowStatus{}).Select("id").Where("key = ?", filters.Status))
+	}
+
+	// Hide todos that still have open blockers
+	if filters.HideBlocked {
+		query = query.Where("NOT " + openBlockersCondition)
+	}
+
+	// Apply the filter query
+	if filters.Query != "" {
+		query = applyQuery(query, filters.Query, now)
+	}
+
+	return query
 }
 
-// ToggleComplete toggles the completion status of a todo
-func (r *todoRepository) ToggleComplete(id uint) error {
+// SetStatus moves a todo to a workflow status and stores the derived completion flag
+// if it still has the expected version
+func (r *todoRepository) SetStatus(id uint, version uint, statusID uint, completed bool) error {
 	// Get the current todo
 	var todo models.Todo
 	if err := r.db.First(&todo, id).Error; err != nil {
@@ -181,9 +489,546 @@ func (r *todoRepository) ToggleComplete(id uint) error {
 		return err
 	}
 
-	// Toggle the completion status
-	todo.ToggleComplete()
+	// Update the status and completion flag together
+	columns := completionColumns(completed)
+	columns["status_id"] = statusID
+	columns["version"] = nextVersion
+	return writeTransaction(r.db, func(tx *gorm.DB) error {
+		query := tx.Model(&models.Todo{}).Where("id = ?", id)
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+		result := query.Updates(columns)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("todo was modified by another request")
+		}
+		return recordRevisions(tx, models.RevisionActionUpdate, id)
+	})
+}
 
-	// Save the updated todo
-	return r.db.Save(&todo).Error
-}
\ No newline at end of file
+// UpdateFields updates only the given columns of a todo if it still has the expected version
+// Changing the completed column also updates the completion time
+func (r *todoRepository) UpdateFields(id uint, version uint, columns map[string]interface{}) error {
+	// Check if todo exists
+	var todo models.Todo
+	if err := r.db.First(&todo, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("todo not found")
+		}
+		return err
+	}
+
+	addCompletionColumns(columns)
+	columns["version"] = nextVersion
+
+	return writeTransaction(r.db, func(tx *gorm.DB) error {
+		query := tx.Model(&models.Todo{}).Where("id = ?", id)
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+		result := query.Updates(columns)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("todo was modified by another request")
+		}
+		return recordRevisions(tx, models.RevisionActionUpdate, id)
+	})
+}
+
+// SetArchived archives or unarchives a todo if it still has the expected version
+func (r *todoRepository) SetArchived(id uint, version uint, archived bool) error {
+	var archivedAt interface{}
+	action := models.RevisionActionUnarchive
+	if archived {
+		archivedAt = gorm.Expr("NOW()")
+		action = models.RevisionActionArchive
+	}
+
+	return writeTransaction(r.db, func(tx *gorm.DB) error {
+		query := tx.Model(&models.Todo{}).Where("id = ?", id)
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+		result := query.Updates(map[string]interface{}{
+			"archived_at": archivedAt,
+			"version":     nextVersion,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			if version != 0 {
+				return errors.New("todo was modified by another request")
+			}
+			return errors.New("todo not found")
+		}
+		return recordRevisions(tx, action, id)
+	})
+}
+
+// ArchiveCompleted archives completed todos, optionally limited to a category and to todos completed before a time
+// Returns the number of archived todos
+func (r *todoRepository) ArchiveCompleted(categoryID *uint, completedBefore *time.Time) (int64, error) {
+	var ids []uint
+	err := writeTransaction(r.db, func(tx *gorm.DB) error {
+		// Lock the todos to archive, their revisions are recorded after the update
+		query := tx.Model(&models.Todo{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("completed = ? AND archived_at IS NULL", true)
+		if categoryID != nil {
+			query = query.Where("category_id = ?", *categoryID)
+		}
+		if completedBefore != nil {
+			query = query.Where("COALESCE(completed_at, updated_at) < ?", *completedBefore)
+		}
+		if err := query.Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+			return err
+		}
+
+		err := tx.Model(&models.Todo{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"archived_at": gorm.Expr("NOW()"),
+			"version":     nextVersion,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recordRevisions(tx, models.RevisionActionArchive, ids...)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
+// softDeleteColumns returns the columns to update when a todo is moved to the trash
+func softDeleteColumns() map[string]interface{} {
+	return map[string]interface{}{
+		"deleted_at": time.Now().UTC(),
+		"version":    nextVersion,
+	}
+}
+
+// nextVersion increments the version of a row, so clients holding an older version get a conflict
+var nextVersion = gorm.Expr("version + 1")
+
+// completionColumns returns the columns to update when the completed flag of todos changes
+// Completion keeps an earlier completion time; reopening clears it and takes the todo out of the archive
+func completionColumns(completed bool) map[string]interface{} {
+	if completed {
+		return map[string]interface{}{
+			"completed":    true,
+			"completed_at": gorm.Expr("COALESCE(completed_at, NOW())"),
+		}
+	}
+	return map[string]interface{}{
+		"completed":    false,
+		"completed_at": nil,
+		"archived_at":  nil,
+	}
+}
+
+// addCompletionColumns adds the completion columns when the completed flag is among the columns to update
+func addCompletionColumns(columns map[string]interface{}) {
+	if completed, ok := columns["completed"].(bool); ok {
+		for column, value := range completionColumns(completed) {
+			columns[column] = value
+		}
+	}
+}
+
+// MovePosition places a todo between two others in manual order and publishes a todo.moved event
+// With only afterID or beforeID the closest todo on the other side is the other neighbor.
+// Moves are serialized and the neighbors read with their rows locked, so concurrent moves into
+// the same gap get different keys. Other columns, like the category of a move to another list,
+// are written in the same update and recorded in the todo history
+func (r *todoRepository) MovePosition(id uint, version uint, afterID, beforeID *uint, columns map[string]interface{}) error {
+	return writeTransaction(r.db, func(tx *gorm.DB) error {
+		if err := lockPositions(tx); err != nil {
+			return err
+		}
+
+		var todo models.Todo
+		if err := tx.Preload("Tags").Clauses(clause.Locking{Strength: "UPDATE"}).First(&todo, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("todo not found")
+			}
+			return err
+		}
+		if version != 0 && todo.Version != version {
+			return errors.New("todo was modified by another request")
+		}
+
+		// Resolve the neighbors
+		var lower, upper string
+		if afterID != nil {
+			position, err := lockedPosition(tx, *afterID)
+			if err != nil {
+				return errors.New("invalid move: after_id does not exist")
+			}
+			lower = position
+		}
+		if beforeID != nil {
+			position, err := lockedPosition(tx, *beforeID)
+			if err != nil {
+				return errors.New("invalid move: before_id does not exist")
+			}
+			upper = position
+		}
+		var err error
+		if afterID == nil {
+			if lower, err = neighborPosition(tx, upper, id, true); err != nil {
+				return err
+			}
+		}
+		if beforeID == nil {
+			if upper, err = neighborPosition(tx, lower, id, false); err != nil {
+				return err
+			}
+		}
+
+		position, err := rank.Between(lower, upper)
+		if err != nil {
+			if lower == upper && lower != "" {
+				return errors.New("invalid move: after_id and before_id have the same position, retry once positions are rebalanced")
+			}
+			return errors.New("invalid move: after_id must come before before_id")
+		}
+
+		updates := map[string]interface{}{}
+		for column, value := range columns {
+			updates[column] = value
+		}
+		addCompletionColumns(updates)
+		updates["position"] = position
+		updates["version"] = nextVersion
+		if err := tx.Model(&models.Todo{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		// Positions are not part of the todo history, the move is described by the event alone
+		previous := todo.Position
+		if err := tx.Preload("Tags").First(&todo, id).Error; err != nil {
+			return err
+		}
+		change, err := positionChange(previous, position)
+		if err != nil {
+			return err
+		}
+		before := map[string]string{"position": previous}
+		after := map[string]string{"position": position}
+		if err := recordAuditChange(tx, models.EntityTodo, id, models.EventTodoMoved, before, after); err != nil {
+			return err
+		}
+		if err := publishEvents(tx, todoEvent(models.EventTodoMoved, &todo, []models.FieldChange{change})); err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			return nil
+		}
+		return recordRevisions(tx, models.RevisionActionUpdate, id)
+	})
+}
+
+// positionChange describes a change of the manual order position of a todo
+func positionChange(from, to string) (models.FieldChange, error) {
+	change := models.FieldChange{Field: "position"}
+	var err error
+	if change.From, err = json.Marshal(from); err != nil {
+		return change, err
+	}
+	change.To, err = json.Marshal(to)
+	return change, err
+}
+
+// NeedsRebalance reports whether any todo has no position, a position longer than maxLength
+// or the same position as another todo
+// Soft-deleted todos are included so they keep their place when restored
+func (r *todoRepository) NeedsRebalance(maxLength int) (bool, error) {
+	duplicates := r.db.Unscoped().Model(&models.Todo{}).
+		Select("position").
+		Where("position <> ''").
+		Group("position").
+		Having("COUNT(*) > 1")
+
+	var count int64
+	err := r.db.Unscoped().Model(&models.Todo{}).
+		Where("position = '' OR LENGTH(position) > ? OR position IN (?)", maxLength, duplicates).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RebalancePositions rewrites all positions with short, evenly spaced keys, keeping the order
+// Todos without a position are placed last in creation order, todos sharing a position in ID order.
+// Only positions that change are written, in batches. Returns the number of todos updated
+func (r *todoRepository) RebalancePositions() (int, error) {
+	updated := 0
+	err := writeTransaction(r.db, func(tx *gorm.DB) error {
+		// Block moves until the new keys are committed
+		if err := lockPositions(tx); err != nil {
+			return err
+		}
+
+		var rows []struct {
+			ID       uint
+			Position string
+		}
+		err := tx.Unscoped().Model(&models.Todo{}).
+			Select("id", "position").
+			Order("position = '' ASC").
+			Order(positionOrder + " ASC").
+			Order("id ASC").
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		var ids []uint
+		var values []string
+		var args []interface{}
+		flush := func() error {
+			if len(values) == 0 {
+				return nil
+			}
+			err := tx.Exec(`UPDATE todos SET position = v.position
+				FROM (VALUES `+strings.Join(values, ", ")+`) AS v(id, position)
+				WHERE todos.id = v.id`, args...).Error
+			values, args = values[:0], args[:0]
+			return err
+		}
+		for i, position := range rank.Spread(len(rows)) {
+			if rows[i].Position == position {
+				continue
+			}
+			ids = append(ids, rows[i].ID)
+			values = append(values, "(?::bigint, ?::text)")
+			args = append(args, rows[i].ID, position)
+			if len(values) == rebalanceBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+
+		updated = len(ids)
+		return recordSyncChanges(tx, models.EntityTodo, ids...)
+	})
+	return updated, err
+}
+
+// lockPositions takes the lock serializing writers of manual order positions until the transaction ends
+func lockPositions(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", positionLockKey).Error
+}
+
+// lastPosition returns the position of the last todo in manual order, or "" when there are no todos
+func lastPosition(tx *gorm.DB) (string, error) {
+	var positions []string
+	err := tx.Model(&models.Todo{}).
+		Where("position <> ''").
+		Order(positionOrder + " DESC").
+		Limit(1).
+		Pluck("position", &positions).Error
+	if err != nil || len(positions) == 0 {
+		return "", err
+	}
+	return positions[0], nil
+}
+
+// lockedPosition reads the position of a todo, locking its row until the transaction ends
+func lockedPosition(tx *gorm.DB, id uint) (string, error) {
+	var todo models.Todo
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "position").First(&todo, id).Error
+	return todo.Position, err
+}
+
+// neighborPosition returns the closest position before or after the given one, or "" at either end
+// The todo being moved is excluded so it never counts as its own neighbor
+func neighborPosition(tx *gorm.DB, position string, excludeID uint, before bool) (string, error) {
+	query := tx.Model(&models.Todo{}).Where("id <> ? AND position <> ''", excludeID)
+	if before {
+		query = query.Where(positionOrder+" < ?", position).Order(positionOrder + " DESC")
+	} else {
+		query = query.Where(positionOrder+" > ?", position).Order(positionOrder + " ASC")
+	}
+
+	var positions []string
+	if err := query.Limit(1).Pluck("position", &positions).Error; err != nil || len(positions) == 0 {
+		return "", err
+	}
+	return positions[0], nil
+}
+
+// AddDependency records that a todo is blocked by another todo
+func (r *todoRepository) AddDependency(todoID, blockerID uint) error {
+	dependency := models.TodoDependency{
+		TodoID:      todoID,
+		BlockedByID: blockerID,
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Check if both todos exist, locking the blocked todo like completing it does
+		for _, id := range []uint{todoID, blockerID} {
+			query := tx
+			if id == todoID {
+				query = tx.Clauses(clause.Locking{Strength: "UPDATE"})
+			}
+			var todo models.Todo
+			if err := query.First(&todo, id).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.New("todo not found")
+				}
+				return err
+			}
+		}
+
+		if err := tx.Create(&dependency).Error; err != nil {
+			return err
+		}
+		return recordAuditChange(tx, models.EntityTodo, todoID, models.AuditActionBlockerAdded, nil, &dependency)
+	})
+	if err != nil {
+		// Handle unique constraint violation
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "UNIQUE constraint") {
+			return errors.New("dependency already exists")
+		}
+		return err
+	}
+	return nil
+}
+
+// RemoveDependency removes a blocker from a todo
+func (r *todoRepository) RemoveDependency(todoID, blockerID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var dependency models.TodoDependency
+		err := tx.Where("todo_id = ? AND blocked_by_id = ?", todoID, blockerID).First(&dependency).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("dependency not found")
+			}
+			return err
+		}
+
+		result := tx.Where("todo_id = ? AND blocked_by_id = ?", todoID, blockerID).Delete(&models.TodoDependency{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("dependency not found")
+		}
+		return recordAuditChange(tx, models.EntityTodo, todoID, models.AuditActionBlockerRemoved, &dependency, nil)
+	})
+}
+
+// GetBlockerIDs returns the blocker IDs of each of the given todos
+// Blockers in the trash are included: they come back with their dependencies when restored,
+// so a cycle through them must be refused as well. Purged blockers lose their edges by cascade
+func (r *todoRepository) GetBlockerIDs(todoIDs []uint) (map[uint][]uint, error) {
+	blockers := make(map[uint][]uint, len(todoIDs))
+	if len(todoIDs) == 0 {
+		return blockers, nil
+	}
+
+	var edges []models.TodoDependency
+	err := r.db.
+		Where("todo_id IN ?", todoIDs).
+		Find(&edges).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, edge := range edges {
+		blockers[edge.TodoID] = append(blockers[edge.TodoID], edge.BlockedByID)
+	}
+	return blockers, nil
+}
+
+// attachComputedFields fills the computed fields of the given todos
+func (r *todoRepository) attachComputedFields(todos []models.Todo) error {
+	if err := r.attachCommentCounts(todos); err != nil {
+		return err
+	}
+	return r.attachDependencies(todos)
+}
+
+// attachDependencies fills BlockedBy and Blocked for the given todos with a single query
+func (r *todoRepository) attachDependencies(todos []models.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+
+	var edges []struct {
+		TodoID          uint
+		BlockedByID     uint
+		BlockerComplete bool
+	}
+	err := r.db.Table("todo_dependencies d").
+		Select("d.todo_id, d.blocked_by_id, b.completed AS blocker_complete").
+		Joins("JOIN todos b ON b.id = d.blocked_by_id AND b.deleted_at IS NULL").
+		Where("d.todo_id IN ?", ids).
+		Order("d.blocked_by_id").
+		Scan(&edges).Error
+	if err != nil {
+		return err
+	}
+
+	index := make(map[uint]int, len(todos))
+	for i := range todos {
+		index[todos[i].ID] = i
+		todos[i].BlockedBy = []uint{}
+		todos[i].Blocked = false
+	}
+	for _, edge := range edges {
+		todo := &todos[index[edge.TodoID]]
+		todo.BlockedBy = append(todo.BlockedBy, edge.BlockedByID)
+		if !edge.BlockerComplete {
+			todo.Blocked = true
+		}
+	}
+	return nil
+}
+
+// attachCommentCounts fills CommentCount for the given todos with a single grouped query
+func (r *todoRepository) attachCommentCounts(todos []models.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+
+	var counts []struct {
+		TodoID uint
+		Count  int64
+	}
+	err := r.db.Model(&models.Comment{}).
+		Select("todo_id, COUNT(*) AS count").
+		Where("todo_id IN ?", ids).
+		Group("todo_id").
+		Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+
+	countByTodo := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		countByTodo[c.TodoID] = c.Count
+	}
+	for i := range todos {
+		todos[i].CommentCount = countByTodo[todos[i].ID]
+	}
+	return nil
+}
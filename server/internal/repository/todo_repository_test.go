@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// moveRows answers the lookups of a move of todo 5, at version 3, after todo 7 at the end of the list
+func moveRows(query string, args []any) fakeRows {
+	switch {
+	case strings.HasPrefix(query, `SELECT "id","position" FROM "todos"`):
+		return fakeRows{columns: []string{"id", "position"}, values: [][]driver.Value{{int64(7), "c"}}}
+	case strings.HasPrefix(query, `SELECT * FROM "todos"`):
+		return fakeRows{columns: []string{"id", "version", "position"}, values: [][]driver.Value{{int64(5), int64(3), "a"}}}
+	}
+	return fakeRows{}
+}
+
+func TestTodoMovePosition(t *testing.T) {
+	after := uint(7)
+	tests := []struct {
+		name      string
+		version   uint
+		columns   map[string]interface{}
+		wantErr   string
+		wantSet   []string
+		revisions bool
+	}{
+		{
+			name:    "position only",
+			wantSet: []string{`"position"=$`, `"version"=version + 1`},
+		},
+		{
+			name:      "with category",
+			version:   3,
+			columns:   map[string]interface{}{"category_id": uint(2), "status_id": uint(9), "completed": true},
+			wantSet:   []string{`"category_id"=$`, `"status_id"=$`, `"completed"=$`, `"completed_at"=COALESCE(completed_at, NOW())`, `"position"=$`, `"version"=version + 1`},
+			revisions: true,
+		},
+		{
+			name:    "stale version",
+			version: 2,
+			columns: map[string]interface{}{"category_id": uint(2)},
+			wantErr: "todo was modified by another request",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t, moveRows)
+			err := NewTodoRepository(db).MovePosition(5, tt.version, &after, nil, tt.columns)
+			updates := fake.Find(`UPDATE "todos"`)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("MovePosition error = %v; want %q", err, tt.wantErr)
+				}
+				if len(updates) != 0 {
+					t.Errorf("updates = %q; want none", updates)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MovePosition: %v", err)
+			}
+
+			// Position and fields are written by one update that bumps the version once
+			if len(updates) != 1 {
+				t.Fatalf("updates = %d; want 1 in %q", len(updates), fake.Queries())
+			}
+			for _, set := range tt.wantSet {
+				if !strings.Contains(updates[0].query, set) {
+					t.Errorf("update %q does not set %s", updates[0].query, set)
+				}
+			}
+			if got := len(fake.Find(`INSERT INTO "todo_revisions"`)) > 0; got != tt.revisions {
+				t.Errorf("revision recorded = %v; want %v", got, tt.revisions)
+			}
+			if len(fake.Find(`INSERT INTO "sync_changes"`)) == 0 {
+				t.Error("no sync change recorded")
+			}
+		})
+	}
+}
+
+func TestTodoGetBlockerIDsIncludesTrash(t *testing.T) {
+	db, fake := newFakeDB(t, func(query string, args []any) fakeRows {
+		return fakeRows{
+			columns: []string{"id", "todo_id", "blocked_by_id"},
+			values:  [][]driver.Value{{int64(1), int64(1), int64(5)}, {int64(2), int64(1), int64(6)}, {int64(3), int64(2), int64(1)}},
+		}
+	})
+	blockers, err := NewTodoRepository(db).GetBlockerIDs([]uint{1, 2})
+	if err != nil {
+		t.Fatalf("GetBlockerIDs: %v", err)
+	}
+	if want := map[uint][]uint{1: {5, 6}, 2: {1}}; !reflect.DeepEqual(blockers, want) {
+		t.Errorf("blockers = %v; want %v", blockers, want)
+	}
+
+	// Cycles through a trashed todo must be found, so its edges are not filtered out
+	for _, query := range fake.Queries() {
+		if strings.Contains(query, "deleted_at") {
+			t.Errorf("query %q filters deleted todos", query)
+		}
+	}
+}
+
+// bulkRows answers the lookups of a bulk change of todo 5, which has as many open blockers as given
+func bulkRows(openBlockers int64) func(query string, args []any) fakeRows {
+	return func(query string, args []any) fakeRows {
+		switch {
+		case strings.HasPrefix(query, `SELECT count(*) FROM "todos"`):
+			return fakeRows{columns: []string{"count"}, values: [][]driver.Value{{openBlockers}}}
+		case strings.HasPrefix(query, `SELECT * FROM "todos"`):
+			return fakeRows{columns: []string{"id", "version"}, values: [][]driver.Value{{int64(5), int64(3)}}}
+		}
+		return fakeRows{}
+	}
+}
+
+func TestTodoBulkApply(t *testing.T) {
+	statusID := uint(4)
+	categoryID := uint(2)
+	tests := []struct {
+		name         string
+		item         BulkItem
+		openBlockers int64
+		wantErr      string
+		wantSet      []string
+		wantArgs     []any
+	}{
+		{
+			name:         "complete blocked todo",
+			item:         BulkItem{ID: 5, StatusID: &statusID, Completed: true, RejectBlocked: true},
+			openBlockers: 1,
+			wantErr:      "todo has open blockers",
+		},
+		{
+			name:     "complete unblocked todo",
+			item:     BulkItem{ID: 5, StatusID: &statusID, Completed: true, RejectBlocked: true},
+			wantSet:  []string{`"status_id"=$`, `"completed"=$`},
+			wantArgs: []any{int64(4), true},
+		},
+		{
+			name:         "force complete blocked todo",
+			item:         BulkItem{ID: 5, StatusID: &statusID, Completed: true},
+			openBlockers: 1,
+			wantSet:      []string{`"status_id"=$`, `"completed"=$`},
+		},
+		{
+			name:     "set category",
+			item:     BulkItem{ID: 5, SetCategory: true, CategoryID: &categoryID},
+			wantSet:  []string{`"category_id"=$`},
+			wantArgs: []any{int64(2)},
+		},
+		{
+			name:     "clear category",
+			item:     BulkItem{ID: 5, SetCategory: true},
+			wantSet:  []string{`"category_id"=$`},
+			wantArgs: []any{nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t, bulkRows(tt.openBlockers))
+			results, err := NewTodoRepository(db).BulkApply([]BulkItem{tt.item})
+			if err != nil {
+				t.Fatalf("BulkApply: %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("results = %v; want 1", results)
+			}
+
+			updates := fake.Find(`UPDATE "todos"`)
+			if tt.wantErr != "" {
+				if results[0].Success || results[0].Error != tt.wantErr {
+					t.Fatalf("result = %+v; want error %q", results[0], tt.wantErr)
+				}
+				if len(updates) != 0 {
+					t.Errorf("updates = %q; want none", updates)
+				}
+				return
+			}
+			if !results[0].Success {
+				t.Fatalf("result = %+v; want success", results[0])
+			}
+
+			// The blockers are counted only after the todo is locked
+			queries := fake.Queries()
+			locked, counted := -1, -1
+			for i, query := range queries {
+				if strings.HasPrefix(query, `SELECT * FROM "todos"`) && strings.Contains(query, "FOR UPDATE") && locked < 0 {
+					locked = i
+				}
+				if strings.HasPrefix(query, `SELECT count(*) FROM "todos"`) {
+					counted = i
+				}
+			}
+			if tt.item.RejectBlocked && (counted < 0 || counted < locked) {
+				t.Errorf("blockers not counted after the lock in %q", queries)
+			}
+			if !tt.item.RejectBlocked && counted >= 0 {
+				t.Errorf("blockers counted without RejectBlocked in %q", queries)
+			}
+
+			if len(updates) != 1 {
+				t.Fatalf("updates = %d; want 1 in %q", len(updates), queries)
+			}
+			for _, set := range tt.wantSet {
+				if !strings.Contains(updates[0].query, set) {
+					t.Errorf("update %q does not set %s", updates[0].query, set)
+				}
+			}
+			args := normalizeArgs(updates[0].args)
+			for _, want := range tt.wantArgs {
+				found := false
+				for _, arg := range args {
+					if reflect.DeepEqual(arg, want) {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("update args %v do not contain %v", args, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTodoApplyFilters(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name     string
+		filters  TodoFilters
+		want     []string // parts of the WHERE clause
+		wantNot  []string
+		wantArgs []any
+		wantErr  string
+	}{
+		{
+			name:    "archived todos are hidden by default",
+			want:    []string{"archived_at IS NULL"},
+			wantNot: []string{"category_id", "priority", "due_date"},
+		},
+		{name: "include archived", filters: TodoFilters{IncludeArchived: true}, wantNot: []string{"archived_at"}},
+		{name: "filter query asking for archived todos", filters: TodoFilters{Query: "is:archived"}, wantNot: []string{"archived_at IS NULL"}},
+		{
+			name:     "categories and none",
+			filters:  TodoFilters{CategoryID: FilterValues{"none", "2", "3"}},
+			want:     []string{"(category_id IS NULL OR category_id IN ($1,$2))"},
+			wantArgs: []any{int64(2), int64(3)},
+		},
+		{name: "only none", filters: TodoFilters{CategoryID: FilterValues{"none"}}, want: []string{"category_id IS NULL"}, wantNot: []string{"category_id IN"}},
+		{name: "no valid category matches nothing", filters: TodoFilters{CategoryID: FilterValues{"work"}}, want: []string{"FALSE"}},
+		{
+			name:     "priorities",
+			filters:  TodoFilters{Priority: FilterValues{"high", "low"}},
+			want:     []string{"priority IN ($1,$2)"},
+			wantArgs: []any{"high", "low"},
+		},
+		{
+			name:     "due dates include the whole last day",
+			filters:  TodoFilters{DueAfter: "2026-01-01", DueBefore: "2026-01-31"},
+			want:     []string{"due_date >= $1", "due_date < $2"},
+			wantArgs: []any{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:     "exact due time",
+			filters:  TodoFilters{DueBefore: "2026-01-31T12:00:00Z"},
+			want:     []string{"due_date < $1"},
+			wantArgs: []any{time.Date(2026, 1, 31, 12, 0, 0, 1, time.UTC)},
+		},
+		{name: "without due date", filters: TodoFilters{HasDueDate: &no}, want: []string{"due_date IS NULL"}},
+		{name: "with due date", filters: TodoFilters{HasDueDate: &yes}, want: []string{"due_date IS NOT NULL"}},
+		{name: "overdue", filters: TodoFilters{Overdue: &yes}, want: []string{"completed = false AND due_date < $1"}, wantNot: []string{"NOT (completed"}},
+		{name: "not overdue keeps todos without due date", filters: TodoFilters{Overdue: &no}, want: []string{"NOT (completed = false AND due_date IS NOT NULL AND due_date < $1)"}},
+		{name: "hide blocked", filters: TodoFilters{HideBlocked: true}, want: []string{"NOT EXISTS ("}},
+		{name: "unvalidated date", filters: TodoFilters{DueAfter: "soon"}, wantErr: "invalid date filter soon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t, nil)
+			_, err := NewTodoRepository(db).ListIDs(tt.filters, 10)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ListIDs error = %v; want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ListIDs: %v", err)
+			}
+
+			selects := fake.Find(`SELECT "id" FROM "todos"`)
+			if len(selects) != 1 {
+				t.Fatalf("selects = %d; want 1 in %q", len(selects), fake.Queries())
+			}
+			query := selects[0].query
+			for _, part := range tt.want {
+				if !strings.Contains(query, part) {
+					t.Errorf("query %q does not contain %s", query, part)
+				}
+			}
+			for _, part := range tt.wantNot {
+				if strings.Contains(query, part) {
+					t.Errorf("query %q contains %s", query, part)
+				}
+			}
+			args := normalizeArgs(selects[0].args)
+			for i, want := range tt.wantArgs {
+				if i >= len(args) || !reflect.DeepEqual(args[i], want) {
+					t.Errorf("args = %v; want %v first", args, tt.wantArgs)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"todo-backend/internal/models"
+)
+
+// trashRepository implements TrashRepository interface
+type trashRepository struct {
+	db *gorm.DB
+}
+
+// NewTrashRepository creates a new trash repository
+func NewTrashRepository(db *gorm.DB) TrashRepository {
+	return &trashRepository{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the repository running its queries with ctx
+func (r *trashRepository) WithContext(ctx context.Context) TrashRepository {
+	return &trashRepository{db: r.db.WithContext(ctx)}
+}
+
+// ListTodos retrieves soft-deleted todos with pagination, most recently deleted first
+func (r *trashRepository) ListTodos(pagination PaginationParams) ([]models.Todo, PaginationResult, error) {
+	var todos []models.Todo
+	var total int64
+
+	// Deleted categories are preloaded as well, so the trash shows the original link
+	query := r.db.Unscoped().Model(&models.Todo{}).
+		Preload("Category", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Status").
+		Where("deleted_at IS NOT NULL")
+
+	// Count total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, PaginationResult{}, err
+	}
+
+	// Apply pagination
+	offset := pagination.GetOffset()
+	limit := pagination.GetLimit()
+	err := query.Order("deleted_at " + pagination.GetSortOrder()).Order("id DESC").
+		Offset(offset).Limit(limit).Find(&todos).Error
+	if err != nil {
+		return nil, PaginationResult{}, err
+	}
+
+	// Calculate pagination result
+	paginationResult := PaginationResult{
+		CurrentPage: pagination.Page,
+		PerPage:     limit,
+		Total:       total,
+	}
+	paginationResult.CalculateTotalPages()
+
+	return todos, paginationResult, nil
+}
+
+// ListCategories retrieves soft-deleted categories with pagination, most recently deleted first
+func (r *trashRepository) ListCategories(pagination PaginationParams) ([]models.Category, PaginationResult, error) {
+	var categories []models.Category
+	var total int64
+
+	query := r.db.Unscoped().Model(&models.Category{}).Where("deleted_at IS NOT NULL")
+
+	// Count total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, PaginationResult{}, err
+	}
+
+	// Apply pagination
+	offset := pagination.GetOffset()
+	limit := pagination.GetLimit()
+	err := query.Order("deleted_at " + pagination.GetSortOrder()).Order("id DESC").
+		Offset(offset).Limit(limit).Find(&categories).Error
+	if err != nil {
+		return nil, PaginationResult{}, err
+	}
+
+	// Calculate pagination result
+	paginationResult := PaginationResult{
+		CurrentPage: pagination.Page,
+		PerPage:     limit,
+		Total:       total,
+	}
+	paginationResult.CalculateTotalPages()
+
+	return categories, paginationResult, nil
+}
+
+// GetTodo retrieves a soft-deleted todo by its ID
+func (r *trashRepository) GetTodo(id uint) (*models.Todo, error) {
+	var todo models.Todo
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&todo, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found in trash")
+		}
+		return nil, err
+	}
+	return &todo, nil
+}
+
+// GetCategory retrieves a soft-deleted category by its ID
+func (r *trashRepository) GetCategory(id uint) (*models.Category, error) {
+	var category models.Category
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&category, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("category not found in trash")
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// RestoreTodo restores a soft-deleted todo
+// A deleted category of the todo is restored as well, so the todo keeps its category
+func (r *trashRepository) RestoreTodo(id uint) error {
+	todo, err := r.GetTodo(id)
+	if err != nil {
+		return err
+	}
+
+	return writeTransaction(r.db, func(tx *gorm.DB) error {
+		if todo.CategoryID != nil {
+			result := tx.Unscoped().Model(&models.Category{}).
+				Where("id = ? AND deleted_at IS NOT NULL", *todo.CategoryID).
+				Update("deleted_at", nil)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				if err := recordSyncChanges(tx, models.EntityCategory, *todo.CategoryID); err != nil {
+					return err
+				}
+			}
+		}
+		err := tx.Unscoped().Model(&models.Todo{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    nextVersion,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recordRevisions(tx, models.RevisionActionRestore, id)
+	})
+}
+
+// RestoreCategory restores a soft-deleted category
+func (r *trashRepository) RestoreCategory(id uint) error {
+	category, err := r.GetCategory(id)
+	if err != nil {
+		return err
+	}
+
+	return writeTransaction(r.db, func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Category{}).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return publishCategoryChange(tx, models.EventCategoryRestored, nil, category)
+	})
+}
+
+// PurgeTodo permanently deletes a soft-deleted todo, recording its last state in the audit log
+// and a sync change, so clients that synced it after the soft delete drop it as well
+// Comments, dependencies and attachment records are removed by the database cascade.
+// Returns the storage keys of the attachments, their blobs can only be released after the commit
+func (r *trashRepository) PurgeTodo(id uint) ([]string, error) {
+	var storageKeys []string
+	err := writeTransaction(r.db, func(tx *gorm.DB) error {
+		var todo models.Todo
+		err := tx.Unscoped().Preload("Tags").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at IS NOT NULL").First(&todo, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("todo not found in trash")
+			}
+			return err
+		}
+
+		err = tx.Model(&models.Attachment{}).
+			Where("todo_id = ?", id).
+			Distinct().
+			Pluck("storage_key", &storageKeys).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Delete(&models.Todo{}, id).Error; err != nil {
+			return err
+		}
+		if err := recordSyncChanges(tx, models.EntityTodo, id); err != nil {
+			return err
+		}
+		snapshot := models.NewTodoSnapshot(&todo)
+		return recordAuditChange(tx, models.EntityTodo, id, models.AuditActionTodoPurged, &snapshot, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return storageKeys, nil
+}
+
+// PurgeCategory permanently deletes a soft-deleted category, recording its last state in the audit log
+// and a sync change
+// Deleted todos that still link to it lose their category
+func (r *trashRepository) PurgeCategory(id uint) error {
+	return writeTransaction(r.db, func(tx *gorm.DB) error {
+		var category models.Category
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at IS NOT NULL").First(&category, id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("category not found in trash")
+			}
+			return err
+		}
+
+		if err := tx.Unscoped().Delete(&models.Category{}, id).Error; err != nil {
+			return err
+		}
+		if err := recordSyncChanges(tx, models.EntityCategory, id); err != nil {
+			return err
+		}
+		return recordAuditChange(tx, models.EntityCategory, id, models.AuditActionCategoryPurged, &category, nil)
+	})
+}
+
+// ListExpiredTodoIDs returns the IDs of todos deleted before the given time
+func (r *trashRepository) ListExpiredTodoIDs(deletedBefore time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Unscoped().Model(&models.Todo{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+		Order("id ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// PurgeExpiredCategories permanently deletes categories deleted before the given time,
+// recording their last state in the audit log and a sync change for each
+// Categories still linked to a deleted todo are kept until that todo is purged
+func (r *trashRepository) PurgeExpiredCategories(deletedBefore time.Time) (int64, error) {
+	var purged int64
+	err := writeTransaction(r.db, func(tx *gorm.DB) error {
+		var categories []models.Category
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Where("NOT EXISTS (SELECT 1 FROM todos t WHERE t.category_id = categories.id)").
+			Order("id ASC").
+			Find(&categories).Error
+		if err != nil || len(categories) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(categories))
+		audits := make([]models.AuditEvent, len(categories))
+		for i := range categories {
+			ids[i] = categories[i].ID
+			if audits[i], err = auditEvent(models.EntityCategory, categories[i].ID, models.AuditActionCategoryPurged, &categories[i], nil); err != nil {
+				return err
+			}
+		}
+		result := tx.Unscoped().Where("id IN ?", ids).Delete(&models.Category{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged = result.RowsAffected
+		if err := recordSyncChanges(tx, models.EntityCategory, ids...); err != nil {
+			return err
+		}
+		return recordAudit(tx, audits...)
+	})
+	return purged, err
+}
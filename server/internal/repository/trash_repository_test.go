@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-backend/internal/models"
+)
+
+// trashRows answers the trash lookups with deleted rows of the given IDs
+func trashRows(ids ...int64) func(query string, args []any) fakeRows {
+	deletedAt := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	return func(query string, args []any) fakeRows {
+		if strings.Contains(query, `"storage_key" FROM "attachments"`) {
+			return fakeRows{columns: []string{"storage_key"}, values: [][]driver.Value{{"blob-a"}, {"blob-b"}}}
+		}
+		if !strings.HasPrefix(query, "SELECT") || !strings.Contains(query, "deleted_at IS NOT NULL") {
+			return fakeRows{}
+		}
+		rows := fakeRows{columns: []string{"id", "deleted_at"}}
+		for _, id := range ids {
+			rows.values = append(rows.values, []driver.Value{id, deletedAt})
+		}
+		return rows
+	}
+}
+
+func TestTrashPurge(t *testing.T) {
+	tests := []struct {
+		name        string
+		ids         []int64
+		purge       func(r TrashRepository) ([]string, error)
+		entityType  string
+		storageKeys []string
+	}{
+		{
+			name:        "todo",
+			ids:         []int64{5},
+			purge:       func(r TrashRepository) ([]string, error) { return r.PurgeTodo(5) },
+			entityType:  models.EntityTodo,
+			storageKeys: []string{"blob-a", "blob-b"},
+		},
+		{
+			name:       "category",
+			ids:        []int64{3},
+			purge:      func(r TrashRepository) ([]string, error) { return nil, r.PurgeCategory(3) },
+			entityType: models.EntityCategory,
+		},
+		{
+			name: "expired categories",
+			ids:  []int64{3, 4},
+			purge: func(r TrashRepository) ([]string, error) {
+				_, err := r.PurgeExpiredCategories(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+				return nil, err
+			},
+			entityType: models.EntityCategory,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := newFakeDB(t, trashRows(tt.ids...))
+			storageKeys, err := tt.purge(NewTrashRepository(db))
+			if err != nil {
+				t.Fatalf("purge: %v", err)
+			}
+			if !reflect.DeepEqual(storageKeys, tt.storageKeys) {
+				t.Errorf("storage keys = %v; want %v", storageKeys, tt.storageKeys)
+			}
+
+			queries := fake.Queries()
+			if len(queries) < 2 || queries[0] != "BEGIN" || !strings.Contains(queries[1], "pg_advisory_xact_lock") {
+				t.Fatalf("statements = %q; want the sync lock first", queries)
+			}
+			if last := queries[len(queries)-1]; last != "COMMIT" {
+				t.Fatalf("last statement = %q; want COMMIT", last)
+			}
+
+			inserts := fake.Find(`INSERT INTO "sync_changes"`)
+			if len(inserts) != 1 {
+				t.Fatalf("sync change inserts = %d; want 1 in %q", len(inserts), queries)
+			}
+			var got []any
+			for i := 0; i+1 < len(inserts[0].args); i += 3 {
+				got = append(got, inserts[0].args[i], inserts[0].args[i+1])
+			}
+			var want []any
+			for _, id := range tt.ids {
+				want = append(want, tt.entityType, int64(id))
+			}
+			if !reflect.DeepEqual(normalizeArgs(got), want) {
+				t.Errorf("sync changes = %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+// normalizeArgs converts unsigned integer arguments, pointers included, to int64 for comparisons
+func normalizeArgs(args []any) []any {
+	normalized := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case uint:
+			normalized[i] = int64(v)
+		case uint64:
+			normalized[i] = int64(v)
+		case *uint:
+			normalized[i] = nil
+			if v != nil {
+				normalized[i] = int64(*v)
+			}
+		default:
+			normalized[i] = arg
+		}
+	}
+	return normalized
+}
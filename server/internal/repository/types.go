@@ -1,5 +1,13 @@
 package repository
 
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"todo-backend/internal/models"
+)
+
 // PaginationParams represents pagination parameters
 type PaginationParams struct {
 	Page     int `json:"page" form:"page" binding:"omitempty,min=1"`
@@ -52,13 +60,149 @@ func (p *PaginationResult) CalculateTotalPages() {
 
 // TodoFilters represents filters for todo queries
 type TodoFilters struct {
-	Search     string `json:"search" form:"search"`
-	Completed  *bool  `json:"completed" form:"completed"`
-	CategoryID *uint  `json:"category_id" form:"category_id"`
-	Priority   string `json:"priority" form:"priority" binding:"omitempty,oneof=low medium high"`
+	Search    string `json:"search" form:"search"`
+	Completed *bool  `json:"completed" form:"completed"`
+	// CategoryID matches any of the listed category IDs, "none" matches todos without a category
+	CategoryID FilterValues `json:"category_id" form:"category_id" collection_format:"csv"`
+	// Priority matches any of the listed priorities
+	Priority FilterValues `json:"priority" form:"priority" collection_format:"csv"`
+	// Status filters by workflow status key, e.g. "in_progress"
+	Status string `json:"status" form:"status"`
+	// HideBlocked excludes todos that still have open blockers
+	HideBlocked bool `json:"hide_blocked" form:"hide_blocked"`
+	// IncludeArchived also returns archived todos, which are hidden by default
+	IncludeArchived bool `json:"include_archived" form:"include_archived"`
+	// DueAfter and DueBefore limit due dates to a range including both ends, see ParseFilterTime for their format
+	DueAfter  string `json:"due_after" form:"due_after"`
+	DueBefore string `json:"due_before" form:"due_before"`
+	// HasDueDate selects todos with or without a due date
+	HasDueDate *bool `json:"has_due_date" form:"has_due_date"`
+	// Overdue selects open todos due before now, or all other todos when false
+	Overdue *bool `json:"overdue" form:"overdue"`
+	// CreatedAfter and UpdatedAfter select todos created or changed on or after a time
+	CreatedAfter string `json:"created_after" form:"created_after"`
+	UpdatedAfter string `json:"updated_after" form:"updated_after"`
+	// Query is a filter query such as `priority:high,medium due:<7d -completed`, see package todoquery
+	Query string `json:"q" form:"q"`
+}
+
+// FilterValueNone is the category_id filter value matching todos without a category
+const FilterValueNone = "none"
+
+// FilterValues is a list of filter values
+// Query parameters are repeated or separated by commas; JSON takes a single string or number, or an array of them
+type FilterValues []string
+
+// UnmarshalJSON reads a single value or an array of values, numbers are kept in their decimal form
+func (v *FilterValues) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		raw = []json.RawMessage{data}
+	}
+
+	values := FilterValues{}
+	for _, item := range raw {
+		if string(item) == "null" {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(item, &value); err != nil {
+			var number json.Number
+			if err := json.Unmarshal(item, &number); err != nil {
+				return errors.New("filter values must be strings or numbers")
+			}
+			value = number.String()
+		}
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		values = nil
+	}
+	*v = values
+	return nil
+}
+
+// AuditFilters represents filters for audit event queries
+type AuditFilters struct {
+	Actor      string     `json:"actor" form:"actor"`
+	EntityType string     `json:"entity_type" form:"entity_type" binding:"omitempty,oneof=todo category"`
+	EntityID   *uint      `json:"entity_id" form:"entity_id"`
+	Action     string     `json:"action" form:"action"`
+	RequestID  string     `json:"request_id" form:"request_id"`
+	// From and To limit events to a time range, both RFC 3339
+	From *time.Time `json:"from" form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   *time.Time `json:"to" form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// BulkItem is the change a bulk operation applies to one todo
+// The service prepares one item per selected todo; unset fields are left unchanged
+type BulkItem struct {
+	ID     uint
+	Delete bool
+
+	// StatusID moves the todo to a workflow status, Completed is the status' done flag
+	StatusID  *uint
+	Completed bool
+	// RejectBlocked fails the item when the todo has open blockers, checked while the todo is locked
+	RejectBlocked bool
+
+	Priority    models.Priority
+	SetCategory bool
+	CategoryID  *uint
+	SetDueDate  bool
+	DueDate     *time.Time
+	AddTagID    uint
+}
+
+// BulkItemResult is the outcome of a bulk operation for one todo
+type BulkItemResult struct {
+	ID      uint   `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
 }
 
 // CategoryFilters represents filters for category queries
 type CategoryFilters struct {
 	Search string `json:"search" form:"search"`
-}
\ No newline at end of file
+}
+
+// SyncChangeSet holds the current state of todos and categories changed after a sync token
+// Deleted and permanently deleted items are listed as tombstones by ID
+type SyncChangeSet struct {
+	Token              uint64            `json:"-"`
+	HasMore            bool              `json:"has_more"`
+	Todos              []models.Todo     `json:"todos"`
+	Categories         []models.Category `json:"categories"`
+	DeletedTodoIDs     []uint            `json:"deleted_todo_ids"`
+	DeletedCategoryIDs []uint            `json:"deleted_category_ids"`
+}
+
+// TodoExportRow is a todo with the names of its category, status and tags, as read for exports
+type TodoExportRow struct {
+	ID            uint            `json:"id"`
+	Title         string          `json:"title"`
+	Description   string          `json:"description"`
+	Completed     bool            `json:"completed"`
+	Priority      models.Priority `json:"priority"`
+	DueDate       *time.Time      `json:"due_date"`
+	Recurrence    string          `json:"recurrence"`
+	CategoryID    *uint           `json:"category_id"`
+	CategoryName  *string         `json:"category_name"`
+	CategoryColor *string         `json:"category_color"`
+	Status        *string         `json:"status"`
+	Tags          []string        `json:"tags" gorm:"-"`
+	TagsJSON      string          `json:"-" gorm:"column:tags_json"`
+	Position      string          `json:"position"`
+	CompletedAt   *time.Time      `json:"completed_at"`
+	ArchivedAt    *time.Time      `json:"archived_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// ImportItem is a todo to create in an import
+// CategoryName links the todo to a category created by the same import, its CategoryID is set once created
+type ImportItem struct {
+	Todo         *models.Todo
+	CategoryName string
+	Tags         []string
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"todo-backend/internal/models"
+)
+
+// webhookRepository implements WebhookRepository interface
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+// CreateSubscription creates a new webhook subscription
+func (r *webhookRepository) CreateSubscription(subscription *models.WebhookSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+// GetSubscription retrieves a webhook subscription by its ID
+func (r *webhookRepository) GetSubscription(id uint) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	err := r.db.First(&subscription, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("webhook subscription not found")
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// ListSubscriptions retrieves all webhook subscriptions ordered by ID
+func (r *webhookRepository) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := r.db.Order("id ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// UpdateSubscription updates an existing webhook subscription
+func (r *webhookRepository) UpdateSubscription(subscription *models.WebhookSubscription) error {
+	result := r.db.Model(subscription).Select("url", "secret", "events", "description", "disabled").Updates(subscription)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("webhook subscription not found")
+	}
+	return nil
+}
+
+// DeleteSubscription deletes a webhook subscription together with its deliveries
+func (r *webhookRepository) DeleteSubscription(id uint) error {
+	result := r.db.Delete(&models.WebhookSubscription{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("webhook subscription not found")
+	}
+	return nil
+}
+
+// ListDeliveries retrieves the deliveries of a subscription with pagination, newest first
+func (r *webhookRepository) ListDeliveries(subscriptionID uint, pagination PaginationParams) ([]models.WebhookDelivery, PaginationResult, error) {
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := r.db.Model(&models.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+
+	// Count total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, PaginationResult{}, err
+	}
+
+	// Apply pagination
+	offset := pagination.GetOffset()
+	limit := pagination.GetLimit()
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, PaginationResult{}, err
+	}
+
+	// Calculate pagination result
+	paginationResult := PaginationResult{
+		CurrentPage: pagination.Page,
+		PerPage:     limit,
+		Total:       total,
+	}
+	paginationResult.CalculateTotalPages()
+
+	return deliveries, paginationResult, nil
+}
+
+// GetDelivery retrieves a delivery of a subscription by its ID
+func (r *webhookRepository) GetDelivery(subscriptionID, id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.Where("subscription_id = ?", subscriptionID).First(&delivery, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("webhook delivery not found")
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// CreateDelivery queues a delivery
+func (r *webhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+// ClaimDueDeliveries locks up to limit pending deliveries that are due and hides them from other
+// workers for the lease duration, so several server instances can share the queue
+func (r *webhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryStatusPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", deliveryIDs(deliveries)).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+
+	// Load the subscriptions, they may have been changed since the deliveries were queued
+	var claimed []models.WebhookDelivery
+	if err := r.db.Preload("Subscription").Order("id ASC").Find(&claimed, deliveryIDs(deliveries)).Error; err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// SaveDeliveryResult stores the outcome of a delivery attempt
+func (r *webhookRepository) SaveDeliveryResult(delivery *models.WebhookDelivery) error {
+	return r.db.Model(delivery).Select(
+		"status", "attempts", "next_attempt_at", "last_attempt_at", "delivered_at",
+		"response_status", "response_body", "error",
+	).Updates(delivery).Error
+}
+
+// deliveryIDs returns the IDs of deliveries
+func deliveryIDs(deliveries []models.WebhookDelivery) []uint {
+	ids := make([]uint, len(deliveries))
+	for i := range deliveries {
+		ids[i] = deliveries[i].ID
+	}
+	return ids
+}
+
+// enqueueWebhookDeliveries queues a delivery of each event for every enabled subscription selecting its type
+func enqueueWebhookDeliveries(tx *gorm.DB, events []models.ChangeEvent, payloads [][]byte) error {
+	var subscriptions []models.WebhookSubscription
+	if err := tx.Where("disabled = ?", false).Find(&subscriptions).Error; err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	var deliveries []models.WebhookDelivery
+	for i, event := range events {
+		for _, subscription := range subscriptions {
+			if !subscription.Subscribes(event.Type) {
+				continue
+			}
+			deliveries = append(deliveries, models.WebhookDelivery{
+				SubscriptionID: subscription.ID,
+				EventID:        event.ID,
+				EventType:      event.Type,
+				Payload:        payloads[i],
+				Status:         models.DeliveryStatusPending,
+				NextAttemptAt:  event.CreatedAt,
+			})
+		}
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return tx.Create(&deliveries).Error
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"todo-backend/internal/models"
+)
+
+// workflowRepository implements WorkflowRepository interface
+type workflowRepository struct {
+	db *gorm.DB
+}
+
+// NewWorkflowRepository creates a new workflow repository
+func NewWorkflowRepository(db *gorm.DB) WorkflowRepository {
+	return &workflowRepository{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the repository running its queries with ctx
+func (r *workflowRepository) WithContext(ctx context.Context) WorkflowRepository {
+	return &workflowRepository{db: r.db.WithContext(ctx)}
+}
+
+// ListStatuses retrieves the statuses of a category workflow, or of the default workflow when categoryID is nil
+func (r *workflowRepository) ListStatuses(categoryID *uint) ([]models.WorkflowStatus, error) {
+	var statuses []models.WorkflowStatus
+
+	query := r.db.Model(&models.WorkflowStatus{})
+	if categoryID != nil {
+		query = query.Where("category_id = ?", *categoryID)
+	} else {
+		query = query.Where("category_id IS NULL")
+	}
+
+	err := query.Order("position ASC, id ASC").Find(&statuses).Error
+	return statuses, err
+}
+
+// GetStatusByID retrieves a workflow status by its ID
+func (r *workflowRepository) GetStatusByID(id uint) (*models.WorkflowStatus, error) {
+	var status models.WorkflowStatus
+	err := r.db.First(&status, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("status not found")
+		}
+		return nil, err
+	}
+	return &status, nil
+}
+
+// CreateStatuses creates several workflow statuses in one transaction
+func (r *workflowRepository) CreateStatuses(statuses []models.WorkflowStatus) error {
+	if len(statuses) == 0 {
+		return nil
+	}
+
+	if err := r.db.Create(&statuses).Error; err != nil {
+		// Handle unique constraint violation
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "UNIQUE constraint") {
+			return errors.New("status key already exists")
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateStatus updates an existing workflow status
+func (r *workflowRepository) UpdateStatus(status *models.WorkflowStatus) error {
+	// Check if status exists
+	if _, err := r.GetStatusByID(status.ID); err != nil {
+		return err
+	}
+
+	// Update the status and keep the completion flag of its todos in sync
+	err := writeTransaction(r.db, func(tx *gorm.DB) error {
+		if err := tx.Save(status).Error; err != nil {
+			return err
+		}
+		var ids []uint
+		err := tx.Model(&models.Todo{}).
+			Where("status_id = ? AND completed <> ?", status.ID, status.IsDone).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		columns := completionColumns(status.IsDone)
+		columns["version"] = nextVersion
+		if err := tx.Model(&models.Todo{}).Where("id IN ?", ids).Updates(columns).Error; err != nil {
+			return err
+		}
+		return recordRevisions(tx, models.RevisionActionUpdate, ids...)
+	})
+	if err != nil {
+		// Handle unique constraint violation
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "UNIQUE constraint") {
+			return errors.New("status key already exists")
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteStatus deletes a workflow status that is not used by any todo
+func (r *workflowRepository) DeleteStatus(id uint) error {
+	// Check if status exists
+	status, err := r.GetStatusByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Check if status has associated todos
+	var todoCount int64
+	if err := r.db.Model(&models.Todo{}).Where("status_id = ?", id).Count(&todoCount).Error; err != nil {
+		return err
+	}
+	if todoCount > 0 {
+		return errors.New("cannot delete status with associated todos")
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("from_status_id = ? OR to_status_id = ?", id, id).Delete(&models.StatusTransition{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(status).Error
+	})
+}
+
+// ListTransitions retrieves the transitions leaving any of the given statuses
+func (r *workflowRepository) ListTransitions(fromStatusIDs []uint) ([]models.StatusTransition, error) {
+	var transitions []models.StatusTransition
+	if len(fromStatusIDs) == 0 {
+		return transitions, nil
+	}
+
+	err := r.db.Where("from_status_id IN ?", fromStatusIDs).Order("from_status_id ASC, to_status_id ASC").Find(&transitions).Error
+	return transitions, err
+}
+
+// CreateTransition creates an allowed transition between two statuses
+func (r *workflowRepository) CreateTransition(transition *models.StatusTransition) error {
+	if err := r.db.Create(transition).Error; err != nil {
+		// Handle unique constraint violation
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "UNIQUE constraint") {
+			return errors.New("transition already exists")
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteTransition deletes a transition by ID
+func (r *workflowRepository) DeleteTransition(id uint) error {
+	result := r.db.Delete(&models.StatusTransition{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("transition not found")
+	}
+	return nil
+}
+
+// BackfillTodoStatuses assigns a status to todos that do not have one yet
+// Todos are mapped by their completion flag, including soft-deleted ones
+func (r *workflowRepository) BackfillTodoStatuses(openStatusID, doneStatusID uint) error {
+	return r.db.Exec(
+		"UPDATE todos SET status_id = CASE WHEN completed THEN ? ELSE ? END WHERE status_id IS NULL",
+		doneStatusID, openStatusID,
+	).Error
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/storage"
+)
+
+// attachmentService implements AttachmentService interface
+type attachmentService struct {
+	attachmentRepo repository.AttachmentRepository
+	storage        storage.Storage
+	maxSize        int64
+	allowedTypes   []string
+}
+
+// NewAttachmentService creates a new attachment service
+// allowedTypes may contain exact MIME types or wildcards such as "image/*"
+func NewAttachmentService(attachmentRepo repository.AttachmentRepository, store storage.Storage, maxSize int64, allowedTypes []string) AttachmentService {
+	return &attachmentService{
+		attachmentRepo: attachmentRepo,
+		storage:        store,
+		maxSize:        maxSize,
+		allowedTypes:   allowedTypes,
+	}
+}
+
+// WithContext returns a copy of the service whose changes are recorded with the audit info of ctx
+func (s *attachmentService) WithContext(ctx context.Context) AttachmentService {
+	scoped := *s
+	scoped.attachmentRepo = s.attachmentRepo.WithContext(ctx)
+	return &scoped
+}
+
+// UploadAttachment validates and stores an uploaded file for a todo
+// The content is spooled to a temporary file while its checksum is computed,
+// and the blob is only uploaded when no identical blob is stored yet. The check, the upload
+// and the record are made with the blob locked, so a concurrent release cannot delete it in between
+func (s *attachmentService) UploadAttachment(todoID uint, fileName string, content io.Reader) (*models.Attachment, error) {
+	if todoID == 0 {
+		return nil, errors.New("invalid todo ID")
+	}
+
+	fileName = cleanFileName(fileName)
+	if fileName == "" {
+		return nil, errors.New("file name is required")
+	}
+
+	// Spool the upload to disk, enforcing the size limit
+	tmp, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), io.LimitReader(content, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, errors.New("file cannot be empty")
+	}
+	if size > s.maxSize {
+		return nil, fmt.Errorf("file exceeds maximum size of %d bytes", s.maxSize)
+	}
+
+	// Detect the content type from the file content rather than trusting the client
+	sniff := make([]byte, 512)
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	n, err := io.ReadFull(tmp, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	contentType := http.DetectContentType(sniff[:n])
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	if !s.isAllowedType(mediaType) {
+		return nil, fmt.Errorf("file type %s is not allowed", mediaType)
+	}
+
+	// Store the blob unless an identical one already exists
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+	storageKey := blobKey(checksum)
+
+	attachment := &models.Attachment{
+		TodoID:      todoID,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		Checksum:    checksum,
+		StorageKey:  storageKey,
+	}
+	stored := false
+	storeBlob := func() error {
+		exists, err := s.storage.Exists(storageKey)
+		if err != nil || exists {
+			return err
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := s.storage.Put(storageKey, tmp, size, contentType); err != nil {
+			return err
+		}
+		stored = true
+		return nil
+	}
+	if err := s.attachmentRepo.Create(attachment, storeBlob); err != nil {
+		if stored {
+			s.releaseBlob(storageKey)
+		}
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// ListAttachments retrieves all attachments of a todo
+func (s *attachmentService) ListAttachments(todoID uint) ([]models.Attachment, error) {
+	if todoID == 0 {
+		return nil, errors.New("invalid todo ID")
+	}
+	return s.attachmentRepo.ListByTodo(todoID)
+}
+
+// OpenAttachment retrieves an attachment and opens its stored content for reading
+func (s *attachmentService) OpenAttachment(todoID, id uint) (*models.Attachment, storage.Object, error) {
+	if todoID == 0 {
+		return nil, nil, errors.New("invalid todo ID")
+	}
+	if id == 0 {
+		return nil, nil, errors.New("invalid attachment ID")
+	}
+
+	attachment, err := s.attachmentRepo.GetByID(todoID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	object, err := s.storage.Open(attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, errors.New("attachment content not found")
+		}
+		return nil, nil, err
+	}
+
+	return attachment, object, nil
+}
+
+// DeleteAttachment removes an attachment and its blob when no longer referenced
+func (s *attachmentService) DeleteAttachment(todoID, id uint) error {
+	if todoID == 0 {
+		return errors.New("invalid todo ID")
+	}
+	if id == 0 {
+		return errors.New("invalid attachment ID")
+	}
+
+	storageKey, err := s.attachmentRepo.Delete(todoID, id)
+	if err != nil {
+		return err
+	}
+
+	s.releaseBlob(storageKey)
+	return nil
+}
+
+// ReleaseBlobs deletes the stored blobs of purged attachments that no attachment references anymore
+// Failures are logged only, like for a single attachment
+func (s *attachmentService) ReleaseBlobs(storageKeys []string) {
+	for _, storageKey := range storageKeys {
+		s.releaseBlob(storageKey)
+	}
+}
+
+// releaseBlob deletes a stored blob once no attachment references it anymore
+// Failures are logged only, an orphaned blob does not affect the API
+func (s *attachmentService) releaseBlob(storageKey string) {
+	err := s.attachmentRepo.ReleaseBlob(storageKey, func() error {
+		return s.storage.Delete(storageKey)
+	})
+	if err != nil {
+		log.Printf("Failed to release blob %s: %v", storageKey, err)
+	}
+}
+
+// isAllowedType checks a media type against the configured allow list
+func (s *attachmentService) isAllowedType(mediaType string) bool {
+	for _, allowed := range s.allowedTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == "*/*" || allowed == mediaType {
+			return true
+		}
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// blobKey builds the content-addressed storage key for a checksum
+func blobKey(checksum string) string {
+	return "sha256/" + checksum[:2] + "/" + checksum[2:4] + "/" + checksum
+}
+
+// cleanFileName strips directories and control characters from a client supplied file name
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if len(name) > 255 {
+		ext := filepath.Ext(name)
+		if len(ext) > 20 {
+			ext = ""
+		}
+		name = strings.ToValidUTF8(name[:255-len(ext)], "") + ext
+	}
+	return name
+}
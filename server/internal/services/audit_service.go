@@ -0,0 +1,50 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+)
+
+// auditService implements AuditService interface
+type auditService struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// ListEvents retrieves audit events with pagination and filtering, newest first
+func (s *auditService) ListEvents(filters repository.AuditFilters, pagination repository.PaginationParams) ([]models.AuditEvent, repository.PaginationResult, error) {
+	if err := validateAuditFilters(filters); err != nil {
+		return nil, repository.PaginationResult{}, err
+	}
+	return s.auditRepo.List(filters, pagination)
+}
+
+// ExportEvents writes the audit events matching the filters to w as JSON Lines, oldest first
+func (s *auditService) ExportEvents(filters repository.AuditFilters, w io.Writer) error {
+	if err := validateAuditFilters(filters); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	return s.auditRepo.Each(filters, func(event *models.AuditEvent) error {
+		return encoder.Encode(event)
+	})
+}
+
+// validateAuditFilters checks that the time range of audit filters is not empty
+func validateAuditFilters(filters repository.AuditFilters) error {
+	if filters.From != nil && filters.To != nil && !filters.From.Before(*filters.To) {
+		return errors.New("invalid time range: from must be before to")
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/ical"
+)
+
+// CalDAVInbox is the name of the calendar holding todos without a category
+const CalDAVInbox = "inbox"
+
+// CalDAVCalendar is a calendar collection: a category, or the inbox for todos without one
+type CalDAVCalendar struct {
+	Name        string
+	DisplayName string
+	Color       string
+	CategoryID  *uint
+}
+
+// CalDAVObject is a todo served as a calendar object resource
+type CalDAVObject struct {
+	Name string
+	Data []byte
+	Todo *models.Todo
+}
+
+// caldavService implements CalDAVService interface
+type caldavService struct {
+	caldavRepo   repository.CalDAVRepository
+	todoRepo     repository.TodoRepository
+	categoryRepo repository.CategoryRepository
+	syncRepo     repository.SyncRepository
+	todoService  TodoService
+}
+
+// NewCalDAVService creates a new CalDAV service
+// Changes are made through the todo service, so they are validated like requests to the todo API
+func NewCalDAVService(caldavRepo repository.CalDAVRepository, todoRepo repository.TodoRepository, categoryRepo repository.CategoryRepository, syncRepo repository.SyncRepository, todoService TodoService) CalDAVService {
+	return &caldavService{
+		caldavRepo:   caldavRepo,
+		todoRepo:     todoRepo,
+		categoryRepo: categoryRepo,
+		syncRepo:     syncRepo,
+		todoService:  todoService,
+	}
+}
+
+// WithContext returns a copy of the service whose changes are recorded with the audit info of ctx
+func (s *caldavService) WithContext(ctx context.Context) CalDAVService {
+	scoped := *s
+	scoped.caldavRepo = s.caldavRepo.WithContext(ctx)
+	scoped.todoRepo = s.todoRepo.WithContext(ctx)
+	scoped.categoryRepo = s.categoryRepo.WithContext(ctx)
+	scoped.syncRepo = s.syncRepo.WithContext(ctx)
+	scoped.todoService = s.todoService.WithContext(ctx)
+	return &scoped
+}
+
+// Calendars returns the inbox followed by a calendar for every category
+func (s *caldavService) Calendars() ([]CalDAVCalendar, error) {
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	calendars := []CalDAVCalendar{{Name: CalDAVInbox, DisplayName: "Inbox"}}
+	for i := range categories {
+		calendars = append(calendars, categoryCalendar(&categories[i]))
+	}
+	return calendars, nil
+}
+
+// Calendar returns the calendar with the given name
+func (s *caldavService) Calendar(name string) (*CalDAVCalendar, error) {
+	if name == CalDAVInbox {
+		return &CalDAVCalendar{Name: CalDAVInbox, DisplayName: "Inbox"}, nil
+	}
+
+	id, err := strconv.ParseUint(name, 10, 32)
+	if err != nil || id == 0 {
+		return nil, errors.New("calendar not found")
+	}
+	category, err := s.categoryRepo.GetByID(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, errors.New("calendar not found")
+		}
+		return nil, err
+	}
+	calendar := categoryCalendar(category)
+	return &calendar, nil
+}
+
+// CTag returns a tag that changes whenever a todo or category changes
+// Clients compare it to skip listing calendars that did not change
+func (s *caldavService) CTag() (string, error) {
+	token, err := s.syncRepo.LatestToken()
+	if err != nil {
+		return "", err
+	}
+	return `"` + strconv.FormatUint(token, 10) + `"`, nil
+}
+
+// Objects returns the todos of a calendar that are not archived
+func (s *caldavService) Objects(calendar string) ([]CalDAVObject, error) {
+	cal, err := s.Calendar(calendar)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := s.caldavRepo.Todos(cal.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(todos))
+	for i := range todos {
+		ids[i] = todos[i].ID
+	}
+	resources, err := s.caldavRepo.Resources(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]CalDAVObject, 0, len(todos))
+	for i := range todos {
+		resource, ok := resources[todos[i].ID]
+		objects = append(objects, caldavObject(&todos[i], resource, ok))
+	}
+	return objects, nil
+}
+
+// Object returns a todo of a calendar by its resource name
+func (s *caldavService) Object(calendar, name string) (*CalDAVObject, error) {
+	cal, err := s.Calendar(calendar)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, resource, err := s.findObject(name)
+	if err != nil {
+		return nil, err
+	}
+	if !inCalendar(todo, cal) {
+		return nil, errors.New("calendar object not found")
+	}
+	object := caldavObject(todo, *resource, resource.ID != 0)
+	return &object, nil
+}
+
+// PutObject creates or replaces a todo from a VTODO, returning whether it was created
+// A version other than 0 must match the todo's; createOnly refuses to replace an existing todo.
+// A todo of another calendar with the same resource name is moved, as clients move todos by
+// putting them into the new calendar before deleting them from the old one
+func (s *caldavService) PutObject(calendar, name string, data []byte, version uint, createOnly bool) (bool, error) {
+	cal, err := s.Calendar(calendar)
+	if err != nil {
+		return false, err
+	}
+	if name == "" || len(name) > 255 || strings.Contains(name, "/") {
+		return false, errors.New("invalid calendar object name")
+	}
+
+	fields, uid, err := decodeVTodo(data)
+	if err != nil {
+		return false, err
+	}
+	fields.CategoryID = cal.CategoryID
+
+	existing, _, err := s.findObject(name)
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return false, err
+	}
+
+	// Create a new todo under the client's resource name and UID
+	// Default names belong to the todos with those IDs, a new todo would get another ID
+	if existing == nil {
+		if version != 0 {
+			return false, errors.New("calendar object not found")
+		}
+		if _, ok := defaultObjectID(name); ok {
+			return false, errors.New("invalid calendar object name: todo-<id>.ics names are reserved for existing todos")
+		}
+		if err := s.todoService.PrepareTodo(fields); err != nil {
+			return false, err
+		}
+		resource := &models.CalDAVResource{Name: name, UID: uid}
+		return true, s.caldavRepo.CreateTodo(fields, resource)
+	}
+	if createOnly {
+		return false, errors.New("calendar object already exists")
+	}
+
+	// Replace the fields a VTODO carries, keeping the rest of the todo
+	todo := *existing
+	todo.Category = nil
+	todo.Status = nil
+	todo.Tags = nil
+	todo.Title = fields.Title
+	todo.Description = fields.Description
+	todo.Priority = fields.Priority
+	todo.DueDate = fields.DueDate
+	todo.Recurrence = fields.Recurrence
+	todo.Completed = fields.Completed
+	todo.CategoryID = fields.CategoryID
+	todo.Version = version
+	return false, s.todoService.UpdateTodo(&todo)
+}
+
+// DeleteObject deletes a todo of a calendar by its resource name
+// A version other than 0 must match the todo's
+func (s *caldavService) DeleteObject(calendar, name string, version uint) error {
+	object, err := s.Object(calendar, name)
+	if err != nil {
+		return err
+	}
+	return s.todoService.DeleteTodo(object.Todo.ID, version)
+}
+
+// findObject finds the todo with a resource name, either chosen by a client or todo-<id>.ics
+// Returns the client-chosen resource, or an empty one for todos served under their default name
+func (s *caldavService) findObject(name string) (*models.Todo, *models.CalDAVResource, error) {
+	resource, err := s.caldavRepo.ResourceByName(name)
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return nil, nil, err
+	}
+
+	// A default name always resolves to the todo with that ID
+	id, isDefault := defaultObjectID(name)
+	if resource != nil && (!isDefault || resource.TodoID == id) {
+		id = resource.TodoID
+	} else {
+		resource = &models.CalDAVResource{}
+		if !isDefault {
+			return nil, nil, errors.New("calendar object not found")
+		}
+	}
+
+	todo, err := s.todoRepo.GetByID(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, nil, errors.New("calendar object not found")
+		}
+		return nil, nil, err
+	}
+	if todo.ArchivedAt != nil {
+		return nil, nil, errors.New("calendar object not found")
+	}
+	return todo, resource, nil
+}
+
+// categoryCalendar returns the calendar of a category
+func categoryCalendar(category *models.Category) CalDAVCalendar {
+	id := category.ID
+	return CalDAVCalendar{
+		Name:        strconv.FormatUint(uint64(category.ID), 10),
+		DisplayName: category.Name,
+		Color:       category.Color,
+		CategoryID:  &id,
+	}
+}
+
+// inCalendar reports whether a todo belongs to a calendar
+func inCalendar(todo *models.Todo, cal *CalDAVCalendar) bool {
+	if cal.CategoryID == nil || todo.CategoryID == nil {
+		return cal.CategoryID == nil && todo.CategoryID == nil
+	}
+	return *cal.CategoryID == *todo.CategoryID
+}
+
+// caldavObject renders a todo as an iCalendar object under its resource name and UID
+// The todo's update time is its DTSTAMP, so the data only changes when the todo does
+func caldavObject(todo *models.Todo, resource models.CalDAVResource, ok bool) CalDAVObject {
+	name, uid := defaultObjectName(todo.ID), todoUID(todo.ID)
+	if ok {
+		name, uid = resource.Name, resource.UID
+	}
+
+	// The calendar stands for the category, so the VTODO doesn't repeat it
+	plain := *todo
+	plain.Category = nil
+
+	var data bytes.Buffer
+	writer := ical.NewWriter(&data)
+	writer.Begin("VCALENDAR")
+	writer.Line("VERSION", "2.0")
+	writer.Line("PRODID", calendarProductID)
+	writeVTodo(writer, uid, &plain, time.UTC, todo.UpdatedAt)
+	writer.End("VCALENDAR")
+	_ = writer.Flush()
+
+	return CalDAVObject{Name: name, Data: data.Bytes(), Todo: todo}
+}
+
+// defaultObjectName returns the resource name of a todo that was not created over CalDAV
+func defaultObjectName(id uint) string {
+	return "todo-" + strconv.FormatUint(uint64(id), 10) + ".ics"
+}
+
+// defaultObjectID returns the todo ID of a default resource name
+func defaultObjectID(name string) (uint, bool) {
+	var id uint
+	if _, err := fmt.Sscanf(name, "todo-%d.ics", &id); err != nil || id == 0 || defaultObjectName(id) != name {
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeVTodo reads the todo fields and the UID of the VTODO in an iCalendar object
+// PRIORITY 1-4 is high, 6-9 low and anything else medium; a COMPLETED status or time completes the todo
+func decodeVTodo(data []byte) (*models.Todo, string, error) {
+	root, err := ical.Parse(data)
+	if err != nil {
+		return nil, "", err
+	}
+	vtodo := root.Component("VTODO")
+	if root.Name != "VCALENDAR" || vtodo == nil {
+		return nil, "", errors.New("invalid calendar object: only VTODO components are supported")
+	}
+
+	uid := strings.TrimSpace(vtodo.Text("UID"))
+	if uid == "" || len(uid) > 255 {
+		return nil, "", errors.New("invalid calendar object: UID is required")
+	}
+
+	todo := &models.Todo{
+		Title:       strings.TrimSpace(vtodo.Text("SUMMARY")),
+		Description: strings.TrimSpace(vtodo.Text("DESCRIPTION")),
+		Priority:    models.PriorityMedium,
+	}
+
+	if prop := vtodo.Property("DUE"); prop != nil {
+		due, _, err := prop.Time()
+		if err != nil {
+			return nil, "", errors.New("invalid calendar object: " + err.Error())
+		}
+		todo.DueDate = &due
+	}
+	if prop := vtodo.Property("RRULE"); prop != nil {
+		todo.Recurrence = prop.Value
+	}
+
+	if priority, err := strconv.Atoi(vtodo.Text("PRIORITY")); err == nil {
+		switch {
+		case priority >= 1 && priority <= 4:
+			todo.Priority = models.PriorityHigh
+		case priority >= 6 && priority <= 9:
+			todo.Priority = models.PriorityLow
+		}
+	}
+
+	completed := vtodo.Property("COMPLETED")
+	todo.Completed = strings.EqualFold(vtodo.Text("STATUS"), "COMPLETED") || completed != nil
+	if todo.Completed && completed != nil {
+		if completedAt, _, err := completed.Time(); err == nil {
+			todo.CompletedAt = &completedAt
+		}
+	}
+	return todo, uid, nil
+}
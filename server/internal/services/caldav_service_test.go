@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/ical"
+)
+
+func TestCalDAVObjectRecurrenceRoundTrip(t *testing.T) {
+	due := time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC)
+	updated := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		todo models.Todo
+	}{
+		{
+			name: "not repeating",
+			todo: models.Todo{Title: "Call Ann", Priority: models.PriorityMedium, DueDate: &due},
+		},
+		{
+			name: "repeating with a due date",
+			todo: models.Todo{Title: "Pay rent", Priority: models.PriorityHigh, DueDate: &due, Recurrence: "FREQ=MONTHLY"},
+		},
+		{
+			name: "repeating without a due date",
+			todo: models.Todo{Title: "Water plants", Priority: models.PriorityLow, Recurrence: "FREQ=WEEKLY;BYDAY=MO,TH"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.todo.ID = 7
+			tt.todo.Version = 1
+			tt.todo.CreatedAt = updated
+			tt.todo.UpdatedAt = updated
+
+			object := caldavObject(&tt.todo, models.CalDAVResource{}, false)
+			data := string(object.Data)
+			if got := strings.Contains(data, "\r\nRRULE:"); got != (tt.todo.Recurrence != "") {
+				t.Errorf("object has an RRULE %v; want %v:\n%s", got, tt.todo.Recurrence != "", data)
+			}
+
+			decoded, uid, err := decodeVTodo(object.Data)
+			if err != nil {
+				t.Fatalf("decodeVTodo: %v\n%s", err, data)
+			}
+			if uid != todoUID(tt.todo.ID) {
+				t.Errorf("UID = %q; want %q", uid, todoUID(tt.todo.ID))
+			}
+			if decoded.Recurrence != tt.todo.Recurrence {
+				t.Errorf("recurrence = %q; want %q", decoded.Recurrence, tt.todo.Recurrence)
+			}
+			if decoded.Title != tt.todo.Title || decoded.Priority != tt.todo.Priority {
+				t.Errorf("decoded = %q %s; want %q %s", decoded.Title, decoded.Priority, tt.todo.Title, tt.todo.Priority)
+			}
+		})
+	}
+}
+
+func TestCalendarDueEventRepeats(t *testing.T) {
+	due := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+	todo := &models.Todo{ID: 3, Title: "Pay rent", DueDate: &due, Recurrence: "FREQ=MONTHLY", UpdatedAt: due}
+
+	var data strings.Builder
+	w := ical.NewWriter(&data)
+	writeDueEvent(w, todo, time.UTC, due)
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	want := "DTSTART;VALUE=DATE:20261102\r\nRRULE:FREQ=MONTHLY\r\n"
+	if !strings.Contains(data.String(), want) {
+		t.Errorf("event =\n%s\nwant it to contain\n%s", data.String(), want)
+	}
+}
+
+// fakeCalDAVRepo keeps client-chosen resources in memory
+type fakeCalDAVRepo struct {
+	repository.CalDAVRepository
+	resources map[string]models.CalDAVResource
+	created   []models.CalDAVResource
+}
+
+func (r *fakeCalDAVRepo) ResourceByName(name string) (*models.CalDAVResource, error) {
+	resource, ok := r.resources[name]
+	if !ok {
+		return nil, errors.New("calendar resource not found")
+	}
+	return &resource, nil
+}
+
+func (r *fakeCalDAVRepo) CreateTodo(todo *models.Todo, resource *models.CalDAVResource) error {
+	todo.ID = 100
+	resource.TodoID = todo.ID
+	r.created = append(r.created, *resource)
+	return nil
+}
+
+func TestCalDAVPutObject(t *testing.T) {
+	// Todo 5 has been overdue for a week, todo 7 was created over CalDAV
+	overdue := time.Now().UTC().Truncate(time.Second).AddDate(0, 0, -7)
+	todos := []models.Todo{
+		{ID: 5, Title: "File taxes", Priority: models.PriorityHigh, DueDate: &overdue, StatusID: uintPtr(1), Version: 2},
+		{ID: 7, Title: "Call Ann", Priority: models.PriorityLow, StatusID: uintPtr(1), Version: 1},
+	}
+	data := func(todo models.Todo, change func(todo *models.Todo)) []byte {
+		change(&todo)
+		return caldavObject(&todo, models.CalDAVResource{}, false).Data
+	}
+	earlier := overdue.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name      string
+		object    string
+		data      []byte
+		wantErr   string
+		created   bool
+		updatedID uint
+		completed bool
+	}{
+		{
+			name:      "complete an overdue todo",
+			object:    "todo-5.ics",
+			data:      data(todos[0], func(todo *models.Todo) { todo.Completed = true }),
+			updatedID: 5,
+			completed: true,
+		},
+		{
+			name:    "move an overdue todo further into the past",
+			object:  "todo-5.ics",
+			data:    data(todos[0], func(todo *models.Todo) { todo.DueDate = &earlier }),
+			wantErr: "due date cannot be in the past",
+		},
+		{
+			name:      "default name shadowed by a client resource",
+			object:    "todo-5.ics",
+			data:      data(todos[0], func(todo *models.Todo) { todo.Title = "File taxes today" }),
+			updatedID: 5,
+		},
+		{
+			name:      "client resource",
+			object:    "call-ann.ics",
+			data:      data(todos[1], func(todo *models.Todo) { todo.Title = "Call Ann back" }),
+			updatedID: 7,
+		},
+		{
+			name:    "new todo under a default name",
+			object:  "todo-9.ics",
+			data:    data(todos[1], func(todo *models.Todo) { todo.ID = 9 }),
+			wantErr: "invalid calendar object name",
+		},
+		{
+			name:    "new todo",
+			object:  "groceries.ics",
+			data:    data(todos[1], func(todo *models.Todo) { todo.ID = 9; todo.Title = "Groceries" }),
+			created: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todoService, todoRepo := newTestTodoService(todos...)
+			caldavRepo := &fakeCalDAVRepo{resources: map[string]models.CalDAVResource{
+				"todo-5.ics":   {TodoID: 7, Name: "todo-5.ics", UID: "legacy"},
+				"call-ann.ics": {TodoID: 7, Name: "call-ann.ics", UID: "call-ann"},
+			}}
+			s := &caldavService{caldavRepo: caldavRepo, todoRepo: todoRepo, todoService: todoService}
+
+			created, err := s.PutObject(CalDAVInbox, tt.object, tt.data, 0, false)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("PutObject error = %v; want %q", err, tt.wantErr)
+				}
+				if len(todoRepo.updated) != 0 || len(caldavRepo.created) != 0 {
+					t.Errorf("PutObject failed but updated %d and created %d todos", len(todoRepo.updated), len(caldavRepo.created))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PutObject: %v", err)
+			}
+			if created != tt.created {
+				t.Errorf("created = %v; want %v", created, tt.created)
+			}
+
+			if tt.created {
+				if len(caldavRepo.created) != 1 || caldavRepo.created[0].Name != tt.object || caldavRepo.created[0].TodoID == 0 {
+					t.Errorf("created resources = %+v; want %s with its todo", caldavRepo.created, tt.object)
+				}
+				return
+			}
+			if len(todoRepo.updated) != 1 || todoRepo.updated[0].ID != tt.updatedID {
+				t.Fatalf("updated todos = %+v; want todo %d", todoRepo.updated, tt.updatedID)
+			}
+			if todoRepo.updated[0].Completed != tt.completed {
+				t.Errorf("completed = %v; want %v", todoRepo.updated[0].Completed, tt.completed)
+			}
+		})
+	}
+}
+
+func uintPtr(v uint) *uint {
+	return &v
+}
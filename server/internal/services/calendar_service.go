@@ -0,0 +1,302 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Time zones of calendar feeds must load on hosts without a zone database
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/ical"
+)
+
+// calendarProductID identifies this application in iCalendar files
+const calendarProductID = "-//todo-backend//Todo Calendar//EN"
+
+// calendarRefreshInterval is how often calendar apps are asked to reload a feed
+const calendarRefreshInterval = "PT1H"
+
+// icalPriorities maps todo priorities to iCalendar priorities, 1 is the highest and 9 the lowest
+var icalPriorities = map[models.Priority]int{
+	models.PriorityHigh:   1,
+	models.PriorityMedium: 5,
+	models.PriorityLow:    9,
+}
+
+// calendarService implements CalendarService interface
+type calendarService struct {
+	calendarRepo repository.CalendarRepository
+	categoryRepo repository.CategoryRepository
+}
+
+// NewCalendarService creates a new calendar service
+func NewCalendarService(calendarRepo repository.CalendarRepository, categoryRepo repository.CategoryRepository) CalendarService {
+	return &calendarService{
+		calendarRepo: calendarRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// CreateFeed creates a calendar feed with a new token, which is only returned here
+func (s *calendarService) CreateFeed(feed *models.CalendarFeed) error {
+	if err := s.validateFeed(feed); err != nil {
+		return err
+	}
+
+	feed.ID = 0
+	feed.Token = newCalendarToken()
+	feed.TokenHash = hashCalendarToken(feed.Token)
+	return s.calendarRepo.CreateFeed(feed)
+}
+
+// GetFeed retrieves a calendar feed by its ID
+func (s *calendarService) GetFeed(id uint) (*models.CalendarFeed, error) {
+	if id == 0 {
+		return nil, errors.New("invalid calendar feed ID")
+	}
+	return s.calendarRepo.GetFeed(id)
+}
+
+// ListFeeds retrieves all calendar feeds
+func (s *calendarService) ListFeeds() ([]models.CalendarFeed, error) {
+	return s.calendarRepo.ListFeeds()
+}
+
+// UpdateFeed updates the settings of a calendar feed, its token stays the same
+func (s *calendarService) UpdateFeed(feed *models.CalendarFeed) error {
+	if feed.ID == 0 {
+		return errors.New("invalid calendar feed ID")
+	}
+	if err := s.validateFeed(feed); err != nil {
+		return err
+	}
+
+	existing, err := s.calendarRepo.GetFeed(feed.ID)
+	if err != nil {
+		return err
+	}
+	feed.Token = ""
+	feed.TokenHash = existing.TokenHash
+	feed.CreatedAt = existing.CreatedAt
+	return s.calendarRepo.UpdateFeed(feed)
+}
+
+// DeleteFeed deletes a calendar feed, its URL stops working
+func (s *calendarService) DeleteFeed(id uint) error {
+	if id == 0 {
+		return errors.New("invalid calendar feed ID")
+	}
+	return s.calendarRepo.DeleteFeed(id)
+}
+
+// WriteFeed writes the iCalendar file of the feed with the given token to w
+func (s *calendarService) WriteFeed(token string, w io.Writer) error {
+	if token == "" {
+		return errors.New("calendar feed not found")
+	}
+	feed, err := s.calendarRepo.GetFeedByTokenHash(hashCalendarToken(token))
+	if err != nil {
+		return err
+	}
+
+	loc, err := loadCalendarLocation(feed.TimeZone)
+	if err != nil {
+		return err
+	}
+	todos, err := s.calendarRepo.FeedTodos(feed.CategoryIDs, feed.HideCompleted)
+	if err != nil {
+		return err
+	}
+
+	writer := ical.NewWriter(w)
+	writer.Begin("VCALENDAR")
+	writer.Line("VERSION", "2.0")
+	writer.Line("PRODID", calendarProductID)
+	writer.Line("CALSCALE", "GREGORIAN")
+	writer.Line("METHOD", "PUBLISH")
+	writer.Text("X-WR-CALNAME", feed.Name)
+	if loc != time.UTC {
+		writer.Line("X-WR-TIMEZONE", loc.String())
+	}
+	writer.Line("REFRESH-INTERVAL;VALUE=DURATION", calendarRefreshInterval)
+	writer.Line("X-PUBLISHED-TTL", calendarRefreshInterval)
+
+	// Times with a time of day refer to the time zone, which is described for the years they span
+	if from, to, ok := dueYears(todos); ok && loc != time.UTC {
+		writer.VTimezone(loc, from, to)
+	}
+
+	stamp := time.Now()
+	for i := range todos {
+		writeVTodo(writer, todoUID(todos[i].ID), &todos[i], loc, stamp)
+		if feed.IncludeEvents {
+			writeDueEvent(writer, &todos[i], loc, stamp)
+		}
+	}
+
+	writer.End("VCALENDAR")
+	return writer.Flush()
+}
+
+// validateFeed normalizes the settings of a calendar feed and checks that its time zone and categories exist
+func (s *calendarService) validateFeed(feed *models.CalendarFeed) error {
+	feed.Name = strings.TrimSpace(feed.Name)
+	if feed.Name == "" {
+		return errors.New("calendar feed name is required")
+	}
+
+	feed.TimeZone = strings.TrimSpace(feed.TimeZone)
+	if _, err := loadCalendarLocation(feed.TimeZone); err != nil {
+		return err
+	}
+
+	if feed.CategoryIDs == nil {
+		feed.CategoryIDs = []uint{}
+	}
+	for _, id := range feed.CategoryIDs {
+		if _, err := s.categoryRepo.GetByID(id); err != nil {
+			return errors.New("specified category does not exist")
+		}
+	}
+	return nil
+}
+
+// writeVTodo writes a todo as a VTODO component with the given UID
+// Due dates at midnight UTC are dates without a time of day, other due times are written in loc;
+// the recurrence is written even without a due date so CalDAV clients get back what they saved
+func writeVTodo(w *ical.Writer, uid string, todo *models.Todo, loc *time.Location, stamp time.Time) {
+	w.Begin("VTODO")
+	w.Text("UID", uid)
+	w.Time("DTSTAMP", stamp, time.UTC)
+	w.Time("CREATED", todo.CreatedAt, time.UTC)
+	w.Time("LAST-MODIFIED", todo.UpdatedAt, time.UTC)
+	if todo.Version > 0 {
+		w.Line("SEQUENCE", strconv.FormatUint(uint64(todo.Version-1), 10))
+	}
+	w.Text("SUMMARY", todo.Title)
+	if todo.Description != "" {
+		w.Text("DESCRIPTION", todo.Description)
+	}
+	if todo.DueDate != nil {
+		writeDue(w, "DUE", *todo.DueDate, loc)
+	}
+	if todo.Recurrence != "" {
+		w.Line("RRULE", todo.Recurrence)
+	}
+	if priority, ok := icalPriorities[todo.Priority]; ok {
+		w.Line("PRIORITY", strconv.Itoa(priority))
+	}
+	if todo.Category != nil {
+		w.Texts("CATEGORIES", []string{todo.Category.Name})
+	}
+	if todo.Completed {
+		w.Line("STATUS", "COMPLETED")
+		w.Line("PERCENT-COMPLETE", "100")
+		if todo.CompletedAt != nil {
+			w.Time("COMPLETED", *todo.CompletedAt, time.UTC)
+		}
+	} else {
+		w.Line("STATUS", "NEEDS-ACTION")
+	}
+	w.End("VTODO")
+}
+
+// writeDueEvent writes the due date of a todo as a VEVENT, for calendar apps that don't show tasks
+// Events are transparent so they don't count as busy time, and repeat like their todo
+func writeDueEvent(w *ical.Writer, todo *models.Todo, loc *time.Location, stamp time.Time) {
+	if todo.DueDate == nil {
+		return
+	}
+
+	w.Begin("VEVENT")
+	w.Line("UID", "todo-"+strconv.FormatUint(uint64(todo.ID), 10)+"-due@todo-backend")
+	w.Time("DTSTAMP", stamp, time.UTC)
+	w.Time("LAST-MODIFIED", todo.UpdatedAt, time.UTC)
+	summary := todo.Title
+	if todo.Completed {
+		summary = "✓ " + summary
+	}
+	w.Text("SUMMARY", summary)
+	if todo.Description != "" {
+		w.Text("DESCRIPTION", todo.Description)
+	}
+	writeDue(w, "DTSTART", *todo.DueDate, loc)
+	if todo.Recurrence != "" {
+		w.Line("RRULE", todo.Recurrence)
+	}
+	if todo.Category != nil {
+		w.Texts("CATEGORIES", []string{todo.Category.Name})
+	}
+	w.Line("TRANSP", "TRANSPARENT")
+	w.End("VEVENT")
+}
+
+// writeDue writes a due time as a date when it is at midnight UTC, otherwise as a time in loc
+func writeDue(w *ical.Writer, name string, due time.Time, loc *time.Location) {
+	if isDateOnly(due) {
+		w.Date(name, due.UTC())
+		return
+	}
+	w.Time(name, due, loc)
+}
+
+// isDateOnly reports whether a due time stands for a whole day, i.e. it is midnight UTC
+func isDateOnly(t time.Time) bool {
+	t = t.UTC()
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
+
+// dueYears returns the first and last year of the due times with a time of day
+func dueYears(todos []models.Todo) (int, int, bool) {
+	from, to, ok := 0, 0, false
+	for _, todo := range todos {
+		if todo.DueDate == nil || isDateOnly(*todo.DueDate) {
+			continue
+		}
+		year := todo.DueDate.UTC().Year()
+		if !ok || year < from {
+			from = year
+		}
+		if !ok || year > to {
+			to = year
+		}
+		ok = true
+	}
+	return from, to, ok
+}
+
+// loadCalendarLocation loads the time zone of a calendar, UTC when none is set
+func loadCalendarLocation(name string) (*time.Location, error) {
+	if name == "" || name == "UTC" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, errors.New("invalid time zone: must be an IANA time zone such as Europe/Berlin")
+	}
+	return loc, nil
+}
+
+// todoUID returns the iCalendar UID of a todo
+func todoUID(id uint) string {
+	return "todo-" + strconv.FormatUint(uint64(id), 10) + "@todo-backend"
+}
+
+// newCalendarToken generates a random calendar feed token
+func newCalendarToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// hashCalendarToken returns the hash under which a calendar feed token is stored
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strings"
 
 	"todo-backend/internal/models"
@@ -20,6 +22,13 @@ func NewCategoryService(categoryRepo repository.CategoryRepository) CategoryServ
 	}
 }
 
+// WithContext returns a copy of the service whose changes are recorded with the audit info of ctx
+func (s *categoryService) WithContext(ctx context.Context) CategoryService {
+	scoped := *s
+	scoped.categoryRepo = s.categoryRepo.WithContext(ctx)
+	return &scoped
+}
+
 // CreateCategory creates a new category with validation
 func (s *categoryService) CreateCategory(category *models.Category) error {
 	// Business logic validation
@@ -58,12 +67,77 @@ func (s *categoryService) UpdateCategory(category *models.Category) error {
 	return s.categoryRepo.Update(category)
 }
 
+// PatchCategory applies a JSON Merge Patch or JSON Patch to a category
+// Only the fields the patch changes are validated and written
+func (s *categoryService) PatchCategory(id uint, version uint, patch []byte, mediaType string) (*models.Category, error) {
+	if id == 0 {
+		return nil, errors.New("invalid category ID")
+	}
+
+	existing, err := s.categoryRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != existing.Version {
+		return nil, errors.New("category was modified by another request")
+	}
+
+	changes, err := patchChanges(existing, patch, mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode the changed fields onto a copy of the category
+	category := *existing
+	columns := map[string]interface{}{}
+	for field, raw := range changes {
+		switch field {
+		case "name":
+			if err := decodeRequiredField(field, raw, &category.Name); err != nil {
+				return nil, err
+			}
+		case "color":
+			if err := decodeRequiredField(field, raw, &category.Color); err != nil {
+				return nil, err
+			}
+		case "id", "version", "created_at", "updated_at", "todos":
+			return nil, fmt.Errorf("invalid patch: field %s is read-only", field)
+		default:
+			return nil, fmt.Errorf("invalid patch: unknown field %s", field)
+		}
+	}
+	if len(changes) == 0 {
+		return existing, nil
+	}
+
+	// Business logic validation
+	if err := s.validateCategory(&category); err != nil {
+		return nil, err
+	}
+
+	// Clean and format the data
+	s.cleanCategoryData(&category)
+
+	if _, ok := changes["name"]; ok {
+		columns["name"] = category.Name
+	}
+	if _, ok := changes["color"]; ok {
+		columns["color"] = category.Color
+	}
+	if err := s.categoryRepo.UpdateFields(id, existing.Version, columns); err != nil {
+		return nil, err
+	}
+
+	return s.categoryRepo.GetByID(id)
+}
+
 // DeleteCategory soft deletes a category by ID
-func (s *categoryService) DeleteCategory(id uint) error {
+// A non-zero version must match the current version of the category
+func (s *categoryService) DeleteCategory(id uint, version uint) error {
 	if id == 0 {
 		return errors.New("invalid category ID")
 	}
-	return s.categoryRepo.Delete(id)
+	return s.categoryRepo.Delete(id, version)
 }
 
 // ListCategories retrieves categories with pagination and filtering
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+)
+
+// mentionPattern matches @username mentions that are not part of an email address
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9_][A-Za-z0-9_.-]{0,99})`)
+
+// commentService implements CommentService interface
+type commentService struct {
+	commentRepo repository.CommentRepository
+	actor       string // who makes the request, from the audit info of the context
+}
+
+// NewCommentService creates a new comment service
+func NewCommentService(commentRepo repository.CommentRepository) CommentService {
+	return &commentService{
+		commentRepo: commentRepo,
+	}
+}
+
+// WithContext returns a copy of the service whose changes are recorded with the audit info of ctx
+func (s *commentService) WithContext(ctx context.Context) CommentService {
+	scoped := *s
+	scoped.commentRepo = s.commentRepo.WithContext(ctx)
+	scoped.actor = ""
+	if info, ok := repository.AuditInfoFrom(ctx); ok {
+		scoped.actor = strings.TrimSpace(info.Actor)
+	}
+	return &scoped
+}
+
+// CreateComment adds a comment to a todo and records its mentions
+// The author is the actor of the request, the author in the comment is only used without one
+func (s *commentService) CreateComment(todoID uint, comment *models.Comment) error {
+	if todoID == 0 {
+		return errors.New("invalid todo ID")
+	}
+
+	// Business logic validation
+	if err := s.validateComment(comment); err != nil {
+		return err
+	}
+	if s.actor != "" {
+		comment.Author = s.actor
+	}
+	if err := validateCommentAuthor(comment.Author); err != nil {
+		return err
+	}
+
+	// Clean and format the data
+	comment.ID = 0
+	comment.TodoID = todoID
+	comment.EditedAt = nil
+	comment.Revisions = nil
+	comment.Author = strings.TrimSpace(comment.Author)
+	comment.Body = strings.TrimSpace(comment.Body)
+	comment.Mentions = buildMentions(comment.Body)
+
+	return s.commentRepo.Create(comment)
+}
+
+// GetComment retrieves a comment of a todo including its edit history
+func (s *commentService) GetComment(todoID, id uint) (*models.Comment, error) {
+	if todoID == 0 {
+		return nil, errors.New("invalid todo ID")
+	}
+	if id == 0 {
+		return nil, errors.New("invalid comment ID")
+	}
+	return s.commentRepo.GetByID(todoID, id)
+}
+
+// UpdateComment edits the body of a comment, keeping the previous body as a revision
+func (s *commentService) UpdateComment(todoID uint, comment *models.Comment) error {
+	if todoID == 0 {
+		return errors.New("invalid todo ID")
+	}
+	if comment.ID == 0 {
+		return errors.New("invalid comment ID")
+	}
+
+	// Business logic validation
+	if err := s.validateComment(comment); err != nil {
+		return err
+	}
+
+	existing, err := s.commentRepo.GetByID(todoID, comment.ID)
+	if err != nil {
+		return err
+	}
+
+	// Only the original author may edit a comment
+	if !s.isAuthor(existing) {
+		return errors.New("only the comment author can edit this comment")
+	}
+
+	body := strings.TrimSpace(comment.Body)
+	if body == existing.Body {
+		*comment = *existing
+		return nil
+	}
+
+	previousBody := existing.Body
+	existing.Body = body
+	existing.Mentions = buildMentions(body)
+	if err := s.commentRepo.Update(existing, previousBody); err != nil {
+		return err
+	}
+
+	updated, err := s.commentRepo.GetByID(todoID, comment.ID)
+	if err != nil {
+		return err
+	}
+	*comment = *updated
+	return nil
+}
+
+// DeleteComment soft deletes a comment by ID
+func (s *commentService) DeleteComment(todoID, id uint) error {
+	if todoID == 0 {
+		return errors.New("invalid todo ID")
+	}
+	if id == 0 {
+		return errors.New("invalid comment ID")
+	}
+
+	existing, err := s.commentRepo.GetByID(todoID, id)
+	if err != nil {
+		return err
+	}
+
+	// Only the original author may delete a comment
+	if !s.isAuthor(existing) {
+		return errors.New("only the comment author can delete this comment")
+	}
+	return s.commentRepo.Delete(todoID, id)
+}
+
+// isAuthor reports whether the actor of the request wrote a comment
+// Requests without an actor are not the author of any comment
+func (s *commentService) isAuthor(comment *models.Comment) bool {
+	return s.actor != "" && strings.EqualFold(comment.Author, s.actor)
+}
+
+// ListComments retrieves comments of a todo with pagination
+func (s *commentService) ListComments(todoID uint, pagination repository.PaginationParams) ([]models.Comment, repository.PaginationResult, error) {
+	if todoID == 0 {
+		return nil, repository.PaginationResult{}, errors.New("invalid todo ID")
+	}
+
+	// Set default pagination values
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+	if pagination.Limit <= 0 {
+		pagination.Limit = 10
+	}
+
+	return s.commentRepo.ListByTodo(todoID, pagination)
+}
+
+// validateComment validates comment data
+func (s *commentService) validateComment(comment *models.Comment) error {
+	if comment == nil {
+		return errors.New("comment cannot be nil")
+	}
+
+	// Validate body
+	if strings.TrimSpace(comment.Body) == "" {
+		return errors.New("comment body is required")
+	}
+
+	if len(comment.Body) > 10000 {
+		return errors.New("comment body cannot exceed 10000 characters")
+	}
+
+	return nil
+}
+
+// validateCommentAuthor validates the author of a new comment
+func validateCommentAuthor(author string) error {
+	if strings.TrimSpace(author) == "" {
+		return errors.New("comment author is required")
+	}
+
+	if len(author) > 100 {
+		return errors.New("comment author cannot exceed 100 characters")
+	}
+
+	return nil
+}
+
+// parseMentions extracts the unique @usernames mentioned in a markdown body
+// Mentions inside inline code or fenced code blocks are ignored
+func parseMentions(body string) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		for _, match := range mentionPattern.FindAllStringSubmatch(stripInlineCode(line), -1) {
+			username := strings.TrimRight(match[1], ".-")
+			key := strings.ToLower(username)
+			if username == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			usernames = append(usernames, username)
+		}
+	}
+
+	return usernames
+}
+
+// stripInlineCode removes `inline code` spans from a markdown line
+func stripInlineCode(line string) string {
+	var b strings.Builder
+	inCode := false
+	for _, r := range line {
+		if r == '`' {
+			inCode = !inCode
+			b.WriteRune(' ')
+			continue
+		}
+		if !inCode {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// buildMentions converts the mentions of a body into mention records
+func buildMentions(body string) []models.CommentMention {
+	usernames := parseMentions(body)
+	mentions := make([]models.CommentMention, 0, len(usernames))
+	for _, username := range usernames {
+		mentions = append(mentions, models.CommentMention{Username: username})
+	}
+	return mentions
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+)
+
+// fakeCommentRepo keeps comments in memory
+type fakeCommentRepo struct {
+	repository.CommentRepository
+	comments map[uint]*models.Comment
+	deleted  []uint
+}
+
+func (r *fakeCommentRepo) WithContext(ctx context.Context) repository.CommentRepository {
+	return r
+}
+
+func (r *fakeCommentRepo) Create(comment *models.Comment) error {
+	comment.ID = uint(len(r.comments) + 1)
+	copied := *comment
+	r.comments[comment.ID] = &copied
+	return nil
+}
+
+func (r *fakeCommentRepo) GetByID(todoID, id uint) (*models.Comment, error) {
+	comment, ok := r.comments[id]
+	if !ok || comment.TodoID != todoID {
+		return nil, errors.New("comment not found")
+	}
+	copied := *comment
+	return &copied, nil
+}
+
+func (r *fakeCommentRepo) Update(comment *models.Comment, previousBody string) error {
+	copied := *comment
+	r.comments[comment.ID] = &copied
+	return nil
+}
+
+func (r *fakeCommentRepo) Delete(todoID, id uint) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "single mention", body: "@bob can you look?", want: []string{"bob"}},
+		{name: "several mentions in order", body: "cc @carol and @bob", want: []string{"carol", "bob"}},
+		{name: "duplicates ignore case", body: "@Bob and @bob again", want: []string{"Bob"}},
+		{name: "trailing punctuation", body: "thanks @bob. and @carol-", want: []string{"bob", "carol"}},
+		{name: "dots and dashes inside", body: "@jane.doe-smith", want: []string{"jane.doe-smith"}},
+		{name: "after punctuation", body: "(@bob) and \"@carol\"", want: []string{"bob", "carol"}},
+		{name: "email address", body: "mail alice@example.com", want: nil},
+		{name: "double at", body: "@@bob", want: nil},
+		{name: "inline code", body: "run `git log --author @bob` first, @carol", want: []string{"carol"}},
+		{name: "fenced code", body: "```\n@bob\n```\n@carol", want: []string{"carol"}},
+		{name: "unclosed fence", body: "@carol\n```\n@bob", want: []string{"carol"}},
+		{name: "bare at", body: "meet @ noon", want: nil},
+		{name: "empty body", body: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseMentions(tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMentions(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMentionPattern(t *testing.T) {
+	tests := []struct {
+		text string
+		want string // captured username, empty for no match
+	}{
+		{text: "@bob", want: "bob"},
+		{text: "hi @bob", want: "bob"},
+		{text: "hi,@bob", want: "bob"},
+		{text: "_@bob", want: ""},
+		{text: "a@bob", want: ""},
+		{text: ".@bob", want: ""},
+		{text: "@@bob", want: ""},
+		{text: "@_bob", want: "_bob"},
+		{text: "@-bob", want: ""},
+		{text: "@.bob", want: ""},
+		{text: "@bob!", want: "bob"},
+		{text: "@" + strings.Repeat("a", 120), want: strings.Repeat("a", 100)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := ""
+			if match := mentionPattern.FindStringSubmatch(tt.text); match != nil {
+				got = match[1]
+			}
+			if got != tt.want {
+				t.Errorf("mentionPattern on %q captured %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommentServiceAuthorPermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   string // X-Actor of the request, empty for none
+		author  string // author sent by the client
+		wantErr string
+	}{
+		{name: "author", actor: "alice", author: "alice"},
+		{name: "author ignores case", actor: " Alice ", author: "alice"},
+		{name: "other actor claiming the author", actor: "mallory", author: "alice", wantErr: "only the comment author"},
+		{name: "no actor claiming the author", author: "alice", wantErr: "only the comment author"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, action := range []string{"edit", "delete"} {
+				repo := &fakeCommentRepo{comments: map[uint]*models.Comment{
+					1: {ID: 1, TodoID: 1, Author: "alice", Body: "first"},
+				}}
+				ctx := context.Background()
+				if tt.actor != "" {
+					ctx = repository.WithAuditInfo(ctx, repository.AuditInfo{Actor: tt.actor})
+				}
+				service := NewCommentService(repo).WithContext(ctx)
+
+				var err error
+				if action == "edit" {
+					err = service.UpdateComment(1, &models.Comment{ID: 1, Author: tt.author, Body: "second"})
+				} else {
+					err = service.DeleteComment(1, 1)
+				}
+
+				if tt.wantErr == "" {
+					if err != nil {
+						t.Fatalf("%s: unexpected error: %v", action, err)
+					}
+					continue
+				}
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("%s: error = %v, want %q", action, err, tt.wantErr)
+				}
+				if repo.comments[1].Body != "first" || len(repo.deleted) != 0 {
+					t.Errorf("%s: comment changed despite the error", action)
+				}
+			}
+		})
+	}
+}
+
+func TestCommentServiceCreateUsesActor(t *testing.T) {
+	repo := &fakeCommentRepo{comments: map[uint]*models.Comment{}}
+	ctx := repository.WithAuditInfo(context.Background(), repository.AuditInfo{Actor: "alice"})
+
+	comment := &models.Comment{Author: "mallory", Body: "hello"}
+	if err := NewCommentService(repo).WithContext(ctx).CreateComment(1, comment); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if comment.Author != "alice" {
+		t.Errorf("author = %q, want the actor %q", comment.Author, "alice")
+	}
+}
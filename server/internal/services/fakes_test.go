@@ -0,0 +1,161 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+)
+
+// The fakes embed the repository interfaces and implement only what the tests call,
+// calling anything else panics
+
+// fakeTodoRepo keeps todos in memory
+type fakeTodoRepo struct {
+	repository.TodoRepository
+	todos    map[uint]*models.Todo
+	updated  []models.Todo
+	blockers map[uint][]uint
+}
+
+func newFakeTodoRepo(todos ...models.Todo) *fakeTodoRepo {
+	r := &fakeTodoRepo{todos: map[uint]*models.Todo{}}
+	for i := range todos {
+		todo := todos[i]
+		r.todos[todo.ID] = &todo
+	}
+	return r
+}
+
+func (r *fakeTodoRepo) GetByID(id uint) (*models.Todo, error) {
+	todo, ok := r.todos[id]
+	if !ok {
+		return nil, errors.New("todo not found")
+	}
+	copied := *todo
+	return &copied, nil
+}
+
+func (r *fakeTodoRepo) Update(todo *models.Todo) error {
+	stored, ok := r.todos[todo.ID]
+	if !ok {
+		return errors.New("todo not found")
+	}
+	if todo.Version != 0 && todo.Version != stored.Version {
+		return errors.New("todo was modified by another request")
+	}
+	todo.Version = stored.Version + 1
+	copied := *todo
+	r.todos[todo.ID] = &copied
+	r.updated = append(r.updated, copied)
+	return nil
+}
+
+func (r *fakeTodoRepo) SetStatus(id uint, version uint, statusID uint, completed bool) error {
+	return r.updateFields(id, version, func(todo *models.Todo) {
+		todo.StatusID = &statusID
+		todo.Completed = completed
+	})
+}
+
+func (r *fakeTodoRepo) SetArchived(id uint, version uint, archived bool) error {
+	return r.updateFields(id, version, func(todo *models.Todo) {
+		todo.ArchivedAt = nil
+		if archived {
+			now := time.Now()
+			todo.ArchivedAt = &now
+		}
+	})
+}
+
+func (r *fakeTodoRepo) MovePosition(id uint, version uint, afterID, beforeID *uint, columns map[string]interface{}) error {
+	return r.updateFields(id, version, func(todo *models.Todo) {})
+}
+
+func (r *fakeTodoRepo) GetBlockerIDs(todoIDs []uint) (map[uint][]uint, error) {
+	blockers := map[uint][]uint{}
+	for _, id := range todoIDs {
+		if ids := r.blockers[id]; len(ids) > 0 {
+			blockers[id] = ids
+		}
+	}
+	return blockers, nil
+}
+
+func (r *fakeTodoRepo) AddDependency(todoID, blockerID uint) error {
+	if r.blockers == nil {
+		r.blockers = map[uint][]uint{}
+	}
+	r.blockers[todoID] = append(r.blockers[todoID], blockerID)
+	return nil
+}
+
+// updateFields changes a stored todo if it still has the expected version
+func (r *fakeTodoRepo) updateFields(id uint, version uint, change func(todo *models.Todo)) error {
+	stored, ok := r.todos[id]
+	if !ok {
+		return errors.New("todo not found")
+	}
+	if version != 0 && version != stored.Version {
+		return errors.New("todo was modified by another request")
+	}
+	copied := *stored
+	change(&copied)
+	copied.Version++
+	r.todos[id] = &copied
+	r.updated = append(r.updated, copied)
+	return nil
+}
+
+// fakeWorkflowRepo serves a fixed global workflow and its transitions
+type fakeWorkflowRepo struct {
+	repository.WorkflowRepository
+	statuses    []models.WorkflowStatus
+	transitions []models.StatusTransition
+}
+
+// newFakeWorkflowRepo returns the default workflow with IDs 1 to 4: todo, in_progress, in_review and done
+func newFakeWorkflowRepo(transitions ...models.StatusTransition) *fakeWorkflowRepo {
+	statuses := models.DefaultWorkflowStatuses()
+	for i := range statuses {
+		statuses[i].ID = uint(i + 1)
+	}
+	return &fakeWorkflowRepo{statuses: statuses, transitions: transitions}
+}
+
+func (r *fakeWorkflowRepo) ListStatuses(categoryID *uint) ([]models.WorkflowStatus, error) {
+	if categoryID != nil {
+		return nil, nil
+	}
+	return append([]models.WorkflowStatus(nil), r.statuses...), nil
+}
+
+func (r *fakeWorkflowRepo) GetStatusByID(id uint) (*models.WorkflowStatus, error) {
+	for i := range r.statuses {
+		if r.statuses[i].ID == id {
+			status := r.statuses[i]
+			return &status, nil
+		}
+	}
+	return nil, errors.New("status not found")
+}
+
+func (r *fakeWorkflowRepo) ListTransitions(fromStatusIDs []uint) ([]models.StatusTransition, error) {
+	var transitions []models.StatusTransition
+	for _, transition := range r.transitions {
+		for _, id := range fromStatusIDs {
+			if transition.FromStatusID == id {
+				transitions = append(transitions, transition)
+			}
+		}
+	}
+	return transitions, nil
+}
+
+// newTestTodoService returns a todo service over the given todos and the default workflow
+func newTestTodoService(todos ...models.Todo) (*todoService, *fakeTodoRepo) {
+	todoRepo := newFakeTodoRepo(todos...)
+	workflowService := NewWorkflowService(newFakeWorkflowRepo(), nil)
+	return NewTodoService(todoRepo, nil, workflowService).(*todoService), todoRepo
+}
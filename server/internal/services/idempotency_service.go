@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+)
+
+// idempotencyLockTimeout is how long a request holds its idempotency key before a retry may take it over
+const idempotencyLockTimeout = 5 * time.Minute
+
+// maxIdempotencyKeyLength is the longest idempotency key accepted
+const maxIdempotencyKeyLength = 255
+
+// idempotencyService implements IdempotencyService interface
+type idempotencyService struct {
+	idempotencyRepo repository.IdempotencyRepository
+	ttl             time.Duration
+}
+
+// NewIdempotencyService creates a new idempotency service
+// Responses are replayed for ttl after the first request with a key
+func NewIdempotencyService(idempotencyRepo repository.IdempotencyRepository, ttl time.Duration) IdempotencyService {
+	return &idempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             ttl,
+	}
+}
+
+// Begin claims an idempotency key for a request
+// Returns nil when the request should be handled, or the stored key whose response must be replayed
+func (s *idempotencyService) Begin(key, fingerprint string) (*models.IdempotencyKey, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, errors.New("invalid idempotency key: key cannot be empty")
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, errors.New("invalid idempotency key: key cannot exceed 255 characters")
+	}
+
+	now := time.Now()
+	lockedUntil := now.Add(idempotencyLockTimeout)
+	existing, err := s.idempotencyRepo.Acquire(&models.IdempotencyKey{
+		Key:         key,
+		Fingerprint: fingerprint,
+		LockedUntil: &lockedUntil,
+		ExpiresAt:   now.Add(s.ttl),
+	})
+	if err != nil || existing == nil {
+		return nil, err
+	}
+
+	if existing.Fingerprint != fingerprint {
+		return nil, errors.New("idempotency key was already used for a different request")
+	}
+	if !existing.Completed() {
+		return nil, errors.New("a request with this idempotency key is still in progress")
+	}
+	return existing, nil
+}
+
+// Complete stores the response to the request holding an idempotency key
+func (s *idempotencyService) Complete(key string, status int, headers models.Headers, body []byte) error {
+	return s.idempotencyRepo.Complete(strings.TrimSpace(key), status, headers, body)
+}
+
+// Release gives up an idempotency key whose request failed, so a retry is handled again
+func (s *idempotencyService) Release(key string) error {
+	return s.idempotencyRepo.Release(strings.TrimSpace(key))
+}
+
+// PurgeExpired removes idempotency keys older than the retention period
+func (s *idempotencyService) PurgeExpired() (int64, error) {
+	return s.idempotencyRepo.DeleteExpired()
+}
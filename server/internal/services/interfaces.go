@@ -1,33 +1,111 @@
 package services
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"todo-backend/internal/models"
 	"todo-backend/internal/repository"
+	"todo-backend/pkg/storage"
 )
 
 // TodoService defines the interface for todo business logic
 type TodoService interface {
+	// WithContext returns a copy of the service whose changes are recorded in the audit log
+	// with the audit info of ctx
+	WithContext(ctx context.Context) TodoService
+	
 	// CreateTodo creates a new todo with validation
 	CreateTodo(todo *models.Todo) error
 	
+	// PrepareTodo validates a new todo and resolves its workflow status without creating it,
+	// for callers that create it along with other records
+	PrepareTodo(todo *models.Todo) error
+	
 	// GetTodoByID retrieves a todo by its ID
 	GetTodoByID(id uint) (*models.Todo, error)
 	
 	// UpdateTodo updates an existing todo with validation
+	// A non-zero todo.Version must match the current version of the todo
 	UpdateTodo(todo *models.Todo) error
+
+	// PatchTodo applies a JSON Merge Patch or JSON Patch to a todo, validating and writing only changed fields
+	// A non-zero version must match the current version of the todo
+	PatchTodo(id uint, version uint, patch []byte, mediaType string) (*models.Todo, error)
 	
-	// DeleteTodo soft deletes a todo by ID
-	DeleteTodo(id uint) error
+	// DeleteTodo soft deletes a todo by ID, a non-zero version must match the current version
+	DeleteTodo(id uint, version uint) error
 	
 	// ListTodos retrieves todos with pagination and filtering
 	ListTodos(filters repository.TodoFilters, pagination repository.PaginationParams) ([]models.Todo, repository.PaginationResult, error)
 	
-	// ToggleTodoComplete toggles the completion status of a todo
-	ToggleTodoComplete(id uint) error
+	// ToggleTodoComplete toggles the completion status of a todo, a non-zero version must match the todo's
+	// Completing a todo with open blockers is refused unless force is set
+	ToggleTodoComplete(id uint, version uint, force bool) error
+
+	// ChangeTodoStatus moves a todo to another status of its workflow, enforcing transition rules,
+	// a non-zero version must match the todo's
+	ChangeTodoStatus(id, statusID uint, version uint, force bool) error
+
+	// AddBlocker records that a todo is blocked by another todo, refusing dependency cycles
+	AddBlocker(todoID, blockerID uint) error
+
+	// RemoveBlocker removes a blocker from a todo
+	RemoveBlocker(todoID, blockerID uint) error
+
+	// MoveTodo places a todo in manual order after afterID and/or before beforeID,
+	// optionally moving it to another category, a non-zero version must match the todo's
+	MoveTodo(id uint, version uint, beforeID, afterID, categoryID *uint) error
+
+	// BulkUpdateTodos applies an action to the selected todos in a single transaction
+	BulkUpdateTodos(op BulkOperation) (*BulkResult, error)
+
+	// ArchiveTodo archives a completed todo, hiding it from the default todo list,
+	// a non-zero version must match the todo's
+	ArchiveTodo(id uint, version uint) error
+
+	// UnarchiveTodo takes a todo out of the archive, a non-zero version must match the todo's
+	UnarchiveTodo(id uint, version uint) error
+
+	// ArchiveCompletedTodos archives all completed todos of a category, or of all categories when categoryID is nil
+	ArchiveCompletedTodos(categoryID *uint) (int64, error)
+
+	// AutoArchive archives todos that were completed longer than the given delay ago
+	AutoArchive(delay time.Duration) (int64, error)
+
+	// GetTodoHistory retrieves the change history of a todo, newest first
+	GetTodoHistory(id uint) ([]models.TodoRevision, error)
+
+	// RevertTodo restores the fields of a todo from the revision at a version, validated like UpdateTodo
+	// A non-zero expectedVersion must match the current version of the todo
+	RevertTodo(id, version, expectedVersion uint) (*models.Todo, error)
+
+	// RebalancePositions rewrites manual order positions once any of them is missing, duplicated or longer than maxLength
+	// Returns the number of todos updated
+	RebalancePositions(maxLength int) (int, error)
+
+	// ExportTodos streams the todos matching the filters to w as csv, jsonl or md, sorted like ListTodos
+	ExportTodos(filters repository.TodoFilters, pagination repository.PaginationParams, format string, w io.Writer) error
+
+	// ExportAccount writes a zip archive with all todos and categories to w
+	ExportAccount(w io.Writer) error
+
+	// ImportTodos creates the todos of a CSV, JSON, Todoist or Trello file in a single transaction,
+	// creating missing categories, and reports the outcome of every row
+	ImportTodos(req ImportRequest) (*ImportReport, error)
+
+	// QuickAddTodo creates a todo from a line of text with its due date, priority and category written in it,
+	// returning what was parsed alongside the todo
+	QuickAddTodo(req QuickAddRequest) (*QuickAddResult, error)
 }
 
 // CategoryService defines the interface for category business logic
 type CategoryService interface {
+	// WithContext returns a copy of the service whose changes are recorded in the audit log
+	// with the audit info of ctx
+	WithContext(ctx context.Context) CategoryService
+	
 	// CreateCategory creates a new category with validation
 	CreateCategory(category *models.Category) error
 	
@@ -35,14 +113,261 @@ type CategoryService interface {
 	GetCategoryByID(id uint) (*models.Category, error)
 	
 	// UpdateCategory updates an existing category with validation
+	// A non-zero category.Version must match the current version of the category
 	UpdateCategory(category *models.Category) error
+
+	// PatchCategory applies a JSON Merge Patch or JSON Patch to a category, validating and writing only changed fields
+	// A non-zero version must match the current version of the category
+	PatchCategory(id uint, version uint, patch []byte, mediaType string) (*models.Category, error)
 	
-	// DeleteCategory soft deletes a category by ID
-	DeleteCategory(id uint) error
+	// DeleteCategory soft deletes a category by ID, a non-zero version must match the current version
+	DeleteCategory(id uint, version uint) error
 	
 	// ListCategories retrieves categories with pagination and filtering
 	ListCategories(filters repository.CategoryFilters, pagination repository.PaginationParams) ([]models.Category, repository.PaginationResult, error)
 	
 	// GetAllCategories retrieves all categories without pagination (for dropdowns)
 	GetAllCategories() ([]models.Category, error)
-}
\ No newline at end of file
+}
+
+// CommentService defines the interface for comment business logic
+type CommentService interface {
+	// WithContext returns a copy of the service whose changes are recorded in the audit log
+	// with the audit info of ctx
+	WithContext(ctx context.Context) CommentService
+	
+	// CreateComment adds a comment to a todo and records its mentions
+	CreateComment(todoID uint, comment *models.Comment) error
+
+	// GetComment retrieves a comment of a todo including its edit history
+	GetComment(todoID, id uint) (*models.Comment, error)
+
+	// UpdateComment edits the body of a comment, keeping the previous body as a revision
+	UpdateComment(todoID uint, comment *models.Comment) error
+
+	// DeleteComment soft deletes a comment by ID
+	DeleteComment(todoID, id uint) error
+
+	// ListComments retrieves comments of a todo with pagination
+	ListComments(todoID uint, pagination repository.PaginationParams) ([]models.Comment, repository.PaginationResult, error)
+}
+
+// AttachmentService defines the interface for attachment business logic
+type AttachmentService interface {
+	// WithContext returns a copy of the service whose changes are recorded in the audit log
+	// with the audit info of ctx
+	WithContext(ctx context.Context) AttachmentService
+	
+	// UploadAttachment validates and stores an uploaded file for a todo
+	UploadAttachment(todoID uint, fileName string, content io.Reader) (*models.Attachment, error)
+
+	// ListAttachments retrieves all attachments of a todo
+	ListAttachments(todoID uint) ([]models.Attachment, error)
+
+	// OpenAttachment retrieves an attachment and opens its stored content for reading
+	OpenAttachment(todoID, id uint) (*models.Attachment, storage.Object, error)
+
+	// DeleteAttachment removes an attachment and its blob when no longer referenced
+	DeleteAttachment(todoID, id uint) error
+
+	// ReleaseBlobs deletes the stored blobs of purged attachments that no attachment references anymore
+	// Failures are logged only
+	ReleaseBlobs(storageKeys []string)
+}
+
+// WorkflowService defines the interface for workflow status business logic
+type WorkflowService interface {
+	// WithContext returns a copy of the service whose changes are recorded in the audit log
+	// with the audit info of ctx
+	WithContext(ctx context.Context) WorkflowService
+	
+	// EnsureDefaultWorkflow seeds the default workflow and assigns a status to todos without one
+	EnsureDefaultWorkflow() error
+
+	// GetWorkflow retrieves the effective statuses of a category, falling back to the default workflow
+	GetWorkflow(categoryID *uint) ([]models.WorkflowStatus, error)
+
+	// GetTransitions retrieves the transition rules of the effective workflow of a category
+	GetTransitions(categoryID *uint) ([]models.StatusTransition, error)
+
+	// CreateStatus adds a status to a workflow, copying the default workflow for a category on first use
+	CreateStatus(status *models.WorkflowStatus) error
+
+	// UpdateStatus updates the name, key, position or done flag of a status
+	UpdateStatus(status *models.WorkflowStatus) error
+
+	// DeleteStatus deletes a status that is not used by any todo
+	DeleteStatus(id uint) error
+
+	// CreateTransition allows todos to move between two statuses of the same workflow
+	CreateTransition(transition *models.StatusTransition) error
+
+	// DeleteTransition deletes a transition rule by ID
+	DeleteTransition(id uint) error
+
+	// CheckTransition returns an error when a todo may not move between the given statuses
+	CheckTransition(fromStatusID, toStatusID uint) error
+}
+
+// TrashService defines the interface for restoring and purging soft-deleted items
+type TrashService interface {
+	// WithContext returns a copy of the service whose changes are recorded in the audit log
+	// with the audit info of ctx
+	WithContext(ctx context.Context) TrashService
+	
+	// ListTodos retrieves soft-deleted todos with their purge time
+	ListTodos(pagination repository.PaginationParams) ([]models.TrashedTodo, repository.PaginationResult, error)
+
+	// ListCategories retrieves soft-deleted categories with their purge time
+	ListCategories(pagination repository.PaginationParams) ([]models.TrashedCategory, repository.PaginationResult, error)
+
+	// RestoreTodo restores a soft-deleted todo and its category
+	RestoreTodo(id uint) (*models.Todo, error)
+
+	// RestoreCategory restores a soft-deleted category
+	RestoreCategory(id uint) (*models.Category, error)
+
+	// DeleteTodo permanently deletes a soft-deleted todo and its attachments
+	DeleteTodo(id uint) error
+
+	// DeleteCategory permanently deletes a soft-deleted category
+	DeleteCategory(id uint) error
+
+	// PurgeExpired permanently deletes items that stayed in the trash longer than the retention period
+	PurgeExpired() (int, error)
+}
+
+// AuditService defines the interface for querying the audit log
+type AuditService interface {
+	// ListEvents retrieves audit events with pagination and filtering, newest first
+	ListEvents(filters repository.AuditFilters, pagination repository.PaginationParams) ([]models.AuditEvent, repository.PaginationResult, error)
+
+	// ExportEvents writes the audit events matching the filters to w as JSON Lines, oldest first
+	ExportEvents(filters repository.AuditFilters, w io.Writer) error
+}
+
+// WebhookService defines the interface for webhook subscriptions and deliveries
+type WebhookService interface {
+	// CreateSubscription creates a webhook subscription, generating a secret when none is given
+	CreateSubscription(subscription *models.WebhookSubscription) error
+
+	// GetSubscription retrieves a webhook subscription by its ID, without its secret
+	GetSubscription(id uint) (*models.WebhookSubscription, error)
+
+	// ListSubscriptions retrieves all webhook subscriptions, without their secrets
+	ListSubscriptions() ([]models.WebhookSubscription, error)
+
+	// UpdateSubscription updates a webhook subscription, keeping its secret when none is given
+	UpdateSubscription(subscription *models.WebhookSubscription) error
+
+	// DeleteSubscription deletes a webhook subscription and its delivery log
+	DeleteSubscription(id uint) error
+
+	// ListDeliveries retrieves the delivery log of a subscription, newest first
+	ListDeliveries(subscriptionID uint, pagination repository.PaginationParams) ([]models.WebhookDelivery, repository.PaginationResult, error)
+
+	// Redeliver queues a new delivery of the event of an earlier delivery
+	Redeliver(subscriptionID, deliveryID uint) (*models.WebhookDelivery, error)
+
+	// DeliverDue sends the deliveries that are due, retrying failed ones with exponential backoff
+	DeliverDue() (int, error)
+}
+
+// SyncService defines the interface for offline synchronization of todos and categories
+type SyncService interface {
+	// WithContext returns a copy of the service whose changes are recorded in the audit log
+	// with the audit info of ctx
+	WithContext(ctx context.Context) SyncService
+	
+	// Changes returns up to limit records changed after the since token, or all records for an empty token
+	Changes(since string, limit int) (*SyncChanges, error)
+
+	// Apply applies a batch of client mutations, resolving stale ones with the batch policy
+	Apply(batch SyncBatch) ([]SyncResult, error)
+}
+
+// IdempotencyService defines the interface for replaying responses to retried requests
+type IdempotencyService interface {
+	// Begin claims an idempotency key for a request with the given fingerprint
+	// Returns nil when the request should be handled, or the stored key whose response must be replayed
+	Begin(key, fingerprint string) (*models.IdempotencyKey, error)
+
+	// Complete stores the response to the request holding an idempotency key
+	Complete(key string, status int, headers models.Headers, body []byte) error
+
+	// Release gives up an idempotency key whose request failed, so a retry is handled again
+	Release(key string) error
+
+	// PurgeExpired removes idempotency keys older than the retention period
+	PurgeExpired() (int64, error)
+}
+
+// CalendarService defines the interface for iCalendar feeds of todos with a due date
+type CalendarService interface {
+	// CreateFeed creates a calendar feed with a new token, which is only returned here
+	CreateFeed(feed *models.CalendarFeed) error
+
+	// GetFeed retrieves a calendar feed by its ID
+	GetFeed(id uint) (*models.CalendarFeed, error)
+
+	// ListFeeds retrieves all calendar feeds
+	ListFeeds() ([]models.CalendarFeed, error)
+
+	// UpdateFeed updates the settings of a calendar feed, its token stays the same
+	UpdateFeed(feed *models.CalendarFeed) error
+
+	// DeleteFeed deletes a calendar feed, its URL stops working
+	DeleteFeed(id uint) error
+
+	// WriteFeed writes the iCalendar file of the feed with the given token to w
+	WriteFeed(token string, w io.Writer) error
+}
+
+// CalDAVService defines the interface for serving categories as CalDAV calendars of VTODO resources
+type CalDAVService interface {
+	// WithContext returns a copy of the service whose changes are recorded in the audit log
+	// with the audit info of ctx
+	WithContext(ctx context.Context) CalDAVService
+	
+	// Calendars returns the inbox for todos without a category followed by a calendar for every category
+	Calendars() ([]CalDAVCalendar, error)
+
+	// Calendar returns the calendar with the given name
+	Calendar(name string) (*CalDAVCalendar, error)
+
+	// CTag returns a tag that changes whenever a todo or category changes
+	CTag() (string, error)
+
+	// Objects returns the todos of a calendar that are not archived
+	Objects(calendar string) ([]CalDAVObject, error)
+
+	// Object returns a todo of a calendar by its resource name
+	Object(calendar, name string) (*CalDAVObject, error)
+
+	// PutObject creates or replaces a todo from a VTODO, returning whether it was created
+	PutObject(calendar, name string, data []byte, version uint, createOnly bool) (bool, error)
+
+	// DeleteObject deletes a todo of a calendar by its resource name
+	DeleteObject(calendar, name string, version uint) error
+}
+
+// SmartListService defines the interface for built-in smart lists and saved filters
+type SmartListService interface {
+	// ListSmartLists returns the built-in smart lists followed by the saved filters
+	ListSmartLists() ([]SmartList, error)
+
+	// GetSmartList returns a built-in smart list by its name or a saved filter by its ID
+	GetSmartList(id string) (*SmartList, error)
+
+	// CreateSavedFilter validates and stores a saved filter
+	CreateSavedFilter(filter *models.SavedFilter) (*SmartList, error)
+
+	// UpdateSavedFilter replaces the name, filters and sort order of a saved filter
+	UpdateSavedFilter(id string, filter *models.SavedFilter) (*SmartList, error)
+
+	// DeleteSavedFilter deletes a saved filter, built-in smart lists cannot be deleted
+	DeleteSavedFilter(id string) error
+
+	// SmartListTodos returns a page of the todos of a smart list, resolving relative dates at the time of the call
+	SmartListTodos(id string, pagination repository.PaginationParams) (*SmartList, []models.Todo, repository.PaginationResult, error)
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+)
+
+// SmartList is a named todo list defined by filters and a sort order
+// Built-in lists have a name as their ID, saved filters their numeric ID
+type SmartList struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	BuiltIn   bool                   `json:"built_in"`
+	Filters   repository.TodoFilters `json:"filters"`
+	SortBy    string                 `json:"sort_by"`
+	SortOrder string                 `json:"sort_order"`
+	CreatedAt *time.Time             `json:"created_at,omitempty"`
+	UpdatedAt *time.Time             `json:"updated_at,omitempty"`
+}
+
+// builtInSmartLists are the smart lists every user has, defined by filter queries evaluated when they are read
+var builtInSmartLists = []SmartList{
+	{ID: "today", Name: "Today", Filters: repository.TodoFilters{Query: "due:<=today open"}, SortBy: "due_date", SortOrder: "asc"},
+	{ID: "overdue", Name: "Overdue", Filters: repository.TodoFilters{Query: "overdue"}, SortBy: "due_date", SortOrder: "asc"},
+	{ID: "upcoming", Name: "Upcoming", Filters: repository.TodoFilters{Query: "due:>today due:<=7d open"}, SortBy: "due_date", SortOrder: "asc"},
+	{ID: "no-due-date", Name: "No due date", Filters: repository.TodoFilters{Query: "due:none open"}, SortBy: "position", SortOrder: "asc"},
+}
+
+// todoSortFields are the fields todo lists can be sorted by
+var todoSortFields = map[string]bool{
+	"title": true, "completed": true, "priority": true, "due_date": true,
+	"created_at": true, "updated_at": true, "position": true,
+}
+
+// smartListService implements SmartListService interface
+type smartListService struct {
+	savedFilterRepo repository.SavedFilterRepository
+	categoryRepo    repository.CategoryRepository
+	todoService     TodoService
+}
+
+// NewSmartListService creates a new smart list service
+// Todos of a list are read through the todo service, so its filters are validated like query parameters
+func NewSmartListService(savedFilterRepo repository.SavedFilterRepository, categoryRepo repository.CategoryRepository, todoService TodoService) SmartListService {
+	return &smartListService{
+		savedFilterRepo: savedFilterRepo,
+		categoryRepo:    categoryRepo,
+		todoService:     todoService,
+	}
+}
+
+// ListSmartLists returns the built-in smart lists followed by the saved filters
+func (s *smartListService) ListSmartLists() ([]SmartList, error) {
+	saved, err := s.savedFilterRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([]SmartList, 0, len(builtInSmartLists)+len(saved))
+	for _, list := range builtInSmartLists {
+		list.BuiltIn = true
+		lists = append(lists, list)
+	}
+	for i := range saved {
+		list, err := savedSmartList(&saved[i])
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, *list)
+	}
+	return lists, nil
+}
+
+// GetSmartList returns a built-in smart list by its name or a saved filter by its ID
+func (s *smartListService) GetSmartList(id string) (*SmartList, error) {
+	for _, list := range builtInSmartLists {
+		if list.ID == id {
+			list.BuiltIn = true
+			return &list, nil
+		}
+	}
+
+	filterID, err := parseSavedFilterID(id)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := s.savedFilterRepo.GetByID(filterID)
+	if err != nil {
+		return nil, err
+	}
+	return savedSmartList(filter)
+}
+
+// CreateSavedFilter creates a smart list from a saved filter
+func (s *smartListService) CreateSavedFilter(filter *models.SavedFilter) (*SmartList, error) {
+	if err := s.validateSavedFilter(filter); err != nil {
+		return nil, err
+	}
+
+	filter.ID = 0
+	if err := s.savedFilterRepo.Create(filter); err != nil {
+		return nil, err
+	}
+	return savedSmartList(filter)
+}
+
+// UpdateSavedFilter replaces the name, filters and sort order of a saved filter
+func (s *smartListService) UpdateSavedFilter(id string, filter *models.SavedFilter) (*SmartList, error) {
+	filterID, err := parseSavedFilterID(id)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.savedFilterRepo.GetByID(filterID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.ID = existing.ID
+	if err := s.validateSavedFilter(filter); err != nil {
+		return nil, err
+	}
+	filter.CreatedAt = existing.CreatedAt
+	if err := s.savedFilterRepo.Update(filter); err != nil {
+		return nil, err
+	}
+	return savedSmartList(filter)
+}
+
+// DeleteSavedFilter deletes a saved filter, built-in smart lists cannot be deleted
+func (s *smartListService) DeleteSavedFilter(id string) error {
+	filterID, err := parseSavedFilterID(id)
+	if err != nil {
+		return err
+	}
+	return s.savedFilterRepo.Delete(filterID)
+}
+
+// SmartListTodos returns a page of the todos of a smart list
+// The list's sort order is used unless it has none, then the requested one applies
+func (s *smartListService) SmartListTodos(id string, pagination repository.PaginationParams) (*SmartList, []models.Todo, repository.PaginationResult, error) {
+	list, err := s.GetSmartList(id)
+	if err != nil {
+		return nil, nil, repository.PaginationResult{}, err
+	}
+
+	if list.SortBy != "" {
+		pagination.SortBy = list.SortBy
+		pagination.SortOrder = list.SortOrder
+	}
+	todos, result, err := s.todoService.ListTodos(list.Filters, pagination)
+	if err != nil {
+		return nil, nil, repository.PaginationResult{}, err
+	}
+	return list, todos, result, nil
+}
+
+// validateSavedFilter checks the name, filters and sort order of a saved filter, storing the filters normalized
+func (s *smartListService) validateSavedFilter(filter *models.SavedFilter) error {
+	filter.Name = strings.TrimSpace(filter.Name)
+	if filter.Name == "" {
+		return errors.New("smart list name is required")
+	}
+	if len(filter.Name) > 100 {
+		return errors.New("smart list name cannot exceed 100 characters")
+	}
+	if existing, err := s.savedFilterRepo.GetByName(filter.Name); err == nil && existing.ID != filter.ID {
+		return errors.New("smart list with this name already exists")
+	} else if err != nil && !strings.Contains(err.Error(), "not found") {
+		return err
+	}
+
+	// Filters are the query parameters of GET /api/todos, unknown ones are refused so typos don't go unnoticed
+	var filters repository.TodoFilters
+	if len(bytes.TrimSpace(filter.Filters)) > 0 && string(bytes.TrimSpace(filter.Filters)) != "null" {
+		decoder := json.NewDecoder(bytes.NewReader(filter.Filters))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&filters); err != nil {
+			return errors.New("invalid smart list filters: " + err.Error())
+		}
+	}
+	if err := validateTodoFilters(&filters); err != nil {
+		return err
+	}
+	for _, value := range filters.CategoryID {
+		if value == repository.FilterValueNone {
+			continue
+		}
+		id, _ := strconv.ParseUint(value, 10, 32)
+		if _, err := s.categoryRepo.GetByID(uint(id)); err != nil {
+			return errors.New("specified category does not exist")
+		}
+	}
+
+	if filter.SortBy != "" && !todoSortFields[filter.SortBy] {
+		return errors.New("invalid sort field: must be title, completed, priority, due_date, created_at, updated_at or position")
+	}
+	if filter.SortOrder != "" && filter.SortOrder != "asc" && filter.SortOrder != "desc" {
+		return errors.New("invalid sort order: must be asc or desc")
+	}
+	if filter.SortBy == "" {
+		filter.SortOrder = ""
+	}
+
+	normalized, err := json.Marshal(filters)
+	if err != nil {
+		return err
+	}
+	filter.Filters = normalized
+	return nil
+}
+
+// savedSmartList returns the smart list of a saved filter
+func savedSmartList(filter *models.SavedFilter) (*SmartList, error) {
+	list := &SmartList{
+		ID:        strconv.FormatUint(uint64(filter.ID), 10),
+		Name:      filter.Name,
+		SortBy:    filter.SortBy,
+		SortOrder: filter.SortOrder,
+		CreatedAt: &filter.CreatedAt,
+		UpdatedAt: &filter.UpdatedAt,
+	}
+	if len(filter.Filters) > 0 {
+		if err := json.Unmarshal(filter.Filters, &list.Filters); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// parseSavedFilterID parses the ID of a saved filter, refusing the names of built-in smart lists
+func parseSavedFilterID(id string) (uint, error) {
+	for _, list := range builtInSmartLists {
+		if list.ID == id {
+			return 0, errors.New("built-in smart lists cannot be changed")
+		}
+	}
+	filterID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil || filterID == 0 {
+		return 0, errors.New("smart list not found")
+	}
+	return uint(filterID), nil
+}
@@ -0,0 +1,503 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/jsonpatch"
+)
+
+// Conflict resolution policies of a sync batch
+const (
+	// SyncPolicyLastWriterWins applies a stale mutation when the client changed the record after the server did
+	SyncPolicyLastWriterWins = "lww"
+	// SyncPolicyMerge applies the fields of a stale mutation that the server has not changed since the client's version
+	SyncPolicyMerge = "merge"
+)
+
+// Sync mutation operations
+const (
+	SyncOpCreate = "create"
+	SyncOpUpdate = "update"
+	SyncOpDelete = "delete"
+)
+
+// Outcomes of a sync mutation
+const (
+	SyncStatusApplied  = "applied"
+	SyncStatusMerged   = "merged"
+	SyncStatusConflict = "conflict"
+	SyncStatusNotFound = "not_found"
+	SyncStatusRejected = "rejected"
+)
+
+// syncTodoFields are the todo fields a sync mutation may write
+var syncTodoFields = map[string]bool{
+	"title": true, "description": true, "completed": true, "priority": true,
+	"due_date": true, "recurrence": true, "category_id": true, "status_id": true,
+}
+
+// syncCategoryFields are the category fields a sync mutation may write
+var syncCategoryFields = map[string]bool{
+	"name": true, "color": true,
+}
+
+// SyncChanges is the response to a pull: the changed records and the token to pull from next
+type SyncChanges struct {
+	Token string `json:"token"`
+	*repository.SyncChangeSet
+}
+
+// SyncBatch is a batch of client mutations pushed in one request
+type SyncBatch struct {
+	Policy    string         `json:"policy" binding:"omitempty,oneof=lww merge"`
+	Mutations []SyncMutation `json:"mutations" binding:"required,min=1,max=500,dive"`
+}
+
+// SyncMutation is a change a client made while offline
+// BaseVersion is the version of the record the change was made on, Base optionally holds the
+// field values at that version for records without history, such as categories
+type SyncMutation struct {
+	ClientID    string          `json:"client_id" binding:"max=100"`
+	Entity      string          `json:"entity" binding:"required,oneof=todo category"`
+	Op          string          `json:"op" binding:"required,oneof=create update delete"`
+	ID          uint            `json:"id"`
+	BaseVersion uint            `json:"base_version"`
+	Fields      json.RawMessage `json:"fields"`
+	Base        json.RawMessage `json:"base"`
+	UpdatedAt   *time.Time      `json:"updated_at"`
+}
+
+// SyncResult is the outcome of one mutation, Data holds the current server state of the record
+type SyncResult struct {
+	ClientID  string      `json:"client_id,omitempty"`
+	Entity    string      `json:"entity"`
+	Op        string      `json:"op"`
+	ID        uint        `json:"id,omitempty"`
+	Status    string      `json:"status"`
+	Conflicts []string    `json:"conflicts,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// syncService implements SyncService interface
+type syncService struct {
+	syncRepo        repository.SyncRepository
+	todoRepo        repository.TodoRepository
+	todoService     TodoService
+	categoryService CategoryService
+}
+
+// NewSyncService creates a new sync service
+func NewSyncService(syncRepo repository.SyncRepository, todoRepo repository.TodoRepository, todoService TodoService, categoryService CategoryService) SyncService {
+	return &syncService{
+		syncRepo:        syncRepo,
+		todoRepo:        todoRepo,
+		todoService:     todoService,
+		categoryService: categoryService,
+	}
+}
+
+// WithContext returns a copy of the service whose changes are recorded with the audit info of ctx
+func (s *syncService) WithContext(ctx context.Context) SyncService {
+	scoped := *s
+	scoped.syncRepo = s.syncRepo.WithContext(ctx)
+	scoped.todoRepo = s.todoRepo.WithContext(ctx)
+	scoped.todoService = s.todoService.WithContext(ctx)
+	scoped.categoryService = s.categoryService.WithContext(ctx)
+	return &scoped
+}
+
+// Changes returns the records changed after the since token
+// An empty token returns all todos and categories
+func (s *syncService) Changes(since string, limit int) (*SyncChanges, error) {
+	var set *repository.SyncChangeSet
+	if since == "" {
+		snapshot, err := s.syncRepo.Snapshot()
+		if err != nil {
+			return nil, err
+		}
+		set = snapshot
+	} else {
+		token, err := strconv.ParseUint(since, 10, 64)
+		if err != nil {
+			return nil, errors.New("invalid sync token")
+		}
+		latest, err := s.syncRepo.LatestToken()
+		if err != nil {
+			return nil, err
+		}
+		if token > latest {
+			return nil, errors.New("invalid sync token: token is ahead of the server")
+		}
+		if set, err = s.syncRepo.Changes(token, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	return &SyncChanges{Token: strconv.FormatUint(set.Token, 10), SyncChangeSet: set}, nil
+}
+
+// Apply applies a batch of mutations in order and reports the outcome of each
+// Mutations are independent: a conflicting or rejected mutation does not stop the others
+func (s *syncService) Apply(batch SyncBatch) ([]SyncResult, error) {
+	if batch.Policy == "" {
+		batch.Policy = SyncPolicyLastWriterWins
+	}
+
+	results := make([]SyncResult, 0, len(batch.Mutations))
+	for _, mutation := range batch.Mutations {
+		result := SyncResult{
+			ClientID: mutation.ClientID,
+			Entity:   mutation.Entity,
+			Op:       mutation.Op,
+			ID:       mutation.ID,
+		}
+
+		var err error
+		if mutation.Op != SyncOpCreate && mutation.ID == 0 {
+			err = errors.New("invalid mutation: id is required")
+		} else if mutation.Entity == models.EntityTodo {
+			err = s.applyTodo(batch.Policy, mutation, &result)
+		} else {
+			err = s.applyCategory(batch.Policy, mutation, &result)
+		}
+
+		if err != nil {
+			switch {
+			case strings.Contains(err.Error(), "not found"):
+				result.Status = SyncStatusNotFound
+			case strings.Contains(err.Error(), "modified by another request"),
+				strings.Contains(err.Error(), "already exists"):
+				result.Status = SyncStatusConflict
+			case isSyncValidationError(err):
+				result.Status = SyncStatusRejected
+			default:
+				return nil, err
+			}
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// applyTodo applies a mutation of a todo
+func (s *syncService) applyTodo(policy string, mutation SyncMutation, result *SyncResult) error {
+	fields, err := decodeSyncFields(mutation.Fields, syncTodoFields)
+	if err != nil {
+		return err
+	}
+
+	if mutation.Op == SyncOpCreate {
+		var todo models.Todo
+		if err := json.Unmarshal(mutation.Fields, &todo); err != nil {
+			return errors.New("invalid mutation: " + err.Error())
+		}
+		created := &models.Todo{
+			Title:       todo.Title,
+			Description: todo.Description,
+			Completed:   todo.Completed,
+			Priority:    todo.Priority,
+			DueDate:     todo.DueDate,
+			Recurrence:  todo.Recurrence,
+			CategoryID:  todo.CategoryID,
+			StatusID:    todo.StatusID,
+		}
+		if err := s.todoService.CreateTodo(created); err != nil {
+			return err
+		}
+		result.ID = created.ID
+		result.Status = SyncStatusApplied
+		result.Data, err = s.todoService.GetTodoByID(created.ID)
+		return err
+	}
+
+	current, err := s.todoService.GetTodoByID(mutation.ID)
+	if err != nil {
+		// Deleting a todo that is already gone has the intended effect
+		if mutation.Op == SyncOpDelete && strings.Contains(err.Error(), "not found") {
+			result.Status = SyncStatusApplied
+			return nil
+		}
+		return err
+	}
+
+	stale := mutation.BaseVersion != 0 && mutation.BaseVersion != current.Version
+	if mutation.Op == SyncOpDelete {
+		if stale && !wins(policy, mutation, current.UpdatedAt) {
+			result.Status = SyncStatusConflict
+			result.Data = current
+			return nil
+		}
+		if err := s.todoService.DeleteTodo(current.ID, current.Version); err != nil {
+			return err
+		}
+		result.Status = SyncStatusApplied
+		return nil
+	}
+
+	// Resolve the fields to write against the current state of the todo
+	status := SyncStatusApplied
+	var conflicts []string
+	if stale {
+		switch {
+		case policy == SyncPolicyMerge:
+			base, err := s.todoBase(mutation)
+			if err != nil {
+				return err
+			}
+			if fields, conflicts, err = mergeFields(fields, base, current); err != nil {
+				return err
+			}
+			if len(conflicts) > 0 {
+				status = SyncStatusMerged
+			}
+		case !wins(policy, mutation, current.UpdatedAt):
+			fields = nil
+			conflicts = fieldNames(mutation.Fields)
+		}
+		if len(fields) == 0 && len(conflicts) > 0 {
+			status = SyncStatusConflict
+		}
+	}
+
+	todo := current
+	if len(fields) > 0 {
+		patch, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		if todo, err = s.todoService.PatchTodo(current.ID, current.Version, patch, jsonpatch.MediaTypeMergePatch); err != nil {
+			return err
+		}
+	}
+	result.Status = status
+	result.Conflicts = conflicts
+	result.Data = todo
+	return nil
+}
+
+// applyCategory applies a mutation of a category
+func (s *syncService) applyCategory(policy string, mutation SyncMutation, result *SyncResult) error {
+	fields, err := decodeSyncFields(mutation.Fields, syncCategoryFields)
+	if err != nil {
+		return err
+	}
+
+	if mutation.Op == SyncOpCreate {
+		var category models.Category
+		if err := json.Unmarshal(mutation.Fields, &category); err != nil {
+			return errors.New("invalid mutation: " + err.Error())
+		}
+		created := &models.Category{Name: category.Name, Color: category.Color}
+		if err := s.categoryService.CreateCategory(created); err != nil {
+			return err
+		}
+		result.ID = created.ID
+		result.Status = SyncStatusApplied
+		result.Data = created
+		return nil
+	}
+
+	current, err := s.categoryService.GetCategoryByID(mutation.ID)
+	if err != nil {
+		// Deleting a category that is already gone has the intended effect
+		if mutation.Op == SyncOpDelete && strings.Contains(err.Error(), "not found") {
+			result.Status = SyncStatusApplied
+			return nil
+		}
+		return err
+	}
+
+	stale := mutation.BaseVersion != 0 && mutation.BaseVersion != current.Version
+	if mutation.Op == SyncOpDelete {
+		if stale && !wins(policy, mutation, current.UpdatedAt) {
+			result.Status = SyncStatusConflict
+			result.Data = current
+			return nil
+		}
+		if err := s.categoryService.DeleteCategory(current.ID, current.Version); err != nil {
+			return err
+		}
+		result.Status = SyncStatusApplied
+		return nil
+	}
+
+	status := SyncStatusApplied
+	var conflicts []string
+	if stale {
+		switch {
+		case policy == SyncPolicyMerge:
+			// Categories have no history, the client sends the values its change was based on
+			base, err := decodeSyncFields(mutation.Base, syncCategoryFields)
+			if err != nil {
+				return err
+			}
+			if fields, conflicts, err = mergeFields(fields, base, current); err != nil {
+				return err
+			}
+			if len(conflicts) > 0 {
+				status = SyncStatusMerged
+			}
+		case !wins(policy, mutation, current.UpdatedAt):
+			fields = nil
+			conflicts = fieldNames(mutation.Fields)
+		}
+		if len(fields) == 0 && len(conflicts) > 0 {
+			status = SyncStatusConflict
+		}
+	}
+
+	category := current
+	if len(fields) > 0 {
+		patch, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		if category, err = s.categoryService.PatchCategory(current.ID, current.Version, patch, jsonpatch.MediaTypeMergePatch); err != nil {
+			return err
+		}
+	}
+	result.Status = status
+	result.Conflicts = conflicts
+	result.Data = category
+	return nil
+}
+
+// todoBase returns the fields of a todo at the version a mutation was based on
+// The client's base values are used when the revision is not recorded
+func (s *syncService) todoBase(mutation SyncMutation) (map[string]json.RawMessage, error) {
+	revision, err := s.todoRepo.GetRevision(mutation.ID, mutation.BaseVersion)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return decodeSyncFields(mutation.Base, syncTodoFields)
+		}
+		return nil, err
+	}
+
+	data, err := json.Marshal(revision.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	base := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, err
+	}
+	return base, nil
+}
+
+// wins reports whether a stale mutation is applied under the last-writer-wins policy
+// The client wins when it changed the record after the server's last change
+func wins(policy string, mutation SyncMutation, serverUpdatedAt time.Time) bool {
+	return policy == SyncPolicyLastWriterWins && mutation.UpdatedAt != nil && mutation.UpdatedAt.After(serverUpdatedAt)
+}
+
+// mergeFields resolves the fields of a stale mutation against the current record
+// A field is applied when the server still has its base value and is a conflict when both sides
+// changed it to different values; without a known base value any difference is a conflict
+func mergeFields(fields, base map[string]json.RawMessage, current interface{}) (map[string]json.RawMessage, []string, error) {
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, nil, err
+	}
+	server := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &server); err != nil {
+		return nil, nil, err
+	}
+
+	merged := map[string]json.RawMessage{}
+	var conflicts []string
+	for field, value := range fields {
+		switch {
+		case sameValue(server[field], value):
+			// Both sides agree, nothing to write
+		case hasField(base, field) && sameValue(server[field], base[field]):
+			merged[field] = value
+		default:
+			conflicts = append(conflicts, field)
+		}
+	}
+	sort.Strings(conflicts)
+	return merged, conflicts, nil
+}
+
+// decodeSyncFields decodes the fields of a mutation, refusing fields a client may not write
+func decodeSyncFields(raw json.RawMessage, allowed map[string]bool) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if len(raw) == 0 || string(raw) == "null" {
+		return fields, nil
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, errors.New("invalid mutation: fields must be a JSON object")
+	}
+	for field := range fields {
+		if !allowed[field] {
+			return nil, errors.New("invalid mutation: unknown or read-only field " + field)
+		}
+	}
+	return fields, nil
+}
+
+// sameValue reports whether two JSON values are equal, a missing value counts as null
+// Strings holding timestamps are compared as instants
+func sameValue(a, b json.RawMessage) bool {
+	var left, right interface{}
+	if len(a) > 0 {
+		if err := json.Unmarshal(a, &left); err != nil {
+			return false
+		}
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &right); err != nil {
+			return false
+		}
+	}
+
+	leftText, leftIsText := left.(string)
+	rightText, rightIsText := right.(string)
+	if leftIsText && rightIsText {
+		leftTime, leftErr := time.Parse(time.RFC3339Nano, leftText)
+		rightTime, rightErr := time.Parse(time.RFC3339Nano, rightText)
+		if leftErr == nil && rightErr == nil {
+			return leftTime.Equal(rightTime)
+		}
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// hasField reports whether a field is present in a set of fields
+func hasField(fields map[string]json.RawMessage, field string) bool {
+	_, ok := fields[field]
+	return ok
+}
+
+// fieldNames returns the sorted names of the fields of a mutation
+func fieldNames(raw json.RawMessage) []string {
+	fields := map[string]json.RawMessage{}
+	_ = json.Unmarshal(raw, &fields)
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isSyncValidationError reports whether an error describes an invalid mutation
+func isSyncValidationError(err error) bool {
+	for _, keyword := range []string{"invalid", "required", "does not exist", "exceed", "past", "not allowed", "open blockers", "cannot"} {
+		if strings.Contains(err.Error(), keyword) {
+			return true
+		}
+	}
+	return false
+}
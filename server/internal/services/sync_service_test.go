@@ -0,0 +1,202 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"todo-backend/internal/models"
+)
+
+func TestSameValue(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{name: "equal strings", a: `"a"`, b: `"a"`, want: true},
+		{name: "different strings", a: `"a"`, b: `"b"`},
+		{name: "equal numbers", a: `1`, b: `1.0`, want: true},
+		{name: "different numbers", a: `1`, b: `2`},
+		{name: "number and string", a: `1`, b: `"1"`},
+		{name: "booleans", a: `true`, b: `true`, want: true},
+		{name: "null and missing", a: `null`, b: ``, want: true},
+		{name: "both missing", a: ``, b: ``, want: true},
+		{name: "missing and value", a: ``, b: `"a"`},
+		{name: "false and missing", a: `false`, b: ``},
+		{name: "same instant in other zones", a: `"2024-01-10T10:00:00Z"`, b: `"2024-01-10T11:00:00+01:00"`, want: true},
+		{name: "same instant with fractions", a: `"2024-01-10T10:00:00.000Z"`, b: `"2024-01-10T10:00:00Z"`, want: true},
+		{name: "different instants", a: `"2024-01-10T10:00:00Z"`, b: `"2024-01-10T10:00:01Z"`},
+		{name: "time and plain string", a: `"2024-01-10T10:00:00Z"`, b: `"2024-01-10"`},
+		{name: "arrays in order", a: `["a","b"]`, b: `["a","b"]`, want: true},
+		{name: "arrays out of order", a: `["a","b"]`, b: `["b","a"]`},
+		{name: "objects", a: `{"a":1,"b":2}`, b: `{"b":2,"a":1}`, want: true},
+		{name: "invalid JSON", a: `{`, b: `{`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameValue(json.RawMessage(tt.a), json.RawMessage(tt.b)); got != tt.want {
+				t.Errorf("sameValue(%s, %s) = %v; want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got := sameValue(json.RawMessage(tt.b), json.RawMessage(tt.a)); got != tt.want {
+				t.Errorf("sameValue(%s, %s) = %v; want %v", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeFields(t *testing.T) {
+	due := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+	current := &models.Todo{
+		Title:       "Server title",
+		Description: "Shared description",
+		Priority:    models.PriorityHigh,
+		DueDate:     &due,
+	}
+
+	tests := []struct {
+		name          string
+		fields        string
+		base          string
+		wantMerged    string
+		wantConflicts []string
+	}{
+		{
+			name:       "unchanged on the server",
+			fields:     `{"description":"Client description"}`,
+			base:       `{"description":"Shared description"}`,
+			wantMerged: `{"description":"Client description"}`,
+		},
+		{
+			name:          "changed on both sides",
+			fields:        `{"title":"Client title"}`,
+			base:          `{"title":"Base title"}`,
+			wantMerged:    `{}`,
+			wantConflicts: []string{"title"},
+		},
+		{
+			name:       "both sides agree",
+			fields:     `{"title":"Server title"}`,
+			base:       `{"title":"Base title"}`,
+			wantMerged: `{}`,
+		},
+		{
+			name:          "no base value",
+			fields:        `{"title":"Client title"}`,
+			base:          `{}`,
+			wantMerged:    `{}`,
+			wantConflicts: []string{"title"},
+		},
+		{
+			name:       "no base value but equal",
+			fields:     `{"priority":"high"}`,
+			wantMerged: `{}`,
+		},
+		{
+			name:       "due date in another zone is unchanged",
+			fields:     `{"due_date":"2024-02-01T00:00:00Z"}`,
+			base:       `{"due_date":"2024-01-10T11:00:00+01:00"}`,
+			wantMerged: `{"due_date":"2024-02-01T00:00:00Z"}`,
+		},
+		{
+			name:       "clearing a field the server kept",
+			fields:     `{"due_date":null}`,
+			base:       `{"due_date":"2024-01-10T10:00:00Z"}`,
+			wantMerged: `{"due_date":null}`,
+		},
+		{
+			name:       "setting a field that was unset",
+			fields:     `{"category_id":3}`,
+			base:       `{"category_id":null}`,
+			wantMerged: `{"category_id":3}`,
+		},
+		{
+			name:          "mixed",
+			fields:        `{"title":"Client title","description":"Client description","priority":"low","completed":true}`,
+			base:          `{"title":"Base title","description":"Shared description","priority":"medium"}`,
+			wantMerged:    `{"description":"Client description"}`,
+			wantConflicts: []string{"completed", "priority", "title"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts, err := mergeFields(decodeFields(t, tt.fields), decodeFields(t, tt.base), current)
+			if err != nil {
+				t.Fatalf("mergeFields: %v", err)
+			}
+			if !reflect.DeepEqual(merged, decodeFields(t, tt.wantMerged)) {
+				data, _ := json.Marshal(merged)
+				t.Errorf("merged = %s; want %s", data, tt.wantMerged)
+			}
+			if !reflect.DeepEqual(conflicts, tt.wantConflicts) {
+				t.Errorf("conflicts = %q; want %q", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestDecodeSyncFields(t *testing.T) {
+	allowed := map[string]bool{"title": true, "completed": true}
+	tests := []struct {
+		raw     string
+		want    int
+		wantErr bool
+	}{
+		{raw: ``, want: 0},
+		{raw: `null`, want: 0},
+		{raw: `{}`, want: 0},
+		{raw: `{"title":"a","completed":true}`, want: 2},
+		{raw: `{"version":3}`, wantErr: true},
+		{raw: `[]`, wantErr: true},
+		{raw: `"title"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		fields, err := decodeSyncFields(json.RawMessage(tt.raw), allowed)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("decodeSyncFields(%s) error = %v; want error %v", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && len(fields) != tt.want {
+			t.Errorf("decodeSyncFields(%s) = %d fields; want %d", tt.raw, len(fields), tt.want)
+		}
+	}
+}
+
+func TestWins(t *testing.T) {
+	server := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+	later := server.Add(time.Second)
+	earlier := server.Add(-time.Second)
+	tests := []struct {
+		name      string
+		policy    string
+		updatedAt *time.Time
+		want      bool
+	}{
+		{name: "later client change", policy: SyncPolicyLastWriterWins, updatedAt: &later, want: true},
+		{name: "earlier client change", policy: SyncPolicyLastWriterWins, updatedAt: &earlier},
+		{name: "same time", policy: SyncPolicyLastWriterWins, updatedAt: &server},
+		{name: "no client time", policy: SyncPolicyLastWriterWins},
+		{name: "merge policy", policy: SyncPolicyMerge, updatedAt: &later},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wins(tt.policy, SyncMutation{UpdatedAt: tt.updatedAt}, server); got != tt.want {
+				t.Errorf("wins = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// decodeFields decodes a JSON object into raw fields, an empty string is no fields
+func decodeFields(t *testing.T, raw string) map[string]json.RawMessage {
+	t.Helper()
+	fields := map[string]json.RawMessage{}
+	if raw == "" {
+		return fields
+	}
+	if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+		t.Fatalf("invalid fields %s: %v", raw, err)
+	}
+	return fields
+}
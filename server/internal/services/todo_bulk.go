@@ -0,0 +1,300 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+)
+
+// MaxBulkTodos is the maximum number of todos a single bulk operation may touch
+const MaxBulkTodos = 1000
+
+// Bulk actions
+const (
+	BulkActionComplete    = "complete"
+	BulkActionUncomplete  = "uncomplete"
+	BulkActionDelete      = "delete"
+	BulkActionSetPriority = "set_priority"
+	BulkActionSetCategory = "set_category"
+	BulkActionAddTag      = "add_tag"
+	BulkActionSetDueDate  = "set_due_date"
+)
+
+// BulkOperation selects todos by IDs or by filters and applies one action to them
+type BulkOperation struct {
+	IDs    []uint                  `json:"ids"`
+	Filter *repository.TodoFilters `json:"filter"`
+	Action string                  `json:"action" binding:"required"`
+
+	// Action arguments
+	Priority   models.Priority `json:"priority"`
+	CategoryID *uint           `json:"category_id"`
+	Tag        string          `json:"tag"`
+	DueDate    *time.Time      `json:"due_date"`
+
+	// Force completes todos with open blockers
+	Force bool `json:"force"`
+	// DryRun only reports which todos would be changed
+	DryRun bool `json:"dry_run"`
+}
+
+// BulkResult summarizes a bulk operation
+type BulkResult struct {
+	Action    string                      `json:"action"`
+	DryRun    bool                        `json:"dry_run"`
+	Matched   int                         `json:"matched"`
+	Succeeded int                         `json:"succeeded"`
+	Failed    int                         `json:"failed"`
+	Results   []repository.BulkItemResult `json:"results"`
+}
+
+// BulkUpdateTodos applies an action to the selected todos in a single transaction
+// Todos that cannot be changed are reported as failed without affecting the others
+func (s *todoService) BulkUpdateTodos(op BulkOperation) (*BulkResult, error) {
+	if err := s.validateBulkOperation(&op); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.selectBulkTodos(op)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := s.todoRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[uint]*models.Todo, len(todos))
+	for i := range todos {
+		found[todos[i].ID] = &todos[i]
+	}
+
+	// Tags are created once for the whole operation
+	var tagID uint
+	if op.Action == BulkActionAddTag && !op.DryRun {
+		tag, err := s.todoRepo.FindOrCreateTag(op.Tag)
+		if err != nil {
+			return nil, err
+		}
+		tagID = tag.ID
+	}
+
+	// Prepare the change of every todo, collecting the ones that cannot be changed
+	results := make([]repository.BulkItemResult, 0, len(ids))
+	items := make([]repository.BulkItem, 0, len(ids))
+	workflows := make(map[uint][]models.WorkflowStatus)
+	for _, id := range ids {
+		todo, ok := found[id]
+		if !ok {
+			results = append(results, repository.BulkItemResult{ID: id, Error: "todo not found"})
+			continue
+		}
+
+		item, err := s.planBulkItem(op, todo, tagID, workflows)
+		if err != nil {
+			results = append(results, repository.BulkItemResult{ID: id, Error: err.Error()})
+			continue
+		}
+		if op.DryRun {
+			results = append(results, repository.BulkItemResult{ID: id, Success: true})
+			continue
+		}
+		items = append(items, item)
+	}
+
+	if len(items) > 0 {
+		applied, err := s.todoRepo.BulkApply(items)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, applied...)
+	}
+
+	// Report results in selection order
+	position := make(map[uint]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return position[results[i].ID] < position[results[j].ID]
+	})
+
+	result := &BulkResult{
+		Action:  op.Action,
+		DryRun:  op.DryRun,
+		Matched: len(ids),
+		Results: results,
+	}
+	for _, item := range results {
+		if item.Success {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// selectBulkTodos resolves the todo IDs a bulk operation applies to
+func (s *todoService) selectBulkTodos(op BulkOperation) ([]uint, error) {
+	if len(op.IDs) > 0 {
+		seen := make(map[uint]bool, len(op.IDs))
+		ids := make([]uint, 0, len(op.IDs))
+		for _, id := range op.IDs {
+			if id == 0 {
+				return nil, errors.New("invalid todo ID")
+			}
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+
+	filters := *op.Filter
+	if err := validateTodoFilters(&filters); err != nil {
+		return nil, err
+	}
+
+	// Select one more than allowed to detect selections that are too large
+	ids, err := s.todoRepo.ListIDs(filters, MaxBulkTodos+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > MaxBulkTodos {
+		return nil, fmt.Errorf("invalid selection: filter matches more than %d todos", MaxBulkTodos)
+	}
+	return ids, nil
+}
+
+// planBulkItem prepares the change of one todo, returning an error when the todo cannot be changed
+func (s *todoService) planBulkItem(op BulkOperation, todo *models.Todo, tagID uint, workflows map[uint][]models.WorkflowStatus) (repository.BulkItem, error) {
+	item := repository.BulkItem{ID: todo.ID}
+
+	switch op.Action {
+	case BulkActionComplete, BulkActionUncomplete:
+		done := op.Action == BulkActionComplete
+		if todo.Completed == done {
+			return item, nil
+		}
+		// Blockers are checked again with the todo locked, a dry run only sees the current ones
+		item.RejectBlocked = done && !op.Force
+		if item.RejectBlocked && op.DryRun && todo.Blocked {
+			return item, errors.New("todo has open blockers")
+		}
+		workflow, err := s.cachedWorkflow(todo.CategoryID, workflows)
+		if err != nil {
+			return item, err
+		}
+		target, err := s.pickStatus(workflow, todo.StatusID, done)
+		if err != nil {
+			return item, err
+		}
+		item.StatusID = &target.ID
+		item.Completed = target.IsDone
+
+	case BulkActionDelete:
+		item.Delete = true
+
+	case BulkActionSetPriority:
+		item.Priority = op.Priority
+
+	case BulkActionSetCategory:
+		item.SetCategory = true
+		item.CategoryID = op.CategoryID
+
+		// The todo may move to another workflow, keep an equivalent status
+		moved := *todo
+		moved.CategoryID = op.CategoryID
+		if err := s.applyStatus(&moved, todo); err != nil {
+			return item, err
+		}
+		if moved.StatusID != nil && (todo.StatusID == nil || *moved.StatusID != *todo.StatusID) {
+			item.StatusID = moved.StatusID
+			item.Completed = moved.Completed
+		}
+
+	case BulkActionAddTag:
+		item.AddTagID = tagID
+
+	case BulkActionSetDueDate:
+		item.SetDueDate = true
+		item.DueDate = op.DueDate
+	}
+
+	return item, nil
+}
+
+// cachedWorkflow returns the workflow of a category, loading each workflow only once per bulk operation
+func (s *todoService) cachedWorkflow(categoryID *uint, workflows map[uint][]models.WorkflowStatus) ([]models.WorkflowStatus, error) {
+	var key uint
+	if categoryID != nil {
+		key = *categoryID
+	}
+	if workflow, ok := workflows[key]; ok {
+		return workflow, nil
+	}
+
+	workflow, err := s.workflowService.GetWorkflow(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	workflows[key] = workflow
+	return workflow, nil
+}
+
+// validateBulkOperation validates the selector and the arguments of a bulk operation
+func (s *todoService) validateBulkOperation(op *BulkOperation) error {
+	// Validate selector
+	if len(op.IDs) == 0 && op.Filter == nil {
+		return errors.New("ids or filter is required")
+	}
+	if len(op.IDs) > 0 && op.Filter != nil {
+		return errors.New("invalid selection: use either ids or filter, not both")
+	}
+	if len(op.IDs) > MaxBulkTodos {
+		return fmt.Errorf("invalid selection: at most %d todos can be changed at once", MaxBulkTodos)
+	}
+
+	// Validate action arguments
+	switch op.Action {
+	case BulkActionComplete, BulkActionUncomplete, BulkActionDelete:
+	case BulkActionSetPriority:
+		if !op.Priority.IsValid() {
+			return errors.New("invalid priority value")
+		}
+	case BulkActionSetCategory:
+		// A null category removes the todos from their category
+		if op.CategoryID != nil {
+			if _, err := s.categoryRepo.GetByID(*op.CategoryID); err != nil {
+				return errors.New("specified category does not exist")
+			}
+		}
+	case BulkActionAddTag:
+		op.Tag = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(op.Tag), "#")))
+		if op.Tag == "" {
+			return errors.New("tag is required")
+		}
+		if len(op.Tag) > 50 {
+			return errors.New("tag cannot exceed 50 characters")
+		}
+	case BulkActionSetDueDate:
+		// A null due date clears it
+		if op.DueDate != nil {
+			dueDate := op.DueDate.UTC()
+			op.DueDate = &dueDate
+			if err := s.validateTodoBusinessRules(&models.Todo{DueDate: op.DueDate}); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("invalid action %q", op.Action)
+	}
+	return nil
+}
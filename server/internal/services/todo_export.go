@@ -0,0 +1,283 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-backend/internal/repository"
+)
+
+// Todo export formats
+const (
+	ExportFormatCSV      = "csv"
+	ExportFormatJSONL    = "jsonl"
+	ExportFormatMarkdown = "md"
+	ExportFormatTodoTxt  = "txt"
+)
+
+// todoCSVHeader is the header row of CSV todo exports
+var todoCSVHeader = []string{
+	"id", "title", "description", "completed", "priority", "due_date", "category_id",
+	"category_name", "category_color", "status", "tags", "position", "completed_at",
+	"archived_at", "created_at", "updated_at", "recurrence",
+}
+
+// ExportTodos writes the todos matching the filters to w in the given format, sorted like ListTodos
+// Todos are streamed as they are read, so there is no limit on their number
+func (s *todoService) ExportTodos(filters repository.TodoFilters, pagination repository.PaginationParams, format string, w io.Writer) error {
+	var export func(w io.Writer, each func(fn func(row *repository.TodoExportRow) error) error) (int, error)
+	switch format {
+	case ExportFormatCSV:
+		export = exportTodosCSV
+	case ExportFormatJSONL:
+		export = exportTodosJSONL
+	case ExportFormatMarkdown:
+		export = exportTodosMarkdown
+	case ExportFormatTodoTxt:
+		export = exportTodosTxt
+	default:
+		return errors.New("invalid export format: must be csv, jsonl, md or txt")
+	}
+	if err := validateTodoFilters(&filters); err != nil {
+		return err
+	}
+
+	_, err := export(w, func(fn func(row *repository.TodoExportRow) error) error {
+		return s.todoRepo.EachExportRow(filters, pagination, fn)
+	})
+	return err
+}
+
+// ExportAccount writes a zip archive with all todos, including archived ones, and all categories
+// The archive holds todos in every export format, categories as CSV and JSON Lines, and a manifest
+func (s *todoService) ExportAccount(w io.Writer) error {
+	archive := zip.NewWriter(w)
+	exportedAt := time.Now().UTC()
+
+	// Every todo, in manual order
+	filters := repository.TodoFilters{IncludeArchived: true}
+	pagination := repository.PaginationParams{SortBy: "position"}
+	each := func(fn func(row *repository.TodoExportRow) error) error {
+		return s.todoRepo.EachExportRow(filters, pagination, fn)
+	}
+
+	todoCount := 0
+	files := []struct {
+		name   string
+		export func(w io.Writer, each func(fn func(row *repository.TodoExportRow) error) error) (int, error)
+	}{
+		{"todos.csv", exportTodosCSV},
+		{"todos.jsonl", exportTodosJSONL},
+		{"todos.md", exportTodosMarkdown},
+		{"todo.txt", exportTodosTxt},
+	}
+	for _, file := range files {
+		fw, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: exportedAt})
+		if err != nil {
+			return err
+		}
+		if todoCount, err = file.export(fw, each); err != nil {
+			return err
+		}
+	}
+
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return err
+	}
+
+	fw, err := archive.CreateHeader(&zip.FileHeader{Name: "categories.csv", Method: zip.Deflate, Modified: exportedAt})
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(fw)
+	if err := writer.Write([]string{"id", "name", "color", "created_at", "updated_at"}); err != nil {
+		return err
+	}
+	for _, category := range categories {
+		err := writer.Write([]string{
+			strconv.FormatUint(uint64(category.ID), 10),
+			csvCell(category.Name),
+			category.Color,
+			category.CreatedAt.UTC().Format(time.RFC3339),
+			category.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+
+	fw, err = archive.CreateHeader(&zip.FileHeader{Name: "categories.jsonl", Method: zip.Deflate, Modified: exportedAt})
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(fw)
+	for i := range categories {
+		if err := encoder.Encode(&categories[i]); err != nil {
+			return err
+		}
+	}
+
+	fw, err = archive.CreateHeader(&zip.FileHeader{Name: "manifest.json", Method: zip.Deflate, Modified: exportedAt})
+	if err != nil {
+		return err
+	}
+	manifest := map[string]interface{}{
+		"exported_at": exportedAt,
+		"todos":       todoCount,
+		"categories":  len(categories),
+	}
+	if err := json.NewEncoder(fw).Encode(manifest); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// exportTodosCSV writes todos as CSV with a header row and returns the number of todos written
+func exportTodosCSV(w io.Writer, each func(fn func(row *repository.TodoExportRow) error) error) (int, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(todoCSVHeader); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err := each(func(row *repository.TodoExportRow) error {
+		count++
+		return writer.Write([]string{
+			strconv.FormatUint(uint64(row.ID), 10),
+			csvCell(row.Title),
+			csvCell(row.Description),
+			strconv.FormatBool(row.Completed),
+			string(row.Priority),
+			formatExportTime(row.DueDate),
+			formatExportID(row.CategoryID),
+			csvCell(stringValue(row.CategoryName)),
+			stringValue(row.CategoryColor),
+			stringValue(row.Status),
+			csvCell(strings.Join(row.Tags, ",")),
+			row.Position,
+			formatExportTime(row.CompletedAt),
+			formatExportTime(row.ArchivedAt),
+			row.CreatedAt.UTC().Format(time.RFC3339),
+			row.UpdatedAt.UTC().Format(time.RFC3339),
+			row.Recurrence,
+		})
+	})
+	if err != nil {
+		return count, err
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
+
+// exportTodosJSONL writes one JSON object per todo and returns the number of todos written
+func exportTodosJSONL(w io.Writer, each func(fn func(row *repository.TodoExportRow) error) error) (int, error) {
+	encoder := json.NewEncoder(w)
+	count := 0
+	err := each(func(row *repository.TodoExportRow) error {
+		count++
+		return encoder.Encode(row)
+	})
+	return count, err
+}
+
+// exportTodosMarkdown writes todos as a Markdown checklist and returns the number of todos written
+// Each item shows its priority, due date, category and tags; descriptions follow as indented paragraphs
+func exportTodosMarkdown(w io.Writer, each func(fn func(row *repository.TodoExportRow) error) error) (int, error) {
+	if _, err := io.WriteString(w, "# Todos\n\n"); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err := each(func(row *repository.TodoExportRow) error {
+		count++
+
+		check := " "
+		if row.Completed {
+			check = "x"
+		}
+		details := []string{string(row.Priority) + " priority"}
+		if row.DueDate != nil {
+			details = append(details, "due "+row.DueDate.UTC().Format("2006-01-02"))
+		}
+		if row.CategoryName != nil {
+			details = append(details, escapeMarkdown(*row.CategoryName))
+		}
+		for _, tag := range row.Tags {
+			details = append(details, "#"+escapeMarkdown(tag))
+		}
+
+		item := fmt.Sprintf("- [%s] %s — %s\n", check, escapeMarkdown(row.Title), strings.Join(details, " · "))
+		if description := strings.TrimSpace(row.Description); description != "" {
+			for _, line := range strings.Split(description, "\n") {
+				item += "\n  " + escapeMarkdown(strings.TrimRight(line, "\r"))
+			}
+			item += "\n\n"
+		}
+		_, err := io.WriteString(w, item)
+		return err
+	})
+	if err != nil {
+		return count, err
+	}
+
+	if count == 0 {
+		_, err = io.WriteString(w, "_No todos_\n")
+	}
+	return count, err
+}
+
+// markdownEscaper escapes characters with a meaning in inline Markdown
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`,
+)
+
+// escapeMarkdown escapes text for use in a Markdown list item
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// csvCell guards a text cell against formula injection when the file is opened in a spreadsheet
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// formatExportTime formats an optional timestamp as RFC 3339 in UTC, empty when unset
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatExportID formats an optional ID, empty when unset
+func formatExportID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
+
+// stringValue returns the value of an optional string, empty when unset
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"errors"
+
+	"todo-backend/internal/models"
+)
+
+// GetTodoHistory retrieves the change history of a todo, newest first
+func (s *todoService) GetTodoHistory(id uint) ([]models.TodoRevision, error) {
+	if id == 0 {
+		return nil, errors.New("invalid todo ID")
+	}
+
+	revisions, err := s.todoRepo.ListRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Todos without recorded changes still need to exist
+	if len(revisions) == 0 {
+		if _, err := s.todoRepo.GetByID(id); err != nil {
+			return nil, err
+		}
+	}
+	return revisions, nil
+}
+
+// RevertTodo restores the fields of a todo from the revision at a version
+// The restored fields go through UpdateTodo, so a revision whose due date has passed or whose
+// category or status no longer exists cannot be restored. Tags and the archive state are kept
+func (s *todoService) RevertTodo(id, version, expectedVersion uint) (*models.Todo, error) {
+	if id == 0 {
+		return nil, errors.New("invalid todo ID")
+	}
+
+	revision, err := s.todoRepo.GetRevision(id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := revision.Snapshot
+	todo := &models.Todo{
+		ID:          id,
+		Title:       snapshot.Title,
+		Description: snapshot.Description,
+		Completed:   snapshot.Completed,
+		Priority:    snapshot.Priority,
+		DueDate:     snapshot.DueDate,
+		Recurrence:  snapshot.Recurrence,
+		CategoryID:  snapshot.CategoryID,
+		StatusID:    snapshot.StatusID,
+		Version:     expectedVersion,
+	}
+	if err := s.UpdateTodo(todo); err != nil {
+		return nil, err
+	}
+
+	return s.todoRepo.GetByID(id)
+}
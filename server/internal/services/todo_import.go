@@ -0,0 +1,647 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+)
+
+// MaxImportTodos is the maximum number of todos a single import may create
+const MaxImportTodos = 5000
+
+// defaultImportColor is the color of imported categories that come without a usable one
+const defaultImportColor = "#6B7280"
+
+// Import formats
+const (
+	ImportFormatCSV     = "csv"
+	ImportFormatJSON    = "json"
+	ImportFormatTodoist = "todoist"
+	ImportFormatTrello  = "trello"
+	ImportFormatTodoTxt = "todotxt"
+)
+
+// Outcomes of an imported row
+const (
+	ImportRowValid   = "valid"
+	ImportRowInvalid = "invalid"
+	ImportRowCreated = "created"
+	ImportRowSkipped = "skipped"
+)
+
+// importFields are the todo fields a CSV column can be mapped to
+var importFields = []string{
+	"title", "description", "completed", "priority", "due_date", "recurrence",
+	"category_name", "category_color", "tags", "completed_at",
+}
+
+// importFieldAliases are further column names recognized without a mapping
+var importFieldAliases = map[string]string{
+	"category": "category_name",
+	"due":      "due_date",
+	"done":     "completed",
+	"labels":   "tags",
+	"content":  "title",
+	"name":     "title",
+	"notes":    "description",
+}
+
+// todoistColors maps the named colors of Todoist projects to hex colors
+var todoistColors = map[string]string{
+	"berry_red": "#B8255F", "red": "#DB4035", "orange": "#FF9933", "yellow": "#FAD000",
+	"olive_green": "#AFB83B", "lime_green": "#7ECC49", "green": "#299438", "mint_green": "#6ACCBC",
+	"teal": "#158FAD", "sky_blue": "#14AAF5", "light_blue": "#96C3EB", "blue": "#4073FF",
+	"grape": "#884DFF", "violet": "#AF38EB", "lavender": "#EB96EB", "magenta": "#E05194",
+	"salmon": "#FF8D85", "charcoal": "#808080", "grey": "#B8B8B8", "taupe": "#CCAC93",
+}
+
+// ImportRequest is a file of todos to import
+type ImportRequest struct {
+	// Format is csv, json, todoist or trello; it is detected from the data when empty
+	Format string
+	Data   []byte
+	// Mapping maps todo fields to CSV column names, e.g. {"title": "Task"}
+	Mapping map[string]string
+	// DryRun validates the file and reports what would be created without storing anything
+	DryRun bool
+}
+
+// ImportReport summarizes an import
+// The import is all or nothing: when any row is invalid, nothing is created
+type ImportReport struct {
+	Format            string            `json:"format"`
+	DryRun            bool              `json:"dry_run"`
+	Total             int               `json:"total"`
+	Valid             int               `json:"valid"`
+	Invalid           int               `json:"invalid"`
+	Skipped           int               `json:"skipped"`
+	Imported          int               `json:"imported"`
+	CreatedCategories []string          `json:"created_categories"`
+	Rows              []ImportRowResult `json:"rows"`
+}
+
+// ImportRowResult is the outcome of one row of an import
+type ImportRowResult struct {
+	Row      int      `json:"row"`
+	Title    string   `json:"title"`
+	Status   string   `json:"status"`
+	ID       uint     `json:"id,omitempty"`
+	Category string   `json:"category,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// importRow is a todo read from an import file, before validation
+type importRow struct {
+	Row           int
+	Title         string
+	Description   string
+	Completed     bool
+	Priority      string
+	DueDate       *time.Time
+	Recurrence    string
+	CompletedAt   *time.Time
+	CategoryName  string
+	CategoryColor string
+	Tags          []string
+	Skip          bool
+	Errors        []string
+}
+
+// ImportTodos creates the todos of an import file, and the categories they name that do not exist yet
+// Every row is validated like a new todo; due dates in the past are kept so history can be imported
+func (s *todoService) ImportTodos(req ImportRequest) (*ImportReport, error) {
+	format := req.Format
+	if format == "" {
+		format = detectImportFormat(req.Data)
+	}
+
+	var rows []importRow
+	var err error
+	switch format {
+	case ImportFormatCSV:
+		rows, err = parseImportCSV(req.Data, req.Mapping)
+	case ImportFormatJSON:
+		rows, err = parseImportJSON(req.Data)
+	case ImportFormatTodoist:
+		rows, err = parseImportTodoist(req.Data)
+	case ImportFormatTrello:
+		rows, err = parseImportTrello(req.Data)
+	case ImportFormatTodoTxt:
+		rows, err = parseImportTodoTxt(req.Data)
+	default:
+		return nil, errors.New("invalid import format: must be csv, json, todoist, trello or todotxt")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("import file contains no todos")
+	}
+	if len(rows) > MaxImportTodos {
+		return nil, fmt.Errorf("import cannot exceed %d todos", MaxImportTodos)
+	}
+
+	// Existing categories are matched by name, case-insensitively
+	existing, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	categoryIDs := make(map[string]uint, len(existing))
+	for _, category := range existing {
+		categoryIDs[strings.ToLower(category.Name)] = category.ID
+	}
+
+	report := &ImportReport{
+		Format:            format,
+		DryRun:            req.DryRun,
+		Total:             len(rows),
+		CreatedCategories: []string{},
+		Rows:              make([]ImportRowResult, 0, len(rows)),
+	}
+	var categories []*models.Category
+	newCategories := make(map[string]bool)
+	items := make([]repository.ImportItem, 0, len(rows))
+	itemRows := make([]int, 0, len(rows))
+
+	for _, row := range rows {
+		result := ImportRowResult{Row: row.Row, Title: row.Title, Category: row.CategoryName}
+		if row.Skip {
+			result.Status = ImportRowSkipped
+			report.Skipped++
+			report.Rows = append(report.Rows, result)
+			continue
+		}
+
+		item, category, errs := s.planImportRow(row, categoryIDs, newCategories)
+		if len(errs) > 0 {
+			result.Status = ImportRowInvalid
+			result.Errors = errs
+			report.Invalid++
+			report.Rows = append(report.Rows, result)
+			continue
+		}
+		if category != nil {
+			categories = append(categories, category)
+			newCategories[strings.ToLower(category.Name)] = true
+			report.CreatedCategories = append(report.CreatedCategories, category.Name)
+		}
+
+		result.Status = ImportRowValid
+		report.Valid++
+		report.Rows = append(report.Rows, result)
+		items = append(items, item)
+		itemRows = append(itemRows, len(report.Rows)-1)
+	}
+
+	if req.DryRun || report.Invalid > 0 || len(items) == 0 {
+		return report, nil
+	}
+
+	if err := s.todoRepo.Import(categories, items); err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		report.Rows[itemRows[i]].Status = ImportRowCreated
+		report.Rows[itemRows[i]].ID = item.Todo.ID
+	}
+	report.Imported = len(items)
+	return report, nil
+}
+
+// planImportRow validates a row and prepares its todo
+// Returns the category to create when the row names one that does not exist yet
+func (s *todoService) planImportRow(row importRow, categoryIDs map[string]uint, newCategories map[string]bool) (repository.ImportItem, *models.Category, []string) {
+	errs := row.Errors
+	todo := &models.Todo{
+		Title:       row.Title,
+		Description: row.Description,
+		Completed:   row.Completed,
+		Priority:    models.Priority(strings.ToLower(strings.TrimSpace(row.Priority))),
+		DueDate:     row.DueDate,
+		Recurrence:  row.Recurrence,
+	}
+	if err := s.validateTodo(todo); err != nil {
+		errs = append(errs, err.Error())
+	}
+	s.cleanTodoData(todo)
+
+	item := repository.ImportItem{Todo: todo}
+	var category *models.Category
+	if name := strings.TrimSpace(row.CategoryName); name != "" {
+		if id, ok := categoryIDs[strings.ToLower(name)]; ok {
+			todo.CategoryID = &id
+		} else {
+			item.CategoryName = name
+			if !newCategories[strings.ToLower(name)] {
+				color := strings.TrimSpace(row.CategoryColor)
+				if !isValidHexColor(color) {
+					color = defaultImportColor
+				}
+				category = &models.Category{Name: name, Color: strings.ToUpper(color)}
+				if len(name) > 100 {
+					errs = append(errs, "category name cannot exceed 100 characters")
+				}
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(row.Tags))
+	for _, tag := range row.Tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#")))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > 50 {
+			errs = append(errs, "tag cannot exceed 50 characters")
+			continue
+		}
+		seen[tag] = true
+		item.Tags = append(item.Tags, tag)
+	}
+
+	// Todos in new categories start in the default workflow, which new categories use
+	if len(errs) == 0 {
+		if err := s.applyStatus(todo, nil); err != nil {
+			errs = append(errs, err.Error())
+		}
+		todo.Status = nil
+	}
+	if todo.Completed {
+		completedAt := time.Now().UTC()
+		if row.CompletedAt != nil {
+			completedAt = row.CompletedAt.UTC()
+		}
+		todo.CompletedAt = &completedAt
+	}
+
+	if len(errs) > 0 {
+		return item, nil, errs
+	}
+	return item, category, nil
+}
+
+// detectImportFormat guesses the format of an import file from its content
+func detectImportFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return ImportFormatCSV
+	}
+
+	switch trimmed[0] {
+	case '[':
+		return ImportFormatJSON
+	case '{':
+		var probe map[string]json.RawMessage
+		if err := json.NewDecoder(bytes.NewReader(trimmed)).Decode(&probe); err == nil {
+			if _, ok := probe["items"]; ok {
+				return ImportFormatTodoist
+			}
+			if _, ok := probe["cards"]; ok {
+				return ImportFormatTrello
+			}
+		}
+		return ImportFormatJSON
+	}
+	return ImportFormatCSV
+}
+
+// parseImportCSV reads todos from CSV with a header row
+// Columns are matched to todo fields by name, or by the mapping from field to column name
+func parseImportCSV(data []byte, mapping map[string]string) ([]importRow, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	// Resolve the column of every field, explicit mappings first
+	fieldColumns := make(map[string]int)
+	for field, column := range mapping {
+		if !containsString(importFields, field) {
+			return nil, fmt.Errorf("invalid mapping: unknown field %s", field)
+		}
+		index, ok := columns[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			return nil, fmt.Errorf("invalid mapping: column %q not found", column)
+		}
+		fieldColumns[field] = index
+	}
+	for _, field := range importFields {
+		if index, ok := columns[field]; ok {
+			if _, mapped := fieldColumns[field]; !mapped {
+				fieldColumns[field] = index
+			}
+		}
+	}
+	for alias, field := range importFieldAliases {
+		if index, ok := columns[alias]; ok {
+			if _, mapped := fieldColumns[field]; !mapped {
+				fieldColumns[field] = index
+			}
+		}
+	}
+	if _, ok := fieldColumns["title"]; !ok {
+		return nil, errors.New("invalid CSV: no title column, map one with mapping[title]")
+	}
+
+	var rows []importRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		value := func(field string) string {
+			index, ok := fieldColumns[field]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return csvValue(record[index])
+		}
+
+		// Blank lines in spreadsheets are exported as empty records
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		row := importRow{
+			Row:           line,
+			Title:         value("title"),
+			Description:   value("description"),
+			Priority:      value("priority"),
+			Recurrence:    value("recurrence"),
+			CategoryName:  value("category_name"),
+			CategoryColor: value("category_color"),
+			Tags:          splitImportTags(value("tags")),
+		}
+		if text := value("completed"); text != "" {
+			completed, err := parseImportBool(text)
+			if err != nil {
+				row.Errors = append(row.Errors, err.Error())
+			}
+			row.Completed = completed
+		}
+		row.DueDate = parseImportTime("due_date", value("due_date"), &row)
+		row.CompletedAt = parseImportTime("completed_at", value("completed_at"), &row)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// importJSONTodo is a todo in the layout of the JSON Lines export
+type importJSONTodo struct {
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	Completed     bool       `json:"completed"`
+	Priority      string     `json:"priority"`
+	DueDate       *time.Time `json:"due_date"`
+	Recurrence    string     `json:"recurrence"`
+	CompletedAt   *time.Time `json:"completed_at"`
+	CategoryName  string     `json:"category_name"`
+	CategoryColor string     `json:"category_color"`
+	Tags          []string   `json:"tags"`
+}
+
+// parseImportJSON reads todos in the layout of the JSON export, as JSON Lines or as an array
+func parseImportJSON(data []byte) ([]importRow, error) {
+	var todos []importJSONTodo
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &todos); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		for {
+			var todo importJSONTodo
+			if err := decoder.Decode(&todo); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("invalid JSON on todo %d: %w", len(todos)+1, err)
+			}
+			todos = append(todos, todo)
+		}
+	}
+
+	rows := make([]importRow, len(todos))
+	for i, todo := range todos {
+		rows[i] = importRow{
+			Row:           i + 1,
+			Title:         todo.Title,
+			Description:   todo.Description,
+			Completed:     todo.Completed,
+			Priority:      todo.Priority,
+			DueDate:       todo.DueDate,
+			Recurrence:    todo.Recurrence,
+			CompletedAt:   todo.CompletedAt,
+			CategoryName:  todo.CategoryName,
+			CategoryColor: todo.CategoryColor,
+			Tags:          todo.Tags,
+		}
+	}
+	return rows, nil
+}
+
+// parseImportTodoist reads the items of a Todoist-style backup, projects become categories
+// Priorities run from 1 (normal) to 4 (urgent); deleted items are skipped
+func parseImportTodoist(data []byte) ([]importRow, error) {
+	var backup struct {
+		Projects []struct {
+			ID    json.RawMessage `json:"id"`
+			Name  string          `json:"name"`
+			Color string          `json:"color"`
+		} `json:"projects"`
+		Items []struct {
+			Content     string          `json:"content"`
+			Description string          `json:"description"`
+			ProjectID   json.RawMessage `json:"project_id"`
+			Priority    int             `json:"priority"`
+			Checked     bool            `json:"checked"`
+			IsDeleted   bool            `json:"is_deleted"`
+			Labels      []string        `json:"labels"`
+			CompletedAt string          `json:"completed_at"`
+			Due         *struct {
+				Date string `json:"date"`
+			} `json:"due"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, fmt.Errorf("invalid Todoist JSON: %w", err)
+	}
+
+	type project struct{ name, color string }
+	projects := make(map[string]project, len(backup.Projects))
+	for _, p := range backup.Projects {
+		projects[rawID(p.ID)] = project{name: p.Name, color: todoistColors[p.Color]}
+	}
+
+	rows := make([]importRow, len(backup.Items))
+	for i, item := range backup.Items {
+		row := importRow{
+			Row:         i + 1,
+			Title:       item.Content,
+			Description: item.Description,
+			Completed:   item.Checked,
+			Tags:        item.Labels,
+			Skip:        item.IsDeleted,
+		}
+		switch item.Priority {
+		case 4, 3:
+			row.Priority = string(models.PriorityHigh)
+		case 2, 1, 0:
+			row.Priority = string(models.PriorityMedium)
+		default:
+			row.Errors = append(row.Errors, "invalid priority value")
+		}
+		if p, ok := projects[rawID(item.ProjectID)]; ok {
+			row.CategoryName = p.name
+			row.CategoryColor = p.color
+		}
+		if item.Due != nil {
+			row.DueDate = parseImportTime("due_date", item.Due.Date, &row)
+		}
+		row.CompletedAt = parseImportTime("completed_at", item.CompletedAt, &row)
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// parseImportTrello reads the cards of a Trello-style board export, lists become categories
+// Labels become tags; archived cards and cards of archived lists are skipped
+func parseImportTrello(data []byte) ([]importRow, error) {
+	var board struct {
+		Lists []struct {
+			ID     string `json:"id"`
+			Name   string `json:"name"`
+			Closed bool   `json:"closed"`
+		} `json:"lists"`
+		Cards []struct {
+			Name        string `json:"name"`
+			Desc        string `json:"desc"`
+			IDList      string `json:"idList"`
+			Due         string `json:"due"`
+			DueComplete bool   `json:"dueComplete"`
+			Closed      bool   `json:"closed"`
+			Labels      []struct {
+				Name  string `json:"name"`
+				Color string `json:"color"`
+			} `json:"labels"`
+		} `json:"cards"`
+	}
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, fmt.Errorf("invalid Trello JSON: %w", err)
+	}
+
+	lists := make(map[string]string, len(board.Lists))
+	closedLists := make(map[string]bool)
+	for _, list := range board.Lists {
+		lists[list.ID] = list.Name
+		closedLists[list.ID] = list.Closed
+	}
+
+	rows := make([]importRow, len(board.Cards))
+	for i, card := range board.Cards {
+		row := importRow{
+			Row:          i + 1,
+			Title:        card.Name,
+			Description:  card.Desc,
+			Completed:    card.DueComplete,
+			CategoryName: lists[card.IDList],
+			Skip:         card.Closed || closedLists[card.IDList],
+		}
+		for _, label := range card.Labels {
+			if label.Name != "" {
+				row.Tags = append(row.Tags, label.Name)
+			} else if label.Color != "" {
+				row.Tags = append(row.Tags, label.Color)
+			}
+		}
+		row.DueDate = parseImportTime("due_date", card.Due, &row)
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// parseImportTime parses an RFC 3339 timestamp or a plain date, recording an error on the row
+func parseImportTime(field, value string, row *importRow) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	row.Errors = append(row.Errors, fmt.Sprintf("invalid %s: %q is not a date", field, value))
+	return nil
+}
+
+// parseImportBool parses the completion flag of a CSV row
+func parseImportBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "1", "yes", "y", "x", "done", "completed":
+		return true, nil
+	case "false", "0", "no", "n", "", "open", "todo":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid completed value: %q", value)
+}
+
+// splitImportTags splits a list of tags separated by commas or semicolons
+func splitImportTags(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';'
+	})
+}
+
+// csvValue trims a CSV cell and removes the formula guard that CSV exports add
+func csvValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// rawID returns an ID that may be encoded as a JSON string or number
+func rawID(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+// containsString reports whether a list contains a value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"todo-backend/internal/models"
+	"todo-backend/pkg/ical"
+	"todo-backend/pkg/jsonpatch"
+)
+
+// todoReadOnlyFields are todo fields that a patch may not change
+var todoReadOnlyFields = map[string]bool{
+	"id": true, "version": true, "position": true, "completed_at": true, "archived_at": true,
+	"created_at": true, "updated_at": true, "comment_count": true, "blocked": true,
+	"blocked_by": true, "category": true, "status": true, "tags": true,
+}
+
+// PatchTodo applies a JSON Merge Patch or JSON Patch to a todo
+// Only the fields the patch changes are validated and written; null clears optional fields
+func (s *todoService) PatchTodo(id uint, version uint, patch []byte, mediaType string) (*models.Todo, error) {
+	if id == 0 {
+		return nil, errors.New("invalid todo ID")
+	}
+
+	existing, err := s.todoRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != existing.Version {
+		return nil, errors.New("todo was modified by another request")
+	}
+
+	changes, err := patchChanges(existing, patch, mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode the changed fields onto a copy of the todo
+	todo := *existing
+	columns := map[string]interface{}{}
+	for field, raw := range changes {
+		if todoReadOnlyFields[field] {
+			return nil, fmt.Errorf("invalid patch: field %s is read-only", field)
+		}
+
+		switch field {
+		case "title":
+			if err := decodeRequiredField(field, raw, &todo.Title); err != nil {
+				return nil, err
+			}
+			todo.Title = strings.TrimSpace(todo.Title)
+			if todo.Title == "" {
+				return nil, errors.New("todo title is required")
+			}
+			if len(todo.Title) > 255 {
+				return nil, errors.New("todo title cannot exceed 255 characters")
+			}
+			columns["title"] = todo.Title
+
+		case "description":
+			// null clears the description
+			todo.Description = ""
+			if err := decodeField(field, raw, &todo.Description); err != nil {
+				return nil, err
+			}
+			todo.Description = strings.TrimSpace(todo.Description)
+			if len(todo.Description) > 5000 {
+				return nil, errors.New("todo description cannot exceed 5000 characters")
+			}
+			columns["description"] = todo.Description
+
+		case "priority":
+			if err := decodeRequiredField(field, raw, &todo.Priority); err != nil {
+				return nil, err
+			}
+			if !todo.Priority.IsValid() {
+				return nil, errors.New("invalid priority value")
+			}
+			columns["priority"] = todo.Priority
+
+		case "due_date":
+			todo.DueDate = nil
+			if err := decodeField(field, raw, &todo.DueDate); err != nil {
+				return nil, err
+			}
+			if todo.DueDate != nil {
+				dueDate := todo.DueDate.UTC()
+				todo.DueDate = &dueDate
+				if err := s.validateTodoBusinessRules(&models.Todo{DueDate: todo.DueDate}); err != nil {
+					return nil, err
+				}
+			}
+			columns["due_date"] = todo.DueDate
+
+		case "recurrence":
+			// null stops the todo repeating
+			todo.Recurrence = ""
+			if err := decodeField(field, raw, &todo.Recurrence); err != nil {
+				return nil, err
+			}
+			if err := validateRecurrence(todo.Recurrence); err != nil {
+				return nil, err
+			}
+			todo.Recurrence, _ = ical.NormalizeRecurrence(todo.Recurrence)
+			columns["recurrence"] = todo.Recurrence
+
+		case "category_id":
+			todo.CategoryID = nil
+			if err := decodeField(field, raw, &todo.CategoryID); err != nil {
+				return nil, err
+			}
+			if todo.CategoryID != nil {
+				if _, err := s.categoryRepo.GetByID(*todo.CategoryID); err != nil {
+					return nil, errors.New("specified category does not exist")
+				}
+			}
+			columns["category_id"] = todo.CategoryID
+
+		case "status_id":
+			if err := decodeRequiredField(field, raw, &todo.StatusID); err != nil {
+				return nil, err
+			}
+
+		case "completed":
+			if err := decodeRequiredField(field, raw, &todo.Completed); err != nil {
+				return nil, err
+			}
+
+		default:
+			return nil, fmt.Errorf("invalid patch: unknown field %s", field)
+		}
+	}
+
+	// Status, completion and category together decide the workflow status
+	_, statusChanged := changes["status_id"]
+	_, completedChanged := changes["completed"]
+	_, categoryChanged := changes["category_id"]
+	if statusChanged || completedChanged || categoryChanged {
+		if !statusChanged {
+			todo.StatusID = existing.StatusID
+		}
+		if err := s.applyStatus(&todo, existing); err != nil {
+			return nil, err
+		}
+		if todo.Completed && !existing.Completed && existing.Blocked {
+			return nil, errors.New("todo has open blockers")
+		}
+		if todo.StatusID != nil && (existing.StatusID == nil || *todo.StatusID != *existing.StatusID) {
+			columns["status_id"] = *todo.StatusID
+		}
+		if todo.Completed != existing.Completed {
+			columns["completed"] = todo.Completed
+		}
+	}
+
+	if len(columns) > 0 {
+		// The patch was applied to the loaded todo, so the todo must not have changed since
+		if err := s.todoRepo.UpdateFields(id, existing.Version, columns); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.todoRepo.GetByID(id)
+}
+
+// patchChanges applies a patch to the JSON representation of a resource and returns the changed fields
+func patchChanges(resource interface{}, patch []byte, mediaType string) (map[string]json.RawMessage, error) {
+	original, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := jsonpatch.Apply(original, patch, mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonpatch.ChangedFields(original, patched)
+}
+
+// decodeField decodes a patched field value, leaving dst unchanged for null
+func decodeField(field string, raw json.RawMessage, dst interface{}) error {
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", field, err)
+	}
+	return nil
+}
+
+// decodeRequiredField decodes a patched field value that cannot be cleared
+func decodeRequiredField(field string, raw json.RawMessage, dst interface{}) error {
+	if string(raw) == "null" {
+		return fmt.Errorf("invalid patch: %s cannot be null", field)
+	}
+	return decodeField(field, raw, dst)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/pkg/quickadd"
+)
+
+// maxQuickAddLength is the longest quick-add text accepted
+const maxQuickAddLength = 1000
+
+// QuickAddRequest is a todo written as a line of text
+type QuickAddRequest struct {
+	Text string `json:"text" binding:"required"`
+	// TimeZone is the IANA time zone relative dates and times are read in, UTC when empty
+	TimeZone string `json:"time_zone"`
+	// DryRun only parses the text and validates the todo without creating it
+	DryRun bool `json:"dry_run"`
+}
+
+// QuickAddPreview is what was read from a quick-add text
+type QuickAddPreview struct {
+	Title    string          `json:"title"`
+	DueDate  *time.Time      `json:"due_date"`
+	AllDay   bool            `json:"all_day"`
+	Priority models.Priority `json:"priority"`
+	// CategoryID is the category matched by name, CategoryName the name as written
+	CategoryID   *uint            `json:"category_id"`
+	CategoryName string           `json:"category_name,omitempty"`
+	Recurrence   string           `json:"recurrence,omitempty"`
+	TimeZone     string           `json:"time_zone"`
+	Tokens       []quickadd.Token `json:"tokens"`
+	Warnings     []string         `json:"warnings"`
+}
+
+// QuickAddResult is the parsed preview and the todo created from it, which is nil for dry runs
+type QuickAddResult struct {
+	DryRun  bool            `json:"dry_run"`
+	Preview QuickAddPreview `json:"preview"`
+	Todo    *models.Todo    `json:"todo"`
+}
+
+// QuickAddTodo creates a todo from a line of text such as "Pay rent tomorrow 9am !high #Personal every month"
+// Due dates without a time of day are stored as midnight UTC like other date-only due dates;
+// the category must already exist. A recurrence is saved as the todo's RRULE
+func (s *todoService) QuickAddTodo(req QuickAddRequest) (*QuickAddResult, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, errors.New("quick add text is required")
+	}
+	if len(text) > maxQuickAddLength {
+		return nil, errors.New("quick add text cannot exceed 1000 characters")
+	}
+	loc, err := loadCalendarLocation(strings.TrimSpace(req.TimeZone))
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := quickadd.Parse(text, time.Now().In(loc))
+	preview := QuickAddPreview{
+		Title:        parsed.Title,
+		Priority:     models.Priority(parsed.Priority),
+		CategoryName: parsed.Category,
+		Recurrence:   parsed.Recurrence,
+		TimeZone:     loc.String(),
+		Tokens:       parsed.Tokens,
+		Warnings:     []string{},
+	}
+	if preview.Tokens == nil {
+		preview.Tokens = []quickadd.Token{}
+	}
+	if preview.Priority == "" {
+		preview.Priority = models.PriorityMedium
+	}
+	if parsed.Due != nil {
+		due := parsed.Due.UTC()
+		if !parsed.HasTime {
+			due = time.Date(parsed.Due.Year(), parsed.Due.Month(), parsed.Due.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		preview.DueDate = &due
+		preview.AllDay = !parsed.HasTime
+	}
+	if parsed.Recurrence != "" && preview.DueDate == nil {
+		preview.Warnings = append(preview.Warnings, "recurrence has no due date to repeat from")
+	}
+
+	if parsed.Category != "" {
+		categories, err := s.categoryRepo.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		for i := range categories {
+			if strings.EqualFold(categories[i].Name, parsed.Category) {
+				id := categories[i].ID
+				preview.CategoryID = &id
+				break
+			}
+		}
+		if preview.CategoryID == nil {
+			return nil, errors.New("category \"" + parsed.Category + "\" does not exist")
+		}
+	}
+
+	todo := &models.Todo{
+		Title:      preview.Title,
+		Priority:   preview.Priority,
+		DueDate:    preview.DueDate,
+		Recurrence: preview.Recurrence,
+		CategoryID: preview.CategoryID,
+	}
+	result := &QuickAddResult{DryRun: req.DryRun, Preview: preview}
+	if req.DryRun {
+		if err := s.validateTodo(todo); err != nil {
+			return nil, err
+		}
+		s.cleanTodoData(todo)
+		if err := s.validateTodoBusinessRules(todo); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if err := s.CreateTodo(todo); err != nil {
+		return nil, err
+	}
+	result.Todo = todo
+	return result, nil
+}
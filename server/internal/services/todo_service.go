@@ -1,30 +1,55 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
 	"todo-backend/internal/models"
 	"todo-backend/internal/repository"
+	"todo-backend/pkg/ical"
+	"todo-backend/pkg/todoquery"
 )
 
 // todoService implements TodoService interface
 type todoService struct {
-	todoRepo     repository.TodoRepository
-	categoryRepo repository.CategoryRepository
+	todoRepo        repository.TodoRepository
+	categoryRepo    repository.CategoryRepository
+	workflowService WorkflowService
 }
 
 // NewTodoService creates a new todo service
-func NewTodoService(todoRepo repository.TodoRepository, categoryRepo repository.CategoryRepository) TodoService {
+func NewTodoService(todoRepo repository.TodoRepository, categoryRepo repository.CategoryRepository, workflowService WorkflowService) TodoService {
 	return &todoService{
-		todoRepo:     todoRepo,
-		categoryRepo: categoryRepo,
+		todoRepo:        todoRepo,
+		categoryRepo:    categoryRepo,
+		workflowService: workflowService,
 	}
 }
 
+// WithContext returns a copy of the service whose changes are recorded with the audit info of ctx
+func (s *todoService) WithContext(ctx context.Context) TodoService {
+	scoped := *s
+	scoped.todoRepo = s.todoRepo.WithContext(ctx)
+	scoped.categoryRepo = s.categoryRepo.WithContext(ctx)
+	scoped.workflowService = s.workflowService.WithContext(ctx)
+	return &scoped
+}
+
 // CreateTodo creates a new todo with validation
 func (s *todoService) CreateTodo(todo *models.Todo) error {
+	if err := s.PrepareTodo(todo); err != nil {
+		return err
+	}
+
+	// The repository adds new todos to the end of the manual order
+	return s.todoRepo.Create(todo)
+}
+
+// PrepareTodo validates and cleans a new todo and resolves its initial workflow status
+func (s *todoService) PrepareTodo(todo *models.Todo) error {
 	// Business logic validation
 	if err := s.validateTodo(todo); err != nil {
 		return err
@@ -38,7 +63,8 @@ func (s *todoService) CreateTodo(todo *models.Todo) error {
 		return err
 	}
 
-	return s.todoRepo.Create(todo)
+	// Resolve the initial workflow status
+	return s.applyStatus(todo, nil)
 }
 
 // GetTodoByID retrieves a todo by its ID
@@ -63,20 +89,46 @@ func (s *todoService) UpdateTodo(todo *models.Todo) error {
 	// Clean and format the data
 	s.cleanTodoData(todo)
 
+	existing, err := s.todoRepo.GetByID(todo.ID)
+	if err != nil {
+		return err
+	}
+
 	// Additional business logic validation
-	if err := s.validateTodoBusinessRules(todo); err != nil {
+	// An unchanged due date may lie in the past, so overdue todos can still be edited and completed
+	rules := *todo
+	if sameTime(todo.DueDate, existing.DueDate) {
+		rules.DueDate = nil
+	}
+	if err := s.validateTodoBusinessRules(&rules); err != nil {
+		return err
+	}
+
+	// Resolve the workflow status, enforcing transition rules
+	if err := s.applyStatus(todo, existing); err != nil {
 		return err
 	}
 
+	// The manual order only changes through MoveTodo, the archive only through the archive endpoints
+	todo.Position = existing.Position
+	todo.CompletedAt = existing.CompletedAt
+	todo.ArchivedAt = existing.ArchivedAt
+
+	// A todo with open blockers cannot be completed
+	if todo.Completed && !existing.Completed && existing.Blocked {
+		return errors.New("todo has open blockers")
+	}
+
 	return s.todoRepo.Update(todo)
 }
 
 // DeleteTodo soft deletes a todo by ID
-func (s *todoService) DeleteTodo(id uint) error {
+// A non-zero version must match the current version of the todo
+func (s *todoService) DeleteTodo(id uint, version uint) error {
 	if id == 0 {
 		return errors.New("invalid todo ID")
 	}
-	return s.todoRepo.Delete(id)
+	return s.todoRepo.Delete(id, version)
 }
 
 // ListTodos retrieves todos with pagination and filtering
@@ -89,28 +141,355 @@ func (s *todoService) ListTodos(filters repository.TodoFilters, pagination repos
 		pagination.Limit = 10
 	}
 
-	// Clean search filter
-	if filters.Search != "" {
-		filters.Search = strings.TrimSpace(filters.Search)
+	// Clean and validate the filters
+	if err := validateTodoFilters(&filters); err != nil {
+		return nil, repository.PaginationResult{}, err
 	}
 
-	// Validate priority filter
-	if filters.Priority != "" {
-		priority := models.Priority(filters.Priority)
-		if !priority.IsValid() {
-			return nil, repository.PaginationResult{}, errors.New("invalid priority filter")
+	return s.todoRepo.List(filters, pagination)
+}
+
+// ToggleTodoComplete toggles the completion status of a todo
+// Completing a todo with open blockers is refused unless force is set
+func (s *todoService) ToggleTodoComplete(id uint, version uint, force bool) error {
+	if id == 0 {
+		return errors.New("invalid todo ID")
+	}
+
+	todo, err := s.todoRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != todo.Version {
+		return errors.New("todo was modified by another request")
+	}
+
+	// Move to the first open or done status the workflow allows
+	workflow, err := s.workflowService.GetWorkflow(todo.CategoryID)
+	if err != nil {
+		return err
+	}
+	target, err := s.pickStatus(workflow, todo.StatusID, !todo.Completed)
+	if err != nil {
+		return err
+	}
+
+	return s.setTodoStatus(todo, target, force)
+}
+
+// ChangeTodoStatus moves a todo to another status of its workflow
+// Moving a todo with open blockers to a done status is refused unless force is set
+func (s *todoService) ChangeTodoStatus(id, statusID uint, version uint, force bool) error {
+	if id == 0 {
+		return errors.New("invalid todo ID")
+	}
+	if statusID == 0 {
+		return errors.New("invalid status ID")
+	}
+
+	todo, err := s.todoRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != todo.Version {
+		return errors.New("todo was modified by another request")
+	}
+
+	workflow, err := s.workflowService.GetWorkflow(todo.CategoryID)
+	if err != nil {
+		return err
+	}
+	target := findStatus(workflow, statusID)
+	if target == nil {
+		return errors.New("invalid status: it does not belong to the todo's workflow")
+	}
+	if err := s.checkTransition(workflow, todo.StatusID, target); err != nil {
+		return err
+	}
+
+	return s.setTodoStatus(todo, target, force)
+}
+
+// MoveTodo places a todo in manual order after afterID and/or before beforeID
+// Only the moved todo is written: it gets a position key between its new neighbors.
+// With only one neighbor given, the other one is the closest todo in the overall order
+func (s *todoService) MoveTodo(id uint, version uint, beforeID, afterID, categoryID *uint) error {
+	if id == 0 {
+		return errors.New("invalid todo ID")
+	}
+	if beforeID == nil && afterID == nil {
+		return errors.New("before_id or after_id is required")
+	}
+	if (beforeID != nil && *beforeID == id) || (afterID != nil && *afterID == id) {
+		return errors.New("invalid move: a todo cannot be placed next to itself")
+	}
+
+	todo, err := s.todoRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != todo.Version {
+		return errors.New("todo was modified by another request")
+	}
+
+	// Moving to another category may move the todo to another workflow as well
+	moveCategory := categoryID != nil && (todo.CategoryID == nil || *todo.CategoryID != *categoryID)
+	if moveCategory {
+		if _, err := s.categoryRepo.GetByID(*categoryID); err != nil {
+			return errors.New("specified category does not exist")
 		}
 	}
 
-	return s.todoRepo.List(filters, pagination)
+	columns := map[string]interface{}{}
+	if moveCategory {
+		existing := *todo
+		todo.CategoryID = categoryID
+		todo.Category = nil
+		if err := s.applyStatus(todo, &existing); err != nil {
+			return err
+		}
+		columns["category_id"] = *categoryID
+		columns["status_id"] = *todo.StatusID
+		if todo.Completed != existing.Completed {
+			columns["completed"] = todo.Completed
+		}
+	}
+
+	// The position is generated from the neighbors while concurrent moves are locked out,
+	// the category and status are written with it unless the todo changed since it was read
+	return s.todoRepo.MovePosition(id, todo.Version, afterID, beforeID, columns)
 }
 
-// ToggleTodoComplete toggles the completion status of a todo
-func (s *todoService) ToggleTodoComplete(id uint) error {
+// ArchiveTodo archives a completed todo, hiding it from the default todo list
+func (s *todoService) ArchiveTodo(id uint, version uint) error {
 	if id == 0 {
 		return errors.New("invalid todo ID")
 	}
-	return s.todoRepo.ToggleComplete(id)
+
+	todo, err := s.todoRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != todo.Version {
+		return errors.New("todo was modified by another request")
+	}
+	if !todo.Completed {
+		return errors.New("cannot archive a todo that is not completed")
+	}
+	if todo.ArchivedAt != nil {
+		return nil
+	}
+
+	return s.todoRepo.SetArchived(id, todo.Version, true)
+}
+
+// UnarchiveTodo takes a todo out of the archive
+func (s *todoService) UnarchiveTodo(id uint, version uint) error {
+	if id == 0 {
+		return errors.New("invalid todo ID")
+	}
+
+	todo, err := s.todoRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != todo.Version {
+		return errors.New("todo was modified by another request")
+	}
+	if todo.ArchivedAt == nil {
+		return nil
+	}
+
+	return s.todoRepo.SetArchived(id, todo.Version, false)
+}
+
+// ArchiveCompletedTodos archives all completed todos of a category, or of all categories when categoryID is nil
+func (s *todoService) ArchiveCompletedTodos(categoryID *uint) (int64, error) {
+	if categoryID != nil {
+		if _, err := s.categoryRepo.GetByID(*categoryID); err != nil {
+			return 0, errors.New("specified category does not exist")
+		}
+	}
+	return s.todoRepo.ArchiveCompleted(categoryID, nil)
+}
+
+// AutoArchive archives todos that were completed longer than the given delay ago
+func (s *todoService) AutoArchive(delay time.Duration) (int64, error) {
+	if delay <= 0 {
+		return 0, nil
+	}
+	completedBefore := time.Now().Add(-delay)
+	return s.todoRepo.ArchiveCompleted(nil, &completedBefore)
+}
+
+// RebalancePositions rewrites manual order positions once any of them is missing, duplicated or longer than maxLength
+func (s *todoService) RebalancePositions(maxLength int) (int, error) {
+	needed, err := s.todoRepo.NeedsRebalance(maxLength)
+	if err != nil || !needed {
+		return 0, err
+	}
+	return s.todoRepo.RebalancePositions()
+}
+
+// setTodoStatus stores a new status of a todo after checking its blockers
+// The todo must not have changed since it was read, the checks were made against that version
+func (s *todoService) setTodoStatus(todo *models.Todo, target *models.WorkflowStatus, force bool) error {
+	if target.IsDone && !todo.Completed && todo.Blocked && !force {
+		return errors.New("todo has open blockers")
+	}
+	return s.todoRepo.SetStatus(todo.ID, todo.Version, target.ID, target.IsDone)
+}
+
+// applyStatus resolves the workflow status of a todo and derives its completion flag
+// An explicit status_id wins; otherwise the completed flag selects an open or done status,
+// so clients that only know about completed keep working. existing is nil for new todos
+func (s *todoService) applyStatus(todo *models.Todo, existing *models.Todo) error {
+	workflow, err := s.workflowService.GetWorkflow(todo.CategoryID)
+	if err != nil {
+		return err
+	}
+
+	var currentID *uint
+	if existing != nil {
+		currentID = existing.StatusID
+	}
+
+	var target *models.WorkflowStatus
+	switch {
+	case todo.StatusID != nil && (currentID == nil || *todo.StatusID != *currentID):
+		// Explicit status change
+		target = findStatus(workflow, *todo.StatusID)
+		if target == nil {
+			return errors.New("invalid status: it does not belong to the todo's workflow")
+		}
+		if err := s.checkTransition(workflow, currentID, target); err != nil {
+			return err
+		}
+	case existing != nil && currentID != nil && existing.Completed == todo.Completed:
+		// Status unchanged, remap it when the todo moved to a category with another workflow
+		target = findStatus(workflow, *currentID)
+		if target == nil && existing.Status != nil {
+			target = findStatusByKey(workflow, existing.Status.Key)
+		}
+		if target == nil {
+			target = firstStatus(workflow, existing.Completed)
+		}
+	default:
+		// Compatibility view, derive the status from the completed flag
+		target, err = s.pickStatus(workflow, currentID, todo.Completed)
+		if err != nil {
+			return err
+		}
+	}
+
+	todo.StatusID = &target.ID
+	todo.Status = target
+	todo.Completed = target.IsDone
+	return nil
+}
+
+// pickStatus selects the first open or done status that can be reached from the current status
+func (s *todoService) pickStatus(workflow []models.WorkflowStatus, currentID *uint, done bool) (*models.WorkflowStatus, error) {
+	if currentID != nil {
+		if current := findStatus(workflow, *currentID); current != nil && current.IsDone == done {
+			return current, nil
+		}
+	}
+
+	var firstErr error
+	for i := range workflow {
+		if workflow[i].IsDone != done {
+			continue
+		}
+		err := s.checkTransition(workflow, currentID, &workflow[i])
+		if err == nil {
+			return &workflow[i], nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return nil, errors.New("workflow has no matching status")
+}
+
+// checkTransition enforces the transition rules when moving within the same workflow
+// Moving in from another workflow (after a category change) is always allowed
+func (s *todoService) checkTransition(workflow []models.WorkflowStatus, currentID *uint, target *models.WorkflowStatus) error {
+	if currentID == nil || findStatus(workflow, *currentID) == nil {
+		return nil
+	}
+	return s.workflowService.CheckTransition(*currentID, target.ID)
+}
+
+// AddBlocker records that a todo is blocked by another todo, refusing dependency cycles
+func (s *todoService) AddBlocker(todoID, blockerID uint) error {
+	if todoID == 0 || blockerID == 0 {
+		return errors.New("invalid todo ID")
+	}
+	if todoID == blockerID {
+		return errors.New("invalid dependency: a todo cannot block itself")
+	}
+
+	// Check if both todos exist
+	if _, err := s.todoRepo.GetByID(todoID); err != nil {
+		return err
+	}
+	if _, err := s.todoRepo.GetByID(blockerID); err != nil {
+		return errors.New("blocker todo does not exist")
+	}
+
+	// Detect cycles before inserting the edge
+	createsCycle, err := s.dependsOn(blockerID, todoID)
+	if err != nil {
+		return err
+	}
+	if createsCycle {
+		return errors.New("invalid dependency: it would create a cycle")
+	}
+
+	return s.todoRepo.AddDependency(todoID, blockerID)
+}
+
+// RemoveBlocker removes a blocker from a todo
+func (s *todoService) RemoveBlocker(todoID, blockerID uint) error {
+	if todoID == 0 || blockerID == 0 {
+		return errors.New("invalid todo ID")
+	}
+	return s.todoRepo.RemoveDependency(todoID, blockerID)
+}
+
+// dependsOn reports whether target is reachable from start by following blocker edges
+// The graph is walked breadth-first, one query per level
+func (s *todoService) dependsOn(start, target uint) (bool, error) {
+	visited := map[uint]bool{start: true}
+	frontier := []uint{start}
+
+	for len(frontier) > 0 {
+		blockers, err := s.todoRepo.GetBlockerIDs(frontier)
+		if err != nil {
+			return false, err
+		}
+
+		var next []uint
+		for _, ids := range blockers {
+			for _, id := range ids {
+				if id == target {
+					return true, nil
+				}
+				if !visited[id] {
+					visited[id] = true
+					next = append(next, id)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return false, nil
 }
 
 // validateTodo validates basic todo data
@@ -138,6 +517,19 @@ func (s *todoService) validateTodo(todo *models.Todo) error {
 		return errors.New("invalid priority value")
 	}
 
+	// Validate the recurrence rule
+	return validateRecurrence(todo.Recurrence)
+}
+
+// validateRecurrence checks that a recurrence is empty or an iCalendar RRULE value that fits its column
+func validateRecurrence(recurrence string) error {
+	rule, err := ical.NormalizeRecurrence(recurrence)
+	if err != nil {
+		return errors.New("invalid recurrence: " + err.Error())
+	}
+	if len(rule) > 255 {
+		return errors.New("todo recurrence cannot exceed 255 characters")
+	}
 	return nil
 }
 
@@ -163,6 +555,107 @@ func (s *todoService) validateTodoBusinessRules(todo *models.Todo) error {
 	return nil
 }
 
+// sameTime reports whether two optional times are both unset or the same instant
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// validateTodoFilters normalizes todo list filters and checks their values and combinations
+// Date filters are resolved against the current time only to compare them, they are stored as given
+func validateTodoFilters(filters *repository.TodoFilters) error {
+	filters.Search = strings.TrimSpace(filters.Search)
+	filters.Status = normalizeStatusKey(filters.Status)
+	filters.Query = strings.TrimSpace(filters.Query)
+
+	// Category IDs or none
+	categories := repository.FilterValues{}
+	for _, value := range filters.CategoryID {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value != repository.FilterValueNone {
+			if id, err := strconv.ParseUint(value, 10, 32); err != nil || id == 0 {
+				return errors.New("invalid category_id filter: must be category IDs or none")
+			}
+		}
+		categories = appendFilterValue(categories, value)
+	}
+	filters.CategoryID = nil
+	if len(categories) > 0 {
+		filters.CategoryID = categories
+	}
+
+	// Priorities
+	priorities := repository.FilterValues{}
+	for _, value := range filters.Priority {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if !models.Priority(value).IsValid() {
+			return errors.New("invalid priority filter: must be low, medium or high")
+		}
+		priorities = appendFilterValue(priorities, value)
+	}
+	filters.Priority = nil
+	if len(priorities) > 0 {
+		filters.Priority = priorities
+	}
+
+	// Date filters
+	now := time.Now()
+	dates := []struct {
+		name  string
+		value *string
+	}{
+		{"due_after", &filters.DueAfter},
+		{"due_before", &filters.DueBefore},
+		{"created_after", &filters.CreatedAfter},
+		{"updated_after", &filters.UpdatedAfter},
+	}
+	for _, date := range dates {
+		*date.value = strings.TrimSpace(*date.value)
+		if *date.value == "" {
+			continue
+		}
+		if _, _, err := repository.ParseFilterTime(*date.value, now); err != nil {
+			return errors.New("invalid " + date.name + " filter: " + err.Error())
+		}
+	}
+	if filters.DueAfter != "" && filters.DueBefore != "" {
+		after, _, _ := repository.ParseFilterTime(filters.DueAfter, now)
+		_, before, _ := repository.ParseFilterTime(filters.DueBefore, now)
+		if !after.Before(before) {
+			return errors.New("invalid due date range: due_after is later than due_before")
+		}
+	}
+
+	// Combinations that can never match
+	if filters.HasDueDate != nil && !*filters.HasDueDate {
+		if filters.DueAfter != "" || filters.DueBefore != "" {
+			return errors.New("invalid filter combination: has_due_date=false cannot be combined with due_after or due_before")
+		}
+		if filters.Overdue != nil && *filters.Overdue {
+			return errors.New("invalid filter combination: overdue todos have a due date")
+		}
+	}
+	if filters.Overdue != nil && *filters.Overdue && filters.Completed != nil && *filters.Completed {
+		return errors.New("invalid filter combination: overdue todos are not completed")
+	}
+
+	// Filter query, so syntax errors are reported with their position
+	_, err := todoquery.Parse(filters.Query, now)
+	return err
+}
+
+// appendFilterValue appends a filter value unless the list already has it
+func appendFilterValue(values repository.FilterValues, value string) repository.FilterValues {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
 // cleanTodoData cleans and formats todo data
 func (s *todoService) cleanTodoData(todo *models.Todo) {
 	// Trim whitespace from title and description
@@ -179,4 +672,9 @@ func (s *todoService) cleanTodoData(todo *models.Todo) {
 		utcTime := todo.DueDate.UTC()
 		todo.DueDate = &utcTime
 	}
+
+	// Upper-case the recurrence rule and drop its spaces
+	if rule, err := ical.NormalizeRecurrence(todo.Recurrence); err == nil {
+		todo.Recurrence = rule
+	}
 }
\ No newline at end of file
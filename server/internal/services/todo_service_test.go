@@ -0,0 +1,204 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+)
+
+func TestTodoServiceIfMatchVersion(t *testing.T) {
+	archived := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	todos := []models.Todo{
+		{ID: 1, Title: "Open", StatusID: uintPtr(1), Version: 3},
+		{ID: 2, Title: "Done", StatusID: uintPtr(4), Completed: true, Version: 3},
+		{ID: 3, Title: "Archived", StatusID: uintPtr(4), Completed: true, ArchivedAt: &archived, Version: 3},
+	}
+	tests := []struct {
+		name   string
+		change func(s *todoService, version uint) error
+	}{
+		{name: "complete", change: func(s *todoService, version uint) error { return s.ToggleTodoComplete(1, version, false) }},
+		{name: "status", change: func(s *todoService, version uint) error { return s.ChangeTodoStatus(1, 2, version, false) }},
+		{name: "move", change: func(s *todoService, version uint) error { return s.MoveTodo(1, version, nil, uintPtr(2), nil) }},
+		{name: "archive", change: func(s *todoService, version uint) error { return s.ArchiveTodo(2, version) }},
+		{name: "unarchive", change: func(s *todoService, version uint) error { return s.UnarchiveTodo(3, version) }},
+	}
+	for _, tt := range tests {
+		for _, version := range []uint{0, 3, 2} {
+			s, todoRepo := newTestTodoService(todos...)
+			err := tt.change(s, version)
+			if version == 2 {
+				if err == nil || err.Error() != "todo was modified by another request" {
+					t.Errorf("%s with stale version: error = %v; want a version conflict", tt.name, err)
+				}
+				if len(todoRepo.updated) != 0 {
+					t.Errorf("%s with stale version changed %+v", tt.name, todoRepo.updated)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s with version %d: %v", tt.name, version, err)
+				continue
+			}
+			if len(todoRepo.updated) != 1 || todoRepo.updated[0].Version != 4 {
+				t.Errorf("%s with version %d updated %+v; want one update to version 4", tt.name, version, todoRepo.updated)
+			}
+		}
+	}
+}
+
+func TestTodoServiceAddBlockerCycles(t *testing.T) {
+	// Todos 1 to 4 are live, todo 5 is in the trash: 1 blocks 2, 2 blocks 3, 5 blocks 1 and 4 blocks 5
+	todos := []models.Todo{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}
+	blockers := map[uint][]uint{2: {1}, 3: {2}, 1: {5}, 5: {4}}
+
+	tests := []struct {
+		name      string
+		todoID    uint
+		blockerID uint
+		wantErr   string
+	}{
+		{name: "self", todoID: 1, blockerID: 1, wantErr: "invalid dependency: a todo cannot block itself"},
+		{name: "direct", todoID: 1, blockerID: 2, wantErr: "invalid dependency: it would create a cycle"},
+		{name: "transitive", todoID: 1, blockerID: 3, wantErr: "invalid dependency: it would create a cycle"},
+		{name: "through a trashed todo", todoID: 4, blockerID: 1, wantErr: "invalid dependency: it would create a cycle"},
+		{name: "missing blocker", todoID: 1, blockerID: 9, wantErr: "blocker todo does not exist"},
+		{name: "no cycle", todoID: 3, blockerID: 4},
+		{name: "blocked transitively already", todoID: 3, blockerID: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, todoRepo := newTestTodoService(todos...)
+			todoRepo.blockers = map[uint][]uint{}
+			for id, ids := range blockers {
+				todoRepo.blockers[id] = append([]uint(nil), ids...)
+			}
+
+			err := s.AddBlocker(tt.todoID, tt.blockerID)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("AddBlocker(%d, %d) error = %v; want %q", tt.todoID, tt.blockerID, err, tt.wantErr)
+				}
+				if len(todoRepo.blockers[tt.todoID]) != len(blockers[tt.todoID]) {
+					t.Errorf("AddBlocker(%d, %d) failed but added the dependency", tt.todoID, tt.blockerID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddBlocker(%d, %d): %v", tt.todoID, tt.blockerID, err)
+			}
+			ids := todoRepo.blockers[tt.todoID]
+			if len(ids) == 0 || ids[len(ids)-1] != tt.blockerID {
+				t.Errorf("blockers of %d = %v; want %d added", tt.todoID, ids, tt.blockerID)
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestValidateTodoFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters repository.TodoFilters
+		want    repository.TodoFilters // the filters after cleaning, when valid
+		wantErr string
+	}{
+		{name: "empty", filters: repository.TodoFilters{}, want: repository.TodoFilters{}},
+		{
+			name:    "trims text and normalizes the status",
+			filters: repository.TodoFilters{Search: "  report ", Status: " In Progress ", Query: " -completed "},
+			want:    repository.TodoFilters{Search: "report", Status: "in_progress", Query: "-completed"},
+		},
+		{
+			name:    "categories are trimmed and deduplicated",
+			filters: repository.TodoFilters{CategoryID: repository.FilterValues{" 2 ", "NONE", "2", "none"}},
+			want:    repository.TodoFilters{CategoryID: repository.FilterValues{"2", "none"}},
+		},
+		{name: "category zero", filters: repository.TodoFilters{CategoryID: repository.FilterValues{"0"}}, wantErr: "invalid category_id filter"},
+		{name: "category negative", filters: repository.TodoFilters{CategoryID: repository.FilterValues{"-1"}}, wantErr: "invalid category_id filter"},
+		{name: "category name", filters: repository.TodoFilters{CategoryID: repository.FilterValues{"work"}}, wantErr: "invalid category_id filter"},
+		{name: "category empty value", filters: repository.TodoFilters{CategoryID: repository.FilterValues{""}}, wantErr: "invalid category_id filter"},
+		{name: "category over 32 bits", filters: repository.TodoFilters{CategoryID: repository.FilterValues{"4294967296"}}, wantErr: "invalid category_id filter"},
+		{
+			name:    "empty lists are dropped",
+			filters: repository.TodoFilters{CategoryID: repository.FilterValues{}, Priority: repository.FilterValues{}},
+			want:    repository.TodoFilters{},
+		},
+		{
+			name:    "priorities are lowercased and deduplicated",
+			filters: repository.TodoFilters{Priority: repository.FilterValues{"HIGH", " high", "low"}},
+			want:    repository.TodoFilters{Priority: repository.FilterValues{"high", "low"}},
+		},
+		{name: "unknown priority", filters: repository.TodoFilters{Priority: repository.FilterValues{"urgent"}}, wantErr: "invalid priority filter"},
+		{
+			name:    "relative and absolute dates",
+			filters: repository.TodoFilters{DueAfter: " today ", DueBefore: "7d", CreatedAfter: "2026-01-01", UpdatedAfter: "2026-01-01T10:00:00Z"},
+			want:    repository.TodoFilters{DueAfter: "today", DueBefore: "7d", CreatedAfter: "2026-01-01", UpdatedAfter: "2026-01-01T10:00:00Z"},
+		},
+		{name: "unknown date", filters: repository.TodoFilters{DueAfter: "soon"}, wantErr: "invalid due_after filter"},
+		{name: "invalid calendar date", filters: repository.TodoFilters{CreatedAfter: "2026-02-30"}, wantErr: "invalid created_after filter"},
+		{
+			name:    "due range of a single day",
+			filters: repository.TodoFilters{DueAfter: "2026-01-01", DueBefore: "2026-01-01"},
+			want:    repository.TodoFilters{DueAfter: "2026-01-01", DueBefore: "2026-01-01"},
+		},
+		{
+			name:    "due range of a single instant",
+			filters: repository.TodoFilters{DueAfter: "2026-01-01T10:00:00Z", DueBefore: "2026-01-01T10:00:00Z"},
+			want:    repository.TodoFilters{DueAfter: "2026-01-01T10:00:00Z", DueBefore: "2026-01-01T10:00:00Z"},
+		},
+		{name: "reversed due range", filters: repository.TodoFilters{DueAfter: "2026-01-02", DueBefore: "2026-01-01"}, wantErr: "invalid due date range"},
+		{
+			name:    "without due date in a due range",
+			filters: repository.TodoFilters{HasDueDate: boolPtr(false), DueBefore: "2026-01-01"},
+			wantErr: "invalid filter combination",
+		},
+		{
+			name:    "overdue without due date",
+			filters: repository.TodoFilters{HasDueDate: boolPtr(false), Overdue: boolPtr(true)},
+			wantErr: "invalid filter combination",
+		},
+		{
+			name:    "not overdue without due date",
+			filters: repository.TodoFilters{HasDueDate: boolPtr(false), Overdue: boolPtr(false)},
+			want:    repository.TodoFilters{HasDueDate: boolPtr(false), Overdue: boolPtr(false)},
+		},
+		{
+			name:    "overdue and completed",
+			filters: repository.TodoFilters{Overdue: boolPtr(true), Completed: boolPtr(true)},
+			wantErr: "invalid filter combination",
+		},
+		{
+			name:    "not overdue and completed",
+			filters: repository.TodoFilters{Overdue: boolPtr(false), Completed: boolPtr(true)},
+			want:    repository.TodoFilters{Overdue: boolPtr(false), Completed: boolPtr(true)},
+		},
+		{name: "query syntax error", filters: repository.TodoFilters{Query: "priority:"}, wantErr: "priority"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters := tt.filters
+			err := validateTodoFilters(&filters)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("validateTodoFilters error = %v; want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateTodoFilters: unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(filters, tt.want) {
+				t.Errorf("filters = %+v; want %+v", filters, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/todotxt"
+)
+
+// todoTxtPriorities maps todo priorities to todo.txt priorities
+var todoTxtPriorities = map[models.Priority]byte{
+	models.PriorityHigh:   'A',
+	models.PriorityMedium: 'B',
+	models.PriorityLow:    'C',
+}
+
+// exportTodosTxt writes one todo.txt line per todo and returns the number of todos written
+// The category becomes a +project, tags become @contexts and the due date a due: tag
+func exportTodosTxt(w io.Writer, each func(fn func(row *repository.TodoExportRow) error) error) (int, error) {
+	count := 0
+	err := each(func(row *repository.TodoExportRow) error {
+		count++
+		_, err := io.WriteString(w, todoTxtTask(row).String()+"\n")
+		return err
+	})
+	return count, err
+}
+
+// todoTxtTask converts a todo to a todo.txt task
+func todoTxtTask(row *repository.TodoExportRow) todotxt.Task {
+	created := row.CreatedAt.UTC()
+	task := todotxt.Task{
+		Completed:    row.Completed,
+		Priority:     todoTxtPriorities[row.Priority],
+		CreationDate: &created,
+		Text:         strings.Join(strings.Fields(row.Title), " "),
+		Tags:         map[string]string{},
+	}
+	if row.Completed {
+		completed := row.UpdatedAt.UTC()
+		if row.CompletedAt != nil {
+			completed = row.CompletedAt.UTC()
+		}
+		task.CompletionDate = &completed
+	}
+	if row.CategoryName != nil {
+		task.Projects = []string{todotxt.Word(*row.CategoryName)}
+	}
+	for _, tag := range row.Tags {
+		task.Contexts = append(task.Contexts, todotxt.Word(tag))
+	}
+	if row.DueDate != nil {
+		task.Tags["due"] = row.DueDate.UTC().Format(todotxt.DateLayout)
+	}
+	return task
+}
+
+// parseImportTodoTxt reads todos from a todo.txt file
+// The first +project names the category and further ones become tags, as do @contexts;
+// key:value tags other than due: stay in the title so nothing is lost
+func parseImportTodoTxt(data []byte) ([]importRow, error) {
+	var rows []importRow
+	err := todotxt.ReadAll(bytes.NewReader(data), func(line int, task todotxt.Task, err error) error {
+		row := importRow{
+			Row:       line,
+			Title:     task.Text,
+			Completed: task.Completed,
+			Priority:  string(models.PriorityMedium),
+		}
+		if err != nil {
+			row.Errors = append(row.Errors, err.Error())
+			rows = append(rows, row)
+			return nil
+		}
+
+		switch {
+		case task.Priority == 'A':
+			row.Priority = string(models.PriorityHigh)
+		case task.Priority >= 'C':
+			row.Priority = string(models.PriorityLow)
+		}
+
+		for i, project := range task.Projects {
+			if i == 0 {
+				row.CategoryName = todotxt.Name(project)
+			} else {
+				row.Tags = append(row.Tags, todotxt.Name(project))
+			}
+		}
+		for _, context := range task.Contexts {
+			row.Tags = append(row.Tags, todotxt.Name(context))
+		}
+
+		if due, ok := task.Tags["due"]; ok {
+			row.DueDate = parseImportTime("due_date", due, &row)
+			delete(task.Tags, "due")
+		}
+		if task.CompletionDate != nil {
+			completed := *task.CompletionDate
+			row.CompletedAt = &completed
+		}
+
+		keys := make([]string, 0, len(task.Tags))
+		for key := range task.Tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			row.Title = strings.TrimSpace(fmt.Sprintf("%s %s:%s", row.Title, key, task.Tags[key]))
+		}
+
+		// A task of only projects and contexts is titled after them
+		if row.Title == "" {
+			row.Title = strings.TrimSpace(row.CategoryName + " " + strings.Join(row.Tags, " "))
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	return rows, err
+}
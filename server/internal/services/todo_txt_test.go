@@ -0,0 +1,132 @@
+package services
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+)
+
+func TestTodoTxtExportImportRoundTrip(t *testing.T) {
+	created := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	completedAt := time.Date(2024, 1, 12, 17, 30, 0, 0, time.UTC)
+	due := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	name := func(s string) *string { return &s }
+
+	tests := []struct {
+		name string
+		row  repository.TodoExportRow
+		want importRow
+	}{
+		{
+			name: "plain",
+			row:  repository.TodoExportRow{Title: "Call Ann", Priority: models.PriorityMedium},
+			want: importRow{Title: "Call Ann", Priority: "medium"},
+		},
+		{
+			name: "all fields",
+			row: repository.TodoExportRow{
+				Title: "Write report", Priority: models.PriorityHigh, DueDate: &due,
+				CategoryName: name("Home Office"), Tags: []string{"urgent", "deep work"},
+			},
+			want: importRow{
+				Title: "Write report", Priority: "high", DueDate: &due,
+				CategoryName: "Home Office", Tags: []string{"urgent", "deep work"},
+			},
+		},
+		{
+			name: "completed",
+			row: repository.TodoExportRow{
+				Title: "Pay rent", Priority: models.PriorityLow, Completed: true, CompletedAt: &completedAt,
+			},
+			want: importRow{
+				Title: "Pay rent", Priority: "low", Completed: true,
+				CompletedAt: func() *time.Time { d := time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC); return &d }(),
+			},
+		},
+		{
+			name: "title words that look like markup",
+			row: repository.TodoExportRow{
+				Title: "Ask @bob about +1 for re:login due:friday", Priority: models.PriorityMedium,
+			},
+			want: importRow{Title: "Ask @bob about +1 for re:login due:friday", Priority: "medium"},
+		},
+		{
+			name: "title starting like a completed task",
+			row:  repository.TodoExportRow{Title: "x (A) 2024-01-01 marks the spot", Priority: models.PriorityMedium},
+			want: importRow{Title: "x (A) 2024-01-01 marks the spot", Priority: "medium"},
+		},
+		{
+			name: "underscores and backslashes in names",
+			row: repository.TodoExportRow{
+				Title: "Deploy", Priority: models.PriorityMedium,
+				CategoryName: name("my_project"), Tags: []string{"C:\\temp", "a_b c"},
+			},
+			want: importRow{
+				Title: "Deploy", Priority: "medium",
+				CategoryName: "my_project", Tags: []string{"C:\\temp", "a_b c"},
+			},
+		},
+		{
+			name: "extra spaces in the title",
+			row:  repository.TodoExportRow{Title: "  Call   Ann  ", Priority: models.PriorityMedium},
+			want: importRow{Title: "Call Ann", Priority: "medium"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.row.CreatedAt = created
+			tt.row.UpdatedAt = created
+
+			var file bytes.Buffer
+			count, err := exportTodosTxt(&file, func(fn func(row *repository.TodoExportRow) error) error {
+				return fn(&tt.row)
+			})
+			if err != nil || count != 1 {
+				t.Fatalf("exportTodosTxt = %d, %v; want 1 todo", count, err)
+			}
+
+			rows, err := parseImportTodoTxt(file.Bytes())
+			if err != nil {
+				t.Fatalf("parseImportTodoTxt(%q): %v", file.String(), err)
+			}
+			if len(rows) != 1 {
+				t.Fatalf("parseImportTodoTxt(%q) = %d rows; want 1", file.String(), len(rows))
+			}
+			tt.want.Row = 1
+			if !reflect.DeepEqual(rows[0], tt.want) {
+				t.Errorf("round trip through %q =\n%+v\nwant\n%+v", file.String(), rows[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImportTodoTxt(t *testing.T) {
+	data := []byte("(A) Call Ann +Work +Clients @phone due:2024-01-15 area:sales\n" +
+		"\n" +
+		"x 2024-01-12 2024-01-10 Pay rent pri:C\n" +
+		"Broken due:someday\n" +
+		"+Errands @town\n")
+	due := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	completed := time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)
+
+	rows, err := parseImportTodoTxt(data)
+	if err != nil {
+		t.Fatalf("parseImportTodoTxt: %v", err)
+	}
+	want := []importRow{
+		{
+			Row: 1, Title: "Call Ann area:sales", Priority: "high", DueDate: &due,
+			CategoryName: "Work", Tags: []string{"Clients", "phone"},
+		},
+		{Row: 3, Title: "Pay rent", Priority: "low", Completed: true, CompletedAt: &completed},
+		{Row: 4, Title: "Broken", Priority: "medium", Errors: []string{`invalid due_date: "someday" is not a date`}},
+		{Row: 5, Title: "Errands town", Priority: "medium", CategoryName: "Errands", Tags: []string{"town"}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("parseImportTodoTxt =\n%+v\nwant\n%+v", rows, want)
+	}
+}
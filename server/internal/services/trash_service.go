@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+)
+
+// trashService implements TrashService interface
+type trashService struct {
+	trashRepo         repository.TrashRepository
+	todoRepo          repository.TodoRepository
+	categoryRepo      repository.CategoryRepository
+	workflowService   WorkflowService
+	attachmentService AttachmentService
+	retention         time.Duration
+}
+
+// NewTrashService creates a new trash service
+// Deleted items are purged after retentionDays; zero keeps them until deleted permanently
+func NewTrashService(trashRepo repository.TrashRepository, todoRepo repository.TodoRepository, categoryRepo repository.CategoryRepository, workflowService WorkflowService, attachmentService AttachmentService, retentionDays int) TrashService {
+	return &trashService{
+		trashRepo:         trashRepo,
+		todoRepo:          todoRepo,
+		categoryRepo:      categoryRepo,
+		workflowService:   workflowService,
+		attachmentService: attachmentService,
+		retention:         time.Duration(retentionDays) * 24 * time.Hour,
+	}
+}
+
+// WithContext returns a copy of the service whose changes are recorded with the audit info of ctx
+func (s *trashService) WithContext(ctx context.Context) TrashService {
+	scoped := *s
+	scoped.trashRepo = s.trashRepo.WithContext(ctx)
+	scoped.todoRepo = s.todoRepo.WithContext(ctx)
+	scoped.categoryRepo = s.categoryRepo.WithContext(ctx)
+	scoped.workflowService = s.workflowService.WithContext(ctx)
+	scoped.attachmentService = s.attachmentService.WithContext(ctx)
+	return &scoped
+}
+
+// ListTodos retrieves soft-deleted todos with their purge time
+func (s *trashService) ListTodos(pagination repository.PaginationParams) ([]models.TrashedTodo, repository.PaginationResult, error) {
+	todos, paginationResult, err := s.trashRepo.ListTodos(pagination)
+	if err != nil {
+		return nil, repository.PaginationResult{}, err
+	}
+
+	trashed := make([]models.TrashedTodo, len(todos))
+	for i, todo := range todos {
+		trashed[i] = models.TrashedTodo{
+			Todo:      todo,
+			DeletedAt: todo.DeletedAt.Time,
+			PurgeAt:   s.purgeAt(todo.DeletedAt.Time),
+		}
+	}
+	return trashed, paginationResult, nil
+}
+
+// ListCategories retrieves soft-deleted categories with their purge time
+func (s *trashService) ListCategories(pagination repository.PaginationParams) ([]models.TrashedCategory, repository.PaginationResult, error) {
+	categories, paginationResult, err := s.trashRepo.ListCategories(pagination)
+	if err != nil {
+		return nil, repository.PaginationResult{}, err
+	}
+
+	trashed := make([]models.TrashedCategory, len(categories))
+	for i, category := range categories {
+		trashed[i] = models.TrashedCategory{
+			Category:  category,
+			DeletedAt: category.DeletedAt.Time,
+			PurgeAt:   s.purgeAt(category.DeletedAt.Time),
+		}
+	}
+	return trashed, paginationResult, nil
+}
+
+// RestoreTodo restores a soft-deleted todo and its category
+// A todo whose workflow status was removed meanwhile gets the first matching status of its workflow
+func (s *trashService) RestoreTodo(id uint) (*models.Todo, error) {
+	if id == 0 {
+		return nil, errors.New("invalid todo ID")
+	}
+
+	if err := s.trashRepo.RestoreTodo(id); err != nil {
+		return nil, err
+	}
+
+	todo, err := s.todoRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if todo.StatusID == nil {
+		workflow, err := s.workflowService.GetWorkflow(todo.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		status := firstStatus(workflow, todo.Completed)
+		if status == nil {
+			return nil, errors.New("workflow has no matching status")
+		}
+		if err := s.todoRepo.SetStatus(id, 0, status.ID, status.IsDone); err != nil {
+			return nil, err
+		}
+		return s.todoRepo.GetByID(id)
+	}
+
+	return todo, nil
+}
+
+// RestoreCategory restores a soft-deleted category
+func (s *trashService) RestoreCategory(id uint) (*models.Category, error) {
+	if id == 0 {
+		return nil, errors.New("invalid category ID")
+	}
+
+	if err := s.trashRepo.RestoreCategory(id); err != nil {
+		return nil, err
+	}
+
+	return s.categoryRepo.GetByID(id)
+}
+
+// DeleteTodo permanently deletes a soft-deleted todo and its attachments
+func (s *trashService) DeleteTodo(id uint) error {
+	if id == 0 {
+		return errors.New("invalid todo ID")
+	}
+
+	storageKeys, err := s.trashRepo.PurgeTodo(id)
+	if err != nil {
+		return err
+	}
+
+	// Blobs are not covered by the database cascade, they are deleted once the purge is committed
+	// so a failed purge keeps the attachments intact
+	s.attachmentService.ReleaseBlobs(storageKeys)
+	return nil
+}
+
+// DeleteCategory permanently deletes a soft-deleted category
+func (s *trashService) DeleteCategory(id uint) error {
+	if id == 0 {
+		return errors.New("invalid category ID")
+	}
+	return s.trashRepo.PurgeCategory(id)
+}
+
+// PurgeExpired permanently deletes todos and categories that stayed in the trash longer than the retention period
+// Returns the number of purged items
+func (s *trashService) PurgeExpired() (int, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().Add(-s.retention)
+
+	ids, err := s.trashRepo.ListExpiredTodoIDs(cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		if err := s.DeleteTodo(id); err != nil {
+			// Keep purging the other todos, this one is retried on the next run
+			log.Printf("Failed to purge todo %d: %v", id, err)
+			continue
+		}
+		purged++
+	}
+
+	categories, err := s.trashRepo.PurgeExpiredCategories(cutoff)
+	if err != nil {
+		return purged, err
+	}
+	return purged + int(categories), nil
+}
+
+// purgeAt returns when an item deleted at the given time is purged, nil when purging is disabled
+func (s *trashService) purgeAt(deletedAt time.Time) *time.Time {
+	if s.retention <= 0 {
+		return nil
+	}
+	purgeAt := deletedAt.Add(s.retention)
+	return &purgeAt
+}
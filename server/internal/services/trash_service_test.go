@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"todo-backend/internal/repository"
+)
+
+// fakeTrashRepo purges todos, logging the calls it receives
+type fakeTrashRepo struct {
+	repository.TrashRepository
+	calls    *[]string
+	purgeErr error
+}
+
+func (r *fakeTrashRepo) PurgeTodo(id uint) ([]string, error) {
+	*r.calls = append(*r.calls, "purge")
+	if r.purgeErr != nil {
+		return nil, r.purgeErr
+	}
+	return []string{"blob-a", "blob-b"}, nil
+}
+
+// fakeAttachmentService releases blobs, logging the calls it receives
+type fakeAttachmentService struct {
+	AttachmentService
+	calls *[]string
+}
+
+func (s *fakeAttachmentService) ReleaseBlobs(storageKeys []string) {
+	for _, storageKey := range storageKeys {
+		*s.calls = append(*s.calls, "release "+storageKey)
+	}
+}
+
+func TestTrashDeleteTodoReleasesBlobsAfterPurge(t *testing.T) {
+	tests := []struct {
+		name     string
+		purgeErr error
+		want     []string
+	}{
+		{name: "purged", want: []string{"purge", "release blob-a", "release blob-b"}},
+		{name: "purge failed", purgeErr: errors.New("todo not found in trash"), want: []string{"purge"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			s := &trashService{
+				trashRepo:         &fakeTrashRepo{calls: &calls, purgeErr: tt.purgeErr},
+				attachmentService: &fakeAttachmentService{calls: &calls},
+			}
+			if err := s.DeleteTodo(5); !errors.Is(err, tt.purgeErr) {
+				t.Fatalf("DeleteTodo error = %v; want %v", err, tt.purgeErr)
+			}
+			if !reflect.DeepEqual(calls, tt.want) {
+				t.Errorf("calls = %q; want %q", calls, tt.want)
+			}
+		})
+	}
+}
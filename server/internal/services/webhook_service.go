@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+	"todo-backend/pkg/webhook"
+)
+
+// webhookBatchSize is the number of due deliveries sent per run of the delivery job
+const webhookBatchSize = 20
+
+// webhookService implements WebhookService interface
+type webhookService struct {
+	webhookRepo repository.WebhookRepository
+	sender      *webhook.Sender
+	timeout     time.Duration
+	maxAttempts int
+	retryBase   time.Duration
+	retryMax    time.Duration
+}
+
+// NewWebhookService creates a new webhook service
+// Failed deliveries are retried after retryBase, doubling up to retryMax, until maxAttempts attempts were made
+func NewWebhookService(webhookRepo repository.WebhookRepository, timeout time.Duration, maxAttempts int, retryBase, retryMax time.Duration) WebhookService {
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		sender:      webhook.NewSender(timeout),
+		timeout:     timeout,
+		maxAttempts: maxAttempts,
+		retryBase:   retryBase,
+		retryMax:    retryMax,
+	}
+}
+
+// CreateSubscription creates a webhook subscription, generating a secret when none is given
+func (s *webhookService) CreateSubscription(subscription *models.WebhookSubscription) error {
+	if err := s.validateSubscription(subscription); err != nil {
+		return err
+	}
+
+	subscription.ID = 0
+	if subscription.Secret == "" {
+		subscription.Secret = newWebhookSecret()
+	}
+	return s.webhookRepo.CreateSubscription(subscription)
+}
+
+// GetSubscription retrieves a webhook subscription by its ID, without its secret
+func (s *webhookService) GetSubscription(id uint) (*models.WebhookSubscription, error) {
+	if id == 0 {
+		return nil, errors.New("invalid webhook subscription ID")
+	}
+
+	subscription, err := s.webhookRepo.GetSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+// ListSubscriptions retrieves all webhook subscriptions, without their secrets
+func (s *webhookService) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	subscriptions, err := s.webhookRepo.ListSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription updates a webhook subscription, keeping its secret when none is given
+func (s *webhookService) UpdateSubscription(subscription *models.WebhookSubscription) error {
+	if subscription.ID == 0 {
+		return errors.New("invalid webhook subscription ID")
+	}
+	if err := s.validateSubscription(subscription); err != nil {
+		return err
+	}
+
+	existing, err := s.webhookRepo.GetSubscription(subscription.ID)
+	if err != nil {
+		return err
+	}
+	if subscription.Secret == "" {
+		subscription.Secret = existing.Secret
+	}
+	subscription.CreatedAt = existing.CreatedAt
+
+	if err := s.webhookRepo.UpdateSubscription(subscription); err != nil {
+		return err
+	}
+	subscription.Secret = ""
+	return nil
+}
+
+// DeleteSubscription deletes a webhook subscription and its delivery log
+func (s *webhookService) DeleteSubscription(id uint) error {
+	if id == 0 {
+		return errors.New("invalid webhook subscription ID")
+	}
+	return s.webhookRepo.DeleteSubscription(id)
+}
+
+// ListDeliveries retrieves the delivery log of a subscription, newest first
+func (s *webhookService) ListDeliveries(subscriptionID uint, pagination repository.PaginationParams) ([]models.WebhookDelivery, repository.PaginationResult, error) {
+	if _, err := s.GetSubscription(subscriptionID); err != nil {
+		return nil, repository.PaginationResult{}, err
+	}
+	return s.webhookRepo.ListDeliveries(subscriptionID, pagination)
+}
+
+// Redeliver queues a new delivery of the event of an earlier delivery
+// The new delivery is sent by the next run of the delivery job, with a fresh set of attempts
+func (s *webhookService) Redeliver(subscriptionID, deliveryID uint) (*models.WebhookDelivery, error) {
+	subscription, err := s.GetSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.Disabled {
+		return nil, errors.New("cannot redeliver to a disabled webhook subscription")
+	}
+
+	original, err := s.webhookRepo.GetDelivery(subscriptionID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: subscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.DeliveryStatusPending,
+		NextAttemptAt:  time.Now(),
+		RedeliveryOf:   &original.ID,
+	}
+	if err := s.webhookRepo.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// DeliverDue sends the deliveries that are due and records the outcome of each attempt
+// It returns the number of deliveries attempted
+func (s *webhookService) DeliverDue() (int, error) {
+	// Claimed deliveries stay hidden from other workers while this batch is sent
+	lease := time.Duration(webhookBatchSize)*s.timeout + time.Minute
+	deliveries, err := s.webhookRepo.ClaimDueDeliveries(webhookBatchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		s.attempt(&deliveries[i])
+		if err := s.webhookRepo.SaveDeliveryResult(&deliveries[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// attempt sends a delivery once and updates its status, scheduling a retry after a failure
+func (s *webhookService) attempt(delivery *models.WebhookDelivery) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = 0
+	delivery.ResponseBody = ""
+	delivery.Error = ""
+
+	subscription := delivery.Subscription
+	if subscription == nil || subscription.Disabled {
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.Error = "webhook subscription is disabled"
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	result, err := s.sender.Send(ctx, webhook.Message{
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		Event:      delivery.EventType,
+		DeliveryID: strconv.FormatUint(uint64(delivery.ID), 10),
+		Body:       delivery.Payload,
+	})
+	if result != nil {
+		delivery.ResponseStatus = result.StatusCode
+		delivery.ResponseBody = result.Body
+	}
+
+	if err == nil {
+		delivery.Status = models.DeliveryStatusSucceeded
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	delivery.Error = err.Error()
+	if delivery.Attempts >= s.maxAttempts {
+		delivery.Status = models.DeliveryStatusFailed
+		log.Printf("Webhook delivery %d to %s failed after %d attempts: %v", delivery.ID, subscription.URL, delivery.Attempts, err)
+		return
+	}
+	delivery.NextAttemptAt = now.Add(webhook.Backoff(delivery.Attempts, s.retryBase, s.retryMax))
+}
+
+// validateSubscription validates the URL and event types of a subscription and cleans its data
+func (s *webhookService) validateSubscription(subscription *models.WebhookSubscription) error {
+	subscription.URL = strings.TrimSpace(subscription.URL)
+	subscription.Description = strings.TrimSpace(subscription.Description)
+
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("invalid webhook URL: must be an absolute http or https URL")
+	}
+
+	if len(subscription.Events) == 0 {
+		return errors.New("at least one event type is required")
+	}
+	known := map[string]bool{models.EventAll: true}
+	for _, eventType := range models.EventTypes {
+		known[eventType] = true
+	}
+	seen := make(map[string]bool, len(subscription.Events))
+	events := make([]string, 0, len(subscription.Events))
+	for _, eventType := range subscription.Events {
+		eventType = strings.ToLower(strings.TrimSpace(eventType))
+		if !known[eventType] {
+			return errors.New("invalid event type: " + eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			events = append(events, eventType)
+		}
+	}
+	subscription.Events = events
+	return nil
+}
+
+// newWebhookSecret generates a random signing secret
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"todo-backend/internal/models"
+	"todo-backend/pkg/webhook"
+)
+
+// newTestWebhookService creates a webhook service for attempt, which does not use the repository
+func newTestWebhookService(maxAttempts int) *webhookService {
+	return NewWebhookService(nil, time.Second, maxAttempts, time.Minute, 10*time.Minute).(*webhookService)
+}
+
+func newTestDelivery(url string) *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		ID:        7,
+		EventType: models.EventTodoCreated,
+		Payload:   []byte(`{"type":"todo.created"}`),
+		Status:    models.DeliveryStatusPending,
+		Subscription: &models.WebhookSubscription{
+			URL:    url,
+			Secret: "0123456789abcdef",
+		},
+	}
+}
+
+func TestWebhookAttemptRetriesUntilMaxAttempts(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	s := newTestWebhookService(3)
+	delivery := newTestDelivery(server.URL)
+	wantDelays := []time.Duration{time.Minute, 2 * time.Minute}
+	for attempt := 1; attempt <= 3; attempt++ {
+		before := time.Now()
+		s.attempt(delivery)
+
+		if delivery.Attempts != attempt {
+			t.Fatalf("attempt %d: Attempts = %d", attempt, delivery.Attempts)
+		}
+		if delivery.LastAttemptAt == nil || delivery.LastAttemptAt.Before(before) {
+			t.Errorf("attempt %d: LastAttemptAt = %v; want the attempt time", attempt, delivery.LastAttemptAt)
+		}
+		if delivery.ResponseStatus != http.StatusServiceUnavailable || delivery.ResponseBody != "unavailable\n" {
+			t.Errorf("attempt %d: response = %d %q; want the receiver response", attempt, delivery.ResponseStatus, delivery.ResponseBody)
+		}
+		if delivery.Error == "" {
+			t.Errorf("attempt %d: Error is empty", attempt)
+		}
+		if delivery.DeliveredAt != nil {
+			t.Errorf("attempt %d: DeliveredAt = %v; want nil", attempt, delivery.DeliveredAt)
+		}
+
+		if attempt < 3 {
+			if delivery.Status != models.DeliveryStatusPending {
+				t.Fatalf("attempt %d: Status = %q; want pending", attempt, delivery.Status)
+			}
+			if delay := delivery.NextAttemptAt.Sub(*delivery.LastAttemptAt); delay != wantDelays[attempt-1] {
+				t.Errorf("attempt %d: next attempt after %v; want %v", attempt, delay, wantDelays[attempt-1])
+			}
+		}
+	}
+	if delivery.Status != models.DeliveryStatusFailed {
+		t.Errorf("Status after max attempts = %q; want failed", delivery.Status)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("receiver got %d requests; want 3", got)
+	}
+}
+
+func TestWebhookAttemptSucceedsAfterServerError(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		if r.Header.Get(webhook.HeaderDelivery) != "7" || r.Header.Get(webhook.HeaderEvent) != models.EventTodoCreated {
+			http.Error(w, "missing headers", http.StatusBadRequest)
+			return
+		}
+		body := []byte(`{"type":"todo.created"}`)
+		if err := webhook.Verify("0123456789abcdef", r.Header, body, time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	s := newTestWebhookService(5)
+	delivery := newTestDelivery(server.URL)
+
+	s.attempt(delivery)
+	if delivery.Status != models.DeliveryStatusPending || delivery.ResponseStatus != http.StatusInternalServerError {
+		t.Fatalf("after a 500: Status = %q, ResponseStatus = %d; want pending, 500", delivery.Status, delivery.ResponseStatus)
+	}
+
+	s.attempt(delivery)
+	if delivery.Status != models.DeliveryStatusSucceeded {
+		t.Fatalf("after the retry: Status = %q (%s); want succeeded", delivery.Status, delivery.Error)
+	}
+	if delivery.Attempts != 2 || delivery.ResponseStatus != http.StatusNoContent {
+		t.Errorf("after the retry: Attempts = %d, ResponseStatus = %d; want 2, 204", delivery.Attempts, delivery.ResponseStatus)
+	}
+	if delivery.Error != "" || delivery.ResponseBody != "" {
+		t.Errorf("after the retry: Error = %q, ResponseBody = %q; want them cleared", delivery.Error, delivery.ResponseBody)
+	}
+	if delivery.DeliveredAt == nil {
+		t.Error("after the retry: DeliveredAt is nil")
+	}
+}
+
+func TestWebhookAttemptDisabledSubscription(t *testing.T) {
+	tests := []struct {
+		name         string
+		subscription *models.WebhookSubscription
+	}{
+		{name: "disabled", subscription: &models.WebhookSubscription{URL: "http://127.0.0.1:1", Disabled: true}},
+		{name: "deleted", subscription: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delivery := newTestDelivery("")
+			delivery.Subscription = tt.subscription
+			newTestWebhookService(5).attempt(delivery)
+			if delivery.Status != models.DeliveryStatusFailed || delivery.Attempts != 1 {
+				t.Errorf("Status = %q, Attempts = %d; want failed after 1 attempt", delivery.Status, delivery.Attempts)
+			}
+		})
+	}
+}
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"todo-backend/internal/models"
+	"todo-backend/internal/repository"
+)
+
+// statusKeyPattern matches valid workflow status keys such as "in_review"
+var statusKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// workflowService implements WorkflowService interface
+type workflowService struct {
+	workflowRepo repository.WorkflowRepository
+	categoryRepo repository.CategoryRepository
+}
+
+// NewWorkflowService creates a new workflow service
+func NewWorkflowService(workflowRepo repository.WorkflowRepository, categoryRepo repository.CategoryRepository) WorkflowService {
+	return &workflowService{
+		workflowRepo: workflowRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// WithContext returns a copy of the service whose changes are recorded with the audit info of ctx
+func (s *workflowService) WithContext(ctx context.Context) WorkflowService {
+	scoped := *s
+	scoped.workflowRepo = s.workflowRepo.WithContext(ctx)
+	scoped.categoryRepo = s.categoryRepo.WithContext(ctx)
+	return &scoped
+}
+
+// EnsureDefaultWorkflow seeds the default workflow and assigns a status to todos without one
+func (s *workflowService) EnsureDefaultWorkflow() error {
+	statuses, err := s.workflowRepo.ListStatuses(nil)
+	if err != nil {
+		return err
+	}
+
+	if len(statuses) == 0 {
+		statuses = models.DefaultWorkflowStatuses()
+		if err := s.workflowRepo.CreateStatuses(statuses); err != nil {
+			return err
+		}
+	}
+
+	open, done := firstStatus(statuses, false), firstStatus(statuses, true)
+	if open == nil || done == nil {
+		return errors.New("default workflow must have an open and a done status")
+	}
+
+	return s.workflowRepo.BackfillTodoStatuses(open.ID, done.ID)
+}
+
+// GetWorkflow retrieves the effective statuses of a category, falling back to the default workflow
+func (s *workflowService) GetWorkflow(categoryID *uint) ([]models.WorkflowStatus, error) {
+	if categoryID != nil {
+		statuses, err := s.workflowRepo.ListStatuses(categoryID)
+		if err != nil {
+			return nil, err
+		}
+		if len(statuses) > 0 {
+			return statuses, nil
+		}
+	}
+
+	statuses, err := s.workflowRepo.ListStatuses(nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(statuses) == 0 {
+		if err := s.EnsureDefaultWorkflow(); err != nil {
+			return nil, err
+		}
+		return s.workflowRepo.ListStatuses(nil)
+	}
+	return statuses, nil
+}
+
+// GetTransitions retrieves the transition rules of the effective workflow of a category
+func (s *workflowService) GetTransitions(categoryID *uint) ([]models.StatusTransition, error) {
+	statuses, err := s.GetWorkflow(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	return s.workflowRepo.ListTransitions(statusIDs(statuses))
+}
+
+// CreateStatus adds a status to a workflow, copying the default workflow for a category on first use
+func (s *workflowService) CreateStatus(status *models.WorkflowStatus) error {
+	// Business logic validation
+	if err := s.validateStatus(status); err != nil {
+		return err
+	}
+
+	// Clean and format the data
+	s.cleanStatusData(status)
+	status.ID = 0
+
+	if status.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(*status.CategoryID); err != nil {
+			return errors.New("specified category does not exist")
+		}
+		if err := s.customizeWorkflow(*status.CategoryID); err != nil {
+			return err
+		}
+	}
+
+	// Keys are unique within a workflow
+	statuses, err := s.workflowRepo.ListStatuses(status.CategoryID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range statuses {
+		if existing.Key == status.Key {
+			return errors.New("status key already exists")
+		}
+	}
+
+	created := []models.WorkflowStatus{*status}
+	if err := s.workflowRepo.CreateStatuses(created); err != nil {
+		return err
+	}
+	*status = created[0]
+	return nil
+}
+
+// UpdateStatus updates the name, key, position or done flag of a status
+func (s *workflowService) UpdateStatus(status *models.WorkflowStatus) error {
+	if status.ID == 0 {
+		return errors.New("invalid status ID")
+	}
+
+	// Business logic validation
+	if err := s.validateStatus(status); err != nil {
+		return err
+	}
+
+	// Clean and format the data
+	s.cleanStatusData(status)
+
+	existing, err := s.workflowRepo.GetStatusByID(status.ID)
+	if err != nil {
+		return err
+	}
+
+	// A status cannot move to another workflow
+	status.CategoryID = existing.CategoryID
+	status.CreatedAt = existing.CreatedAt
+
+	statuses, err := s.workflowRepo.ListStatuses(existing.CategoryID)
+	if err != nil {
+		return err
+	}
+	for i, other := range statuses {
+		if other.ID == status.ID {
+			statuses[i] = *status
+			continue
+		}
+		if other.Key == status.Key {
+			return errors.New("status key already exists")
+		}
+	}
+	if err := validateWorkflowShape(statuses); err != nil {
+		return err
+	}
+
+	return s.workflowRepo.UpdateStatus(status)
+}
+
+// DeleteStatus deletes a status that is not used by any todo
+func (s *workflowService) DeleteStatus(id uint) error {
+	if id == 0 {
+		return errors.New("invalid status ID")
+	}
+
+	existing, err := s.workflowRepo.GetStatusByID(id)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := s.workflowRepo.ListStatuses(existing.CategoryID)
+	if err != nil {
+		return err
+	}
+	remaining := make([]models.WorkflowStatus, 0, len(statuses))
+	for _, status := range statuses {
+		if status.ID != id {
+			remaining = append(remaining, status)
+		}
+	}
+	if err := validateWorkflowShape(remaining); err != nil {
+		return err
+	}
+
+	return s.workflowRepo.DeleteStatus(id)
+}
+
+// CreateTransition allows todos to move between two statuses of the same workflow
+func (s *workflowService) CreateTransition(transition *models.StatusTransition) error {
+	if transition.FromStatusID == 0 || transition.ToStatusID == 0 {
+		return errors.New("invalid status ID")
+	}
+	if transition.FromStatusID == transition.ToStatusID {
+		return errors.New("invalid transition: from and to status must differ")
+	}
+
+	from, err := s.workflowRepo.GetStatusByID(transition.FromStatusID)
+	if err != nil {
+		return err
+	}
+	to, err := s.workflowRepo.GetStatusByID(transition.ToStatusID)
+	if err != nil {
+		return err
+	}
+	if !sameCategory(from.CategoryID, to.CategoryID) {
+		return errors.New("invalid transition: statuses belong to different workflows")
+	}
+
+	transition.ID = 0
+	return s.workflowRepo.CreateTransition(transition)
+}
+
+// DeleteTransition deletes a transition rule by ID
+func (s *workflowService) DeleteTransition(id uint) error {
+	if id == 0 {
+		return errors.New("invalid transition ID")
+	}
+	return s.workflowRepo.DeleteTransition(id)
+}
+
+// CheckTransition returns an error when a todo may not move between the given statuses
+// A status without outgoing transitions allows moving to any status
+func (s *workflowService) CheckTransition(fromStatusID, toStatusID uint) error {
+	if fromStatusID == toStatusID {
+		return nil
+	}
+
+	transitions, err := s.workflowRepo.ListTransitions([]uint{fromStatusID})
+	if err != nil {
+		return err
+	}
+	if len(transitions) == 0 {
+		return nil
+	}
+	for _, transition := range transitions {
+		if transition.ToStatusID == toStatusID {
+			return nil
+		}
+	}
+
+	from, err := s.workflowRepo.GetStatusByID(fromStatusID)
+	if err != nil {
+		return err
+	}
+	to, err := s.workflowRepo.GetStatusByID(toStatusID)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("status transition from %s to %s is not allowed", from.Key, to.Key)
+}
+
+// customizeWorkflow copies the default workflow into a category that has no statuses yet
+// Transitions are copied as well, so the category starts with the same rules
+func (s *workflowService) customizeWorkflow(categoryID uint) error {
+	existing, err := s.workflowRepo.ListStatuses(&categoryID)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+
+	defaults, err := s.GetWorkflow(nil)
+	if err != nil {
+		return err
+	}
+	transitions, err := s.workflowRepo.ListTransitions(statusIDs(defaults))
+	if err != nil {
+		return err
+	}
+
+	copies := make([]models.WorkflowStatus, len(defaults))
+	for i, status := range defaults {
+		copies[i] = models.WorkflowStatus{
+			CategoryID: &categoryID,
+			Key:        status.Key,
+			Name:       status.Name,
+			Position:   status.Position,
+			IsDone:     status.IsDone,
+		}
+	}
+	if err := s.workflowRepo.CreateStatuses(copies); err != nil {
+		return err
+	}
+
+	copiedIDs := make(map[uint]uint, len(defaults))
+	for i, status := range defaults {
+		copiedIDs[status.ID] = copies[i].ID
+	}
+	for _, transition := range transitions {
+		if err := s.workflowRepo.CreateTransition(&models.StatusTransition{
+			FromStatusID: copiedIDs[transition.FromStatusID],
+			ToStatusID:   copiedIDs[transition.ToStatusID],
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateStatus validates workflow status data
+func (s *workflowService) validateStatus(status *models.WorkflowStatus) error {
+	if status == nil {
+		return errors.New("status cannot be nil")
+	}
+
+	// Validate name
+	if strings.TrimSpace(status.Name) == "" {
+		return errors.New("status name is required")
+	}
+
+	if len(status.Name) > 100 {
+		return errors.New("status name cannot exceed 100 characters")
+	}
+
+	// Validate key
+	key := normalizeStatusKey(status.Key)
+	if key == "" {
+		return errors.New("status key is required")
+	}
+
+	if len(key) > 50 || !statusKeyPattern.MatchString(key) {
+		return errors.New("invalid status key, use lowercase letters, digits and underscores (e.g., in_review)")
+	}
+
+	// Validate position
+	if status.Position < 0 {
+		return errors.New("invalid status position, must not be negative")
+	}
+
+	return nil
+}
+
+// cleanStatusData cleans and formats workflow status data
+func (s *workflowService) cleanStatusData(status *models.WorkflowStatus) {
+	status.Name = strings.TrimSpace(status.Name)
+	status.Key = normalizeStatusKey(status.Key)
+}
+
+// normalizeStatusKey lower-cases a status key and replaces spaces and dashes with underscores
+func normalizeStatusKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+}
+
+// validateWorkflowShape checks that a workflow keeps at least one open and one done status
+func validateWorkflowShape(statuses []models.WorkflowStatus) error {
+	if firstStatus(statuses, false) == nil {
+		return errors.New("invalid workflow: at least one open status is required")
+	}
+	if firstStatus(statuses, true) == nil {
+		return errors.New("invalid workflow: at least one done status is required")
+	}
+	return nil
+}
+
+// firstStatus returns the lowest positioned status with the given done flag
+// Statuses are expected to be ordered by position
+func firstStatus(statuses []models.WorkflowStatus, done bool) *models.WorkflowStatus {
+	for i := range statuses {
+		if statuses[i].IsDone == done {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+// findStatus returns the status with the given ID from a workflow
+func findStatus(statuses []models.WorkflowStatus, id uint) *models.WorkflowStatus {
+	for i := range statuses {
+		if statuses[i].ID == id {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+// findStatusByKey returns the status with the given key from a workflow
+func findStatusByKey(statuses []models.WorkflowStatus, key string) *models.WorkflowStatus {
+	for i := range statuses {
+		if statuses[i].Key == key {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+// statusIDs collects the IDs of the given statuses
+func statusIDs(statuses []models.WorkflowStatus) []uint {
+	ids := make([]uint, len(statuses))
+	for i, status := range statuses {
+		ids[i] = status.ID
+	}
+	return ids
+}
+
+// sameCategory compares two optional category IDs
+func sameCategory(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
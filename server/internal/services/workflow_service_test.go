@@ -0,0 +1,133 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"todo-backend/internal/models"
+)
+
+// newTestWorkflowTodoService returns a todo service over the default workflow with the given transitions
+func newTestWorkflowTodoService(transitions ...models.StatusTransition) (*todoService, []models.WorkflowStatus) {
+	workflowRepo := newFakeWorkflowRepo(transitions...)
+	workflowService := NewWorkflowService(workflowRepo, nil)
+	return NewTodoService(newFakeTodoRepo(), nil, workflowService).(*todoService), workflowRepo.statuses
+}
+
+// edge returns a transition between two statuses of the default workflow
+func edge(from, to uint) models.StatusTransition {
+	return models.StatusTransition{FromStatusID: from, ToStatusID: to}
+}
+
+func TestTodoServiceCheckTransition(t *testing.T) {
+	// Statuses of the default workflow: 1 todo, 2 in_progress, 3 in_review, 4 done
+	tests := []struct {
+		name        string
+		transitions []models.StatusTransition
+		currentID   *uint
+		targetID    uint
+		wantErr     string
+	}{
+		{name: "no current status", transitions: []models.StatusTransition{edge(1, 2)}, targetID: 4},
+		{name: "from another workflow", transitions: []models.StatusTransition{edge(1, 2)}, currentID: uintPtr(99), targetID: 4},
+		{name: "same status", transitions: []models.StatusTransition{edge(1, 2)}, currentID: uintPtr(1), targetID: 1},
+		{name: "no outgoing transitions", transitions: []models.StatusTransition{edge(2, 3)}, currentID: uintPtr(1), targetID: 4},
+		{name: "allowed", transitions: []models.StatusTransition{edge(1, 2), edge(2, 3)}, currentID: uintPtr(1), targetID: 2},
+		{
+			name:        "rejected",
+			transitions: []models.StatusTransition{edge(1, 2), edge(2, 3)},
+			currentID:   uintPtr(1),
+			targetID:    4,
+			wantErr:     "status transition from todo to done is not allowed",
+		},
+		{
+			name:        "rejected backwards",
+			transitions: []models.StatusTransition{edge(1, 2), edge(2, 3)},
+			currentID:   uintPtr(2),
+			targetID:    1,
+			wantErr:     "status transition from in_progress to todo is not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, workflow := newTestWorkflowTodoService(tt.transitions...)
+			err := s.checkTransition(workflow, tt.currentID, findStatus(workflow, tt.targetID))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkTransition: unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("checkTransition error = %v; want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTodoServicePickStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		transitions []models.StatusTransition
+		statuses    []uint // keep only these statuses of the default workflow, all when empty
+		currentID   *uint
+		done        bool
+		wantID      uint
+		wantErr     string
+	}{
+		{name: "complete without status", done: true, wantID: 4},
+		{name: "reopen without status", wantID: 1},
+		{name: "already done", currentID: uintPtr(4), done: true, wantID: 4},
+		{name: "already open", currentID: uintPtr(3), wantID: 3},
+		{name: "complete from another workflow", transitions: []models.StatusTransition{edge(1, 2)}, currentID: uintPtr(99), done: true, wantID: 4},
+		{name: "complete allowed", transitions: []models.StatusTransition{edge(3, 4)}, currentID: uintPtr(3), done: true, wantID: 4},
+		{
+			name:        "reopen picks first reachable open status",
+			transitions: []models.StatusTransition{edge(4, 2), edge(4, 3)},
+			currentID:   uintPtr(4),
+			wantID:      2,
+		},
+		{
+			name:        "complete rejected",
+			transitions: []models.StatusTransition{edge(2, 3), edge(3, 4)},
+			currentID:   uintPtr(2),
+			done:        true,
+			wantErr:     "status transition from in_progress to done is not allowed",
+		},
+		{
+			name:        "reopen rejected reports first open status",
+			transitions: []models.StatusTransition{edge(4, 4)},
+			currentID:   uintPtr(4),
+			wantErr:     "status transition from done to todo is not allowed",
+		},
+		{name: "no done status", statuses: []uint{1, 2}, done: true, wantErr: "workflow has no matching status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, workflow := newTestWorkflowTodoService(tt.transitions...)
+			if len(tt.statuses) > 0 {
+				var kept []models.WorkflowStatus
+				for _, id := range tt.statuses {
+					kept = append(kept, *findStatus(workflow, id))
+				}
+				workflow = kept
+			}
+
+			status, err := s.pickStatus(workflow, tt.currentID, tt.done)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("pickStatus error = %v; want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pickStatus: unexpected error: %v", err)
+			}
+			if status.ID != tt.wantID {
+				t.Errorf("pickStatus = %s (%d); want %d", status.Key, status.ID, tt.wantID)
+			}
+		})
+	}
+}
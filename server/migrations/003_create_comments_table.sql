@@ -0,0 +1,47 @@
+-- Migration: Create comments tables
+-- This migration creates the comments table for discussion threads on todos
+-- Includes mention tracking and the edit history of each comment
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS comments (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    author VARCHAR(100) NOT NULL,
+    body TEXT NOT NULL,
+    edited_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    id SERIAL PRIMARY KEY,
+    comment_id INTEGER NOT NULL REFERENCES comments(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    username VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (comment_id, username)
+);
+
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    id SERIAL PRIMARY KEY,
+    comment_id INTEGER NOT NULL REFERENCES comments(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Index for soft deletes (GORM requirement)
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments(deleted_at);
+
+-- Index for listing and counting comments of a todo
+CREATE INDEX IF NOT EXISTS idx_comments_todo_id ON comments(todo_id, created_at) WHERE deleted_at IS NULL;
+
+-- Index for finding comments that mention a user
+CREATE INDEX IF NOT EXISTS idx_comment_mentions_username ON comment_mentions(username);
+
+-- Index for loading the edit history of a comment
+CREATE INDEX IF NOT EXISTS idx_comment_revisions_comment_id ON comment_revisions(comment_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS comment_revisions;
+DROP TABLE IF EXISTS comment_mentions;
+DROP TABLE IF EXISTS comments;
@@ -0,0 +1,28 @@
+-- Migration: Create attachments table
+-- This migration creates the attachments table for files attached to todos
+-- Blobs are content-addressed, so several attachments may share one storage key
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS attachments (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Index for listing attachments of a todo
+CREATE INDEX IF NOT EXISTS idx_attachments_todo_id ON attachments(todo_id);
+
+-- Index for checksum deduplication
+CREATE INDEX IF NOT EXISTS idx_attachments_checksum ON attachments(checksum);
+
+-- Index for counting references to a stored blob
+CREATE INDEX IF NOT EXISTS idx_attachments_storage_key ON attachments(storage_key);
+
+-- +migrate Down
+DROP TABLE IF EXISTS attachments;
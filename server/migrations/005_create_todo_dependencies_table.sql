@@ -0,0 +1,21 @@
+-- Migration: Create todo dependencies table
+-- This migration creates the "blocked by" edges between todos
+-- A todo cannot be completed while any of its blockers is still open
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS todo_dependencies (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    blocked_by_id INTEGER NOT NULL REFERENCES todos(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (todo_id <> blocked_by_id)
+);
+
+-- Unique index so an edge can only be recorded once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_dependencies_edge ON todo_dependencies(todo_id, blocked_by_id);
+
+-- Index for walking the graph in the reverse direction
+CREATE INDEX IF NOT EXISTS idx_todo_dependencies_blocked_by_id ON todo_dependencies(blocked_by_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS todo_dependencies;
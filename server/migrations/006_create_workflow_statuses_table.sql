@@ -0,0 +1,56 @@
+-- Migration: Create workflow statuses and transitions tables
+-- This migration replaces the binary completed flag with configurable statuses
+-- Statuses without a category form the default workflow, a category may define its own
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS workflow_statuses (
+    id SERIAL PRIMARY KEY,
+    category_id INTEGER REFERENCES categories(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    key VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    is_done BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Unique index so a key can only be used once per workflow
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workflow_statuses_category_key ON workflow_statuses(category_id, key);
+
+-- Unique index for the default workflow, NULL category IDs are not compared by the index above
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workflow_statuses_default_key ON workflow_statuses(key) WHERE category_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS status_transitions (
+    id SERIAL PRIMARY KEY,
+    from_status_id INTEGER NOT NULL REFERENCES workflow_statuses(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    to_status_id INTEGER NOT NULL REFERENCES workflow_statuses(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (from_status_id <> to_status_id)
+);
+
+-- Unique index so a transition can only be recorded once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_status_transitions_edge ON status_transitions(from_status_id, to_status_id);
+
+-- Seed the default workflow
+INSERT INTO workflow_statuses (category_id, key, name, position, is_done) VALUES
+    (NULL, 'todo', 'To do', 0, FALSE),
+    (NULL, 'in_progress', 'In progress', 1, FALSE),
+    (NULL, 'in_review', 'In review', 2, FALSE),
+    (NULL, 'done', 'Done', 3, TRUE)
+ON CONFLICT DO NOTHING;
+
+-- Link todos to their status
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS status_id INTEGER REFERENCES workflow_statuses(id) ON UPDATE CASCADE ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_todos_status_id ON todos(status_id);
+
+-- Map existing todos by their completion flag
+UPDATE todos SET status_id = (
+    SELECT id FROM workflow_statuses
+    WHERE category_id IS NULL AND key = CASE WHEN todos.completed THEN 'done' ELSE 'todo' END
+) WHERE status_id IS NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_todos_status_id;
+ALTER TABLE todos DROP COLUMN IF EXISTS status_id;
+DROP TABLE IF EXISTS status_transitions;
+DROP TABLE IF EXISTS workflow_statuses;
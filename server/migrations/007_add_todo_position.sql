@@ -0,0 +1,13 @@
+-- Migration: Add manual order position to todos
+-- Positions are lexicographic rank keys (digits and lowercase letters) compared byte-wise,
+-- so moving a todo only rewrites its own key. Existing todos get a position on server startup
+
+-- +migrate Up
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS position VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Index for ordering by position, matching the COLLATE "C" used by queries
+CREATE INDEX IF NOT EXISTS idx_todos_position ON todos ((position COLLATE "C"));
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_todos_position;
+ALTER TABLE todos DROP COLUMN IF EXISTS position;
@@ -0,0 +1,18 @@
+-- Migration: Add archive state to todos
+-- Completed todos can be archived to hide them from the default todo list without deleting them
+-- completed_at records when a todo was completed, for archiving after a delay
+
+-- +migrate Up
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+
+-- Best guess for todos completed before this migration
+UPDATE todos SET completed_at = updated_at WHERE completed = TRUE AND completed_at IS NULL;
+
+-- Index for hiding archived todos
+CREATE INDEX IF NOT EXISTS idx_todos_archived_at ON todos(archived_at);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_todos_archived_at;
+ALTER TABLE todos DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE todos DROP COLUMN IF EXISTS completed_at;
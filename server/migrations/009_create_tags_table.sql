@@ -0,0 +1,25 @@
+-- Migration: Create tags and todo_tags tables
+-- Tags are free-form labels shared by todos, attached through bulk operations
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Unique index so a tag name can only be used once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags(name);
+
+CREATE TABLE IF NOT EXISTS todo_tags (
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+-- Index for finding the todos of a tag
+CREATE INDEX IF NOT EXISTS idx_todo_tags_tag_id ON todo_tags(tag_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS todo_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,11 @@
+-- Migration: Add optimistic locking versions to todos and categories
+-- The version is returned as the ETag and increases with every change; updates sent with
+-- If-Match only succeed while the stored version still matches
+
+-- +migrate Up
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+-- +migrate Down
+ALTER TABLE categories DROP COLUMN IF EXISTS version;
+ALTER TABLE todos DROP COLUMN IF EXISTS version;
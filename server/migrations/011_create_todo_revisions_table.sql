@@ -0,0 +1,20 @@
+-- Migration: Create todo_revisions table
+-- Every change of a todo stores a snapshot of its state at the new version together with
+-- the fields that changed, forming the change history shown by /api/todos/:id/history
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS todo_revisions (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    snapshot JSONB NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Unique index so each version of a todo has one revision, also used for loading the history
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todo_revisions_todo_version ON todo_revisions(todo_id, version);
+
+-- +migrate Down
+DROP TABLE IF EXISTS todo_revisions;
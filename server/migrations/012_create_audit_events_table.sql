@@ -0,0 +1,38 @@
+-- Migration: Create audit_events table
+-- Every successful mutation of a todo or category records who made it, from where, and the
+-- entity state before and after; the log is append-only and served by /api/admin/audit
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(100) NOT NULL,
+    ip VARCHAR(45),
+    request_id VARCHAR(64),
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    status INTEGER NOT NULL,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id INTEGER,
+    action VARCHAR(50) NOT NULL,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Index for filtering by actor
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor);
+
+-- Index for the history of one entity
+CREATE INDEX IF NOT EXISTS idx_audit_events_entity ON audit_events(entity_type, entity_id);
+
+-- Index for filtering by action
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events(action);
+
+-- Index for finding the events of a request
+CREATE INDEX IF NOT EXISTS idx_audit_events_request_id ON audit_events(request_id);
+
+-- Index for time range queries
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS audit_events;
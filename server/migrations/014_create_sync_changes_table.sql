@@ -0,0 +1,14 @@
+-- Migration: Create sync_changes table
+-- Every change of a todo or category, including soft deletes, appends a row; the row ID is the
+-- change sequence behind the tokens of /api/sync, so clients pull only what changed since their token
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS sync_changes (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS sync_changes;
@@ -0,0 +1,22 @@
+-- Migration: Create calendar feeds table
+-- A feed publishes todos with a due date as an iCalendar file under a secret token,
+-- optionally limited to some categories; only a SHA-256 hash of the token is stored
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    category_ids JSONB NOT NULL DEFAULT '[]',
+    time_zone VARCHAR(64),
+    include_events BOOLEAN NOT NULL DEFAULT FALSE,
+    hide_completed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Unique index for looking feeds up by the hash of their token
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_feeds_token_hash ON calendar_feeds(token_hash);
+
+-- +migrate Down
+DROP TABLE IF EXISTS calendar_feeds;
@@ -0,0 +1,21 @@
+-- Migration: Create caldav_resources table
+-- CalDAV clients pick the resource name and UID of the todos they create; they are kept here so
+-- the todo is served under the same name. Other todos are served as todo-<id>.ics
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS caldav_resources (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    uid VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Unique index for one resource per todo
+CREATE UNIQUE INDEX IF NOT EXISTS idx_caldav_resources_todo_id ON caldav_resources(todo_id);
+
+-- Unique index for looking todos up by resource name
+CREATE UNIQUE INDEX IF NOT EXISTS idx_caldav_resources_name ON caldav_resources(name);
+
+-- +migrate Down
+DROP TABLE IF EXISTS caldav_resources;
@@ -0,0 +1,20 @@
+-- Migration: Create saved filters table
+-- A saved filter stores the todo list filters and sort order of a smart list under a name,
+-- the filters are kept as the JSON object of the GET /api/todos query parameters
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS saved_filters (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}',
+    sort_by VARCHAR(20),
+    sort_order VARCHAR(4),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Unique index so smart list names can't be told apart only by case
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_filters_name ON saved_filters(LOWER(name));
+
+-- +migrate Down
+DROP TABLE IF EXISTS saved_filters;
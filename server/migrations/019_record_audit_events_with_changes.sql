@@ -0,0 +1,9 @@
+-- Migration: Record audit events in the transaction of each change
+-- Events are written by the repositories rather than after the response, so changes made by
+-- background jobs, CalDAV clients and imports are recorded too; the response status is not known then
+
+-- +migrate Up
+ALTER TABLE audit_events DROP COLUMN IF EXISTS status;
+
+-- +migrate Down
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS status INTEGER NOT NULL DEFAULT 0;
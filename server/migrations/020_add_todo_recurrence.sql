@@ -0,0 +1,9 @@
+-- Migration: Add a recurrence rule to todos
+-- recurrence is an iCalendar RRULE value such as FREQ=WEEKLY;BYDAY=MO, empty for todos that don't repeat
+-- It is kept so quick-add, CalDAV clients and imports don't lose it, and calendars are given it as RRULE
+
+-- +migrate Up
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS recurrence VARCHAR(255) NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE todos DROP COLUMN IF EXISTS recurrence;
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Listen subscribes to a Postgres NOTIFY channel and calls handle with the payload of every notification
+// It holds one connection of the pool until ctx is cancelled or the connection fails, and returns the error;
+// notifications sent while nobody listens are lost, so callers reconnect and resynchronize
+func (d *Database) Listen(ctx context.Context, channel string, handle func(payload string)) error {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("LISTEN requires the pgx driver, got %T", driverConn)
+		}
+		pgConn := stdConn.Conn()
+
+		if _, err := pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return err
+		}
+
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				// The connection is still subscribed, it must not go back to the pool
+				return errors.Join(err, driver.ErrBadConn)
+			}
+			handle(notification.Payload)
+		}
+	})
+}
@@ -0,0 +1,195 @@
+// Package ical reads and writes iCalendar (RFC 5545) data.
+//
+// Content lines are written with CRLF line endings and folded at 75 octets,
+// text values are escaped, and times are formatted in UTC, as floating local
+// times for a TZID, or as dates. VTimezone describes a time zone by its offset
+// transitions so calendars can resolve TZID references without a zone database.
+// Parse reads a component tree, unfolding lines and keeping property parameters.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Time layouts of DATE and DATE-TIME values
+const (
+	DateLayout     = "20060102"
+	DateTimeLayout = "20060102T150405"
+	UTCLayout      = "20060102T150405Z"
+)
+
+// maxLineLength is the longest content line in octets, without the line break
+const maxLineLength = 75
+
+// Writer writes iCalendar content lines
+// Errors are kept and returned by Flush, so callers can write without checking each line
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// NewWriter creates a writer of iCalendar content lines to w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Begin starts a component such as VCALENDAR or VTODO
+func (w *Writer) Begin(component string) {
+	w.Line("BEGIN", component)
+}
+
+// End ends a component
+func (w *Writer) End(component string) {
+	w.Line("END", component)
+}
+
+// Line writes a content line with a raw value, folding it when it is too long
+// The name may carry parameters, e.g. DUE;VALUE=DATE
+func (w *Writer) Line(name, value string) {
+	if w.err != nil {
+		return
+	}
+	line := name + ":" + value
+	limit := maxLineLength
+	for len(line) > limit {
+		// Fold on a rune boundary, continuation lines start with a space that counts towards their length
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, w.err = w.w.WriteString(line[:cut] + "\r\n "); w.err != nil {
+			return
+		}
+		line = line[cut:]
+		limit = maxLineLength - 1
+	}
+	_, w.err = w.w.WriteString(line + "\r\n")
+}
+
+// Text writes a content line with a text value, escaping it
+func (w *Writer) Text(name, value string) {
+	w.Line(name, Escape(value))
+}
+
+// Texts writes a content line with a list of text values, e.g. CATEGORIES
+func (w *Writer) Texts(name string, values []string) {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = Escape(value)
+	}
+	w.Line(name, strings.Join(escaped, ","))
+}
+
+// Time writes a DATE-TIME content line in UTC, or in the location when it is not UTC
+// Times in another location get a TZID parameter naming it, which needs a matching VTIMEZONE
+func (w *Writer) Time(name string, t time.Time, loc *time.Location) {
+	if loc == nil || loc == time.UTC {
+		w.Line(name, t.UTC().Format(UTCLayout))
+		return
+	}
+	w.Line(name+";TZID="+loc.String(), t.In(loc).Format(DateTimeLayout))
+}
+
+// Date writes a DATE content line
+func (w *Writer) Date(name string, t time.Time) {
+	w.Line(name+";VALUE=DATE", t.Format(DateLayout))
+}
+
+// Flush writes buffered lines and returns the first error that occurred
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// textEscaper escapes the characters of a TEXT value
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// Escape escapes a TEXT value
+func Escape(text string) string {
+	return textEscaper.Replace(text)
+}
+
+// VTimezone writes a VTIMEZONE component for the location, with its offset transitions
+// between the start of the from year and the end of the to year
+// Locations without transitions in that range get a single STANDARD observance
+func (w *Writer) VTimezone(loc *time.Location, from, to int) {
+	w.Begin("VTIMEZONE")
+	w.Line("TZID", loc.String())
+
+	start := time.Date(from, time.January, 1, 0, 0, 0, 0, loc)
+	end := time.Date(to+1, time.January, 1, 0, 0, 0, 0, loc)
+	_, offset := start.Zone()
+	written := false
+	for day := start; day.Before(end); day = day.Add(24 * time.Hour) {
+		next := day.Add(24 * time.Hour)
+		if _, nextOffset := next.Zone(); nextOffset == offset {
+			continue
+		}
+
+		// Narrow the change down to the second it happens
+		lo, hi := day, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, midOffset := mid.Zone(); midOffset == offset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		name, nextOffset := hi.Zone()
+		w.observance(hi, name, offset, nextOffset)
+		offset = nextOffset
+		written = true
+	}
+	if !written {
+		name, _ := start.Zone()
+		w.observance(start, name, offset, offset)
+	}
+
+	w.End("VTIMEZONE")
+}
+
+// observance writes a STANDARD or DAYLIGHT observance starting at the given time
+// An observance whose offset grows is taken for daylight saving time
+func (w *Writer) observance(at time.Time, name string, from, to int) {
+	kind := "STANDARD"
+	if to > from {
+		kind = "DAYLIGHT"
+	}
+	w.Begin(kind)
+	// DTSTART of an observance is in the local time in effect before it
+	w.Line("DTSTART", at.In(time.FixedZone("", from)).Format(DateTimeLayout))
+	w.Line("TZOFFSETFROM", formatOffset(from))
+	w.Line("TZOFFSETTO", formatOffset(to))
+	if name != "" && name[0] != '+' && name[0] != '-' {
+		w.Text("TZNAME", name)
+	}
+	w.End(kind)
+}
+
+// formatOffset formats a UTC offset in seconds as +HHMM, adding seconds when needed
+func formatOffset(seconds int) string {
+	sign := byte('+')
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	out := []byte{sign}
+	out = appendTwoDigits(out, seconds/3600)
+	out = appendTwoDigits(out, seconds%3600/60)
+	if seconds%60 != 0 {
+		out = appendTwoDigits(out, seconds%60)
+	}
+	return string(out)
+}
+
+// appendTwoDigits appends a number below 100 as two digits
+func appendTwoDigits(b []byte, n int) []byte {
+	return append(b, byte('0'+n/10), byte('0'+n%10))
+}
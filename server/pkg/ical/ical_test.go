@@ -0,0 +1,240 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// write returns what fn writes through a Writer
+func write(t *testing.T, fn func(w *Writer)) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	fn(w)
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	return buf.String()
+}
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s is not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "plain", want: "plain"},
+		{text: `a\b`, want: `a\\b`},
+		{text: "a;b,c", want: `a\;b\,c`},
+		{text: "line\nbreak", want: `line\nbreak`},
+		{text: "crlf\r\nbreak", want: `crlf\nbreak`},
+		{text: "cr\rbreak", want: `cr\nbreak`},
+		{text: "colon: kept", want: "colon: kept"},
+	}
+	for _, tt := range tests {
+		if got := Escape(tt.text); got != tt.want {
+			t.Errorf("Escape(%q) = %q; want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestWriterLineFolding(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "short", value: "Call Ann"},
+		{name: "exactly the limit", value: strings.Repeat("a", maxLineLength-len("SUMMARY:"))},
+		{name: "one over the limit", value: strings.Repeat("a", maxLineLength-len("SUMMARY:")+1)},
+		{name: "several folds", value: strings.Repeat("0123456789", 30)},
+		{name: "multi-byte runes", value: strings.Repeat("äöü€😀", 40)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := write(t, func(w *Writer) { w.Line("SUMMARY", tt.value) })
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output %q does not end with CRLF", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, line := range lines {
+				if len(line) > maxLineLength {
+					t.Errorf("line %d has %d octets: %q", i, len(line), line)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a rune: %q", i, line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+			}
+			if want := len("SUMMARY:"+tt.value) > maxLineLength; (len(lines) > 1) != want {
+				t.Errorf("folded into %d lines; want folding %v", len(lines), want)
+			}
+
+			// Unfolding gives the original line back
+			if got := unfold([]byte(out)); len(got) != 1 || got[0] != "SUMMARY:"+tt.value {
+				t.Errorf("unfolded = %q; want the original line", got)
+			}
+		})
+	}
+}
+
+func TestWriterValues(t *testing.T) {
+	berlin := loadLocation(t, "Europe/Berlin")
+	at := time.Date(2024, 7, 1, 8, 30, 0, 0, time.UTC)
+
+	out := write(t, func(w *Writer) {
+		w.Begin("VTODO")
+		w.Text("SUMMARY", "Buy milk, eggs; bread")
+		w.Texts("CATEGORIES", []string{"home", "a,b"})
+		w.Time("DTSTAMP", at, nil)
+		w.Time("DUE", at.In(berlin), time.UTC)
+		w.Time("DTSTART", at, berlin)
+		w.Date("DUE", at)
+		w.End("VTODO")
+	})
+	want := "BEGIN:VTODO\r\n" +
+		"SUMMARY:Buy milk\\, eggs\\; bread\r\n" +
+		"CATEGORIES:home,a\\,b\r\n" +
+		"DTSTAMP:20240701T083000Z\r\n" +
+		"DUE:20240701T083000Z\r\n" +
+		"DTSTART;TZID=Europe/Berlin:20240701T103000\r\n" +
+		"DUE;VALUE=DATE:20240701\r\n" +
+		"END:VTODO\r\n"
+	if out != want {
+		t.Errorf("output =\n%q\nwant\n%q", out, want)
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errWrite }
+
+var errWrite = bytes.ErrTooLarge
+
+func TestWriterKeepsFirstError(t *testing.T) {
+	w := NewWriter(failingWriter{})
+	// Enough lines to overflow the buffer, so a write fails before Flush
+	for i := 0; i < 200; i++ {
+		w.Line("X-LINE", strings.Repeat("x", 60))
+	}
+	if err := w.Flush(); err != errWrite {
+		t.Errorf("Flush error = %v; want %v", err, errWrite)
+	}
+}
+
+func TestFormatOffset(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{seconds: 0, want: "+0000"},
+		{seconds: 3600, want: "+0100"},
+		{seconds: -5 * 3600, want: "-0500"},
+		{seconds: 5*3600 + 45*60, want: "+0545"},
+		{seconds: -(9*3600 + 30*60), want: "-0930"},
+		{seconds: 1172, want: "+001932"},
+	}
+	for _, tt := range tests {
+		if got := formatOffset(tt.seconds); got != tt.want {
+			t.Errorf("formatOffset(%d) = %q; want %q", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestVTimezone(t *testing.T) {
+	berlin := loadLocation(t, "Europe/Berlin")
+	out := write(t, func(w *Writer) { w.VTimezone(berlin, 2024, 2024) })
+	want := "BEGIN:VTIMEZONE\r\n" +
+		"TZID:Europe/Berlin\r\n" +
+		"BEGIN:DAYLIGHT\r\n" +
+		"DTSTART:20240331T020000\r\n" +
+		"TZOFFSETFROM:+0100\r\n" +
+		"TZOFFSETTO:+0200\r\n" +
+		"TZNAME:CEST\r\n" +
+		"END:DAYLIGHT\r\n" +
+		"BEGIN:STANDARD\r\n" +
+		"DTSTART:20241027T030000\r\n" +
+		"TZOFFSETFROM:+0200\r\n" +
+		"TZOFFSETTO:+0100\r\n" +
+		"TZNAME:CET\r\n" +
+		"END:STANDARD\r\n" +
+		"END:VTIMEZONE\r\n"
+	if out != want {
+		t.Errorf("VTimezone(Europe/Berlin) =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestVTimezoneWithoutTransitions(t *testing.T) {
+	tests := []struct {
+		loc  *time.Location
+		want string
+	}{
+		{
+			loc: time.FixedZone("EST", -5*3600),
+			want: "BEGIN:VTIMEZONE\r\nTZID:EST\r\nBEGIN:STANDARD\r\nDTSTART:20240101T000000\r\n" +
+				"TZOFFSETFROM:-0500\r\nTZOFFSETTO:-0500\r\nTZNAME:EST\r\nEND:STANDARD\r\nEND:VTIMEZONE\r\n",
+		},
+		{
+			// Numeric zone abbreviations are not names
+			loc: time.FixedZone("+0530", 5*3600+30*60),
+			want: "BEGIN:VTIMEZONE\r\nTZID:+0530\r\nBEGIN:STANDARD\r\nDTSTART:20240101T000000\r\n" +
+				"TZOFFSETFROM:+0530\r\nTZOFFSETTO:+0530\r\nEND:STANDARD\r\nEND:VTIMEZONE\r\n",
+		},
+	}
+	for _, tt := range tests {
+		if out := write(t, func(w *Writer) { w.VTimezone(tt.loc, 2024, 2025) }); out != tt.want {
+			t.Errorf("VTimezone(%s) =\n%s\nwant\n%s", tt.loc, out, tt.want)
+		}
+	}
+}
+
+func TestWriteParseRoundTrip(t *testing.T) {
+	summary := "Review: \"Q3\" numbers, notes; and\nfollow-ups \\ äöü " + strings.Repeat("long ", 30)
+	due := time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)
+
+	out := write(t, func(w *Writer) {
+		w.Begin("VCALENDAR")
+		w.Line("VERSION", "2.0")
+		w.Begin("VTODO")
+		w.Text("SUMMARY", summary)
+		w.Texts("CATEGORIES", []string{"work", "q3, review"})
+		w.Time("DUE", due, nil)
+		w.Date("DTSTART", due)
+		w.End("VTODO")
+		w.End("VCALENDAR")
+	})
+
+	calendar, err := Parse([]byte(out))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	todo := calendar.Component("VTODO")
+	if todo == nil {
+		t.Fatal("VTODO is missing")
+	}
+	if got := todo.Text("SUMMARY"); got != summary {
+		t.Errorf("SUMMARY = %q; want %q", got, summary)
+	}
+	if got := todo.Property("CATEGORIES").Value; got != `work,q3\, review` {
+		t.Errorf("CATEGORIES = %q", got)
+	}
+	if got, isDate, err := todo.Property("DUE").Time(); err != nil || isDate || !got.Equal(due) {
+		t.Errorf("DUE = %v, %v, %v; want %v", got, isDate, err, due)
+	}
+	if got, isDate, err := todo.Property("DTSTART").Time(); err != nil || !isDate || !got.Equal(time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("DTSTART = %v, %v, %v; want the date", got, isDate, err)
+	}
+}
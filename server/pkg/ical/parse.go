@@ -0,0 +1,227 @@
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Component is a parsed iCalendar component such as VCALENDAR or VTODO
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []*Component
+}
+
+// Property is a content line of a component
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Parse reads an iCalendar object, returning its outermost component
+// Folded lines are joined; property and parameter names are upper-cased
+func Parse(data []byte) (*Component, error) {
+	var root *Component
+	var stack []*Component
+
+	for _, line := range unfold(data) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			component := &Component{Name: strings.ToUpper(prop.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, component)
+			} else if root != nil {
+				return nil, errors.New("invalid iCalendar data: more than one top-level component")
+			} else {
+				root = component
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, errors.New("invalid iCalendar data: unexpected END:" + prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, errors.New("invalid iCalendar data: property outside of a component")
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, prop)
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("invalid iCalendar data: no component")
+	}
+	if len(stack) > 0 {
+		return nil, errors.New("invalid iCalendar data: missing END:" + stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+// Component returns the first subcomponent with the given name, or nil
+func (c *Component) Component(name string) *Component {
+	for _, component := range c.Components {
+		if component.Name == name {
+			return component
+		}
+	}
+	return nil
+}
+
+// Property returns the first property with the given name, or nil
+func (c *Component) Property(name string) *Property {
+	for i := range c.Properties {
+		if c.Properties[i].Name == name {
+			return &c.Properties[i]
+		}
+	}
+	return nil
+}
+
+// Text returns the unescaped value of the first property with the given name, empty when it is missing
+func (c *Component) Text(name string) string {
+	prop := c.Property(name)
+	if prop == nil {
+		return ""
+	}
+	return Unescape(prop.Value)
+}
+
+// Time parses a DATE or DATE-TIME value, reporting whether it is a date
+// UTC times end in Z, times with a TZID are read in that time zone, and floating times are taken as UTC
+func (p *Property) Time() (time.Time, bool, error) {
+	if p.Params["VALUE"] == "DATE" || len(p.Value) == len(DateLayout) {
+		t, err := time.Parse(DateLayout, p.Value)
+		if err != nil {
+			return time.Time{}, false, errors.New("invalid date in " + p.Name + ": " + p.Value)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(p.Value, "Z") {
+		t, err := time.Parse(UTCLayout, p.Value)
+		if err != nil {
+			return time.Time{}, false, errors.New("invalid time in " + p.Name + ": " + p.Value)
+		}
+		return t, false, nil
+	}
+
+	loc := time.UTC
+	if tzid := p.Params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = time.LoadLocation(strings.TrimPrefix(tzid, "/")); err != nil {
+			return time.Time{}, false, errors.New("invalid time zone in " + p.Name + ": " + tzid)
+		}
+	}
+	t, err := time.ParseInLocation(DateTimeLayout, p.Value, loc)
+	if err != nil {
+		return time.Time{}, false, errors.New("invalid time in " + p.Name + ": " + p.Value)
+	}
+	return t.UTC(), false, nil
+}
+
+// Unescape reverses Escape for a TEXT value
+func Unescape(text string) string {
+	if !strings.Contains(text, `\`) {
+		return text
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i == len(text)-1 {
+			b.WriteByte(text[i])
+			continue
+		}
+		i++
+		switch text[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(text[i])
+		}
+	}
+	return b.String()
+}
+
+// unfold splits iCalendar data into content lines, joining folded continuation lines
+func unfold(data []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseLine parses a content line of the form NAME;PARAM=VALUE:VALUE
+// Parameter values may be quoted, so colons and semicolons inside quotes are kept
+func parseLine(line string) (Property, error) {
+	prop := Property{Params: map[string]string{}}
+
+	// Find the colon ending the name and parameters, outside of quotes
+	quoted := false
+	end := -1
+	for i := 0; i < len(line) && end < 0; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				end = i
+			}
+		}
+	}
+	if end < 0 {
+		return prop, errors.New("invalid iCalendar data: malformed line " + line)
+	}
+	prop.Value = line[end+1:]
+
+	parts := splitParams(line[:end])
+	prop.Name = strings.ToUpper(parts[0])
+	if prop.Name == "" {
+		return prop, errors.New("invalid iCalendar data: malformed line " + line)
+	}
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		prop.Params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return prop, nil
+}
+
+// splitParams splits a name and its parameters on semicolons outside of quotes
+func splitParams(s string) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
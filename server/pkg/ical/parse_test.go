@@ -0,0 +1,193 @@
+package ical
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	data := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Test//EN",
+		"",
+		"BEGIN:VTODO",
+		"UID:1@example.com",
+		"SUMMARY:A long summary that was folded",
+		"  across two lines",
+		"DESCRIPTION:tab\tfolded",
+		"\t line",
+		"dtstart;tzid=\"Europe/Berlin\";x-note=\"a:b;c\":20240701T103000",
+		"CATEGORIES:work,home",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"END:VALARM",
+		"END:VTODO",
+		"begin:vtodo",
+		"UID:2@example.com",
+		"end:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n") + "\r\n"
+
+	calendar, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if calendar.Name != "VCALENDAR" || len(calendar.Components) != 2 {
+		t.Fatalf("calendar = %s with %d components; want VCALENDAR with 2", calendar.Name, len(calendar.Components))
+	}
+	if got := calendar.Text("VERSION"); got != "2.0" {
+		t.Errorf("VERSION = %q; want 2.0", got)
+	}
+
+	todo := calendar.Component("VTODO")
+	if got := todo.Text("UID"); got != "1@example.com" {
+		t.Errorf("first VTODO UID = %q; want the first one", got)
+	}
+	if got := todo.Text("SUMMARY"); got != "A long summary that was folded across two lines" {
+		t.Errorf("SUMMARY = %q", got)
+	}
+	if got := todo.Text("DESCRIPTION"); got != "tab\tfolded line" {
+		t.Errorf("DESCRIPTION = %q", got)
+	}
+	start := todo.Property("DTSTART")
+	if start == nil {
+		t.Fatal("DTSTART is missing, names are not upper-cased")
+	}
+	if want := map[string]string{"TZID": "Europe/Berlin", "X-NOTE": "a:b;c"}; !reflect.DeepEqual(start.Params, want) {
+		t.Errorf("DTSTART params = %v; want %v", start.Params, want)
+	}
+	if start.Value != "20240701T103000" {
+		t.Errorf("DTSTART value = %q", start.Value)
+	}
+	if alarm := todo.Component("VALARM"); alarm == nil || alarm.Text("ACTION") != "DISPLAY" {
+		t.Errorf("VALARM = %+v; want a nested component", alarm)
+	}
+	if calendar.Components[1].Name != "VTODO" || calendar.Components[1].Text("UID") != "2@example.com" {
+		t.Errorf("second component = %+v", calendar.Components[1])
+	}
+
+	if todo.Property("LOCATION") != nil || todo.Text("LOCATION") != "" || todo.Component("VEVENT") != nil {
+		t.Error("missing properties and components are not nil or empty")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: ""},
+		{name: "blank lines", data: "\r\n\r\n"},
+		{name: "property outside a component", data: "VERSION:2.0\r\nBEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"},
+		{name: "missing END", data: "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VCALENDAR\r\n"},
+		{name: "unclosed", data: "BEGIN:VCALENDAR\r\n"},
+		{name: "END without BEGIN", data: "END:VCALENDAR\r\n"},
+		{name: "two top-level components", data: "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\nBEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"},
+		{name: "line without colon", data: "BEGIN:VCALENDAR\r\nSUMMARY\r\nEND:VCALENDAR\r\n"},
+		{name: "colon only inside quotes", data: "BEGIN:VCALENDAR\r\nX;P=\"a:b\"\r\nEND:VCALENDAR\r\n"},
+		{name: "empty name", data: "BEGIN:VCALENDAR\r\n:value\r\nEND:VCALENDAR\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if component, err := Parse([]byte(tt.data)); err == nil {
+				t.Errorf("Parse(%q) = %+v; want an error", tt.data, component)
+			}
+		})
+	}
+}
+
+func TestPropertyTime(t *testing.T) {
+	berlin := loadLocation(t, "Europe/Berlin")
+	tests := []struct {
+		name     string
+		prop     Property
+		want     time.Time
+		wantDate bool
+		wantErr  bool
+	}{
+		{
+			name: "UTC",
+			prop: Property{Value: "20240701T083000Z"},
+			want: time.Date(2024, 7, 1, 8, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "TZID",
+			prop: Property{Params: map[string]string{"TZID": "Europe/Berlin"}, Value: "20240701T103000"},
+			want: time.Date(2024, 7, 1, 10, 30, 0, 0, berlin),
+		},
+		{
+			name: "TZID with a leading slash",
+			prop: Property{Params: map[string]string{"TZID": "/Europe/Berlin"}, Value: "20240101T103000"},
+			want: time.Date(2024, 1, 1, 10, 30, 0, 0, berlin),
+		},
+		{
+			name: "floating",
+			prop: Property{Value: "20240701T103000"},
+			want: time.Date(2024, 7, 1, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "date",
+			prop:     Property{Params: map[string]string{"VALUE": "DATE"}, Value: "20240701"},
+			want:     time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+			wantDate: true,
+		},
+		{
+			name:     "date without VALUE",
+			prop:     Property{Value: "20240701"},
+			want:     time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+			wantDate: true,
+		},
+		{name: "invalid date", prop: Property{Params: map[string]string{"VALUE": "DATE"}, Value: "2024-07-01"}, wantErr: true},
+		{name: "invalid UTC time", prop: Property{Value: "20241301T083000Z"}, wantErr: true},
+		{name: "invalid local time", prop: Property{Value: "tomorrow"}, wantErr: true},
+		{name: "unknown TZID", prop: Property{Params: map[string]string{"TZID": "Mars/Olympus"}, Value: "20240701T103000"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prop.Name = "DUE"
+			got, isDate, err := tt.prop.Time()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Time() = %v; want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Time(): %v", err)
+			}
+			if !got.Equal(tt.want) || isDate != tt.wantDate {
+				t.Errorf("Time() = %v, %v; want %v, %v", got, isDate, tt.want, tt.wantDate)
+			}
+			if got.Location() != time.UTC {
+				t.Errorf("Time() location = %v; want UTC", got.Location())
+			}
+		})
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "plain", want: "plain"},
+		{text: `a\\b`, want: `a\b`},
+		{text: `a\;b\,c`, want: "a;b,c"},
+		{text: `line\nbreak\Nagain`, want: "line\nbreak\nagain"},
+		{text: `trailing\`, want: `trailing\`},
+		{text: `unknown\x`, want: "unknownx"},
+	}
+	for _, tt := range tests {
+		if got := Unescape(tt.text); got != tt.want {
+			t.Errorf("Unescape(%q) = %q; want %q", tt.text, got, tt.want)
+		}
+		if tt.text != `trailing\` && tt.text != `unknown\x` {
+			if got := Unescape(Escape(tt.want)); got != tt.want {
+				t.Errorf("Unescape(Escape(%q)) = %q", tt.want, got)
+			}
+		}
+	}
+}
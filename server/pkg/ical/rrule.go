@@ -0,0 +1,124 @@
+package ical
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// frequencies are the FREQ values of a recurrence rule
+var frequencies = map[string]bool{
+	"SECONDLY": true, "MINUTELY": true, "HOURLY": true, "DAILY": true,
+	"WEEKLY": true, "MONTHLY": true, "YEARLY": true,
+}
+
+// weekdays are the two-letter days of BYDAY and WKST
+var weekdays = map[string]bool{
+	"SU": true, "MO": true, "TU": true, "WE": true, "TH": true, "FR": true, "SA": true,
+}
+
+// numberLists are the BYxxx parts that take lists of numbers, with their smallest and largest
+// values; signed parts count from the end of the period and may not be 0
+var numberLists = map[string]struct {
+	min, max int
+	signed   bool
+}{
+	"BYSECOND":   {0, 60, false},
+	"BYMINUTE":   {0, 59, false},
+	"BYHOUR":     {0, 23, false},
+	"BYMONTHDAY": {1, 31, true},
+	"BYYEARDAY":  {1, 366, true},
+	"BYWEEKNO":   {1, 53, true},
+	"BYMONTH":    {1, 12, false},
+	"BYSETPOS":   {1, 366, true},
+}
+
+// NormalizeRecurrence checks an RRULE value (RFC 5545 section 3.3.10) such as
+// FREQ=WEEKLY;BYDAY=MO,WE and returns it upper-cased with its spaces removed.
+// FREQ is required, COUNT and UNTIL exclude each other and X- parts are kept
+// unchecked. An empty rule is no recurrence.
+func NormalizeRecurrence(rule string) (string, error) {
+	rule = strings.ToUpper(strings.Join(strings.Fields(rule), ""))
+	if rule == "" {
+		return "", nil
+	}
+
+	seen := map[string]bool{}
+	for _, part := range strings.Split(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || name == "" || value == "" {
+			return "", errors.New("parts must be NAME=VALUE separated by semicolons")
+		}
+		if seen[name] {
+			return "", errors.New(name + " is given twice")
+		}
+		seen[name] = true
+
+		if err := checkRecurrencePart(name, value); err != nil {
+			return "", err
+		}
+	}
+
+	if !seen["FREQ"] {
+		return "", errors.New("FREQ is required")
+	}
+	if seen["COUNT"] && seen["UNTIL"] {
+		return "", errors.New("COUNT and UNTIL cannot be combined")
+	}
+	return rule, nil
+}
+
+// checkRecurrencePart checks the value of one part of a recurrence rule
+func checkRecurrencePart(name, value string) error {
+	switch name {
+	case "FREQ":
+		if !frequencies[value] {
+			return errors.New("FREQ must be SECONDLY, MINUTELY, HOURLY, DAILY, WEEKLY, MONTHLY or YEARLY")
+		}
+	case "INTERVAL", "COUNT":
+		if n, err := strconv.Atoi(value); err != nil || n < 1 {
+			return errors.New(name + " must be a positive number")
+		}
+	case "UNTIL":
+		for _, layout := range []string{DateLayout, DateTimeLayout, UTCLayout} {
+			if _, err := time.Parse(layout, value); err == nil {
+				return nil
+			}
+		}
+		return errors.New("UNTIL must be a date or a date-time")
+	case "WKST":
+		if !weekdays[value] {
+			return errors.New("WKST must be a weekday such as MO")
+		}
+	case "BYDAY":
+		for _, day := range strings.Split(value, ",") {
+			if len(day) < 2 || !weekdays[day[len(day)-2:]] {
+				return errors.New("BYDAY must be weekdays such as MO or -1FR")
+			}
+			if ordinal := day[:len(day)-2]; ordinal != "" {
+				if n, err := strconv.Atoi(ordinal); err != nil || n == 0 || n < -53 || n > 53 {
+					return errors.New("BYDAY must be weekdays such as MO or -1FR")
+				}
+			}
+		}
+	default:
+		limits, ok := numberLists[name]
+		if !ok {
+			if strings.HasPrefix(name, "X-") {
+				return nil
+			}
+			return errors.New("unknown part " + name)
+		}
+		for _, item := range strings.Split(value, ",") {
+			n, err := strconv.Atoi(item)
+			if err == nil && limits.signed && n < 0 {
+				n = -n
+			}
+			if err != nil || n < limits.min || n > limits.max {
+				return errors.New(name + " must be numbers from " + strconv.Itoa(limits.min) + " to " + strconv.Itoa(limits.max))
+			}
+		}
+	}
+	return nil
+}
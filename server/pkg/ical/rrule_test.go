@@ -0,0 +1,67 @@
+package ical
+
+import "testing"
+
+func TestNormalizeRecurrence(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    string
+		wantErr bool
+	}{
+		{rule: "", want: ""},
+		{rule: "   ", want: ""},
+		{rule: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{rule: "freq=weekly;byday=mo,we", want: "FREQ=WEEKLY;BYDAY=MO,WE"},
+		{rule: " FREQ=MONTHLY; INTERVAL=2 ", want: "FREQ=MONTHLY;INTERVAL=2"},
+		{rule: "FREQ=MONTHLY;BYDAY=-1FR", want: "FREQ=MONTHLY;BYDAY=-1FR"},
+		{rule: "FREQ=MONTHLY;BYDAY=+2TU", want: "FREQ=MONTHLY;BYDAY=+2TU"},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=1,15,-1", want: "FREQ=MONTHLY;BYMONTHDAY=1,15,-1"},
+		{rule: "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29;COUNT=3", want: "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29;COUNT=3"},
+		{rule: "FREQ=WEEKLY;UNTIL=20261231", want: "FREQ=WEEKLY;UNTIL=20261231"},
+		{rule: "FREQ=WEEKLY;UNTIL=20261231T235959Z;WKST=SU", want: "FREQ=WEEKLY;UNTIL=20261231T235959Z;WKST=SU"},
+		{rule: "FREQ=DAILY;BYHOUR=9,17;BYMINUTE=0;BYSECOND=0", want: "FREQ=DAILY;BYHOUR=9,17;BYMINUTE=0;BYSECOND=0"},
+		{rule: "FREQ=YEARLY;BYWEEKNO=-1;BYYEARDAY=100;BYSETPOS=-1", want: "FREQ=YEARLY;BYWEEKNO=-1;BYYEARDAY=100;BYSETPOS=-1"},
+		{rule: "FREQ=DAILY;X-NAME=anything", want: "FREQ=DAILY;X-NAME=ANYTHING"},
+
+		{rule: "every monday", wantErr: true},
+		{rule: "INTERVAL=2", wantErr: true},
+		{rule: "FREQ=FORTNIGHTLY", wantErr: true},
+		{rule: "FREQ=DAILY;", wantErr: true},
+		{rule: "FREQ=DAILY;;COUNT=2", wantErr: true},
+		{rule: "FREQ=DAILY;COUNT", wantErr: true},
+		{rule: "FREQ=DAILY;COUNT=", wantErr: true},
+		{rule: "FREQ=DAILY;FREQ=WEEKLY", wantErr: true},
+		{rule: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{rule: "FREQ=DAILY;COUNT=-1", wantErr: true},
+		{rule: "FREQ=DAILY;COUNT=2;UNTIL=20261231", wantErr: true},
+		{rule: "FREQ=DAILY;UNTIL=2026-12-31", wantErr: true},
+		{rule: "FREQ=WEEKLY;BYDAY=MON", wantErr: true},
+		{rule: "FREQ=WEEKLY;BYDAY=MO,", wantErr: true},
+		{rule: "FREQ=MONTHLY;BYDAY=0MO", wantErr: true},
+		{rule: "FREQ=MONTHLY;BYDAY=54MO", wantErr: true},
+		{rule: "FREQ=WEEKLY;WKST=XX", wantErr: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=0", wantErr: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{rule: "FREQ=YEARLY;BYMONTH=-1", wantErr: true},
+		{rule: "FREQ=YEARLY;BYMONTH=13", wantErr: true},
+		{rule: "FREQ=DAILY;BYHOUR=24", wantErr: true},
+		{rule: "FREQ=DAILY;RSCALE=GREGORIAN", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got, err := NormalizeRecurrence(tt.rule)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NormalizeRecurrence(%q) = %q; want an error", tt.rule, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeRecurrence(%q): %v", tt.rule, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeRecurrence(%q) = %q; want %q", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+// Package jsonpatch applies JSON Merge Patch (RFC 7396) and JSON Patch (RFC 6902)
+// documents to JSON objects, and reports which top-level fields a patch changed.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Patch media types
+const (
+	MediaTypeMergePatch = "application/merge-patch+json"
+	MediaTypeJSONPatch  = "application/json-patch+json"
+)
+
+var (
+	// ErrUnsupportedMediaType is returned for patch documents of an unknown media type
+	ErrUnsupportedMediaType = errors.New("unsupported patch media type")
+
+	// ErrTestFailed is returned when a JSON Patch "test" operation does not match
+	ErrTestFailed = errors.New("patch test operation failed")
+)
+
+// Apply applies a patch of the given media type to a JSON document
+// Plain application/json is treated as a merge patch
+func Apply(doc, patch []byte, mediaType string) ([]byte, error) {
+	switch mediaType {
+	case MediaTypeMergePatch, "application/json", "":
+		return ApplyMergePatch(doc, patch)
+	case MediaTypeJSONPatch:
+		return ApplyPatch(doc, patch)
+	default:
+		return nil, ErrUnsupportedMediaType
+	}
+}
+
+// ApplyMergePatch applies an RFC 7396 merge patch to a JSON document
+// Members set to null are removed, objects are merged recursively and everything else is replaced
+func ApplyMergePatch(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	changes, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+	return json.Marshal(mergePatch(target, changes))
+}
+
+// mergePatch implements the MergePatch function of RFC 7396
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = mergePatch(targetObject[name], value)
+	}
+	return targetObject
+}
+
+// Operation is a single RFC 6902 JSON Patch operation
+type Operation struct {
+	Op   string  `json:"op"`
+	Path *string `json:"path"`
+	From *string `json:"from,omitempty"`
+	// Value is a raw message rather than a pointer, so an explicit null is kept apart from a missing value
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ApplyPatch applies an RFC 6902 JSON Patch to a JSON document
+// Operations are applied in order; if any operation fails the document is left unchanged
+func ApplyPatch(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+
+	var operations []Operation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("invalid patch: a JSON Patch must be an array of operations: %w", err)
+	}
+
+	for i, operation := range operations {
+		if target, err = applyOperation(target, operation); err != nil {
+			if errors.Is(err, ErrTestFailed) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("invalid patch: operation %d (%s): %w", i, operation.Op, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+// applyOperation applies one JSON Patch operation and returns the new document
+func applyOperation(doc interface{}, operation Operation) (interface{}, error) {
+	if operation.Path == nil {
+		return nil, errors.New("path is required")
+	}
+	path, err := parsePointer(*operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add", "replace", "test":
+		if len(operation.Value) == 0 {
+			return nil, errors.New("value is required")
+		}
+		value, err := decode(operation.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch operation.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			if _, err := get(doc, path); err != nil {
+				return nil, err
+			}
+			if len(path) == 0 {
+				return value, nil
+			}
+			if doc, err = remove(doc, path); err != nil {
+				return nil, err
+			}
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrTestFailed
+			}
+			return doc, nil
+		}
+
+	case "remove":
+		return remove(doc, path)
+
+	case "move", "copy":
+		if operation.From == nil {
+			return nil, errors.New("from is required")
+		}
+		from, err := parsePointer(*operation.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if operation.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, errors.New("cannot move a value into one of its children")
+			}
+			if doc, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			// Copy the value so later operations do not change both places
+			if value, err = deepCopy(value); err != nil {
+				return nil, err
+			}
+		}
+		return add(doc, path, value)
+
+	default:
+		return nil, fmt.Errorf("unknown operation %q", operation.Op)
+	}
+}
+
+// get returns the value at path
+func get(doc interface{}, path []string) (interface{}, error) {
+	current := doc
+	for _, token := range path {
+		switch container := current.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("path member %q does not exist", token)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = container[index]
+		default:
+			return nil, fmt.Errorf("path member %q does not exist", token)
+		}
+	}
+	return current, nil
+}
+
+// add sets the value at path, inserting into arrays, and returns the new document
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		container[token] = value
+		return doc, nil
+	case []interface{}:
+		index := len(container)
+		if token != "-" {
+			if index, err = arrayIndex(token, len(container)); err != nil {
+				return nil, err
+			}
+		}
+		updated := append(container[:index:index], append([]interface{}{value}, container[index:]...)...)
+		return replaceAt(doc, path[:len(path)-1], updated)
+	default:
+		return nil, errors.New("path parent is not an object or array")
+	}
+}
+
+// remove deletes the value at path and returns the new document
+func remove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := container[token]; !ok {
+			return nil, fmt.Errorf("path member %q does not exist", token)
+		}
+		delete(container, token)
+		return doc, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated := append(container[:index:index], container[index+1:]...)
+		return replaceAt(doc, path[:len(path)-1], updated)
+	default:
+		return nil, errors.New("path parent is not an object or array")
+	}
+}
+
+// replaceAt stores value at path, used after an array changed length
+func replaceAt(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		container[token] = value
+	case []interface{}:
+		index, err := arrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		container[index] = value
+	}
+	return doc, nil
+}
+
+// arrayIndex parses an array index token, accepting indexes up to max
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	index := 0
+	for _, r := range token {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("invalid array index %q", token)
+		}
+		index = index*10 + int(r-'0')
+		if index > max {
+			return 0, fmt.Errorf("array index %s is out of range", token)
+		}
+	}
+	return index, nil
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// isPrefix reports whether prefix is a leading part of path
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ChangedFields compares two JSON objects and returns the top-level members that differ
+// Members missing from patched are reported as null
+func ChangedFields(original, patched []byte) (map[string]json.RawMessage, error) {
+	before, err := decode(original)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decode(patched)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeObject, ok := before.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid document: not a JSON object")
+	}
+	afterObject, ok := after.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid patch: the document must remain a JSON object")
+	}
+
+	changes := map[string]json.RawMessage{}
+	for name, value := range afterObject {
+		if previous, ok := beforeObject[name]; ok && reflect.DeepEqual(previous, value) {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		changes[name] = raw
+	}
+	for name := range beforeObject {
+		if _, ok := afterObject[name]; !ok {
+			changes[name] = json.RawMessage("null")
+		}
+	}
+	return changes, nil
+}
+
+// decode parses JSON keeping numbers exact
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return value, nil
+}
+
+// deepCopy copies a decoded JSON value
+func deepCopy(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
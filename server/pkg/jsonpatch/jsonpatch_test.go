@@ -0,0 +1,207 @@
+package jsonpatch
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	// The examples of RFC 7396, appendix A
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		// Numbers are kept exactly
+		{`{"id":12345678901234567890}`, `{"n":1.50}`, `{"id":12345678901234567890,"n":1.50}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.doc+" "+tt.patch, func(t *testing.T) {
+			got, err := ApplyMergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("ApplyMergePatch: %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestApplyMergePatchInvalid(t *testing.T) {
+	tests := []struct {
+		doc, patch, wantErr string
+	}{
+		{`{`, `{}`, "invalid document"},
+		{`{}`, `{"a":`, "invalid patch"},
+		{`{}`, `{} {}`, "invalid patch"},
+	}
+	for _, tt := range tests {
+		_, err := ApplyMergePatch([]byte(tt.doc), []byte(tt.patch))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ApplyMergePatch(%s, %s) error = %v; want %q", tt.doc, tt.patch, err, tt.wantErr)
+		}
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	// Mostly the examples of RFC 6902, appendix A
+	tests := []struct {
+		name, doc, patch, want string
+	}{
+		{"add object member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"append to array", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{"add nested member", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{"add replaces member", `{"foo":1}`, `[{"op":"add","path":"/foo","value":2}]`, `{"foo":2}`},
+		{"remove object member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"replace array element", `{"a":[1,2,3]}`, `[{"op":"replace","path":"/a/1","value":9}]`, `{"a":[1,9,3]}`},
+		{"replace whole document", `{"a":1}`, `[{"op":"replace","path":"","value":{"b":2}}]`, `{"b":2}`},
+		{"move member", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"copy", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+		{"test then replace", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2},{"op":"replace","path":"/baz","value":"x"}]`,
+			`{"baz":"x","foo":["a",2,"c"]}`},
+		{"escaped pointer", `{"a/b":1,"m~n":2}`, `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, `{"a/b":3}`},
+		{"test null value", `{"a":null}`, `[{"op":"test","path":"/a","value":null}]`, `{"a":null}`},
+		{"empty patch", `{"a":1}`, `[]`, `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyPatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("ApplyPatch: %v", err)
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	tests := []struct {
+		name, doc, patch string
+		wantErr          string
+		wantIs           error
+	}{
+		{"test failure", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, "", ErrTestFailed},
+		{"test number and string", `{"a":1}`, `[{"op":"test","path":"/a","value":"1"}]`, "", ErrTestFailed},
+		{"missing member", `{"baz":"qux"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, "operation 0 (add)", nil},
+		{"remove missing member", `{"a":1}`, `[{"op":"remove","path":"/b"}]`, `path member "b" does not exist`, nil},
+		{"replace missing member", `{"a":1}`, `[{"op":"replace","path":"/b","value":1}]`, `does not exist`, nil},
+		{"array index out of range", `{"a":[1]}`, `[{"op":"add","path":"/a/2","value":1}]`, "out of range", nil},
+		{"leading zero index", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/01"}]`, "invalid array index", nil},
+		{"move into child", `{"a":{"b":{}}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, "into one of its children", nil},
+		{"unknown operation", `{}`, `[{"op":"merge","path":"/a","value":1}]`, `unknown operation "merge"`, nil},
+		{"missing value", `{}`, `[{"op":"add","path":"/a"}]`, "value is required", nil},
+		{"missing path", `{}`, `[{"op":"remove"}]`, "path is required", nil},
+		{"missing from", `{"a":1}`, `[{"op":"copy","path":"/b"}]`, "from is required", nil},
+		{"invalid pointer", `{}`, `[{"op":"add","path":"a","value":1}]`, "invalid JSON pointer", nil},
+		{"not an array", `{}`, `{"op":"add","path":"/a","value":1}`, "must be an array", nil},
+		{"second operation fails", `{"a":1}`, `[{"op":"remove","path":"/a"},{"op":"remove","path":"/a"}]`, "operation 1 (remove)", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ApplyPatch([]byte(tt.doc), []byte(tt.patch))
+			if err == nil {
+				t.Fatal("ApplyPatch error = nil; want an error")
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("ApplyPatch error = %v; want %v", err, tt.wantIs)
+			}
+			if tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ApplyPatch error = %v; want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	doc := []byte(`{"title":"a","done":false}`)
+	tests := []struct {
+		mediaType, patch, want string
+	}{
+		{MediaTypeMergePatch, `{"title":"b"}`, `{"title":"b","done":false}`},
+		{"application/json", `{"done":true}`, `{"title":"a","done":true}`},
+		{"", `{"title":null}`, `{"done":false}`},
+		{MediaTypeJSONPatch, `[{"op":"replace","path":"/done","value":true}]`, `{"title":"a","done":true}`},
+	}
+	for _, tt := range tests {
+		got, err := Apply(doc, []byte(tt.patch), tt.mediaType)
+		if err != nil {
+			t.Fatalf("Apply(%q): %v", tt.mediaType, err)
+		}
+		assertJSON(t, got, tt.want)
+	}
+
+	if _, err := Apply(doc, []byte(`{}`), "text/plain"); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Apply(text/plain) error = %v; want ErrUnsupportedMediaType", err)
+	}
+}
+
+func TestChangedFields(t *testing.T) {
+	tests := []struct {
+		original, patched string
+		want              map[string]string
+		wantErr           bool
+	}{
+		{original: `{"a":1,"b":"x"}`, patched: `{"a":1,"b":"x"}`, want: map[string]string{}},
+		{original: `{"a":1,"b":"x"}`, patched: `{"a":2,"b":"x"}`, want: map[string]string{"a": "2"}},
+		{original: `{"a":1,"b":"x"}`, patched: `{"a":1}`, want: map[string]string{"b": "null"}},
+		{original: `{"a":1}`, patched: `{"a":1,"c":[1]}`, want: map[string]string{"c": "[1]"}},
+		{original: `{"a":{"b":1}}`, patched: `{"a":{"b":2}}`, want: map[string]string{"a": `{"b":2}`}},
+		{original: `{"a":1.0}`, patched: `{"a":1.00}`, want: map[string]string{"a": "1.00"}},
+		{original: `{"a":1}`, patched: `[1]`, wantErr: true},
+		{original: `[1]`, patched: `{"a":1}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ChangedFields([]byte(tt.original), []byte(tt.patched))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ChangedFields(%s, %s) error = nil; want an error", tt.original, tt.patched)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ChangedFields(%s, %s): %v", tt.original, tt.patched, err)
+		}
+		gotStrings := make(map[string]string, len(got))
+		for name, raw := range got {
+			gotStrings[name] = string(raw)
+		}
+		if !reflect.DeepEqual(gotStrings, tt.want) {
+			t.Errorf("ChangedFields(%s, %s) = %v; want %v", tt.original, tt.patched, gotStrings, tt.want)
+		}
+	}
+}
+
+// assertJSON fails the test unless got and want are the same JSON value
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	gotValue, err := decode(got)
+	if err != nil {
+		t.Fatalf("result %s is not valid JSON: %v", got, err)
+	}
+	wantValue, err := decode([]byte(want))
+	if err != nil {
+		t.Fatalf("expected value %s is not valid JSON: %v", want, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("got %s; want %s", got, want)
+	}
+}
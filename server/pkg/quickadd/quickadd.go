@@ -0,0 +1,564 @@
+// Package quickadd reads a todo written as a line of text, such as
+// "Pay rent tomorrow 9am !high #Personal every month".
+//
+// Dates may be written as today, tonight, tomorrow, weekday names, next
+// week/month/year, "in 3 days", ISO dates or month names with a day. Times
+// are written as 9am, 9:30pm, 21:00, noon or "at 9". !high, !medium and !low
+// (or !1 to !3) set the priority, #Name the category (#Home_Office or
+// #"Home Office" for names with spaces) and daily, weekly, "every month",
+// "every 2 weeks", "every monday" or "every weekday" the recurrence. Words in
+// double quotes are always kept in the title. Everything that is not
+// recognized, or repeats something already recognized, stays in the title.
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Kinds of recognized tokens
+const (
+	KindDate       = "date"
+	KindTime       = "time"
+	KindPriority   = "priority"
+	KindCategory   = "category"
+	KindRecurrence = "recurrence"
+)
+
+// tonightHour is the time of day "tonight" stands for
+const tonightHour = 20
+
+// Token is a part of the text that was recognized, with its byte offsets
+type Token struct {
+	Kind  string `json:"kind"`
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// Result is what was read from the text
+type Result struct {
+	Title string
+	// Due is the due day at midnight, or the due time when HasTime is set, in the location of now
+	Due     *time.Time
+	HasTime bool
+	// Priority is high, medium, low or empty
+	Priority string
+	// Category is the category name without the leading #
+	Category string
+	// Recurrence is an iCalendar RRULE value, e.g. FREQ=WEEKLY;BYDAY=MO
+	Recurrence string
+	Tokens     []Token
+}
+
+// word is a whitespace-separated part of the text
+type word struct {
+	raw    string
+	lower  string
+	start  int
+	end    int
+	quoted bool
+}
+
+var (
+	priorities = map[string]string{
+		"high": "high", "h": "high", "1": "high",
+		"medium": "medium", "med": "medium", "m": "medium", "2": "medium",
+		"low": "low", "l": "low", "3": "low",
+	}
+
+	weekdays = map[string]time.Weekday{
+		"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+		"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	}
+
+	// weekdayAbbreviations are only recognized after on, this or next, as some are common words
+	weekdayAbbreviations = map[string]time.Weekday{
+		"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "tues": time.Tuesday,
+		"wed": time.Wednesday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+		"fri": time.Friday, "sat": time.Saturday,
+	}
+
+	months = map[string]time.Month{
+		"january": time.January, "february": time.February, "march": time.March, "april": time.April,
+		"may": time.May, "june": time.June, "july": time.July, "august": time.August,
+		"september": time.September, "october": time.October, "november": time.November, "december": time.December,
+		"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+		"jun": time.June, "jul": time.July, "aug": time.August, "sep": time.September, "sept": time.September,
+		"oct": time.October, "nov": time.November, "dec": time.December,
+	}
+
+	// frequencies maps recurrence units and their adverbs to RRULE frequencies
+	frequencies = map[string]string{
+		"day": "DAILY", "week": "WEEKLY", "month": "MONTHLY", "year": "YEARLY",
+		"daily": "DAILY", "weekly": "WEEKLY", "monthly": "MONTHLY", "yearly": "YEARLY", "annually": "YEARLY",
+	}
+
+	rruleDays = map[time.Weekday]string{
+		time.Sunday: "SU", time.Monday: "MO", time.Tuesday: "TU", time.Wednesday: "WE",
+		time.Thursday: "TH", time.Friday: "FR", time.Saturday: "SA",
+	}
+
+	clockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	dayPattern   = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+	yearPattern  = regexp.MustCompile(`^\d{4}$`)
+)
+
+// parser holds the state of parsing one text
+type parser struct {
+	text  string
+	words []word
+	now   time.Time
+
+	date    *time.Time
+	clock   *time.Duration
+	tonight bool
+	weekday *time.Weekday
+	result  Result
+}
+
+// Parse reads a todo from text, resolving relative dates and times against now in its location
+func Parse(text string, now time.Time) Result {
+	p := &parser{text: text, words: split(text), now: now}
+
+	var title []string
+	for i := 0; i < len(p.words); {
+		if n := p.match(i); n > 0 {
+			i += n
+			continue
+		}
+		w := p.words[i]
+		if w.quoted {
+			title = append(title, strings.Trim(w.raw, `"`))
+		} else {
+			title = append(title, w.raw)
+		}
+		i++
+	}
+	p.result.Title = strings.TrimSpace(strings.Join(title, " "))
+
+	p.resolveDue()
+	return p.result
+}
+
+// match tries to recognize a token at word i, returning the number of words it spans
+func (p *parser) match(i int) int {
+	if p.words[i].quoted {
+		return 0
+	}
+	if p.result.Priority == "" {
+		if n := p.matchPriority(i); n > 0 {
+			return p.token(KindPriority, i, n)
+		}
+	}
+	if p.result.Category == "" {
+		if n := p.matchCategory(i); n > 0 {
+			return p.token(KindCategory, i, n)
+		}
+	}
+	if p.result.Recurrence == "" {
+		if n := p.matchRecurrence(i); n > 0 {
+			return p.token(KindRecurrence, i, n)
+		}
+	}
+	if p.date == nil {
+		if n := p.matchDate(i); n > 0 {
+			return p.token(KindDate, i, n)
+		}
+	}
+	if p.clock == nil {
+		if n := p.matchTime(i); n > 0 {
+			return p.token(KindTime, i, n)
+		}
+	}
+	return 0
+}
+
+// token records the n words starting at word i as a token of a kind
+func (p *parser) token(kind string, i, n int) int {
+	start, end := p.words[i].start, p.words[i+n-1].end
+	p.result.Tokens = append(p.result.Tokens, Token{Kind: kind, Text: p.text[start:end], Start: start, End: end})
+	return n
+}
+
+// lower returns the lower-cased word i without trailing punctuation, or "" past the end
+func (p *parser) lower(i int) string {
+	if i >= len(p.words) || p.words[i].quoted {
+		return ""
+	}
+	return p.words[i].lower
+}
+
+func (p *parser) matchPriority(i int) int {
+	w := p.lower(i)
+	if !strings.HasPrefix(w, "!") {
+		return 0
+	}
+	priority, ok := priorities[w[1:]]
+	if !ok {
+		return 0
+	}
+	p.result.Priority = priority
+	return 1
+}
+
+func (p *parser) matchCategory(i int) int {
+	raw := strings.TrimRight(p.words[i].raw, ",.;")
+	if len(raw) < 2 || raw[0] != '#' {
+		return 0
+	}
+	name := raw[1:]
+	if strings.HasPrefix(name, `"`) {
+		name = strings.Trim(name, `"`)
+	} else {
+		name = strings.ReplaceAll(name, "_", " ")
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		return 0
+	}
+	p.result.Category = name
+	return 1
+}
+
+func (p *parser) matchRecurrence(i int) int {
+	if freq, ok := frequencies[p.lower(i)]; ok && strings.HasSuffix(p.lower(i), "ly") {
+		p.result.Recurrence = "FREQ=" + freq
+		return 1
+	}
+	if p.lower(i) != "every" {
+		return 0
+	}
+
+	next := p.lower(i + 1)
+	if next == "weekday" || next == "weekdays" {
+		p.result.Recurrence = "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"
+		return 2
+	}
+	if day, ok := weekdays[strings.TrimSuffix(next, "s")]; ok {
+		p.result.Recurrence = "FREQ=WEEKLY;BYDAY=" + rruleDays[day]
+		p.weekday = &day
+		return 2
+	}
+
+	interval, n := 1, 1
+	if next == "other" {
+		interval, n = 2, 2
+	} else if count, err := strconv.Atoi(next); err == nil && count > 0 {
+		interval, n = count, 2
+	}
+	freq, ok := frequencies[strings.TrimSuffix(p.lower(i+n), "s")]
+	if !ok || strings.HasSuffix(p.lower(i+n), "ly") || (interval == 1 && strings.HasSuffix(p.lower(i+n), "s")) {
+		return 0
+	}
+	p.result.Recurrence = "FREQ=" + freq
+	if interval > 1 {
+		p.result.Recurrence += ";INTERVAL=" + strconv.Itoa(interval)
+	}
+	return n + 1
+}
+
+func (p *parser) matchDate(i int) int {
+	today := p.today()
+	w := p.lower(i)
+
+	switch w {
+	case "today":
+		p.setDate(today)
+		return 1
+	case "tonight":
+		p.setDate(today)
+		p.tonight = true
+		return 1
+	case "tomorrow", "tmr", "tmrw":
+		p.setDate(today.AddDate(0, 0, 1))
+		return 1
+	}
+
+	// on friday, by oct 20, due tomorrow
+	if w == "on" || w == "by" || w == "due" {
+		if n := p.matchDate(i + 1); n > 0 {
+			return n + 1
+		}
+		if day, ok := weekdayAbbreviations[p.lower(i+1)]; ok {
+			p.setDate(nextWeekday(today, day, false))
+			return 2
+		}
+		return 0
+	}
+
+	if day, ok := weekdays[w]; ok {
+		p.setDate(nextWeekday(today, day, false))
+		return 1
+	}
+	if w == "this" || w == "next" {
+		next := p.lower(i + 1)
+		day, ok := weekdays[next]
+		if !ok {
+			day, ok = weekdayAbbreviations[next]
+		}
+		if ok {
+			p.setDate(nextWeekday(today, day, w == "next"))
+			return 2
+		}
+		if w == "next" {
+			switch next {
+			case "week":
+				p.setDate(nextWeekday(today, time.Monday, true))
+				return 2
+			case "month":
+				p.setDate(time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()))
+				return 2
+			case "year":
+				p.setDate(time.Date(today.Year()+1, time.January, 1, 0, 0, 0, 0, today.Location()))
+				return 2
+			}
+		}
+		return 0
+	}
+
+	if w == "in" {
+		return p.matchOffset(i)
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", w, p.now.Location()); err == nil {
+		p.setDate(t)
+		return 1
+	}
+	return p.matchMonthDay(i)
+}
+
+// matchOffset recognizes "in 3 days", "in a week" or "in 2 hours"
+func (p *parser) matchOffset(i int) int {
+	count := 0
+	switch next := p.lower(i + 1); next {
+	case "a", "an":
+		count = 1
+	default:
+		n, err := strconv.Atoi(next)
+		if err != nil || n <= 0 || n > 1000 {
+			return 0
+		}
+		count = n
+	}
+
+	today := p.today()
+	switch unit := strings.TrimSuffix(p.lower(i+2), "s"); unit {
+	case "day":
+		p.setDate(today.AddDate(0, 0, count))
+	case "week":
+		p.setDate(today.AddDate(0, 0, 7*count))
+	case "month":
+		p.setDate(today.AddDate(0, count, 0))
+	case "year":
+		p.setDate(today.AddDate(count, 0, 0))
+	case "minute", "min", "hour", "hr":
+		if p.clock != nil {
+			return 0
+		}
+		step := time.Minute
+		if unit == "hour" || unit == "hr" {
+			step = time.Hour
+		}
+		at := p.now.Add(time.Duration(count) * step)
+		p.setDate(time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location()))
+		p.setClock(at.Hour(), at.Minute())
+	default:
+		return 0
+	}
+	return 3
+}
+
+// matchMonthDay recognizes "oct 20", "20 october" or "march 3rd 2027"
+// Dates without a year that already passed this year are taken for next year
+func (p *parser) matchMonthDay(i int) int {
+	var month time.Month
+	var day, n int
+	if m, ok := months[p.lower(i)]; ok {
+		if d := dayPattern.FindStringSubmatch(p.lower(i + 1)); d != nil {
+			month, n = m, 2
+			day, _ = strconv.Atoi(d[1])
+		}
+	} else if d := dayPattern.FindStringSubmatch(p.lower(i)); d != nil {
+		if m, ok := months[p.lower(i+1)]; ok {
+			month, n = m, 2
+			day, _ = strconv.Atoi(d[1])
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+
+	today := p.today()
+	year := today.Year()
+	explicitYear := yearPattern.MatchString(p.lower(i + n))
+	if explicitYear {
+		year, _ = strconv.Atoi(p.lower(i + n))
+		n++
+	}
+
+	date := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+	if date.Month() != month || date.Day() != day {
+		return 0
+	}
+	if !explicitYear && date.Before(today) {
+		date = date.AddDate(1, 0, 0)
+	}
+	p.setDate(date)
+	return n
+}
+
+func (p *parser) matchTime(i int) int {
+	w := p.lower(i)
+	if w == "at" || w == "@" {
+		if n := p.matchClock(i+1, true); n > 0 {
+			return n + 1
+		}
+		return 0
+	}
+	return p.matchClock(i, false)
+}
+
+// matchClock recognizes 9am, 9 pm, 9:30, 21:00 and noon; bare hours need at before them
+func (p *parser) matchClock(i int, bare bool) int {
+	w := p.lower(i)
+	if w == "noon" {
+		p.setClock(12, 0)
+		return 1
+	}
+
+	m := clockPattern.FindStringSubmatch(w)
+	if m == nil {
+		return 0
+	}
+	n := 1
+	suffix := m[3]
+	if suffix == "" && (p.lower(i+1) == "am" || p.lower(i+1) == "pm") {
+		suffix, n = p.lower(i+1), 2
+	}
+	if suffix == "" && m[2] == "" && !bare {
+		return 0
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if minute > 59 {
+		return 0
+	}
+	switch suffix {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0
+		}
+		hour %= 12
+		if suffix == "pm" {
+			hour += 12
+		}
+	default:
+		if hour > 23 {
+			return 0
+		}
+	}
+	p.setClock(hour, minute)
+	return n
+}
+
+func (p *parser) setDate(date time.Time) {
+	p.date = &date
+}
+
+func (p *parser) setClock(hour, minute int) {
+	clock := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+	p.clock = &clock
+}
+
+// today returns the start of the current day
+func (p *parser) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+}
+
+// resolveDue combines the recognized date and time into the due date
+// A time without a date is today, or tomorrow once it has passed; a weekly recurrence on
+// one weekday without a date is due on its next occurrence
+func (p *parser) resolveDue() {
+	date := p.date
+	if date == nil && p.weekday != nil {
+		next := nextWeekday(p.today(), *p.weekday, false)
+		date = &next
+	}
+	if date == nil && p.clock != nil {
+		today := p.today()
+		if !today.Add(*p.clock).After(p.now) {
+			today = today.AddDate(0, 0, 1)
+		}
+		date = &today
+	}
+	if date == nil {
+		return
+	}
+	if p.clock == nil && p.tonight {
+		p.setClock(tonightHour, 0)
+	}
+
+	due := *date
+	if p.clock != nil {
+		// Build the time from its fields so days with a daylight saving change keep the wall clock time
+		due = time.Date(due.Year(), due.Month(), due.Day(), int(*p.clock/time.Hour), int(*p.clock%time.Hour/time.Minute), 0, 0, due.Location())
+		p.result.HasTime = true
+	}
+	p.result.Due = &due
+}
+
+// nextWeekday returns the next day falling on a weekday, today included unless skipToday is set
+func nextWeekday(today time.Time, day time.Weekday, skipToday bool) time.Time {
+	days := (int(day) - int(today.Weekday()) + 7) % 7
+	if days == 0 && skipToday {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
+
+// split splits text into words on whitespace, keeping double-quoted parts together
+func split(text string) []word {
+	var words []word
+	start := -1
+	quoted := false
+	for i, r := range text {
+		switch {
+		case r == '"':
+			if start < 0 {
+				start = i
+			}
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			if start >= 0 {
+				words = append(words, newWord(text, start, i))
+				start = -1
+			}
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+	if start >= 0 {
+		words = append(words, newWord(text, start, len(text)))
+	}
+	return words
+}
+
+func newWord(text string, start, end int) word {
+	raw := text[start:end]
+	return word{
+		raw:    raw,
+		lower:  strings.ToLower(strings.TrimRight(raw, ",.;")),
+		start:  start,
+		end:    end,
+		quoted: len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"',
+	}
+}
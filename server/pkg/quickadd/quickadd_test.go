@@ -0,0 +1,198 @@
+package quickadd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// now is Wednesday 14 October 2026, 10:00 UTC
+var now = time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text       string
+		title      string
+		due        string // in the location of now, "2006-01-02 15:04" with a time or "2006-01-02" without
+		priority   string
+		category   string
+		recurrence string
+	}{
+		{text: "Call Ann", title: "Call Ann"},
+		{
+			text: "Pay rent tomorrow 9am !high #Personal every month", title: "Pay rent",
+			due: "2026-10-15 09:00", priority: "high", category: "Personal", recurrence: "FREQ=MONTHLY",
+		},
+
+		// Dates
+		{text: "Buy milk today", title: "Buy milk", due: "2026-10-14"},
+		{text: "Party tonight", title: "Party", due: "2026-10-14 20:00"},
+		{text: "Party tonight at 11pm", title: "Party", due: "2026-10-14 23:00"},
+		{text: "Call tmrw", title: "Call", due: "2026-10-15"},
+		{text: "Report friday", title: "Report", due: "2026-10-16"},
+		{text: "Report wednesday", title: "Report", due: "2026-10-14"},
+		{text: "Report next wednesday", title: "Report", due: "2026-10-21"},
+		{text: "Report this fri", title: "Report", due: "2026-10-16"},
+		{text: "Meet on tue", title: "Meet", due: "2026-10-20"},
+		{text: "Fix sat bug", title: "Fix sat bug"},
+		{text: "Plan next week", title: "Plan", due: "2026-10-19"},
+		{text: "Plan next month", title: "Plan", due: "2026-11-01"},
+		{text: "Plan next year", title: "Plan", due: "2027-01-01"},
+		{text: "Next steps", title: "Next steps"},
+		{text: "Check in 3 days", title: "Check", due: "2026-10-17"},
+		{text: "Check in a week", title: "Check", due: "2026-10-21"},
+		{text: "Check in 2 months", title: "Check", due: "2026-12-14"},
+		{text: "Check in 2 hours", title: "Check", due: "2026-10-14 12:00"},
+		{text: "Check in 90 min", title: "Check", due: "2026-10-14 11:30"},
+		{text: "Check in 0 days", title: "Check in 0 days"},
+		{text: "Log in please", title: "Log in please"},
+		{text: "Submit 2026-11-01", title: "Submit", due: "2026-11-01"},
+		{text: "Submit by oct 20", title: "Submit", due: "2026-10-20"},
+		{text: "Submit oct 1", title: "Submit", due: "2027-10-01"},
+		{text: "Submit 20 october 2027", title: "Submit", due: "2027-10-20"},
+		{text: "Submit march 3rd 2027", title: "Submit", due: "2027-03-03"},
+		{text: "Submit feb 30", title: "Submit feb 30"},
+		{text: "May the force", title: "May the force"},
+
+		// Times
+		{text: "Call 9:30 pm", title: "Call", due: "2026-10-14 21:30"},
+		{text: "Call 21:00", title: "Call", due: "2026-10-14 21:00"},
+		{text: "Lunch noon", title: "Lunch", due: "2026-10-14 12:00"},
+		{text: "Standup 9am", title: "Standup", due: "2026-10-15 09:00"},
+		{text: "Standup at 9", title: "Standup", due: "2026-10-15 09:00"},
+		{text: "Standup 10:00", title: "Standup", due: "2026-10-15 10:00"},
+		{text: "Standup friday 9:15am", title: "Standup", due: "2026-10-16 09:15"},
+		{text: "Buy 9 apples", title: "Buy 9 apples"},
+		{text: "Call 13pm", title: "Call 13pm"},
+		{text: "Call 25:00", title: "Call 25:00"},
+		{text: "Call 9:75", title: "Call 9:75"},
+
+		// Priorities and categories
+		{text: "Fix bug !1", title: "Fix bug", priority: "high"},
+		{text: "Fix bug !Med", title: "Fix bug", priority: "medium"},
+		{text: "Fix bug !low", title: "Fix bug", priority: "low"},
+		{text: "Fix bug !urgent", title: "Fix bug !urgent"},
+		{text: "File taxes #Home_Office", title: "File taxes", category: "Home Office"},
+		{text: `File taxes #"Home Office"`, title: "File taxes", category: "Home Office"},
+		{text: "File taxes #Work, now", title: "File taxes now", category: "Work"},
+		{text: "Issue # 5", title: "Issue # 5"},
+
+		// Recurrences
+		{text: "Water plants daily", title: "Water plants", recurrence: "FREQ=DAILY"},
+		{text: "Review annually", title: "Review", recurrence: "FREQ=YEARLY"},
+		{text: "Gym every weekday", title: "Gym", recurrence: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{text: "Bins every monday", title: "Bins", due: "2026-10-19", recurrence: "FREQ=WEEKLY;BYDAY=MO"},
+		{text: "Bins every wednesday 7pm", title: "Bins", due: "2026-10-14 19:00", recurrence: "FREQ=WEEKLY;BYDAY=WE"},
+		{text: "Bins every monday oct 20", title: "Bins", due: "2026-10-20", recurrence: "FREQ=WEEKLY;BYDAY=MO"},
+		{text: "Backup every 2 weeks", title: "Backup", recurrence: "FREQ=WEEKLY;INTERVAL=2"},
+		{text: "Backup every other month", title: "Backup", recurrence: "FREQ=MONTHLY;INTERVAL=2"},
+		{text: "Backup every year", title: "Backup", recurrence: "FREQ=YEARLY"},
+		{text: "Backup every weeks", title: "Backup every weeks"},
+		{text: "Backup every monthly", title: "Backup every", recurrence: "FREQ=MONTHLY"},
+		{text: "Every day counts", title: "counts", recurrence: "FREQ=DAILY"},
+
+		// Quoted words and repeated tokens stay in the title
+		{text: `Read "next week" tomorrow`, title: "Read next week", due: "2026-10-15"},
+		{text: `Buy "!high" heels`, title: "Buy !high heels"},
+		{text: "Call tomorrow today", title: "Call today", due: "2026-10-15"},
+		{text: "Fix !high !low", title: "Fix !low", priority: "high"},
+		{text: "Move #Home to #Work", title: "Move to #Work", category: "Home"},
+		{text: "  spaced   out  ", title: "spaced out"},
+		{text: "", title: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := Parse(tt.text, now)
+			if got.Title != tt.title {
+				t.Errorf("Title = %q; want %q", got.Title, tt.title)
+			}
+			if due := formatDue(got); due != tt.due {
+				t.Errorf("Due = %q; want %q", due, tt.due)
+			}
+			if got.Priority != tt.priority {
+				t.Errorf("Priority = %q; want %q", got.Priority, tt.priority)
+			}
+			if got.Category != tt.category {
+				t.Errorf("Category = %q; want %q", got.Category, tt.category)
+			}
+			if got.Recurrence != tt.recurrence {
+				t.Errorf("Recurrence = %q; want %q", got.Recurrence, tt.recurrence)
+			}
+		})
+	}
+}
+
+func TestParseTokens(t *testing.T) {
+	text := "Pay rent tomorrow 9am !high #Personal every month"
+	want := []Token{
+		{Kind: KindDate, Text: "tomorrow", Start: 9, End: 17},
+		{Kind: KindTime, Text: "9am", Start: 18, End: 21},
+		{Kind: KindPriority, Text: "!high", Start: 22, End: 27},
+		{Kind: KindCategory, Text: "#Personal", Start: 28, End: 37},
+		{Kind: KindRecurrence, Text: "every month", Start: 38, End: 49},
+	}
+	got := Parse(text, now).Tokens
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokens = %+v; want %+v", got, want)
+	}
+	for _, token := range got {
+		if text[token.Start:token.End] != token.Text {
+			t.Errorf("token %+v does not match its offsets", token)
+		}
+	}
+
+	// Offsets are in bytes, and trailing punctuation is part of the token
+	text = "Café à emporter demain, tomorrow, 9 pm"
+	want = []Token{
+		{Kind: KindDate, Text: "tomorrow,", Start: 26, End: 35},
+		{Kind: KindTime, Text: "9 pm", Start: 36, End: 40},
+	}
+	if got := Parse(text, now).Tokens; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokens of %q = %+v; want %+v", text, got, want)
+	}
+	if got := Parse("Call Ann", now).Tokens; got != nil {
+		t.Errorf("Tokens without anything recognized = %+v; want none", got)
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone Europe/Berlin is not available: %v", err)
+	}
+
+	// Daylight saving time ends on Sunday 25 October 2026, the wall clock time is kept
+	saturday := time.Date(2026, 10, 24, 12, 0, 0, 0, berlin)
+	tests := []struct {
+		text    string
+		want    time.Time
+		hasTime bool
+	}{
+		{text: "Run tomorrow 9am", want: time.Date(2026, 10, 25, 9, 0, 0, 0, berlin), hasTime: true},
+		{text: "Run in 2 days", want: time.Date(2026, 10, 26, 0, 0, 0, 0, berlin)},
+		{text: "Run 11am", want: time.Date(2026, 10, 25, 11, 0, 0, 0, berlin), hasTime: true},
+	}
+	for _, tt := range tests {
+		got := Parse(tt.text, saturday)
+		if got.Due == nil || !got.Due.Equal(tt.want) || got.Due.Location() != berlin || got.HasTime != tt.hasTime {
+			t.Errorf("Parse(%q) due = %v, %v; want %v, %v", tt.text, got.Due, got.HasTime, tt.want, tt.hasTime)
+		}
+	}
+	if due := Parse("Run tomorrow 9am", saturday).Due.UTC(); due.Hour() != 8 {
+		t.Errorf("9am on 25 October in Berlin = %v UTC; want 08:00 UTC", due)
+	}
+}
+
+// formatDue formats the due date of a result like the test table, or "" without one
+func formatDue(r Result) string {
+	if r.Due == nil {
+		return ""
+	}
+	if r.HasTime {
+		return r.Due.Format("2006-01-02 15:04")
+	}
+	if h, m, s := r.Due.Clock(); h != 0 || m != 0 || s != 0 {
+		return "not midnight: " + r.Due.String()
+	}
+	return r.Due.Format("2006-01-02")
+}
@@ -0,0 +1,151 @@
+// Package rank generates lexicographic sort keys for manually ordered lists.
+//
+// A key is a base-36 fraction written with the digits 0-9 and a-z, compared
+// byte-wise (use COLLATE "C" in Postgres). A key can always be generated between
+// two existing keys, so moving an item only rewrites that item. Keys grow when
+// items are repeatedly inserted at the same spot; Spread generates fresh, short
+// keys to rebalance a list.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+// digits is the alphabet of a key, in ascending order
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// base is the number of digits in the alphabet
+const base = len(digits)
+
+var (
+	// ErrInvalidKey is returned for keys that are empty, end in '0' or contain foreign characters
+	ErrInvalidKey = errors.New("invalid rank key")
+
+	// ErrInvalidRange is returned when the lower key does not sort before the upper key
+	ErrInvalidRange = errors.New("invalid rank range: lower key must sort before upper key")
+)
+
+// Between returns a key that sorts strictly between lower and upper
+// An empty lower means the start of the list, an empty upper means the end of the list
+func Between(lower, upper string) (string, error) {
+	if lower != "" && !Valid(lower) {
+		return "", ErrInvalidKey
+	}
+	if upper != "" && !Valid(upper) {
+		return "", ErrInvalidKey
+	}
+	if lower != "" && upper != "" && lower >= upper {
+		return "", ErrInvalidRange
+	}
+	return midpoint(lower, upper), nil
+}
+
+// After returns a key that sorts after key
+func After(key string) (string, error) {
+	return Between(key, "")
+}
+
+// Before returns a key that sorts before key
+func Before(key string) (string, error) {
+	return Between("", key)
+}
+
+// Valid reports whether key is a well-formed rank key
+// Keys never end in '0', which guarantees there is always room before them
+func Valid(key string) bool {
+	if key == "" || key[len(key)-1] == '0' {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Spread returns n ascending keys spaced evenly over the whole key range
+// Keys are one digit longer than needed, leaving room for later moves
+func Spread(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	// Find the shortest length with room for n keys and a spare digit between them
+	length, capacity := 1, uint64(base)
+	for capacity/uint64(base) <= uint64(n) {
+		length++
+		capacity *= uint64(base)
+	}
+
+	step := capacity / uint64(n+1)
+	keys := make([]string, n)
+	buf := make([]byte, length)
+	for i := range keys {
+		value := step * uint64(i+1)
+		for j := length - 1; j >= 0; j-- {
+			buf[j] = digits[value%uint64(base)]
+			value /= uint64(base)
+		}
+		// Trailing zeros do not change the value of a fraction
+		keys[i] = strings.TrimRight(string(buf), "0")
+	}
+	return keys
+}
+
+// midpoint returns a key between lower and upper, treating both as base-36 fractions
+// lower may be empty (zero) and upper may be empty (one); lower < upper is assumed
+func midpoint(lower, upper string) string {
+	if upper != "" {
+		// Keep the common prefix, padding lower with zeros
+		n := 0
+		for n < len(upper) && digitAt(lower, n) == upper[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(lower) {
+				rest = lower[n:]
+			}
+			return upper[:n] + midpoint(rest, upper[n:])
+		}
+	}
+
+	lo := strings.IndexByte(digits, digitAt(lower, 0))
+	hi := base
+	if upper != "" {
+		hi = strings.IndexByte(digits, upper[0])
+	}
+
+	// Appending takes the next digit instead of halving the gap, so keys grow slowly
+	// when items are added to the end of a list one after another
+	if upper == "" && lower != "" && hi-lo > 1 {
+		return string(digits[lo+1])
+	}
+
+	// There is a free digit between the first digits
+	if hi-lo > 1 {
+		return string(digits[(lo+hi)/2])
+	}
+
+	// The first digits are adjacent; the first digit of upper alone still sorts above lower
+	if len(upper) > 1 {
+		return upper[:1]
+	}
+
+	// Keep the first digit of lower and look for room after it
+	rest := ""
+	if len(lower) > 1 {
+		rest = lower[1:]
+	}
+	return string(digits[lo]) + midpoint(rest, "")
+}
+
+// digitAt returns the digit of key at position i, or '0' past its end
+func digitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return digits[0]
+}
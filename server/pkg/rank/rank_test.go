@@ -0,0 +1,179 @@
+package rank
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		lower, upper string
+		want         string
+		wantErr      error
+	}{
+		{lower: "", upper: "", want: "i"},
+		{lower: "", upper: "i", want: "9"},
+		{lower: "i", upper: "", want: "j"},
+		{lower: "z", upper: "", want: "zi"},
+		{lower: "1", upper: "2", want: "1i"},
+		{lower: "1", upper: "3", want: "2"},
+		{lower: "a", upper: "a1", want: "a0i"},
+		{lower: "ab", upper: "b", want: "ac"},
+		{lower: "", upper: "1", want: "0i"},
+		{lower: "", upper: "01", want: "00i"},
+		{lower: "b", upper: "a", wantErr: ErrInvalidRange},
+		{lower: "a", upper: "a", wantErr: ErrInvalidRange},
+		{lower: "a0", upper: "", wantErr: ErrInvalidKey},
+		{lower: "", upper: "A", wantErr: ErrInvalidKey},
+		{lower: "a-b", upper: "", wantErr: ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lower+"_"+tt.upper, func(t *testing.T) {
+			got, err := Between(tt.lower, tt.upper)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Between(%q, %q) error = %v; want %v", tt.lower, tt.upper, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Between(%q, %q): %v", tt.lower, tt.upper, err)
+			}
+			if got != tt.want {
+				t.Errorf("Between(%q, %q) = %q; want %q", tt.lower, tt.upper, got, tt.want)
+			}
+			checkBetween(t, tt.lower, tt.upper, got)
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := map[string]bool{
+		"a": true, "0a": true, "zz": true, "09az": true,
+		"": false, "0": false, "a0": false, "A": false, "a b": false, "é": false,
+	}
+	for key, want := range tests {
+		if got := Valid(key); got != want {
+			t.Errorf("Valid(%q) = %v; want %v", key, got, want)
+		}
+	}
+}
+
+func TestAfterBefore(t *testing.T) {
+	// Appending one after another takes the next digit, so keys only grow every 17 appends
+	key := ""
+	for i := 0; i < 100; i++ {
+		next, err := After(key)
+		if err != nil {
+			t.Fatalf("After(%q): %v", key, err)
+		}
+		if key != "" && next <= key {
+			t.Fatalf("After(%q) = %q; want a greater key", key, next)
+		}
+		key = next
+	}
+	if len(key) > 6 {
+		t.Errorf("key after 100 appends = %q; want at most 6 digits", key)
+	}
+
+	key = "i"
+	for i := 0; i < 100; i++ {
+		next, err := Before(key)
+		if err != nil {
+			t.Fatalf("Before(%q): %v", key, err)
+		}
+		checkBetween(t, "", key, next)
+		key = next
+	}
+}
+
+func TestRepeatedInsertsIntoSameGap(t *testing.T) {
+	lower, upper := "a", "b"
+	for i := 0; i < 200; i++ {
+		key, err := Between(lower, upper)
+		if err != nil {
+			t.Fatalf("Between(%q, %q): %v", lower, upper, err)
+		}
+		checkBetween(t, lower, upper, key)
+		if i%2 == 0 {
+			lower = key
+		} else {
+			upper = key
+		}
+	}
+}
+
+func TestRandomMovesKeepOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	keys := Spread(20)
+	for i := 0; i < 1000; i++ {
+		// Move a random item to a random gap of the remaining list
+		from := rng.Intn(len(keys))
+		keys = append(keys[:from], keys[from+1:]...)
+		to := rng.Intn(len(keys) + 1)
+		lower, upper := "", ""
+		if to > 0 {
+			lower = keys[to-1]
+		}
+		if to < len(keys) {
+			upper = keys[to]
+		}
+		key, err := Between(lower, upper)
+		if err != nil {
+			t.Fatalf("Between(%q, %q): %v", lower, upper, err)
+		}
+		checkBetween(t, lower, upper, key)
+		keys = append(keys[:to], append([]string{key}, keys[to:]...)...)
+	}
+	if !sort.StringsAreSorted(keys) {
+		t.Fatalf("keys are not sorted: %q", keys)
+	}
+}
+
+func TestSpread(t *testing.T) {
+	tests := []struct {
+		n          int
+		wantLength int
+	}{
+		{n: 0, wantLength: 0},
+		{n: 1, wantLength: 2},
+		{n: 35, wantLength: 2},
+		{n: 36, wantLength: 3},
+		{n: 1000, wantLength: 3},
+		{n: 50000, wantLength: 5},
+	}
+	for _, tt := range tests {
+		keys := Spread(tt.n)
+		if len(keys) != tt.n {
+			t.Fatalf("Spread(%d) returned %d keys", tt.n, len(keys))
+		}
+		longest := 0
+		for i, key := range keys {
+			if !Valid(key) {
+				t.Fatalf("Spread(%d)[%d] = %q is not a valid key", tt.n, i, key)
+			}
+			if i > 0 && keys[i-1] >= key {
+				t.Fatalf("Spread(%d) is not strictly ascending at %d: %q >= %q", tt.n, i, keys[i-1], key)
+			}
+			if len(key) > longest {
+				longest = len(key)
+			}
+		}
+		if longest > tt.wantLength {
+			t.Errorf("Spread(%d) has keys of %d digits; want at most %d", tt.n, longest, tt.wantLength)
+		}
+	}
+}
+
+// checkBetween fails the test unless key is a valid key strictly between lower and upper
+func checkBetween(t *testing.T, lower, upper, key string) {
+	t.Helper()
+	if !Valid(key) {
+		t.Fatalf("key %q between %q and %q is not valid", key, lower, upper)
+	}
+	if (lower != "" && key <= lower) || (upper != "" && key >= upper) {
+		t.Fatalf("key %q is not between %q and %q", key, lower, upper)
+	}
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoragePath(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStorage(root)
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "sha256/ab/cd/abcd", want: "sha256/ab/cd/abcd"},
+		{key: "/leading/slash", want: "leading/slash"},
+		{key: "a//b/./c", want: "a/b/c"},
+		{key: "", wantErr: true},
+		{key: "/", wantErr: true},
+		{key: ".", wantErr: true},
+		{key: "..", wantErr: true},
+		{key: "../outside", wantErr: true},
+		{key: "a/../../outside", wantErr: true},
+		{key: "a/..", wantErr: true},
+		{key: "name..txt", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := store.path(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("path(%q) = %q; want an error", tt.key, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("path(%q): %v", tt.key, err)
+			}
+			if want := filepath.Join(root, filepath.FromSlash(tt.want)); got != want {
+				t.Errorf("path(%q) = %q; want %q", tt.key, got, want)
+			}
+		})
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	store, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	key := "sha256/ab/cd/blob"
+
+	if _, err := store.Open(key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Open before Put error = %v; want ErrNotFound", err)
+	}
+	if err := store.Put(key, strings.NewReader("0123456789"), 10, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if exists, err := store.Exists(key); err != nil || !exists {
+		t.Fatalf("Exists = %v, %v; want true, nil", exists, err)
+	}
+
+	object, err := store.Open(key)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if object.Size() != 10 {
+		t.Errorf("Size = %d; want 10", object.Size())
+	}
+	if _, err := object.Seek(6, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	if got, err := io.ReadAll(object); err != nil || string(got) != "6789" {
+		t.Fatalf("ReadAll after Seek = %q, %v; want %q", got, err, "6789")
+	}
+	object.Close()
+
+	// A size mismatch leaves neither the blob nor a temporary file behind
+	if err := store.Put("short", strings.NewReader("abc"), 4, ""); err == nil {
+		t.Fatal("Put with a wrong size error = nil; want an error")
+	}
+	if exists, _ := store.Exists("short"); exists {
+		t.Error("blob with a wrong size was stored")
+	}
+	entries, err := os.ReadDir(store.root)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".upload-") {
+			t.Errorf("temporary file %s was left behind", entry.Name())
+		}
+	}
+
+	if err := store.Delete(key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if exists, err := store.Exists(key); err != nil || exists {
+		t.Fatalf("Exists after Delete = %v, %v; want false, nil", exists, err)
+	}
+	if err := store.Delete(key); err != nil {
+		t.Fatalf("Delete of a missing key: %v", err)
+	}
+	if err := store.Put("../escape", strings.NewReader("x"), 1, ""); err == nil {
+		t.Fatal("Put outside the root error = nil; want an error")
+	}
+}
@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is a minimal S3-compatible server keeping objects in memory
+// It checks every request's signature by signing a copy of it with the same credentials
+type fakeS3 struct {
+	t      *testing.T
+	bucket string
+	signer *S3Storage
+
+	mu          sync.Mutex
+	objects     map[string][]byte
+	ignoreRange bool
+	gets        []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.checkSignature(r)
+
+	prefix := "/" + f.bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	content, ok := f.objects[key]
+	switch r.Method {
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.ContentLength != int64(len(body)) {
+			http.Error(w, "IncompleteBody", http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = body
+	case http.MethodHead:
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+	case http.MethodGet:
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		rangeHeader := r.Header.Get("Range")
+		f.gets = append(f.gets, rangeHeader)
+		if rangeHeader == "" || f.ignoreRange {
+			w.Write(content)
+			return
+		}
+		start, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rangeHeader, "bytes="), "-"))
+		if err != nil || start >= len(content) {
+			http.Error(w, "InvalidRange", http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Header().Set("Content-Range", "bytes "+strconv.Itoa(start)+"-"+strconv.Itoa(len(content)-1)+"/"+strconv.Itoa(len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(content[start:])
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// checkSignature re-signs the received request and compares the Authorization headers
+func (f *fakeS3) checkSignature(r *http.Request) {
+	f.t.Helper()
+	signed, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		f.t.Errorf("%s %s: invalid X-Amz-Date %q", r.Method, r.URL.Path, r.Header.Get("X-Amz-Date"))
+		return
+	}
+
+	got := r.Header.Get("Authorization")
+	check := r.Clone(r.Context())
+	check.URL.Host = r.Host
+	check.Header.Del("Authorization")
+	f.signer.sign(check, signed)
+	if want := check.Header.Get("Authorization"); got != want {
+		f.t.Errorf("%s %s: signature mismatch\n got: %s\nwant: %s", r.Method, r.URL.Path, got, want)
+	}
+	if r.Header.Get("Range") != "" && !strings.Contains(got, "range") {
+		f.t.Errorf("%s %s: Range header is not signed: %s", r.Method, r.URL.Path, got)
+	}
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *S3Storage) {
+	t.Helper()
+	fake := &fakeS3{t: t, bucket: "attachments", objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	config := S3Config{
+		Endpoint:     server.URL,
+		Region:       "eu-test-1",
+		Bucket:       fake.bucket,
+		AccessKey:    "AKIDEXAMPLE",
+		SecretKey:    "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		UsePathStyle: true,
+		HTTPClient:   server.Client(),
+	}
+	store, err := NewS3Storage(config)
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	fake.signer = store
+	return fake, store
+}
+
+func TestS3Storage(t *testing.T) {
+	fake, store := newFakeS3(t)
+	key := "sha256/ab/cd/file name+1.txt"
+	content := []byte("hello, object storage")
+
+	if exists, err := store.Exists(key); err != nil || exists {
+		t.Fatalf("Exists before Put = %v, %v; want false, nil", exists, err)
+	}
+	if _, err := store.Open(key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Open before Put error = %v; want ErrNotFound", err)
+	}
+
+	if err := store.Put(key, bytes.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := fake.objects[key]; !bytes.Equal(got, content) {
+		t.Fatalf("stored %q; want %q", got, content)
+	}
+	if exists, err := store.Exists(key); err != nil || !exists {
+		t.Fatalf("Exists after Put = %v, %v; want true, nil", exists, err)
+	}
+
+	object, err := store.Open(key)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer object.Close()
+	if object.Size() != int64(len(content)) {
+		t.Errorf("Size = %d; want %d", object.Size(), len(content))
+	}
+	if want := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC); !object.ModTime().Equal(want) {
+		t.Errorf("ModTime = %v; want %v", object.ModTime(), want)
+	}
+	if got, err := io.ReadAll(object); err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("ReadAll = %q, %v; want %q", got, err, content)
+	}
+
+	// Seeking starts a new ranged request from the offset
+	if _, err := object.Seek(7, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	if got, err := io.ReadAll(object); err != nil || string(got) != "object storage" {
+		t.Fatalf("ReadAll after Seek = %q, %v; want %q", got, err, "object storage")
+	}
+	if _, err := object.Seek(-7, io.SeekEnd); err != nil {
+		t.Fatalf("Seek from end: %v", err)
+	}
+	if got, err := io.ReadAll(object); err != nil || string(got) != "storage" {
+		t.Fatalf("ReadAll after Seek from end = %q, %v; want %q", got, err, "storage")
+	}
+	if want := []string{"bytes=0-", "bytes=7-", "bytes=14-"}; strings.Join(fake.gets, " ") != strings.Join(want, " ") {
+		t.Errorf("GET ranges = %q; want %q", fake.gets, want)
+	}
+
+	if err := store.Delete(key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if exists, err := store.Exists(key); err != nil || exists {
+		t.Fatalf("Exists after Delete = %v, %v; want false, nil", exists, err)
+	}
+	if err := store.Delete(key); err != nil {
+		t.Fatalf("Delete of a missing key: %v", err)
+	}
+}
+
+func TestS3StorageIgnoredRange(t *testing.T) {
+	fake, store := newFakeS3(t)
+	fake.ignoreRange = true
+	if err := store.Put("blob", strings.NewReader("0123456789"), 10, ""); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	object, err := store.Open("blob")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer object.Close()
+	if _, err := object.Seek(4, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	if got, err := io.ReadAll(object); err != nil || string(got) != "456789" {
+		t.Fatalf("ReadAll = %q, %v; want %q", got, err, "456789")
+	}
+}
+
+func TestS3StorageErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	store, err := NewS3Storage(S3Config{Endpoint: server.URL, Bucket: "b", UsePathStyle: true})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	if err := store.Put("key", strings.NewReader("x"), 1, ""); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Put error = %v; want a 403 error", err)
+	}
+	if _, err := store.Exists("key"); err == nil {
+		t.Error("Exists error = nil; want a 403 error")
+	}
+	if err := store.Delete("key"); err == nil {
+		t.Error("Delete error = nil; want a 403 error")
+	}
+	if err := store.Put("", strings.NewReader("x"), 1, ""); err == nil {
+		t.Error("Put with an empty key error = nil; want an error")
+	}
+}
+
+func TestS3ObjectURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoint  string
+		pathStyle bool
+		key       string
+		want      string
+	}{
+		{"path style", "http://localhost:9000", true, "a/b.txt", "http://localhost:9000/bucket/a/b.txt"},
+		{"virtual hosted", "https://s3.amazonaws.com", false, "a/b.txt", "https://bucket.s3.amazonaws.com/a/b.txt"},
+		{"endpoint path", "http://localhost:9000/s3/", true, "/a", "http://localhost:9000/s3/bucket/a"},
+		{"encoded segments", "http://localhost:9000", true, "a b/c+d", "http://localhost:9000/bucket/a%20b/c%2Bd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewS3Storage(S3Config{Endpoint: tt.endpoint, Bucket: "bucket", UsePathStyle: tt.pathStyle})
+			if err != nil {
+				t.Fatalf("NewS3Storage: %v", err)
+			}
+			if got := store.objectURL(tt.key).String(); got != tt.want {
+				t.Errorf("objectURL(%q) = %s; want %s", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewS3StorageValidation(t *testing.T) {
+	tests := []S3Config{
+		{Bucket: "b"},
+		{Endpoint: "http://localhost:9000"},
+		{Endpoint: "localhost:9000", Bucket: "b"},
+	}
+	for _, config := range tests {
+		if _, err := NewS3Storage(config); err == nil {
+			t.Errorf("NewS3Storage(%+v) error = nil; want an error", config)
+		}
+	}
+}
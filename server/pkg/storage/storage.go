@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when a blob does not exist in the storage
+var ErrNotFound = errors.New("blob not found")
+
+// Storage defines the interface for blob storage backends
+// Keys are opaque slash-separated paths chosen by the caller
+type Storage interface {
+	// Put stores the content of r under key, replacing any existing blob
+	Put(key string, r io.Reader, size int64, contentType string) error
+
+	// Open returns a seekable reader for the blob stored under key
+	Open(key string) (Object, error)
+
+	// Exists reports whether a blob is stored under key
+	Exists(key string) (bool, error)
+
+	// Delete removes the blob stored under key, it is not an error if it does not exist
+	Delete(key string) error
+}
+
+// Object is a stored blob opened for reading
+// Seeking is supported so that HTTP range requests can be served
+type Object interface {
+	io.ReadSeekCloser
+
+	// Size returns the size of the blob in bytes
+	Size() int64
+
+	// ModTime returns the time the blob was last written
+	ModTime() time.Time
+}
+
+// Config holds the configuration used to create a storage backend
+type Config struct {
+	Driver    string // "local" or "s3"
+	LocalPath string
+
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UsePathStyle bool
+}
+
+// New creates the storage backend selected by the configuration
+func New(config Config) (Storage, error) {
+	switch config.Driver {
+	case "", "local":
+		return NewLocalStorage(config.LocalPath)
+	case "s3":
+		return NewS3Storage(S3Config{
+			Endpoint:     config.S3Endpoint,
+			Region:       config.S3Region,
+			Bucket:       config.S3Bucket,
+			AccessKey:    config.S3AccessKey,
+			SecretKey:    config.S3SecretKey,
+			UsePathStyle: config.S3UsePathStyle,
+		})
+	default:
+		return nil, errors.New("unsupported storage driver: " + config.Driver)
+	}
+}
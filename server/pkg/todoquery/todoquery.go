@@ -0,0 +1,581 @@
+// Package todoquery parses the todo filter language into a syntax tree.
+//
+// A query is a list of terms that must all match:
+//
+//	priority:high,medium category:Work due:<7d -completed "exact phrase" created:>2026-01-01 OR tag:urgent
+//
+// Terms are field:value conditions, flags such as completed or overdue, and
+// words or "quoted phrases" searched for in the title. Values of a field are
+// separated by commas and match any of them; values with spaces are quoted.
+// Date fields take a date (2026-01-01), today, tomorrow, yesterday or a
+// number of days, weeks, months or years from today (7d, -2w, 1m, 1y), each
+// standing for a whole day in UTC, compared with <, <=, >, >= or matched
+// exactly. OR binds more loosely than the implicit AND, AND and NOT may be
+// written out, a leading - negates a term and parentheses group terms.
+package todoquery
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLength is the longest query accepted
+const MaxLength = 1000
+
+// maxDepth is the deepest nesting of parentheses and negations accepted
+const maxDepth = 20
+
+// Fields
+const (
+	FieldText     = "text"
+	FieldIs       = "is"
+	FieldPriority = "priority"
+	FieldCategory = "category"
+	FieldTag      = "tag"
+	FieldStatus   = "status"
+	FieldDue      = "due"
+	FieldCreated  = "created"
+	FieldUpdated  = "updated"
+	FieldDone     = "completed"
+)
+
+// Flags of the is field, also written as bare words
+const (
+	FlagCompleted = "completed"
+	FlagOpen      = "open"
+	FlagArchived  = "archived"
+	FlagBlocked   = "blocked"
+	FlagOverdue   = "overdue"
+)
+
+// Comparison operators of date terms, OpEqual also stands for list membership
+const (
+	OpEqual = "="
+	OpLess  = "<"
+	OpLE    = "<="
+	OpMore  = ">"
+	OpGE    = ">="
+)
+
+// Values of the due field matching todos without or with a due date, and of category and tag
+// matching todos without one
+const (
+	ValueNone = "none"
+	ValueAny  = "any"
+)
+
+// fieldAliases maps the field names of the language to fields
+var fieldAliases = map[string]string{
+	"is":       FieldIs,
+	"priority": FieldPriority, "p": FieldPriority,
+	"category": FieldCategory, "cat": FieldCategory,
+	"tag": FieldTag, "tags": FieldTag,
+	"status":  FieldStatus,
+	"title":   FieldText,
+	"due":     FieldDue,
+	"created": FieldCreated,
+	"updated": FieldUpdated,
+	// completed:<date> filters by completion time, completed:true and completed:false by state
+	"completed": FieldDone,
+}
+
+var flags = map[string]bool{
+	FlagCompleted: true, FlagOpen: true, FlagArchived: true, FlagBlocked: true, FlagOverdue: true,
+}
+
+var priorities = map[string]bool{"low": true, "medium": true, "high": true}
+
+var relativeDatePattern = regexp.MustCompile(`^([+-]?\d{1,4})([dwmy])$`)
+
+// Node is a node of the syntax tree
+type Node interface {
+	// Pos returns the 1-based character position of the node in the query
+	Pos() int
+}
+
+// And matches todos matching all of its nodes
+type And struct {
+	Nodes    []Node
+	Position int
+}
+
+// Or matches todos matching any of its nodes
+type Or struct {
+	Nodes    []Node
+	Position int
+}
+
+// Not matches todos not matching its node
+type Not struct {
+	Node     Node
+	Position int
+}
+
+// Term is a condition on a field
+// List fields have lower-cased Values; date fields have a day range [From, To) in UTC,
+// or ValueNone or ValueAny as their only value
+type Term struct {
+	Field    string
+	Op       string
+	Values   []string
+	From     time.Time
+	To       time.Time
+	Position int
+}
+
+func (n *And) Pos() int  { return n.Position }
+func (n *Or) Pos() int   { return n.Position }
+func (n *Not) Pos() int  { return n.Position }
+func (n *Term) Pos() int { return n.Position }
+
+// IsDate reports whether the term compares dates
+func (t *Term) IsDate() bool {
+	return len(t.Values) == 0
+}
+
+// Error is a syntax or value error at a position of the query
+type Error struct {
+	Position int
+	Message  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid query at position %d: %s", e.Position, e.Message)
+}
+
+// Parse parses a query, resolving relative dates against now
+// An empty query returns a nil node, which matches all todos
+func Parse(query string, now time.Time) (Node, error) {
+	if len(query) > MaxLength {
+		return nil, &Error{Position: 1, Message: fmt.Sprintf("query cannot exceed %d characters", MaxLength)}
+	}
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{query: query, tokens: tokens, now: now.UTC()}
+	if p.peek().kind == tokenEOF {
+		return nil, nil
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok.describe())
+	}
+	return node, nil
+}
+
+// Mentions reports whether a node or any node below it is a term of the field with the value
+func Mentions(node Node, field, value string) bool {
+	switch n := node.(type) {
+	case *And:
+		for _, child := range n.Nodes {
+			if Mentions(child, field, value) {
+				return true
+			}
+		}
+	case *Or:
+		for _, child := range n.Nodes {
+			if Mentions(child, field, value) {
+				return true
+			}
+		}
+	case *Not:
+		return Mentions(n.Node, field, value)
+	case *Term:
+		if n.Field != field {
+			return false
+		}
+		for _, v := range n.Values {
+			if v == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Token kinds
+const (
+	tokenEOF = iota
+	tokenWord
+	tokenPhrase
+	tokenLParen
+	tokenRParen
+	tokenMinus
+)
+
+// token is a lexical token with its byte offset
+type token struct {
+	kind   int
+	text   string
+	offset int
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenLParen, tokenRParen, tokenMinus:
+		return `"` + t.text + `"`
+	default:
+		return strconv.Quote(t.text)
+	}
+}
+
+// lex splits a query into tokens
+// Words run until whitespace or a parenthesis, keeping quoted parts such as category:"Home Office" together
+func lex(query string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(query); {
+		r, size := utf8.DecodeRuneInString(query[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(' || r == ')':
+			kind := tokenLParen
+			if r == ')' {
+				kind = tokenRParen
+			}
+			tokens = append(tokens, token{kind: kind, text: string(r), offset: i})
+			i++
+		case r == '-':
+			if i+1 == len(query) || unicode.IsSpace(rune(query[i+1])) || query[i+1] == ')' {
+				return nil, &Error{Position: position(query, i), Message: `expected a term after "-"`}
+			}
+			tokens = append(tokens, token{kind: tokenMinus, text: "-", offset: i})
+			i++
+		case r == '"':
+			end := strings.IndexByte(query[i+1:], '"')
+			if end < 0 {
+				return nil, &Error{Position: position(query, i), Message: "unclosed quote"}
+			}
+			tokens = append(tokens, token{kind: tokenPhrase, text: query[i+1 : i+1+end], offset: i})
+			i += end + 2
+		default:
+			start := i
+			for i < len(query) {
+				r, size := utf8.DecodeRuneInString(query[i:])
+				if unicode.IsSpace(r) || r == '(' || r == ')' {
+					break
+				}
+				if r == '"' {
+					end := strings.IndexByte(query[i+1:], '"')
+					if end < 0 {
+						return nil, &Error{Position: position(query, i), Message: "unclosed quote"}
+					}
+					i += end + 2
+					continue
+				}
+				i += size
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: query[start:i], offset: start})
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, offset: len(query)}), nil
+}
+
+// position converts a byte offset into a 1-based character position
+func position(query string, offset int) int {
+	return utf8.RuneCountInString(query[:offset]) + 1
+}
+
+// parser is a recursive descent parser over the tokens of a query
+type parser struct {
+	query  string
+	tokens []token
+	next   int
+	depth  int
+	now    time.Time
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEOF {
+		p.next++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return &Error{Position: position(p.query, tok.offset), Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) isKeyword(tok token, keyword string) bool {
+	return tok.kind == tokenWord && tok.text == keyword
+}
+
+// parseOr parses terms separated by OR
+func (p *parser) parseOr() (Node, error) {
+	first := p.peek()
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []Node{node}
+	for p.isKeyword(p.peek(), "OR") {
+		p.advance()
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 1 {
+		return node, nil
+	}
+	return &Or{Nodes: nodes, Position: position(p.query, first.offset)}, nil
+}
+
+// parseAnd parses terms following each other, optionally separated by AND
+func (p *parser) parseAnd() (Node, error) {
+	first := p.peek()
+	var nodes []Node
+	for {
+		tok := p.peek()
+		if tok.kind == tokenEOF || tok.kind == tokenRParen || p.isKeyword(tok, "OR") {
+			break
+		}
+		if p.isKeyword(tok, "AND") {
+			if len(nodes) == 0 {
+				return nil, p.errorf(tok, "expected a term before AND")
+			}
+			p.advance()
+			if next := p.peek(); next.kind == tokenEOF || next.kind == tokenRParen || p.isKeyword(next, "OR") || p.isKeyword(next, "AND") {
+				return nil, p.errorf(next, "expected a term after AND, found %s", next.describe())
+			}
+			continue
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	switch len(nodes) {
+	case 0:
+		tok := p.peek()
+		return nil, p.errorf(tok, "expected a term, found %s", tok.describe())
+	case 1:
+		return nodes[0], nil
+	}
+	return &And{Nodes: nodes, Position: position(p.query, first.offset)}, nil
+}
+
+// parseUnary parses a negated term, a group in parentheses or a term
+func (p *parser) parseUnary() (Node, error) {
+	tok := p.peek()
+	if tok.kind == tokenMinus || p.isKeyword(tok, "NOT") || tok.kind == tokenLParen {
+		if p.depth >= maxDepth {
+			return nil, p.errorf(tok, "query is nested too deeply")
+		}
+		p.depth++
+		defer func() { p.depth-- }()
+	}
+
+	switch {
+	case tok.kind == tokenMinus || p.isKeyword(tok, "NOT"):
+		p.advance()
+		if next := p.peek(); next.kind == tokenEOF || next.kind == tokenRParen || p.isKeyword(next, "OR") || p.isKeyword(next, "AND") {
+			return nil, p.errorf(next, "expected a term after %s, found %s", tok.describe(), next.describe())
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Node: node, Position: position(p.query, tok.offset)}, nil
+
+	case tok.kind == tokenLParen:
+		p.advance()
+		if p.peek().kind == tokenRParen {
+			return nil, p.errorf(p.peek(), "empty parentheses")
+		}
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenRParen {
+			return nil, p.errorf(tok, "missing closing parenthesis")
+		}
+		p.advance()
+		return node, nil
+
+	case tok.kind == tokenPhrase:
+		p.advance()
+		return &Term{Field: FieldText, Op: OpEqual, Values: []string{strings.ToLower(tok.text)}, Position: position(p.query, tok.offset)}, nil
+
+	case tok.kind == tokenWord:
+		p.advance()
+		return p.parseTerm(tok)
+	}
+	return nil, p.errorf(tok, "unexpected %s", tok.describe())
+}
+
+// parseTerm parses a word: a field:value condition, a flag or a word of the title
+func (p *parser) parseTerm(tok token) (Node, error) {
+	pos := position(p.query, tok.offset)
+	name, value, isField := strings.Cut(tok.text, ":")
+	if !isField || strings.Contains(name, `"`) {
+		if word := strings.ToLower(tok.text); flags[word] {
+			return &Term{Field: FieldIs, Op: OpEqual, Values: []string{word}, Position: pos}, nil
+		}
+		return &Term{Field: FieldText, Op: OpEqual, Values: []string{strings.ToLower(unquote(tok.text))}, Position: pos}, nil
+	}
+
+	field, ok := fieldAliases[strings.ToLower(name)]
+	if !ok {
+		return nil, p.errorf(tok, "unknown field %q", name)
+	}
+	valueOffset := tok.offset + len(name) + 1
+	valueTok := token{kind: tokenWord, text: value, offset: valueOffset}
+	if value == "" {
+		return nil, p.errorf(valueTok, "missing value for %s:", name)
+	}
+
+	// completed:true and completed:false are the completed flag, other values are dates
+	if field == FieldDone {
+		switch strings.ToLower(value) {
+		case "true", "yes":
+			return &Term{Field: FieldIs, Op: OpEqual, Values: []string{FlagCompleted}, Position: pos}, nil
+		case "false", "no":
+			return &Term{Field: FieldIs, Op: OpEqual, Values: []string{FlagOpen}, Position: pos}, nil
+		}
+	}
+
+	switch field {
+	case FieldDue, FieldCreated, FieldUpdated, FieldDone:
+		return p.parseDateTerm(field, name, valueTok, pos)
+	}
+
+	if strings.ContainsAny(value[:1], "<>=") {
+		return nil, p.errorf(valueTok, "%s: does not support comparisons", name)
+	}
+	values, err := p.parseList(valueTok)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		switch field {
+		case FieldIs:
+			if !flags[v] {
+				return nil, p.errorf(valueTok, "unknown flag %q, must be completed, open, archived, blocked or overdue", v)
+			}
+		case FieldPriority:
+			if !priorities[v] {
+				return nil, p.errorf(valueTok, "invalid priority %q, must be low, medium or high", v)
+			}
+		case FieldStatus:
+			values[i] = strings.NewReplacer(" ", "_", "-", "_").Replace(v)
+		}
+	}
+	return &Term{Field: field, Op: OpEqual, Values: values, Position: pos}, nil
+}
+
+// parseList splits a value on commas outside of quotes, unquoting and lower-casing the parts
+func (p *parser) parseList(tok token) ([]string, error) {
+	var values []string
+	quoted := false
+	start := 0
+	for i := 0; i <= len(tok.text); i++ {
+		if i < len(tok.text) && tok.text[i] == '"' {
+			quoted = !quoted
+			continue
+		}
+		if i < len(tok.text) && (quoted || tok.text[i] != ',') {
+			continue
+		}
+		value := strings.TrimSpace(unquote(tok.text[start:i]))
+		if value == "" {
+			return nil, p.errorf(token{offset: tok.offset + start}, "empty value in list")
+		}
+		values = append(values, strings.ToLower(value))
+		start = i + 1
+	}
+	return values, nil
+}
+
+// parseDateTerm parses a date condition such as <7d, >=2026-01-01 or today
+func (p *parser) parseDateTerm(field, name string, tok token, pos int) (Node, error) {
+	op := OpEqual
+	value := tok.text
+	for _, candidate := range []string{OpLE, OpGE, OpLess, OpMore, OpEqual} {
+		if strings.HasPrefix(value, candidate) {
+			op, value = candidate, value[len(candidate):]
+			break
+		}
+	}
+	valueTok := token{offset: tok.offset + len(tok.text) - len(value)}
+	if value == "" {
+		return nil, p.errorf(valueTok, "missing date for %s:", name)
+	}
+	if strings.Contains(value, ",") {
+		return nil, p.errorf(valueTok, "%s: takes a single date", name)
+	}
+
+	lower := strings.ToLower(value)
+	if lower == ValueNone || lower == ValueAny {
+		if field != FieldDue && !(field == FieldDone && lower == ValueNone) {
+			return nil, p.errorf(valueTok, "%s: does not support %s", name, lower)
+		}
+		if op != OpEqual {
+			return nil, p.errorf(valueTok, "%s cannot be compared", lower)
+		}
+		return &Term{Field: field, Op: op, Values: []string{lower}, Position: pos}, nil
+	}
+
+	day, ok := ParseDay(lower, p.now)
+	if !ok {
+		return nil, p.errorf(valueTok, "invalid date %q, use YYYY-MM-DD, today, tomorrow, yesterday or an offset such as 7d, -2w, 1m or 1y", value)
+	}
+	return &Term{Field: field, Op: op, From: day, To: day.AddDate(0, 0, 1), Position: pos}, nil
+}
+
+// ParseDay returns the start of the day a date value stands for, in UTC
+// Values are dates (2026-01-01), today, tomorrow, yesterday or offsets from today such as 7d, -2w, 1m or 1y
+func ParseDay(value string, now time.Time) (time.Time, bool) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch strings.ToLower(value) {
+	case "today":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	}
+
+	if m := relativeDatePattern.FindStringSubmatch(strings.ToLower(value)); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "d":
+			return today.AddDate(0, 0, n), true
+		case "w":
+			return today.AddDate(0, 0, 7*n), true
+		case "m":
+			return today.AddDate(0, n, 0), true
+		default:
+			return today.AddDate(n, 0, 0), true
+		}
+	}
+
+	day, err := time.Parse("2006-01-02", value)
+	return day, err == nil
+}
+
+// unquote removes the double quotes of quoted parts of a value
+func unquote(s string) string {
+	return strings.ReplaceAll(s, `"`, "")
+}
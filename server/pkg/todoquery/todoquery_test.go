@@ -0,0 +1,275 @@
+package todoquery
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// now is Wednesday 14 October 2026, 10:00 UTC
+var now = time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "", want: ""},
+		{query: "   ", want: ""},
+
+		// Words, phrases and flags
+		{query: "milk", want: "text:milk"},
+		{query: "Milk Bread", want: "(AND text:milk text:bread)"},
+		{query: `"Exact Phrase"`, want: "text:exact phrase"},
+		{query: `title:"Buy milk"`, want: "text:buy milk"},
+		{query: `say"hi:there"`, want: "text:sayhi:there"},
+		{query: "e-mail", want: "text:e-mail"},
+		{query: "café", want: "text:café"},
+		{query: "completed", want: "is:completed"},
+		{query: "OVERDUE open", want: "(AND is:overdue is:open)"},
+
+		// List fields
+		{query: "is:blocked,archived", want: "is:blocked|archived"},
+		{query: "p:High,medium", want: "priority:high|medium"},
+		{query: `category:Work,"Home Office"`, want: "category:work|home office"},
+		{query: `cat:"a,b"`, want: "category:a,b"},
+		{query: "cat:none", want: "category:none"},
+		{query: "tags:urgent", want: "tag:urgent"},
+		{query: "status:In-Progress", want: "status:in_progress"},
+		{query: `status:"in review"`, want: "status:in_review"},
+		{query: "completed:true", want: "is:completed"},
+		{query: "completed:No", want: "is:open"},
+
+		// Date fields
+		{query: "due:<7d", want: "due<2026-10-21"},
+		{query: "due:<=today", want: "due<=2026-10-14"},
+		{query: "created:>2026-01-01", want: "created>2026-01-01"},
+		{query: "updated:>=yesterday", want: "updated>=2026-10-13"},
+		{query: "due:tomorrow", want: "due=2026-10-15"},
+		{query: "due:=tomorrow", want: "due=2026-10-15"},
+		{query: "due:-2w", want: "due=2026-09-30"},
+		{query: "due:1m", want: "due=2026-11-14"},
+		{query: "due:1Y", want: "due=2027-10-14"},
+		{query: "due:none", want: "due:none"},
+		{query: "due:ANY", want: "due:any"},
+		{query: "completed:none", want: "completed:none"},
+		{query: "completed:<2026-10-01", want: "completed<2026-10-01"},
+
+		// Operators
+		{query: "a OR b c", want: "(OR text:a (AND text:b text:c))"},
+		{query: "a AND b", want: "(AND text:a text:b)"},
+		{query: "a or b", want: "(AND text:a text:or text:b)"},
+		{query: "-completed", want: "(NOT is:completed)"},
+		{query: "NOT NOT a", want: "(NOT (NOT text:a))"},
+		{query: "NOT (a OR b)", want: "(NOT (OR text:a text:b))"},
+		{query: "(a OR b) c", want: "(AND (OR text:a text:b) text:c)"},
+		{query: "((a))", want: "text:a"},
+		{
+			query: `priority:high,medium category:Work due:<7d -completed "exact phrase" created:>2026-01-01 OR tag:urgent`,
+			want:  "(OR (AND priority:high|medium category:work due<2026-10-21 (NOT is:completed) text:exact phrase created>2026-01-01) tag:urgent)",
+		},
+		{query: strings.Repeat("(", maxDepth) + "a" + strings.Repeat(")", maxDepth), want: "text:a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query, now)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.query, err)
+			}
+			if got := format(node); got != tt.want {
+				t.Errorf("Parse(%q) = %s; want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query    string
+		position int
+		message  string
+	}{
+		{query: `"unclosed`, position: 1, message: "unclosed quote"},
+		{query: `cat:"Home`, position: 5, message: "unclosed quote"},
+		{query: "a -", position: 3, message: `expected a term after "-"`},
+		{query: "- a", position: 1, message: `expected a term after "-"`},
+		{query: "(a", position: 1, message: "missing closing parenthesis"},
+		{query: "a)", position: 2, message: `unexpected ")"`},
+		{query: "()", position: 2, message: "empty parentheses"},
+		{query: "AND a", position: 1, message: "expected a term before AND"},
+		{query: "a AND", position: 6, message: "expected a term after AND, found end of query"},
+		{query: "a AND OR b", position: 7, message: `expected a term after AND, found "OR"`},
+		{query: "a OR", position: 5, message: "expected a term, found end of query"},
+		{query: "OR a", position: 1, message: `expected a term, found "OR"`},
+		{query: "NOT", position: 4, message: `expected a term after "NOT", found end of query`},
+		{query: "foo:bar", position: 1, message: `unknown field "foo"`},
+		{query: "meet 12:30", position: 6, message: `unknown field "12"`},
+		{query: "é foo:bar", position: 3, message: `unknown field "foo"`},
+		{query: "priority:urgent", position: 10, message: `invalid priority "urgent"`},
+		{query: "is:done", position: 4, message: `unknown flag "done"`},
+		{query: "tag:", position: 5, message: "missing value for tag:"},
+		{query: "tag:a,,b", position: 7, message: "empty value in list"},
+		{query: "priority:>high", position: 10, message: "priority: does not support comparisons"},
+		{query: "due:<", position: 6, message: "missing date for due:"},
+		{query: "due:soon", position: 5, message: `invalid date "soon"`},
+		{query: "due:2026-13-01", position: 5, message: `invalid date "2026-13-01"`},
+		{query: "due:<=7x", position: 7, message: `invalid date "7x"`},
+		{query: "due:a,b", position: 5, message: "due: takes a single date"},
+		{query: "created:none", position: 9, message: "created: does not support none"},
+		{query: "completed:any", position: 11, message: "completed: does not support any"},
+		{query: "due:<none", position: 6, message: "none cannot be compared"},
+		{query: strings.Repeat("a ", MaxLength/2+1), position: 1, message: "query cannot exceed 1000 characters"},
+		{query: strings.Repeat("(", maxDepth+1) + "a" + strings.Repeat(")", maxDepth+1), position: maxDepth + 1, message: "query is nested too deeply"},
+		{query: strings.Repeat("-", maxDepth+1) + "a", position: maxDepth + 1, message: "query is nested too deeply"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query, now)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %s; want an error", tt.query, format(node))
+			}
+			var queryErr *Error
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("Parse(%q) error %v is not an *Error", tt.query, err)
+			}
+			if queryErr.Position != tt.position || !strings.HasPrefix(queryErr.Message, tt.message) {
+				t.Errorf("Parse(%q) error = %d %q; want %d %q", tt.query, queryErr.Position, queryErr.Message, tt.position, tt.message)
+			}
+			if !strings.HasPrefix(err.Error(), "invalid query at position ") {
+				t.Errorf("Error() = %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	node, err := Parse("é (a b) OR -due:<7d", now)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	or := node.(*Or)
+	and := or.Nodes[0].(*And)
+	group := and.Nodes[1].(*And)
+	not := or.Nodes[1].(*Not)
+	tests := []struct {
+		node Node
+		want int
+	}{
+		{node: or, want: 1},
+		{node: and, want: 1},
+		{node: and.Nodes[0], want: 1},
+		{node: group, want: 4},
+		{node: group.Nodes[1], want: 6},
+		{node: not, want: 12},
+		{node: not.Node, want: 13},
+	}
+	for _, tt := range tests {
+		if got := tt.node.Pos(); got != tt.want {
+			t.Errorf("%s position = %d; want %d", format(tt.node), got, tt.want)
+		}
+	}
+
+	// Date terms cover one whole day
+	due := not.Node.(*Term)
+	if !due.IsDate() || !due.To.Equal(due.From.AddDate(0, 0, 1)) {
+		t.Errorf("due term = %+v; want a day range", due)
+	}
+	if term := group.Nodes[0].(*Term); term.IsDate() {
+		t.Errorf("text term %+v is a date term", term)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	node, err := Parse("a OR -(is:completed p:high,low)", now)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	tests := []struct {
+		field, value string
+		want         bool
+	}{
+		{field: FieldIs, value: FlagCompleted, want: true},
+		{field: FieldPriority, value: "low", want: true},
+		{field: FieldText, value: "a", want: true},
+		{field: FieldIs, value: FlagArchived},
+		{field: FieldPriority, value: "medium"},
+		{field: FieldCategory, value: "a"},
+	}
+	for _, tt := range tests {
+		if got := Mentions(node, tt.field, tt.value); got != tt.want {
+			t.Errorf("Mentions(%s, %s) = %v; want %v", tt.field, tt.value, got, tt.want)
+		}
+	}
+	if Mentions(nil, FieldIs, FlagCompleted) {
+		t.Error("Mentions of an empty query = true; want false")
+	}
+}
+
+func TestParseDay(t *testing.T) {
+	// 23:30 on the 14th in UTC-5 is already the 15th in UTC
+	late := time.Date(2026, 10, 14, 23, 30, 0, 0, time.FixedZone("EST", -5*3600))
+	tests := []struct {
+		value string
+		now   time.Time
+		want  string
+	}{
+		{value: "today", now: now, want: "2026-10-14"},
+		{value: "TODAY", now: now, want: "2026-10-14"},
+		{value: "today", now: late, want: "2026-10-15"},
+		{value: "tomorrow", now: now, want: "2026-10-15"},
+		{value: "yesterday", now: now, want: "2026-10-13"},
+		{value: "0d", now: now, want: "2026-10-14"},
+		{value: "+3d", now: now, want: "2026-10-17"},
+		{value: "-1d", now: now, want: "2026-10-13"},
+		{value: "2w", now: now, want: "2026-10-28"},
+		{value: "-1m", now: now, want: "2026-09-14"},
+		{value: "1y", now: now, want: "2027-10-14"},
+		{value: "2026-02-28", now: now, want: "2026-02-28"},
+		{value: "2026-02-30", now: now},
+		{value: "26-01-01", now: now},
+		{value: "7x", now: now},
+		{value: "12345d", now: now},
+		{value: "", now: now},
+	}
+	for _, tt := range tests {
+		day, ok := ParseDay(tt.value, tt.now)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("ParseDay(%q) = %v; want no day", tt.value, day)
+			}
+			continue
+		}
+		if !ok || day.Format("2006-01-02") != tt.want || day.Location() != time.UTC || day.Hour() != 0 {
+			t.Errorf("ParseDay(%q) = %v, %v; want %s at midnight UTC", tt.value, day, ok, tt.want)
+		}
+	}
+}
+
+// format writes a syntax tree compactly: (AND ...), (OR ...), (NOT ...), field:a|b or field<op>date
+func format(node Node) string {
+	switch n := node.(type) {
+	case nil:
+		return ""
+	case *And:
+		return "(AND " + formatAll(n.Nodes) + ")"
+	case *Or:
+		return "(OR " + formatAll(n.Nodes) + ")"
+	case *Not:
+		return "(NOT " + format(n.Node) + ")"
+	case *Term:
+		if n.IsDate() {
+			return n.Field + n.Op + n.From.Format("2006-01-02")
+		}
+		return n.Field + ":" + strings.Join(n.Values, "|")
+	}
+	return "?"
+}
+
+func formatAll(nodes []Node) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = format(node)
+	}
+	return strings.Join(parts, " ")
+}
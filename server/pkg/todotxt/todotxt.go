@@ -0,0 +1,258 @@
+// Package todotxt reads and writes tasks in the todo.txt format.
+//
+// A task is one line:
+//
+//	x (A) 2024-01-12 2024-01-10 Call Ann +Work @phone due:2024-01-15
+//
+// An optional completion mark "x" and completion date, an optional priority (A) to (Z),
+// an optional creation date, and the description holding +project and @context words and
+// key:value tags. Completed tasks keep their priority as a pri:A tag, as is customary.
+//
+// Words of the description that would be read as markup, such as @bob, +1 or re:login, are
+// written with a leading backslash (\@bob) and read back as plain words. Project and context
+// names stand for names with spaces written as underscores; underscores and backslashes of the
+// name itself are escaped with a backslash.
+package todotxt
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DateLayout is the layout of todo.txt dates
+const DateLayout = "2006-01-02"
+
+// ErrEmptyTask is returned by Parse for blank lines
+var ErrEmptyTask = errors.New("empty todo.txt task")
+
+// Task is one todo.txt line
+type Task struct {
+	Completed      bool
+	Priority       byte // 'A' to 'Z', 0 when unset
+	CompletionDate *time.Time
+	CreationDate   *time.Time
+	// Text is the description without projects, contexts and tags
+	// Words that look like markup are escaped by String and unescaped by Parse
+	Text     string
+	Projects []string
+	Contexts []string
+	// Tags are key:value pairs, e.g. due:2024-01-15
+	Tags map[string]string
+}
+
+// Parse reads a task from a todo.txt line
+func Parse(line string) (Task, error) {
+	task := Task{Tags: map[string]string{}}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return task, ErrEmptyTask
+	}
+
+	// Completion mark and date
+	if fields[0] == "x" {
+		task.Completed = true
+		fields = fields[1:]
+		if len(fields) > 0 {
+			if date, ok := parseDate(fields[0]); ok {
+				task.CompletionDate = &date
+				fields = fields[1:]
+			}
+		}
+	}
+
+	// Priority
+	if len(fields) > 0 && isPriority(fields[0]) {
+		task.Priority = fields[0][1]
+		fields = fields[1:]
+	}
+
+	// Creation date, a completed task may also carry it without a completion date
+	if len(fields) > 0 {
+		if date, ok := parseDate(fields[0]); ok {
+			task.CreationDate = &date
+			fields = fields[1:]
+		}
+	}
+
+	var text []string
+	for _, field := range fields {
+		switch {
+		case len(field) > 1 && field[0] == '\\':
+			text = append(text, field[1:])
+		case len(field) > 1 && field[0] == '+':
+			task.Projects = append(task.Projects, field[1:])
+		case len(field) > 1 && field[0] == '@':
+			task.Contexts = append(task.Contexts, field[1:])
+		case isTag(field):
+			i := strings.IndexByte(field, ':')
+			task.Tags[field[:i]] = field[i+1:]
+		default:
+			text = append(text, field)
+		}
+	}
+	task.Text = strings.Join(text, " ")
+
+	// Completed tasks keep their priority as a tag
+	if pri, ok := task.Tags["pri"]; ok && task.Priority == 0 && isPriority("("+pri+")") {
+		task.Priority = pri[0]
+		delete(task.Tags, "pri")
+	}
+
+	if task.Text == "" && len(task.Projects) == 0 && len(task.Contexts) == 0 {
+		return task, errors.New("invalid todo.txt task: no description")
+	}
+	return task, nil
+}
+
+// String formats a task as a todo.txt line, tags in key order
+func (t Task) String() string {
+	var parts []string
+	if t.Completed {
+		parts = append(parts, "x")
+		if t.CompletionDate != nil {
+			parts = append(parts, t.CompletionDate.Format(DateLayout))
+		}
+	} else if t.Priority != 0 {
+		parts = append(parts, "("+string(t.Priority)+")")
+	}
+	if t.CreationDate != nil {
+		// A creation date needs a completion date before it to be recognized on completed tasks
+		if !t.Completed || t.CompletionDate != nil {
+			parts = append(parts, t.CreationDate.Format(DateLayout))
+		}
+	}
+
+	for i, word := range strings.Fields(t.Text) {
+		if isMarkup(word, i == 0) {
+			word = "\\" + word
+		}
+		parts = append(parts, word)
+	}
+	for _, project := range t.Projects {
+		parts = append(parts, "+"+project)
+	}
+	for _, context := range t.Contexts {
+		parts = append(parts, "@"+context)
+	}
+
+	tags := make(map[string]string, len(t.Tags)+1)
+	for key, value := range t.Tags {
+		tags[key] = value
+	}
+	if t.Completed && t.Priority != 0 {
+		tags["pri"] = string(t.Priority)
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, key+":"+tags[key])
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// ReadAll reads the tasks of a todo.txt file, skipping blank lines
+// Line numbers start at 1; a line that cannot be parsed is returned with its error
+func ReadAll(r io.Reader, fn func(lineNumber int, task Task, err error) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimPrefix(scanner.Text(), "\xef\xbb\xbf")
+		task, err := Parse(line)
+		if errors.Is(err, ErrEmptyTask) {
+			continue
+		}
+		if err := fn(lineNumber, task, err); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// nameEscaper escapes the characters of a name that have a meaning in a project or context
+var nameEscaper = strings.NewReplacer(`\`, `\\`, "_", `\_`)
+
+// Word returns a name usable as a project or context, with spaces replaced by underscores
+// Underscores and backslashes of the name are escaped, so Name returns the name unchanged
+func Word(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		words[i] = nameEscaper.Replace(word)
+	}
+	return strings.Join(words, "_")
+}
+
+// Name returns the name a project or context stands for, with underscores replaced by spaces
+// A backslash keeps the character after it, so \_ stands for an underscore
+func Name(word string) string {
+	var name strings.Builder
+	escaped := false
+	for _, r := range word {
+		switch {
+		case escaped:
+			name.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '_':
+			name.WriteByte(' ')
+		default:
+			name.WriteRune(r)
+		}
+	}
+	if escaped {
+		name.WriteByte('\\')
+	}
+	return name.String()
+}
+
+// parseDate parses a todo.txt date
+func parseDate(value string) (time.Time, bool) {
+	if len(value) != len(DateLayout) {
+		return time.Time{}, false
+	}
+	date, err := time.Parse(DateLayout, value)
+	return date, err == nil
+}
+
+// isPriority reports whether a word is a priority such as (A)
+func isPriority(word string) bool {
+	return len(word) == 3 && word[0] == '(' && word[2] == ')' && word[1] >= 'A' && word[1] <= 'Z'
+}
+
+// isMarkup reports whether a description word would not be read back as a plain word
+// The first word could also be taken for a completion mark, priority or date
+func isMarkup(word string, first bool) bool {
+	if len(word) > 1 && (word[0] == '\\' || word[0] == '+' || word[0] == '@') {
+		return true
+	}
+	if isTag(word) {
+		return true
+	}
+	if first {
+		_, isDate := parseDate(word)
+		return word == "x" || isPriority(word) || isDate
+	}
+	return false
+}
+
+// isTag reports whether a word is a key:value tag
+// Keys start with a letter, so times such as 12:30 and URLs such as https://example.com are not tags
+func isTag(word string) bool {
+	i := strings.IndexByte(word, ':')
+	if i <= 0 || i == len(word)-1 {
+		return false
+	}
+	first := word[0]
+	if !(first >= 'a' && first <= 'z' || first >= 'A' && first <= 'Z') {
+		return false
+	}
+	return !strings.HasPrefix(word[i+1:], "//") && !strings.ContainsAny(word[:i], "/+@")
+}
@@ -0,0 +1,264 @@
+package todotxt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(value string) *time.Time {
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    Task
+		wantErr bool
+	}{
+		{
+			line: "Call Ann",
+			want: Task{Text: "Call Ann"},
+		},
+		{
+			line: "x (A) 2024-01-12 2024-01-10 Call Ann +Work @phone due:2024-01-15",
+			// A priority after the completion mark leaves no place for the completion date
+			want: Task{
+				Completed: true, Priority: 'A', CreationDate: date("2024-01-12"),
+				Text:     "2024-01-10 Call Ann",
+				Projects: []string{"Work"}, Contexts: []string{"phone"},
+				Tags: map[string]string{"due": "2024-01-15"},
+			},
+		},
+		{
+			line: "x 2024-01-12 2024-01-10 Call Ann +Work pri:A",
+			want: Task{
+				Completed: true, Priority: 'A',
+				CompletionDate: date("2024-01-12"), CreationDate: date("2024-01-10"),
+				Text: "Call Ann", Projects: []string{"Work"},
+			},
+		},
+		{
+			line: "(B) 2024-01-10 Write report @desk @office +Q1 +Reports",
+			want: Task{
+				Priority: 'B', CreationDate: date("2024-01-10"),
+				Text:     "Write report",
+				Projects: []string{"Q1", "Reports"}, Contexts: []string{"desk", "office"},
+			},
+		},
+		{
+			line: "Meet at 12:30 see https://example.com/a:b for details",
+			want: Task{Text: "Meet at 12:30 see https://example.com/a:b for details"},
+		},
+		{
+			line: "Email \\@bob about \\+1 and \\re:login",
+			want: Task{Text: "Email @bob about +1 and re:login"},
+		},
+		{
+			line: "\\x marks \\(A) \\\\path",
+			want: Task{Text: "x marks (A) \\path"},
+		},
+		{
+			line: "  Plus + and at @ alone  ",
+			want: Task{Text: "Plus + and at @ alone"},
+		},
+		{
+			line: "+Work @home",
+			want: Task{Projects: []string{"Work"}, Contexts: []string{"home"}},
+		},
+		{line: "", wantErr: true},
+		{line: "x 2024-01-12", wantErr: true},
+		{line: "due:2024-01-15", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := Parse(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %+v; want an error", tt.line, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.line, err)
+			}
+			if tt.want.Tags == nil {
+				tt.want.Tags = map[string]string{}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) =\n%+v\nwant\n%+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	if _, err := Parse("   "); err != ErrEmptyTask {
+		t.Errorf("Parse of a blank line error = %v; want ErrEmptyTask", err)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		name string
+		task Task
+		want string
+	}{
+		{
+			name: "plain",
+			task: Task{Text: "Call Ann"},
+			want: "Call Ann",
+		},
+		{
+			name: "open with priority and dates",
+			task: Task{
+				Priority: 'A', CreationDate: date("2024-01-10"), Text: "Call Ann",
+				Projects: []string{"Work"}, Contexts: []string{"phone"},
+				Tags: map[string]string{"due": "2024-01-15", "area": "home"},
+			},
+			want: "(A) 2024-01-10 Call Ann +Work @phone area:home due:2024-01-15",
+		},
+		{
+			name: "completed keeps its priority as a tag",
+			task: Task{
+				Completed: true, Priority: 'B',
+				CompletionDate: date("2024-01-12"), CreationDate: date("2024-01-10"), Text: "Call Ann",
+			},
+			want: "x 2024-01-12 2024-01-10 Call Ann pri:B",
+		},
+		{
+			name: "completed without completion date drops the creation date",
+			task: Task{Completed: true, CreationDate: date("2024-01-10"), Text: "Call Ann"},
+			want: "x Call Ann",
+		},
+		{
+			name: "markup words are escaped",
+			task: Task{Text: "Email @bob about +1 and re:login due:x"},
+			want: "Email \\@bob about \\+1 and \\re:login \\due:x",
+		},
+		{
+			name: "leading completion mark, priority and date are escaped",
+			task: Task{Text: "x marks the spot"},
+			want: "\\x marks the spot",
+		},
+		{
+			name: "leading date",
+			task: Task{Text: "2024-01-09 meeting notes"},
+			want: "\\2024-01-09 meeting notes",
+		},
+		{
+			name: "later completion mark is not escaped",
+			task: Task{Text: "mark x (A) 2024-01-09"},
+			want: "mark x (A) 2024-01-09",
+		},
+		{
+			name: "backslashes and lone signs",
+			task: Task{Text: "\\path + @ 12:30 https://example.com"},
+			want: "\\\\path + @ 12:30 https://example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.task.String(); got != tt.want {
+				t.Errorf("String() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	texts := []string{
+		"Call Ann",
+		"Email @bob about +1",
+		"Fix re:login and due:tomorrow",
+		"x marks the spot",
+		"(A) is not a priority here",
+		"2024-01-09 is not a date here",
+		"\\\\server\\share and \\@ escaped",
+		"pri:A stays in the text",
+		"C++ and C#",
+		"https://example.com at 12:30",
+	}
+	for _, text := range texts {
+		for _, task := range []Task{
+			{Text: text},
+			{Priority: 'C', Text: text},
+			{CreationDate: date("2024-01-10"), Text: text},
+			{Completed: true, Text: text},
+			{Completed: true, Priority: 'A', CompletionDate: date("2024-01-12"), CreationDate: date("2024-01-10"), Text: text,
+				Projects: []string{"Work"}, Contexts: []string{"phone"}, Tags: map[string]string{"due": "2024-01-15"}},
+		} {
+			line := task.String()
+			got, err := Parse(line)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", line, err)
+			}
+			if task.Tags == nil {
+				task.Tags = map[string]string{}
+			}
+			if !reflect.DeepEqual(got, task) {
+				t.Errorf("round trip of %q through %q =\n%+v\nwant\n%+v", text, line, got, task)
+			}
+		}
+	}
+}
+
+func TestWordName(t *testing.T) {
+	tests := []struct {
+		name string
+		word string
+	}{
+		{name: "Work", word: "Work"},
+		{name: "Home Office", word: "Home_Office"},
+		{name: "my_list", word: "my\\_list"},
+		{name: "a _b", word: "a_\\_b"},
+		{name: "back\\slash", word: "back\\\\slash"},
+		{name: "C++", word: "C++"},
+	}
+	for _, tt := range tests {
+		if got := Word(tt.name); got != tt.word {
+			t.Errorf("Word(%q) = %q; want %q", tt.name, got, tt.word)
+		}
+		if got := Name(tt.word); got != tt.name {
+			t.Errorf("Name(%q) = %q; want %q", tt.word, got, tt.name)
+		}
+	}
+
+	// Spaces are collapsed, and words of other tools without escapes still read as before
+	if got := Word("  Home   Office "); got != "Home_Office" {
+		t.Errorf("Word with extra spaces = %q; want %q", got, "Home_Office")
+	}
+	if got := Name("trailing\\"); got != "trailing\\" {
+		t.Errorf("Name with a trailing backslash = %q; want %q", got, "trailing\\")
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	input := "\xef\xbb\xbfCall Ann +Work\n\n   \ndue:2024-01-15\n(B) Write report\n"
+	type line struct {
+		number int
+		text   string
+		err    bool
+	}
+	var got []line
+	err := ReadAll(strings.NewReader(input), func(lineNumber int, task Task, err error) error {
+		got = append(got, line{number: lineNumber, text: task.Text, err: err != nil})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	want := []line{
+		{number: 1, text: "Call Ann"},
+		{number: 4, err: true},
+		{number: 5, text: "Write report"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadAll lines = %+v; want %+v", got, want)
+	}
+}
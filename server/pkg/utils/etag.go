@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag formats a resource version as a strong entity tag
+func ETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// SetETag sets the ETag header of the response to the given resource version
+func SetETag(c *gin.Context, version uint) {
+	c.Header("ETag", ETag(version))
+}
+
+// IfMatchVersion returns the resource version required by the If-Match header
+// Returns 0 when the header is missing or "*", which matches any version
+func IfMatchVersion(c *gin.Context) (uint, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	if strings.HasPrefix(header, "W/") {
+		return 0, errors.New("invalid If-Match header: weak entity tags cannot be used")
+	}
+	if strings.Contains(header, ",") {
+		return 0, errors.New("invalid If-Match header: only one entity tag is supported")
+	}
+
+	version, err := strconv.ParseUint(strings.Trim(header, `"`), 10, 32)
+	if err != nil || version == 0 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		return 0, errors.New("invalid If-Match header: expected an entity tag such as \"3\"")
+	}
+	return uint(version), nil
+}
+
+// NotModified answers 304 Not Modified when the If-None-Match header matches the resource version
+// Returns true when the response has been sent
+func NotModified(c *gin.Context, version uint) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	etag := ETag(version)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			SetETag(c, version)
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// PreconditionFailedResponse sends a precondition failed response with the current state of the resource
+func PreconditionFailedResponse(c *gin.Context, message string, current interface{}) {
+	c.JSON(http.StatusPreconditionFailed, APIResponse{
+		Success: false,
+		Message: message,
+		Data:    current,
+		Error:   message,
+	})
+}
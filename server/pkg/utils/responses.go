@@ -70,4 +70,9 @@ func InternalServerErrorResponse(c *gin.Context, err error) {
 // ConflictErrorResponse sends a conflict error response
 func ConflictErrorResponse(c *gin.Context, message string) {
 	ErrorResponse(c, http.StatusConflict, message)
+}
+
+// ForbiddenErrorResponse sends a forbidden error response
+func ForbiddenErrorResponse(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusForbidden, message)
 }
\ No newline at end of file
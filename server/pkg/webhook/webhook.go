@@ -0,0 +1,155 @@
+// Package webhook signs and sends webhook requests.
+//
+// Every request is a JSON POST carrying these headers:
+//
+//	X-Webhook-Event:     the event type, e.g. todo.created
+//	X-Webhook-Delivery:  the delivery ID, stable across retries of the same delivery
+//	X-Webhook-Timestamp: the Unix time the request was signed
+//	X-Webhook-Signature: sha256=<hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret>
+//
+// Receivers verify the signature with Verify, which also rejects stale timestamps.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request headers
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// signaturePrefix names the algorithm of a signature
+const signaturePrefix = "sha256="
+
+// maxResponseBody limits how much of a receiver response is kept for the delivery log
+const maxResponseBody = 4096
+
+// ErrInvalidSignature is returned by Verify when a signature does not match
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign returns the signature header value for a body signed at the given Unix time
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and timestamp headers of a received request
+// Timestamps further than tolerance from now are rejected, a zero tolerance skips the check
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid webhook timestamp: %w", err)
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return errors.New("invalid webhook timestamp: outside the allowed tolerance")
+		}
+	}
+
+	expected := Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(HeaderSignature))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Message is a webhook request to send
+type Message struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Body       []byte
+}
+
+// Result describes the response of a receiver
+type Result struct {
+	StatusCode int
+	Body       string
+}
+
+// Sender posts signed webhook requests
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender creates a sender whose requests time out after the given duration
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{
+		client: &http.Client{
+			Timeout: timeout,
+			// Redirects are not followed, a receiver must answer at the registered URL
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send posts a message and returns the response of the receiver
+// Any status other than 2xx is returned as an error together with the result
+func (s *Sender) Send(ctx context.Context, message Message) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, message.URL, bytes.NewReader(message.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-backend-webhooks/1.0")
+	req.Header.Set(HeaderEvent, message.Event)
+	req.Header.Set(HeaderDelivery, message.DeliveryID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(message.Secret, timestamp, message.Body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	result := &Result{
+		StatusCode: resp.StatusCode,
+		Body:       strings.ReplaceAll(strings.ToValidUTF8(string(body), ""), "\x00", ""),
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return result, fmt.Errorf("receiver answered %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return result, nil
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed attempts
+// The delay doubles with every attempt, starting at base and capped at max
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// Computed with: printf '%s' '1700000000.{"id":1}' | openssl dgst -sha256 -hmac secret
+	got := Sign("secret", 1700000000, []byte(`{"id":1}`))
+	want := "sha256=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11"
+	if got != want {
+		t.Fatalf("Sign = %q; want %q", got, want)
+	}
+	if Sign("other", 1700000000, []byte(`{"id":1}`)) == got {
+		t.Error("Sign does not depend on the secret")
+	}
+	if Sign("secret", 1700000001, []byte(`{"id":1}`)) == got {
+		t.Error("Sign does not depend on the timestamp")
+	}
+	if Sign("secret", 1700000000, []byte(`{"id":2}`)) == got {
+		t.Error("Sign does not depend on the body")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"type":"todo.created"}`)
+	now := time.Now().Unix()
+	header := func(timestamp string, signature string) http.Header {
+		h := http.Header{}
+		h.Set(HeaderTimestamp, timestamp)
+		h.Set(HeaderSignature, signature)
+		return h
+	}
+	valid := Sign("secret", now, body)
+
+	tests := []struct {
+		name      string
+		header    http.Header
+		body      []byte
+		tolerance time.Duration
+		wantErr   bool
+	}{
+		{name: "valid", header: header(strconv.FormatInt(now, 10), valid), body: body, tolerance: time.Minute},
+		{name: "wrong secret", header: header(strconv.FormatInt(now, 10), Sign("other", now, body)), body: body, tolerance: time.Minute, wantErr: true},
+		{name: "changed body", header: header(strconv.FormatInt(now, 10), valid), body: []byte(`{"type":"todo.deleted"}`), tolerance: time.Minute, wantErr: true},
+		{name: "changed timestamp", header: header(strconv.FormatInt(now+1, 10), valid), body: body, tolerance: time.Minute, wantErr: true},
+		{name: "missing signature", header: header(strconv.FormatInt(now, 10), ""), body: body, tolerance: time.Minute, wantErr: true},
+		{name: "missing timestamp", header: header("", valid), body: body, tolerance: time.Minute, wantErr: true},
+		{name: "stale", header: header(strconv.FormatInt(now-600, 10), Sign("secret", now-600, body)), body: body, tolerance: time.Minute, wantErr: true},
+		{name: "future", header: header(strconv.FormatInt(now+600, 10), Sign("secret", now+600, body)), body: body, tolerance: time.Minute, wantErr: true},
+		{name: "stale without tolerance", header: header(strconv.FormatInt(now-600, 10), Sign("secret", now-600, body)), body: body},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify("secret", tt.header, tt.body, tt.tolerance)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify error = %v; want error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := Verify("secret", header(strconv.FormatInt(now, 10), Sign("other", now, body)), body, 0); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify with a wrong signature error = %v; want ErrInvalidSignature", err)
+	}
+}
+
+func TestSenderSend(t *testing.T) {
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		if err := Verify("s3cr3t", r.Header, receivedBody, time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	message := Message{
+		URL:        server.URL + "/hook",
+		Secret:     "s3cr3t",
+		Event:      "todo.created",
+		DeliveryID: "42",
+		Body:       []byte(`{"type":"todo.created","data":{"id":1}}`),
+	}
+	result, err := NewSender(time.Second).Send(context.Background(), message)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.StatusCode != http.StatusOK || result.Body != "ok" {
+		t.Errorf("Send result = %+v; want 200 ok", result)
+	}
+
+	if received.Method != http.MethodPost || received.URL.Path != "/hook" {
+		t.Errorf("request = %s %s; want POST /hook", received.Method, received.URL.Path)
+	}
+	if string(receivedBody) != string(message.Body) {
+		t.Errorf("body = %s; want %s", receivedBody, message.Body)
+	}
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		HeaderEvent:     "todo.created",
+		HeaderDelivery:  "42",
+		HeaderSignature: Sign("s3cr3t", mustParseInt(t, received.Header.Get(HeaderTimestamp)), message.Body),
+	}
+	for name, want := range headers {
+		if got := received.Header.Get(name); got != want {
+			t.Errorf("header %s = %q; want %q", name, got, want)
+		}
+	}
+}
+
+func TestSenderSendErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "server error",
+			handler:    func(w http.ResponseWriter, r *http.Request) { http.Error(w, "boom", http.StatusInternalServerError) },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "boom\n",
+		},
+		{
+			name:       "redirect is not followed",
+			handler:    func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/elsewhere", http.StatusFound) },
+			wantStatus: http.StatusFound,
+		},
+		{
+			name: "long and invalid body is cleaned",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("a\x00b\xff"))
+				w.Write([]byte(strings.Repeat("x", 2*maxResponseBody)))
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "ab" + strings.Repeat("x", maxResponseBody-4),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			result, err := NewSender(time.Second).Send(context.Background(), Message{URL: server.URL, Body: []byte("{}")})
+			if err == nil || !strings.Contains(err.Error(), strconv.Itoa(tt.wantStatus)) {
+				t.Fatalf("Send error = %v; want a %d error", err, tt.wantStatus)
+			}
+			if result == nil || result.StatusCode != tt.wantStatus {
+				t.Fatalf("Send result = %+v; want status %d", result, tt.wantStatus)
+			}
+			if tt.wantBody != "" && result.Body != tt.wantBody {
+				t.Errorf("Send result body = %q; want %q", result.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSenderSendTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	result, err := NewSender(50*time.Millisecond).Send(context.Background(), Message{URL: server.URL})
+	if err == nil || result != nil {
+		t.Fatalf("Send = %+v, %v; want a timeout error without result", result, err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: 0},
+		{attempts: 1, want: time.Minute},
+		{attempts: 2, want: 2 * time.Minute},
+		{attempts: 3, want: 4 * time.Minute},
+		{attempts: 5, want: 16 * time.Minute},
+		{attempts: 6, want: 30 * time.Minute},
+		{attempts: 100, want: 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts, time.Minute, 30*time.Minute); got != tt.want {
+			t.Errorf("Backoff(%d) = %v; want %v", tt.attempts, got, tt.want)
+		}
+	}
+	if got := Backoff(3, time.Hour, time.Minute); got != time.Minute {
+		t.Errorf("Backoff with base above max = %v; want %v", got, time.Minute)
+	}
+}
+
+func mustParseInt(t *testing.T, s string) int64 {
+	t.Helper()
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		t.Fatalf("invalid integer %q: %v", s, err)
+	}
+	return n
+}